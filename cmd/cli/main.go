@@ -19,7 +19,7 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	conf, err := config.Get()
 	if err != nil {
-		log.Fatalf("Load conf error: %w", err)
+		log.Fatalf("Load conf error: %v", err)
 	}
 	cliApp := cli.New(ctx, conf.App.Name, app.New(ctx, conf), conf.API, conf.Cli)
 
@@ -28,14 +28,14 @@ func main() {
 	go func() {
 		defer wg.Done()
 		if err = cliApp.Run(); err != nil {
-			log.Fatalf("cliApp.Run() error: %w", err)
+			log.Fatalf("cliApp.Run() error: %v", err)
 			os.Exit(1)
 		}
 	}()
 
 	defer func() {
 		if err = cliApp.Stop(); err != nil {
-			log.Printf("cliApp.Stop() error: %w", err)
+			log.Printf("cliApp.Stop() error: %v", err)
 		}
 	}()
 
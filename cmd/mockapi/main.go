@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"go.uber.org/zap"
+
+	"info/internal/app/mockapi"
+)
+
+// failureFlags collects the repeated -fail flags
+type failureFlags []mockapi.Failure
+
+func (f *failureFlags) String() string {
+	res := make([]string, 0, len(*f))
+	for _, item := range *f {
+		res = append(res, item.Mode+"@"+item.Route)
+	}
+	return strings.Join(res, ";")
+}
+
+func (f *failureFlags) Set(s string) error {
+	item, err := mockapi.ParseFailure(s)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, item)
+	return nil
+}
+
+func main() {
+	cfg := &mockapi.Config{}
+	var failures failureFlags
+	flag.StringVar(&cfg.Addr, "addr", ":8090", "address to listen")
+	flag.StringVar(&cfg.FixturesDir, "fixtures", "", "directory of the fixtures: <dir>/<route>/<key>.json or <dir>/<route>/default.json; without a fixture the response is generated")
+	flag.Int64Var(&cfg.Seed, "seed", 1, "seed of the generator")
+	flag.Var(&failures, "fail", "failure to inject, repeatable: mode=status|error_code|malformed|slow[,route=detail_chart|analytics|currency_simple|portfolio_summary|pro_quotes|holders_stats][,rate=0.5][,status=503][,delay=5s]")
+	flag.Parse()
+	cfg.Failures = failures
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		log.Fatalf("zap.NewDevelopment error: %v", err)
+	}
+	defer logger.Sync()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if err = mockapi.New(cfg, logger).Run(ctx); err != nil {
+		logger.Fatal("mockapi.Run error", zap.Error(err))
+	}
+}
@@ -21,20 +21,20 @@ func main() {
 
 	conf, err := config.Get()
 	if err != nil {
-		log.Fatalf("Load conf error: %w", err)
+		log.Fatalf("Load conf error: %v", err)
 	}
 	restAPI := restapi.New(app.New(ctx, conf), conf.App, conf.API)
 
 	go func() {
 		if err = restAPI.Run(ctx); err != nil {
-			log.Fatalf("restAPI.Run(ctx) error: %w", err)
+			log.Fatalf("restAPI.Run(ctx) error: %v", err)
 			os.Exit(1)
 		}
 	}()
 
 	defer func() {
 		if err = restAPI.Stop(); err != nil {
-			log.Printf("restAPI.Stop() error: %w", err)
+			log.Printf("restAPI.Stop() error: %v", err)
 		}
 	}()
 
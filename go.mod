@@ -3,9 +3,11 @@ module info
 go 1.20
 
 require (
+	github.com/go-ozzo/ozzo-validation/v4 v4.3.0
 	github.com/golang/protobuf v1.5.3
 	github.com/jackc/pgx/v5 v5.6.0
 	github.com/minipkg/db v0.0.16-0.20240721141401-3f9bf98defe7
+	github.com/minipkg/httpclient v0.0.0-20231106153628-7be075ba2467
 	github.com/minipkg/prometheus-utils v0.0.0-20240617141339-13957188343f
 	github.com/minipkg/selection_condition v0.0.5
 	github.com/pressly/goose/v3 v3.21.1
@@ -26,7 +28,6 @@ require (
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
 	github.com/fsnotify/fsnotify v1.6.0 // indirect
 	github.com/go-ozzo/ozzo-validation v3.6.0+incompatible // indirect
-	github.com/go-ozzo/ozzo-validation/v4 v4.3.0 // indirect
 	github.com/go-redis/cache/v9 v9.0.0 // indirect
 	github.com/hashicorp/hcl v1.0.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
@@ -37,7 +38,6 @@ require (
 	github.com/magiconair/properties v1.8.7 // indirect
 	github.com/matttproud/golang_protobuf_extensions v1.0.1 // indirect
 	github.com/mfridman/interpolate v0.0.2 // indirect
-	github.com/minipkg/httpclient v0.0.0-20231106153628-7be075ba2467 // indirect
 	github.com/mitchellh/mapstructure v1.5.0 // indirect
 	github.com/pelletier/go-toml/v2 v2.1.0 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
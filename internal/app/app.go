@@ -4,14 +4,24 @@ import (
 	"context"
 	"errors"
 	"info/internal/domain/concentration"
+	"info/internal/domain/currency_snapshot"
+	"info/internal/domain/global_metrics"
+	"info/internal/domain/import_run"
+	"info/internal/domain/indicator"
+	"info/internal/domain/market_pair"
 	"info/internal/domain/oracul_analytics"
 	"info/internal/domain/oracul_daily_balance_stats"
 	"info/internal/domain/oracul_holder_stats"
 	"info/internal/domain/oracul_speedometers"
 	"info/internal/domain/portfolio_item"
 	"info/internal/domain/price_and_cap"
+	"info/internal/domain/price_divergence"
+	"info/internal/domain/raw_response"
+	"info/internal/domain/universe"
+	"info/internal/domain/whale_momentum"
 	"info/internal/integration"
 	"info/internal/pkg/config"
+	"info/internal/pkg/workerpool"
 	"log"
 
 	"info/internal/domain/currency"
@@ -20,15 +30,26 @@ import (
 )
 
 type App struct {
-	config      *config.AppConfig
-	Infra       *infrastructure.Infrastructure
-	Integration *integration.Integration
-	Domain      *Domain
+	config       *config.AppConfig
+	domainConfig config.DomainConfig
+	workerPool   *workerpool.Pool
+	Infra        *infrastructure.Infrastructure
+	Integration  *integration.Integration
+	Domain       *Domain
 }
 
 type Domain struct {
 	Currency                *currency.Service
+	CurrencySnapshot        *currency_snapshot.Service
+	GlobalMetrics           *global_metrics.Service
+	ImportRun               *import_run.Service
+	Indicator               *indicator.Service
+	MarketPair              *market_pair.Service
 	PriceAndCap             *price_and_cap.Service
+	PriceDivergence         *price_divergence.Service
+	RawResponse             *raw_response.Service
+	Universe                *universe.Service
+	WhaleMomentum           *whale_momentum.Service
 	Concentration           *concentration.Service
 	PortfolioItem           *portfolio_item.Service
 	OraculAnalytics         *oracul_analytics.Service
@@ -60,27 +81,86 @@ func New(ctx context.Context, cfg *config.Configuration) *App {
 	log.Println("done")
 
 	app := &App{
-		config:      cfg.App,
-		Infra:       infr,
-		Integration: integr,
+		config:       cfg.App,
+		domainConfig: cfg.Domain,
+		workerPool:   workerpool.New(cfg.WorkerPool, workerpool.NewMetrics(cfg.App.NameSpace, cfg.App.Name, cfg.App.Service)),
+		Infra:        infr,
+		Integration:  integr,
 	}
 
 	app.SetupServices()
 
+	if cfg.Integration.IsArchiveEnabled {
+		app.Integration.SetArchiver(app.Domain.RawResponse)
+	}
+
 	return app
 }
 
 func (app *App) SetupServices() {
 	app.Domain = &Domain{
-		PriceAndCap:             price_and_cap.NewService(tsdb_cluster.NewPriceAndCapReplicaSet(app.Infra.TsDB), app.Integration.CmcAPI),
+		PriceAndCap:             price_and_cap.NewService(tsdb_cluster.NewPriceAndCapReplicaSet(app.Infra.TsDB), app.domainConfig.PriceAndCap, app.priceAndCapSources()),
 		Concentration:           concentration.NewService(tsdb_cluster.NewConcentrationReplicaSet(app.Infra.TsDB), app.Integration.CmcAPI),
 		PortfolioItem:           portfolio_item.NewService(tsdb_cluster.NewPortfolioItemReplicaSet(app.Infra.TsDB), app.Integration.CmcAPI),
 		OraculDailyBalanceStats: oracul_daily_balance_stats.NewService(tsdb_cluster.NewOraculDailyBalanceStatsReplicaSet(app.Infra.TsDB)),
 		OraculHolderStats:       oracul_holder_stats.NewService(tsdb_cluster.NewOraculHolderStatsReplicaSet(app.Infra.TsDB)),
 		OraculSpeedometers:      oracul_speedometers.NewService(tsdb_cluster.NewOraculSpeedometersReplicaSet(app.Infra.TsDB)),
+		ImportRun:               import_run.NewService(tsdb_cluster.NewImportRunReplicaSet(app.Infra.TsDB)),
+		RawResponse:             raw_response.NewService(tsdb_cluster.NewRawResponseReplicaSet(app.Infra.TsDB)),
+		CurrencySnapshot:        currency_snapshot.NewService(tsdb_cluster.NewCurrencySnapshotReplicaSet(app.Infra.TsDB)),
+		GlobalMetrics:           global_metrics.NewService(tsdb_cluster.NewGlobalMetricsReplicaSet(app.Infra.TsDB), app.globalMetricsApi()),
+		MarketPair:              market_pair.NewService(tsdb_cluster.NewMarketPairReplicaSet(app.Infra.TsDB), app.domainConfig.MarketPair, app.marketPairsApi(), app.workerPool),
+	}
+	app.Domain.PriceDivergence = price_divergence.NewService(tsdb_cluster.NewPriceDivergenceReplicaSet(app.Infra.TsDB), app.domainConfig.PriceDivergence, app.Domain.PriceAndCap, app.priceDivergenceReference())
+	app.Domain.OraculAnalytics = oracul_analytics.NewService(tsdb_cluster.NewOraculAnalyticsReplicaSet(app.Infra.TsDB), app.Integration.OraculAnalyticsAPI, app.Domain.OraculSpeedometers, app.Domain.OraculHolderStats, app.Domain.OraculDailyBalanceStats, app.workerPool)
+	app.Domain.Currency = currency.NewService(tsdb_cluster.NewCurrencyReplicaSet(app.Infra.TsDB), app.Domain.PriceAndCap, app.Domain.Concentration, app.Domain.OraculAnalytics, app.Domain.OraculDailyBalanceStats, app.Domain.CurrencySnapshot, app.Integration.CmcAPI, app.Integration.CmcProAPI, app.workerPool)
+	app.Domain.Universe = universe.NewService(tsdb_cluster.NewUniverseChangeReplicaSet(app.Infra.TsDB), app.domainConfig.Universe, app.Domain.Currency, app.universeListingsApi())
+	app.Domain.WhaleMomentum = whale_momentum.NewService(app.Domain.Currency, app.Domain.Concentration)
+	app.Domain.Indicator = indicator.NewService(app.Domain.PriceAndCap)
+}
+
+// priceAndCapSources returns the configured sources of price_and_cap by their names
+func (app *App) priceAndCapSources() map[string]price_and_cap.Source {
+	res := make(map[string]price_and_cap.Source, len(price_and_cap.DefaultSourceList))
+	if app.Integration.CmcAPI != nil {
+		res[price_and_cap.Source_Cmc] = app.Integration.CmcAPI
+	}
+	if app.Integration.BinanceAPI != nil {
+		res[price_and_cap.Source_Binance] = app.Integration.BinanceAPI
+	}
+	return res
+}
+
+// priceDivergenceReference returns the configured reference source of the reconciliation or nil
+func (app *App) priceDivergenceReference() price_divergence.ReferenceSource {
+	if app.Integration.CoingeckoAPI == nil {
+		return nil
+	}
+	return app.Integration.CoingeckoAPI
+}
+
+// universeListingsApi returns the listings API of the universe discovery or nil
+func (app *App) universeListingsApi() universe.ListingsApi {
+	if app.Integration.CmcProAPI == nil {
+		return nil
+	}
+	return app.Integration.CmcProAPI
+}
+
+// globalMetricsApi returns the global metrics API or nil
+func (app *App) globalMetricsApi() global_metrics.GlobalMetricsApi {
+	if app.Integration.CmcProAPI == nil {
+		return nil
+	}
+	return app.Integration.CmcProAPI
+}
+
+// marketPairsApi returns the market pairs API of the liquidity import or nil
+func (app *App) marketPairsApi() market_pair.MarketPairsApi {
+	if app.Integration.CmcAPI == nil {
+		return nil
 	}
-	app.Domain.OraculAnalytics = oracul_analytics.NewService(tsdb_cluster.NewOraculAnalyticsReplicaSet(app.Infra.TsDB), app.Integration.OraculAnalyticsAPI, app.Domain.OraculSpeedometers, app.Domain.OraculHolderStats, app.Domain.OraculDailyBalanceStats)
-	app.Domain.Currency = currency.NewService(tsdb_cluster.NewCurrencyReplicaSet(app.Infra.TsDB), app.Domain.PriceAndCap, app.Domain.Concentration, app.Domain.OraculAnalytics, app.Integration.CmcAPI, app.Integration.CmcProAPI)
+	return app.Integration.CmcAPI
 }
 
 func (app *App) Run() error {
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"info/internal/domain"
+	"info/internal/domain/currency"
+)
+
+const (
+	flagName_Currencies = "currencies"
+	flagName_From       = "from"
+	flagName_To         = "to"
+	flagName_Datasets   = "datasets"
+)
+
+// backfill ...
+var backfill = &cobra.Command{
+	Use:   "backfill",
+	Short: "It is the backfill command.",
+	Long: `It is the backfill command: reloads the datasets of the currencies for the window and upserts only the rows inside the window.
+Example: backfill --currencies bitcoin,1027 --from 2025-01-01 --to 2025-01-31 --datasets price_and_cap,concentration,oracul`,
+	Run: func(cmd *cobra.Command, args []string) {
+		CliApp.backfill(cmd, args)
+	},
+}
+
+func init() {
+	backfill.Flags().StringSlice(flagName_Currencies, nil, "slugs or IDs of the currencies")
+	backfill.Flags().String(flagName_From, "", "the first day of the window, YYYY-MM-DD")
+	backfill.Flags().String(flagName_To, "", "the last day of the window, YYYY-MM-DD; today by default")
+	backfill.Flags().StringSlice(flagName_Datasets, []string{currency.Dataset_PriceAndCap, currency.Dataset_Concentration, currency.Dataset_Oracul}, "datasets to load: price_and_cap, concentration, oracul")
+	backfill.MarkFlagRequired(flagName_Currencies)
+	backfill.MarkFlagRequired(flagName_From)
+}
+
+func (app *App) backfill(cmd *cobra.Command, args []string) {
+	currencies, err := cmd.Flags().GetStringSlice(flagName_Currencies)
+	if err != nil {
+		app.logger.Error("backfill: flag parse error", zap.String("flag", flagName_Currencies), zap.Error(err))
+		return
+	}
+	datasets, err := cmd.Flags().GetStringSlice(flagName_Datasets)
+	if err != nil {
+		app.logger.Error("backfill: flag parse error", zap.String("flag", flagName_Datasets), zap.Error(err))
+		return
+	}
+	from, err := app.backfillParseDate(cmd, flagName_From, time.Time{})
+	if err != nil {
+		app.logger.Error("backfill: flag parse error", zap.String("flag", flagName_From), zap.Error(err))
+		return
+	}
+	to, err := app.backfillParseDate(cmd, flagName_To, time.Now().UTC().Truncate(time.Hour*24))
+	if err != nil {
+		app.logger.Error("backfill: flag parse error", zap.String("flag", flagName_To), zap.Error(err))
+		return
+	}
+	// the last day is included in the window
+	to = to.Add(time.Hour * 24)
+
+	slugs := make([]string, 0, len(currencies))
+	IDs := make([]uint, 0, len(currencies))
+	var item string
+	for _, item = range currencies {
+		if ID, err := strconv.ParseUint(item, 10, 64); err == nil {
+			IDs = append(IDs, uint(ID))
+			continue
+		}
+		slugs = append(slugs, item)
+	}
+
+	currencyList, err := app.Domain.Currency.GetBySlugsAndIDs(app.ctx, &slugs, &IDs)
+	if err != nil {
+		app.logger.Error("backfill: currencies are not found", zap.Strings(flagName_Currencies, currencies), zap.Error(err))
+		return
+	}
+
+	app.logger.Info("backfill: starts...")
+	var res *currency.BackfillResultList
+	err = execLocked(app.ctx, app.newStageLocker(), datasetStageNames(datasets), func(ctx context.Context) (err error) {
+		res, err = app.Domain.Currency.Backfill(ctx, currencyList, from, to, &datasets)
+		if slices.Contains(datasets, currency.Dataset_PriceAndCap) {
+			// the refresh policies of the candles do not look so far back
+			if err := app.Domain.PriceAndCap.RefreshCandles(ctx, from, to); err != nil {
+				app.logger.Error("backfill: the candles are not refreshed", zap.Error(err))
+			}
+		}
+		return err
+	})
+	if res != nil {
+		printBackfillSummary(res)
+	}
+	if err != nil {
+		app.logger.Info("backfill: completed with errors!", zap.Error(err))
+		return
+	}
+	app.logger.Info("backfill: completed successfully!")
+}
+
+func (app *App) backfillParseDate(cmd *cobra.Command, flagName string, defaultValue time.Time) (time.Time, error) {
+	val, err := cmd.Flags().GetString(flagName)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if val == "" {
+		return defaultValue, nil
+	}
+	return time.Parse(time.DateOnly, val)
+}
+
+func printBackfillSummary(res *currency.BackfillResultList) {
+	total := make(map[string]*domain.UpsertStats)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CURRENCY\tSYMBOL\tDATASET\tINSERTED\tUPDATED\tERROR")
+
+	var item currency.BackfillResult
+	for _, item = range *res {
+		errText := ""
+		if item.Err != nil {
+			errText = item.Err.Error()
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%s\n", item.CurrencyID, item.Symbol, item.Dataset, item.Inserted, item.Updated, errText)
+		if _, ok := total[item.Dataset]; !ok {
+			total[item.Dataset] = &domain.UpsertStats{}
+		}
+		total[item.Dataset].Add(&item.UpsertStats)
+	}
+	var dataset interface{}
+	for _, dataset = range currency.DatasetList {
+		if stats, ok := total[dataset.(string)]; ok {
+			fmt.Fprintf(w, "total\t\t%s\t%d\t%d\t\n", dataset, stats.Inserted, stats.Updated)
+		}
+	}
+	w.Flush()
+}
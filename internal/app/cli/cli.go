@@ -9,7 +9,10 @@ import (
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
 	"info/internal/pkg/config"
+	"info/internal/pkg/fasthttp_tools"
+	"info/internal/pkg/log_key"
 	"net/http"
+	"sync/atomic"
 
 	"info/internal/app"
 )
@@ -29,6 +32,7 @@ type App struct {
 	rootCmd       *cobra.Command
 	serverMetrics *fasthttp.Server
 	serverProbes  *fasthttp.Server
+	scheduler     atomic.Pointer[scheduler]
 }
 
 var CliApp *App
@@ -68,6 +72,12 @@ func New(ctx context.Context, appName string, coreApp *app.App, apicfg *config.A
 func (app *App) init() {
 	app.rootCmd.AddCommand(
 		currencyCollector,
+		oraculCollector,
+		backfill,
+		reparse,
+		reconcile,
+		discover,
+		globalMetrics,
 	)
 	app.buildHandler()
 }
@@ -76,6 +86,7 @@ func (a *App) buildHandler() {
 	rp := routing.New()
 	rp.Get("/live", LiveHandler)
 	rp.Get("/ready", LiveHandler)
+	rp.Get("/schedule", a.ScheduleHandler)
 	a.serverProbes.Handler = rp.HandleRequest
 
 	rm := routing.New()
@@ -117,3 +128,17 @@ func LiveHandler(rctx *routing.Context) error {
 	rctx.SetStatusCode(http.StatusNoContent)
 	return nil
 }
+
+// ScheduleHandler returns last-run and next-run timestamps of the scheduled stages
+func (a *App) ScheduleHandler(rctx *routing.Context) error {
+	sch := a.scheduler.Load()
+	if sch == nil {
+		rctx.SetStatusCode(http.StatusNoContent)
+		return nil
+	}
+	rctx.Response.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if err := fasthttp_tools.FastHTTPWriteResult(rctx.RequestCtx, http.StatusOK, *fasthttp_tools.NewResponse_Success(sch.States())); err != nil {
+		a.logger.Error("fasthttp_tools.FastHTTPWriteResult error", zap.String(log_key.Func, "cli.App.ScheduleHandler"), zap.Error(err))
+	}
+	return nil
+}
@@ -4,45 +4,143 @@ import (
 	"context"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+	"info/internal/infrastructure/repository/redis"
 	"info/internal/pkg/config"
+	"time"
+)
+
+const (
+	flagName_Daemon = "daemon"
 )
 
 // currencyCollector ...
 var currencyCollector = &cobra.Command{
 	Use:   "currency-collector",
 	Short: "It is the currency-collector command.",
-	Long:  `It is the currency-collector command: consumer for collecting currencies.`,
+	Long:  `It is the currency-collector command: consumer for collecting currencies. With the --daemon flag it keeps running and executes every stage by its own schedule.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		CliApp.currencyCollector(cmd, args)
 	},
 }
 
+func init() {
+	currencyCollector.Flags().Bool(flagName_Daemon, false, "keep running and execute the stages by the schedule until the app is stopped")
+}
+
 func (app *App) currencyCollector(cmd *cobra.Command, args []string) {
 	cfg := app.config.CurrencyCollector
 
-	//go func() {
-	//	time.Sleep(time.Duration(rand.Int63n(int64(time.Now().Second()%30)+1)) * time.Second)
-	app.currencyCollector_Exec(app.ctx, cfg)
-	//	ticker := time.NewTicker(cfg.Duration)
-	//	defer ticker.Stop()
-	//
-	//	for {
-	//		select {
-	//		case <-app.ctx.Done():
-	//			return
-	//		case <-ticker.C:
-	//			app.currencyCollector_Exec(app.ctx, cfg)
-	//		}
-	//	}
-	//}()
-
-	return
+	isDaemon, err := cmd.Flags().GetBool(flagName_Daemon)
+	if err != nil {
+		app.logger.Error("currency-collector: flag parse error", zap.String("flag", flagName_Daemon), zap.Error(err))
+		return
+	}
+
+	locker := redis.NewLockRepository(app.Infra.Redis.WriteRepo(), cfg.LockTTL)
+
+	if !isDaemon {
+		app.currencyCollector_Exec(app.ctx, cfg, locker)
+		return
+	}
+
+	sch := app.currencyCollectorScheduler(cfg, locker)
+	app.scheduler.Store(sch)
+	app.logger.Info("currency-collector: daemon mode is started")
+	sch.Run(app.ctx)
+	app.logger.Info("currency-collector: daemon mode is stopped")
+}
+
+func (app *App) currencyCollectorScheduler(cfg *config.CurrencyCollector, locker stageLocker) *scheduler {
+	schedule := cfg.Schedule
+	if schedule == nil {
+		schedule = &config.CurrencyCollectorSchedule{}
+	}
+	interval := func(d time.Duration) time.Duration {
+		if d == 0 {
+			return cfg.Duration
+		}
+		return d
+	}
+
+	return newScheduler(app.logger, locker).
+		Add(StageName_Currency, interval(schedule.Currency), app.withImportRun(StageName_Currency, func(ctx context.Context) error {
+			slugs, err := app.currencyCollectorSlugs(ctx, cfg)
+			if err != nil {
+				return err
+			}
+			_, err = app.Domain.Currency.ImportCurrencies(ctx, slugs)
+			return err
+		})).
+		Add(StageName_PriceAndCap, interval(schedule.PriceAndCap), app.withImportRun(StageName_PriceAndCap, func(ctx context.Context) error {
+			slugs, err := app.currencyCollectorSlugs(ctx, cfg)
+			if err != nil {
+				return err
+			}
+			return app.Domain.Currency.ImportPriceAndCap(ctx, slugs)
+		})).
+		Add(StageName_Concentration, interval(schedule.Concentration), app.withImportRun(StageName_Concentration, func(ctx context.Context) error {
+			slugs, err := app.currencyCollectorSlugs(ctx, cfg)
+			if err != nil {
+				return err
+			}
+			return app.Domain.Currency.ImportConcentration(ctx, slugs)
+		})).
+		Add(StageName_Portfolio, interval(schedule.Portfolio), app.withImportRun(StageName_Portfolio, func(ctx context.Context) error {
+			return app.Domain.PortfolioItem.Import(ctx, &cfg.PortfolioSourceIDs)
+		})).
+		Add(StageName_Oracul, interval(schedule.Oracul), app.withImportRun(StageName_Oracul, func(ctx context.Context) error {
+			slugs, err := app.currencyCollectorSlugs(ctx, cfg)
+			if err != nil {
+				return err
+			}
+			return app.Domain.Currency.ImportOracul(ctx, slugs)
+		})).
+		Add(StageName_TokenAddress, interval(schedule.TokenAddress), app.withImportRun(StageName_TokenAddress, func(ctx context.Context) error {
+			slugs, err := app.currencyCollectorSlugs(ctx, cfg)
+			if err != nil {
+				return err
+			}
+			return app.Domain.Currency.ImportTokenAddresses(ctx, slugs)
+		})).
+		// the reconciliation is not a part of the import, so it runs only by its own schedule
+		Add(StageName_Reconcile, schedule.Reconcile, app.withImportRun(StageName_Reconcile, func(ctx context.Context) error {
+			currencyList, err := app.reconcileCurrencyList(ctx, nil)
+			if err != nil {
+				return err
+			}
+			_, err = app.Domain.PriceDivergence.Reconcile(ctx, currencyList)
+			return err
+		})).
+		Add(StageName_Discover, schedule.Discovery, app.withImportRun(StageName_Discover, func(ctx context.Context) error {
+			_, err := app.Domain.Universe.Discover(ctx, app.discoverPinnedSlugs(), false)
+			return err
+		})).
+		Add(StageName_MarketPairs, schedule.MarketPairs, app.withImportRun(StageName_MarketPairs, func(ctx context.Context) error {
+			slugs, err := app.currencyCollectorSlugs(ctx, cfg)
+			if err != nil {
+				return err
+			}
+			currencyList, err := app.Domain.Currency.GetBySlugsAndIDs(ctx, slugs, nil)
+			if err != nil {
+				return err
+			}
+			return app.Domain.MarketPair.Import(ctx, currencyList)
+		})).
+		Add(StageName_GlobalMetrics, schedule.GlobalMetrics, app.withImportRun(StageName_GlobalMetrics, func(ctx context.Context) error {
+			return app.Domain.GlobalMetrics.Import(ctx)
+		}))
 }
 
-func (app *App) currencyCollector_Exec(ctx context.Context, cfg *config.CurrencyCollector) {
+func (app *App) currencyCollector_Exec(ctx context.Context, cfg *config.CurrencyCollector, locker stageLocker) {
 	app.Infra.Logger.Info("Currency.Import: starts iteration...")
 
-	if err := app.Domain.Currency.Import(ctx, &cfg.ListOfCurrencySlugs); err != nil {
+	if err := execLocked(ctx, locker, []string{StageName_Currency, StageName_PriceAndCap, StageName_Concentration}, app.withImportRun(StageName_Import, func(ctx context.Context) error {
+		slugs, err := app.currencyCollectorSlugs(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		return app.Domain.Currency.Import(ctx, slugs)
+	})); err != nil {
 		app.Infra.Logger.Info("Currency.Import: iteration completed with errors!", zap.Error(err))
 		return
 	}
@@ -50,7 +148,9 @@ func (app *App) currencyCollector_Exec(ctx context.Context, cfg *config.Currency
 
 	app.Infra.Logger.Info("Portfolio.Import: starts iteration...")
 
-	if err := app.Domain.PortfolioItem.Import(ctx, &cfg.PortfolioSourceIDs); err != nil {
+	if err := execLocked(ctx, locker, []string{StageName_Portfolio}, app.withImportRun(StageName_Portfolio, func(ctx context.Context) error {
+		return app.Domain.PortfolioItem.Import(ctx, &cfg.PortfolioSourceIDs)
+	})); err != nil {
 		app.Infra.Logger.Info("PortfolioItem.Import: iteration completed with errors!", zap.Error(err))
 		return
 	}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"info/internal/domain/currency"
+	"info/internal/domain/universe"
+	"info/internal/pkg/apperror"
+	"info/internal/pkg/config"
+)
+
+const (
+	flagName_DryRun = "dry-run"
+)
+
+// discover ...
+var discover = &cobra.Command{
+	Use:   "discover",
+	Short: "It is the discover command.",
+	Long: `It is the discover command: selects the currencies of the CMC Pro listings by the rules of the universe, observes the selected ones and retires the observed ones which do not match the rules any more.
+The currencies of the currency-collector are pinned and never retired; the changes are available at /api/v1/universe/changes.
+Example: discover --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		CliApp.discover(cmd, args)
+	},
+}
+
+func init() {
+	discover.Flags().Bool(flagName_DryRun, false, "print the changes without storing them")
+}
+
+func (app *App) discover(cmd *cobra.Command, args []string) {
+	dryRun, err := cmd.Flags().GetBool(flagName_DryRun)
+	if err != nil {
+		app.logger.Error("discover: flag parse error", zap.String("flag", flagName_DryRun), zap.Error(err))
+		return
+	}
+
+	app.logger.Info("discover: starts...", zap.Bool(flagName_DryRun, dryRun))
+	var res *universe.ChangeList
+	err = execLocked(app.ctx, app.newStageLocker(), []string{StageName_Discover}, app.withImportRun(StageName_Discover, func(ctx context.Context) error {
+		res, err = app.Domain.Universe.Discover(ctx, app.discoverPinnedSlugs(), dryRun)
+		return err
+	}))
+	if res != nil {
+		printDiscoverSummary(res)
+	}
+	if err != nil {
+		app.logger.Info("discover: completed with errors!", zap.Error(err))
+		return
+	}
+	app.logger.Info("discover: completed successfully!")
+}
+
+// discoverPinnedSlugs returns the slugs of the currency-collector, which are never retired by the discovery
+func (app *App) discoverPinnedSlugs() *[]string {
+	if app.config.CurrencyCollector == nil {
+		return nil
+	}
+	return &app.config.CurrencyCollector.ListOfCurrencySlugs
+}
+
+// currencyCollectorSlugs returns the slugs of the import: the slugs of the config and, with IsObservedUniverse, the observed currencies
+func (app *App) currencyCollectorSlugs(ctx context.Context, cfg *config.CurrencyCollector) (*[]string, error) {
+	if !cfg.IsObservedUniverse {
+		return &cfg.ListOfCurrencySlugs, nil
+	}
+
+	observed, err := app.Domain.Currency.GetAll(ctx)
+	if err != nil {
+		if !errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		observed = &currency.CurrencyList{}
+	}
+
+	res := make([]string, 0, len(cfg.ListOfCurrencySlugs)+len(*observed))
+	isAdded := make(map[string]struct{}, cap(res))
+	var slug string
+	for _, slug = range cfg.ListOfCurrencySlugs {
+		if _, ok := isAdded[slug]; !ok {
+			isAdded[slug] = struct{}{}
+			res = append(res, slug)
+		}
+	}
+	var item currency.Currency
+	for _, item = range *observed {
+		if _, ok := isAdded[item.Slug]; !ok {
+			isAdded[item.Slug] = struct{}{}
+			res = append(res, item.Slug)
+		}
+	}
+	return &res, nil
+}
+
+func printDiscoverSummary(res *universe.ChangeList) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CURRENCY\tSLUG\tACTION\tREASON\tRANK\tCAP\tVOLUME")
+
+	var item universe.Change
+	for _, item = range *res {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%.0f\t%.0f\n", item.CurrencyID, item.Slug, item.Action, item.Reason, item.CmcRank, item.MarketCap, item.Volume24h)
+	}
+	w.Flush()
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// globalMetrics ...
+var globalMetrics = &cobra.Command{
+	Use:   "global-metrics",
+	Short: "It is the global-metrics command.",
+	Long: `It is the global-metrics command: imports the latest global metrics of CMC Pro (total cap and volume, BTC and ETH dominance, stablecoin and DeFi caps).
+With --from it loads the hourly history of the window instead; the history is not available on the basic plans of CMC.
+The series is available at /api/v1/cmc/global-metrics.
+Example: global-metrics --from 2025-01-01 --to 2025-01-31`,
+	Run: func(cmd *cobra.Command, args []string) {
+		CliApp.globalMetrics(cmd, args)
+	},
+}
+
+func init() {
+	globalMetrics.Flags().String(flagName_From, "", "the first day of the history, YYYY-MM-DD; the latest metrics only by default")
+	globalMetrics.Flags().String(flagName_To, "", "the last day of the history, YYYY-MM-DD; today by default")
+}
+
+func (app *App) globalMetrics(cmd *cobra.Command, args []string) {
+	from, err := app.backfillParseDate(cmd, flagName_From, time.Time{})
+	if err != nil {
+		app.logger.Error("global-metrics: flag parse error", zap.String("flag", flagName_From), zap.Error(err))
+		return
+	}
+	to, err := app.backfillParseDate(cmd, flagName_To, time.Now().UTC().Truncate(time.Hour*24))
+	if err != nil {
+		app.logger.Error("global-metrics: flag parse error", zap.String("flag", flagName_To), zap.Error(err))
+		return
+	}
+	// the last day is included in the window
+	to = to.Add(time.Hour * 24)
+
+	app.logger.Info("global-metrics: starts...")
+	err = execLocked(app.ctx, app.newStageLocker(), []string{StageName_GlobalMetrics}, app.withImportRun(StageName_GlobalMetrics, func(ctx context.Context) error {
+		if from.IsZero() {
+			return app.Domain.GlobalMetrics.Import(ctx)
+		}
+		nb, err := app.Domain.GlobalMetrics.Backfill(ctx, from, to)
+		app.logger.Info("global-metrics: the history is loaded", zap.Uint("points", nb))
+		return err
+	}))
+	if err != nil {
+		app.logger.Info("global-metrics: completed with errors!", zap.Error(err))
+		return
+	}
+	app.logger.Info("global-metrics: completed successfully!")
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"info/internal/domain/import_run"
+	"info/internal/pkg/ratelimit"
+)
+
+const (
+	importRunFinishTimeout = 5 * time.Second
+)
+
+// withImportRun records the run of the stage to the import run journal: the start and the end, the stats collected by the importers,
+// the CoinMarketCap API credits spent by the requests of the run and the error. A failure of the journal does not stop the stage.
+func (app *App) withImportRun(stage string, exec stageExecFunc) stageExecFunc {
+	return func(ctx context.Context) (err error) {
+		run, errStart := app.Domain.ImportRun.Start(ctx, stage)
+		if errStart != nil {
+			app.logger.Error("ImportRun.Start error", zap.String("stage", stage), zap.Error(errStart))
+			return exec(ctx)
+		}
+
+		stats := &import_run.Stats{}
+		credits := &ratelimit.CreditCounter{}
+
+		defer func() {
+			// the context of the stage might be already cancelled, but the end of the run has to be recorded
+			finishCtx, cancel := context.WithTimeout(context.Background(), importRunFinishTimeout)
+			defer cancel()
+			if errFinish := app.Domain.ImportRun.Finish(finishCtx, run, stats, uint(credits.Count()), err); errFinish != nil {
+				app.logger.Error("ImportRun.Finish error", zap.String("stage", stage), zap.Error(errFinish))
+			}
+		}()
+
+		return exec(ratelimit.WithCreditCounter(import_run.WithStats(ctx, stats), credits))
+	}
+}
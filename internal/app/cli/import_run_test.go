@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"info/internal/app"
+	"info/internal/domain/import_run"
+	"info/internal/pkg/ratelimit"
+)
+
+type importRunRepoMock struct {
+	mu   sync.Mutex
+	runs map[uint]import_run.ImportRun
+}
+
+func (r *importRunRepoMock) WriteRepo() import_run.WriteRepository { return r }
+func (r *importRunRepoMock) ReadRepo() import_run.ReadRepository   { return r }
+
+func (r *importRunRepoMock) Create(ctx context.Context, entity *import_run.ImportRun) (uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ID := uint(len(r.runs) + 1)
+	r.runs[ID] = *entity
+	return ID, nil
+}
+
+func (r *importRunRepoMock) Update(ctx context.Context, entity *import_run.ImportRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs[entity.ID] = *entity
+	return nil
+}
+
+func (r *importRunRepoMock) GetList(ctx context.Context, stage string, limit uint) (*import_run.ImportRunList, error) {
+	return nil, nil
+}
+
+func TestWithImportRun_OverlappingStagesCredits(t *testing.T) {
+	repo := &importRunRepoMock{runs: make(map[uint]import_run.ImportRun)}
+	cliApp := &App{
+		App:    &app.App{Domain: &app.Domain{ImportRun: import_run.NewService(repo)}},
+		logger: zap.NewNop(),
+	}
+	// both the stages spend the credits through the same limiter, as the stages sharing an API client do
+	limiter := ratelimit.New(ratelimit.Config{})
+
+	firstStarted := make(chan struct{})
+	secondDone := make(chan struct{})
+	first := cliApp.withImportRun("first", func(ctx context.Context) error {
+		limiter.AddCredits(ctx, 3)
+		close(firstStarted)
+		<-secondDone
+		limiter.AddCredits(ctx, 4)
+		return nil
+	})
+	second := cliApp.withImportRun("second", func(ctx context.Context) error {
+		<-firstStarted
+		limiter.AddCredits(ctx, 5)
+		close(secondDone)
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for _, stage := range []stageExecFunc{first, second} {
+		wg.Add(1)
+		go func(stage stageExecFunc) {
+			defer wg.Done()
+			if err := stage(context.Background()); err != nil {
+				t.Errorf("stage error: %v", err)
+			}
+		}(stage)
+	}
+	wg.Wait()
+
+	want := map[string]uint{"first": 7, "second": 5}
+	if len(repo.runs) != len(want) {
+		t.Fatalf("runs = %d, want %d", len(repo.runs), len(want))
+	}
+	for _, run := range repo.runs {
+		if run.EndedAt == nil {
+			t.Errorf("run %q is not finished", run.Stage)
+		}
+		if run.CreditsUsed != want[run.Stage] {
+			t.Errorf("run %q CreditsUsed = %d, want %d", run.Stage, run.CreditsUsed, want[run.Stage])
+		}
+	}
+	if got := limiter.CreditCount(); got != 12 {
+		t.Errorf("limiter CreditCount = %d, want 12", got)
+	}
+}
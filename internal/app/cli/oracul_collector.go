@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"context"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"info/internal/infrastructure/repository/redis"
+	"info/internal/pkg/config"
+)
+
+// oraculCollector ...
+var oraculCollector = &cobra.Command{
+	Use:   "oracul-collector",
+	Short: "It is the oracul-collector command.",
+	Long:  `It is the oracul-collector command: imports the holders stats of the token addresses on the supported blockchains from the Oracul analytics API. Only the days after the last imported one are requested.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		CliApp.oraculCollector(cmd, args)
+	},
+}
+
+func (app *App) oraculCollector(cmd *cobra.Command, args []string) {
+	cfg := app.config.OraculCollector
+	if cfg == nil {
+		cfg = &config.OraculCollector{}
+	}
+	locker := redis.NewLockRepository(app.Infra.Redis.WriteRepo(), cfg.LockTTL)
+
+	// the holder analytics is imported by the token addresses, so they are refreshed first; on an error the known ones are used
+	app.Infra.Logger.Info("Currency.ImportTokenAddresses: starts iteration...")
+
+	if err := execLocked(app.ctx, locker, []string{StageName_TokenAddress}, app.withImportRun(StageName_TokenAddress, func(ctx context.Context) error {
+		return app.Domain.Currency.ImportTokenAddresses(ctx, &cfg.ListOfCurrencySlugs)
+	})); err != nil {
+		app.Infra.Logger.Info("Currency.ImportTokenAddresses: iteration completed with errors!", zap.Error(err))
+	} else {
+		app.Infra.Logger.Info("Currency.ImportTokenAddresses: iteration completed successfully!")
+	}
+
+	app.Infra.Logger.Info("OraculAnalytics.Import: starts iteration...")
+
+	if err := execLocked(app.ctx, locker, []string{StageName_Oracul}, app.withImportRun(StageName_Oracul, func(ctx context.Context) error {
+		return app.Domain.Currency.ImportOracul(ctx, &cfg.ListOfCurrencySlugs)
+	})); err != nil {
+		app.Infra.Logger.Info("OraculAnalytics.Import: iteration completed with errors!", zap.Error(err))
+		return
+	}
+	app.Infra.Logger.Info("OraculAnalytics.Import: iteration completed successfully!")
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"info/internal/domain/currency"
+	"info/internal/domain/price_divergence"
+)
+
+// reconcile ...
+var reconcile = &cobra.Command{
+	Use:   "reconcile",
+	Short: "It is the reconcile command.",
+	Long: `It is the reconcile command: compares the stored price_and_cap of the currencies with CoinGecko for the last days and flags the days where the price or the cap diverges beyond the thresholds.
+Without --currencies the currencies of the import of the currency-collector are reconciled, with IsObservedUniverse the observed ones too; the flagged days are available at /api/v1/cmc/report/price-divergence.
+Example: reconcile --currencies bitcoin,1027`,
+	Run: func(cmd *cobra.Command, args []string) {
+		CliApp.reconcile(cmd, args)
+	},
+}
+
+func init() {
+	reconcile.Flags().StringSlice(flagName_Currencies, nil, "slugs or IDs of the currencies; the currencies of the currency-collector by default")
+}
+
+func (app *App) reconcile(cmd *cobra.Command, args []string) {
+	currencies, err := cmd.Flags().GetStringSlice(flagName_Currencies)
+	if err != nil {
+		app.logger.Error("reconcile: flag parse error", zap.String("flag", flagName_Currencies), zap.Error(err))
+		return
+	}
+
+	app.logger.Info("reconcile: starts...")
+	var res *price_divergence.ReconcileResultList
+	err = execLocked(app.ctx, app.newStageLocker(), []string{StageName_Reconcile}, app.withImportRun(StageName_Reconcile, func(ctx context.Context) error {
+		currencyList, err := app.reconcileCurrencyList(ctx, currencies)
+		if err != nil {
+			return err
+		}
+		res, err = app.Domain.PriceDivergence.Reconcile(ctx, currencyList)
+		return err
+	}))
+	if res != nil {
+		printReconcileSummary(res)
+	}
+	if err != nil {
+		app.logger.Info("reconcile: completed with errors!", zap.Error(err))
+		return
+	}
+	app.logger.Info("reconcile: completed successfully!")
+}
+
+// reconcileCurrencyList returns the currencies by the slugs or IDs; an empty list means the currencies of the import of the currency-collector
+func (app *App) reconcileCurrencyList(ctx context.Context, currencies []string) (*currency.CurrencyList, error) {
+	slugs := make([]string, 0, len(currencies))
+	IDs := make([]uint, 0, len(currencies))
+	if len(currencies) == 0 && app.config.CurrencyCollector != nil {
+		collectorSlugs, err := app.currencyCollectorSlugs(ctx, app.config.CurrencyCollector)
+		if err != nil {
+			return nil, err
+		}
+		slugs = append(slugs, *collectorSlugs...)
+	}
+	var item string
+	for _, item = range currencies {
+		if ID, err := strconv.ParseUint(item, 10, 64); err == nil {
+			IDs = append(IDs, uint(ID))
+			continue
+		}
+		slugs = append(slugs, item)
+	}
+
+	return app.Domain.Currency.GetBySlugsAndIDs(ctx, &slugs, &IDs)
+}
+
+func printReconcileSummary(res *price_divergence.ReconcileResultList) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CURRENCY\tSLUG\tCOMPARED\tDIVERGED\tERROR")
+
+	var item price_divergence.ReconcileResult
+	for _, item = range *res {
+		errText := ""
+		if item.Err != nil {
+			errText = item.Err.Error()
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%s\n", item.CurrencyID, item.Slug, item.Compared, item.Diverged, errText)
+	}
+	w.Flush()
+}
@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"info/internal/domain/currency"
+	"info/internal/domain/raw_response"
+	"info/internal/integration/cmc_api"
+	"info/internal/integration/oracul_analytics_api"
+	"info/internal/pkg/apperror"
+)
+
+// reparse ...
+var reparse = &cobra.Command{
+	Use:   "reparse",
+	Short: "It is the reparse command.",
+	Long: `It is the reparse command: converts again the archived raw responses of the APIs and upserts the datasets without requests to the APIs.
+The window is the time of fetching of the responses; without --currencies all the archived currencies are reparsed.
+Example: reparse --currencies bitcoin,1027 --from 2025-01-01 --to 2025-01-31 --datasets price_and_cap,concentration,oracul`,
+	Run: func(cmd *cobra.Command, args []string) {
+		CliApp.reparse(cmd, args)
+	},
+}
+
+func init() {
+	reparse.Flags().StringSlice(flagName_Currencies, nil, "slugs or IDs of the currencies; all the archived currencies by default")
+	reparse.Flags().String(flagName_From, "", "the first day of fetching of the responses, YYYY-MM-DD; from the beginning of the archive by default")
+	reparse.Flags().String(flagName_To, "", "the last day of fetching of the responses, YYYY-MM-DD; today by default")
+	reparse.Flags().StringSlice(flagName_Datasets, []string{currency.Dataset_PriceAndCap, currency.Dataset_Concentration, currency.Dataset_Oracul}, "datasets to rebuild: price_and_cap, concentration, oracul")
+}
+
+type reparseResult struct {
+	Dataset   string
+	Responses uint
+	Failed    uint
+	Rows      uint
+}
+
+func (app *App) reparse(cmd *cobra.Command, args []string) {
+	currencies, err := cmd.Flags().GetStringSlice(flagName_Currencies)
+	if err != nil {
+		app.logger.Error("reparse: flag parse error", zap.String("flag", flagName_Currencies), zap.Error(err))
+		return
+	}
+	datasets, err := cmd.Flags().GetStringSlice(flagName_Datasets)
+	if err != nil {
+		app.logger.Error("reparse: flag parse error", zap.String("flag", flagName_Datasets), zap.Error(err))
+		return
+	}
+	var dataset string
+	for _, dataset = range datasets {
+		if err = currency.DatasetValidate(dataset); err != nil {
+			app.logger.Error("reparse: flag parse error", zap.String("flag", flagName_Datasets), zap.Error(err))
+			return
+		}
+	}
+	from, err := app.backfillParseDate(cmd, flagName_From, time.Time{})
+	if err != nil {
+		app.logger.Error("reparse: flag parse error", zap.String("flag", flagName_From), zap.Error(err))
+		return
+	}
+	to, err := app.backfillParseDate(cmd, flagName_To, time.Now().UTC().Truncate(time.Hour*24))
+	if err != nil {
+		app.logger.Error("reparse: flag parse error", zap.String("flag", flagName_To), zap.Error(err))
+		return
+	}
+	// the last day is included in the window
+	to = to.Add(time.Hour * 24)
+
+	filter := &raw_response.Filter{
+		From: from,
+		To:   to,
+	}
+	if len(currencies) > 0 {
+		if filter.CurrencyIDs, err = app.reparseCurrencyIDs(currencies); err != nil {
+			app.logger.Error("reparse: currencies are not found", zap.Strings(flagName_Currencies, currencies), zap.Error(err))
+			return
+		}
+	}
+
+	app.logger.Info("reparse: starts...")
+	res := make([]reparseResult, 0, len(datasets))
+	err = execLocked(app.ctx, app.newStageLocker(), append(datasetStageNames(datasets), StageName_Reparse), app.withImportRun(StageName_Reparse, func(ctx context.Context) error {
+		var errs error
+		for _, dataset = range datasets {
+			item, err := app.reparseDataset(ctx, dataset, *filter)
+			res = append(res, *item)
+			errs = errors.Join(errs, err)
+		}
+		return errs
+	}))
+	printReparseSummary(res)
+	if err != nil {
+		app.logger.Info("reparse: completed with errors!", zap.Error(err))
+		return
+	}
+	app.logger.Info("reparse: completed successfully!")
+}
+
+func (app *App) reparseCurrencyIDs(currencies []string) ([]uint, error) {
+	slugs := make([]string, 0, len(currencies))
+	IDs := make([]uint, 0, len(currencies))
+	var item string
+	for _, item = range currencies {
+		if ID, err := strconv.ParseUint(item, 10, 64); err == nil {
+			IDs = append(IDs, uint(ID))
+			continue
+		}
+		slugs = append(slugs, item)
+	}
+
+	currencyList, err := app.Domain.Currency.GetBySlugsAndIDs(app.ctx, &slugs, &IDs)
+	if err != nil {
+		return nil, err
+	}
+	return *currencyList.IDs(), nil
+}
+
+// reparseDataset converts again all the archived responses of the dataset matched by the filter; an error of a response does not stop the others
+func (app *App) reparseDataset(ctx context.Context, dataset string, filter raw_response.Filter) (*reparseResult, error) {
+	res := &reparseResult{
+		Dataset: dataset,
+	}
+
+	var parse func(ctx context.Context, entity *raw_response.RawResponse, data []byte) (rows int, err error)
+	// the time range of the upserted prices whose candles are refreshed
+	var pricesFrom, pricesTo time.Time
+	switch dataset {
+	case currency.Dataset_PriceAndCap:
+		filter.Endpoint = cmc_api.URI_GetDetailChart
+		parse = func(ctx context.Context, entity *raw_response.RawResponse, data []byte) (int, error) {
+			list, err := cmc_api.ParseDetailChart(data, entity.CurrencyID)
+			if err != nil {
+				return 0, err
+			}
+			if _, err = app.Domain.PriceAndCap.Reparse(ctx, list); err != nil {
+				return 0, err
+			}
+			if len(*list) > 0 {
+				from, to := list.TimeRange()
+				if pricesTo.IsZero() || from.Before(pricesFrom) {
+					pricesFrom = from
+				}
+				if to.After(pricesTo) {
+					pricesTo = to
+				}
+			}
+			return len(*list), nil
+		}
+	case currency.Dataset_Concentration:
+		filter.Endpoint = cmc_api.URI_GetAnalytics
+		parse = func(ctx context.Context, entity *raw_response.RawResponse, data []byte) (int, error) {
+			list, err := cmc_api.ParseAnalytics(data, entity.CurrencyID)
+			if err != nil {
+				return 0, err
+			}
+			if _, err = app.Domain.Concentration.Reparse(ctx, list); err != nil {
+				return 0, err
+			}
+			return len(*list), nil
+		}
+	case currency.Dataset_Oracul:
+		filter.Endpoint = oracul_analytics_api.URI_GetHoldersStats
+		parse = func(ctx context.Context, entity *raw_response.RawResponse, data []byte) (int, error) {
+			importData, err := oracul_analytics_api.ParseHoldersStats(data, entity.CurrencyID, entity.FetchedAt)
+			if err != nil {
+				return 0, err
+			}
+			if err = app.Domain.OraculAnalytics.Reparse(ctx, importData); err != nil {
+				return 0, err
+			}
+			return importData.RowsNb(), nil
+		}
+	default:
+		return res, fmt.Errorf("[%w] unknown dataset %q", apperror.ErrBadRequest, dataset)
+	}
+
+	var errs error
+	err := app.Domain.RawResponse.Each(ctx, &filter, func(ctx context.Context, entity *raw_response.RawResponse) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		res.Responses++
+
+		data, err := entity.Data()
+		if err == nil {
+			var rows int
+			rows, err = parse(ctx, entity, data)
+			res.Rows += uint(rows)
+		}
+		if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+			res.Failed++
+			errs = errors.Join(errs, fmt.Errorf("dataset: %s; raw response ID: %d; currencyID: %d; error: %w", dataset, entity.ID, entity.CurrencyID, err))
+		}
+		return nil
+	})
+
+	if !pricesTo.IsZero() {
+		errs = errors.Join(errs, app.Domain.PriceAndCap.RefreshCandles(ctx, pricesFrom, pricesTo.Add(time.Second)))
+	}
+
+	return res, errors.Join(errs, err)
+}
+
+func printReparseSummary(res []reparseResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATASET\tRESPONSES\tFAILED\tROWS")
+
+	var item reparseResult
+	for _, item = range res {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", item.Dataset, item.Responses, item.Failed, item.Rows)
+	}
+	w.Flush()
+}
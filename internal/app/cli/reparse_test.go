@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"info/internal/app"
+	"info/internal/domain"
+	"info/internal/domain/concentration"
+	"info/internal/domain/currency"
+	"info/internal/domain/raw_response"
+	"info/internal/integration/cmc_api"
+	"info/internal/pkg/apperror"
+)
+
+type rawResponseRepoMock struct {
+	list raw_response.RawResponseList
+}
+
+func (r *rawResponseRepoMock) WriteRepo() raw_response.WriteRepository { return r }
+func (r *rawResponseRepoMock) ReadRepo() raw_response.ReadRepository   { return r }
+
+func (r *rawResponseRepoMock) Create(ctx context.Context, entity *raw_response.RawResponse) error {
+	entity.ID = uint(len(r.list) + 1)
+	r.list = append(r.list, *entity)
+	return nil
+}
+
+func (r *rawResponseRepoMock) GetList(ctx context.Context, filter *raw_response.Filter, afterID uint, limit uint) (*raw_response.RawResponseList, error) {
+	res := make(raw_response.RawResponseList, 0, limit)
+	for _, item := range r.list {
+		if item.ID <= afterID || item.Endpoint != filter.Endpoint || item.FetchedAt.Before(filter.From) || !item.FetchedAt.Before(filter.To) {
+			continue
+		}
+		res = append(res, item)
+		if uint(len(res)) == limit {
+			break
+		}
+	}
+	if len(res) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+	return &res, nil
+}
+
+type concentrationKey struct {
+	currencyID uint
+	d          time.Time
+}
+
+type concentrationRepoMock struct {
+	concentration.WriteRepository
+	mu   sync.Mutex
+	rows map[concentrationKey]concentration.Concentration
+}
+
+func (r *concentrationRepoMock) WriteRepo() concentration.WriteRepository { return r }
+func (r *concentrationRepoMock) ReadRepo() concentration.ReadRepository   { return nil }
+
+func (r *concentrationRepoMock) MUpsertWithStats(ctx context.Context, entities *[]concentration.Concentration) (*domain.UpsertStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := &domain.UpsertStats{}
+	for _, item := range *entities {
+		key := concentrationKey{item.CurrencyID, item.D}
+		if _, ok := r.rows[key]; ok {
+			res.Updated++
+		} else {
+			res.Inserted++
+		}
+		r.rows[key] = item
+	}
+	return res, nil
+}
+
+func analyticsResponse(whales float64, days ...string) []byte {
+	details := ""
+	for i, d := range days {
+		if i > 0 {
+			details += ","
+		}
+		details += fmt.Sprintf(`{"date":%q,"whales":%g,"investors":1,"retail":2}`, d, whales)
+	}
+	return []byte(fmt.Sprintf(`{"data":{"historicalConcentration":{"historicalConcentrationDetails":[%s]}},"status":{"error_code":"0","error_message":%q}}`, details, cmc_api.ErrorMessage_Success))
+}
+
+func TestReparseDataset_Idempotent(t *testing.T) {
+	ctx := context.Background()
+	fetchedAt := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	rawResponses := raw_response.NewService(&rawResponseRepoMock{})
+	archive := func(currencyID uint, fetchedAt time.Time, data []byte) {
+		if err := rawResponses.Archive(ctx, cmc_api.Source, cmc_api.URI_GetAnalytics, currencyID, fetchedAt, data); err != nil {
+			t.Fatalf("Archive() error: %v", err)
+		}
+	}
+	archive(1, fetchedAt, analyticsResponse(10, "2025-01-08", "2025-01-09"))
+	// the later response of the same days overwrites the earlier one
+	archive(1, fetchedAt.Add(time.Hour), analyticsResponse(20, "2025-01-09", "2025-01-10"))
+	archive(2, fetchedAt, analyticsResponse(30, "2025-01-09"))
+	archive(2, fetchedAt, []byte(`{"data":`))
+	// the response out of the window
+	archive(3, fetchedAt.AddDate(0, 0, 5), analyticsResponse(40, "2025-01-14"))
+
+	repo := &concentrationRepoMock{rows: make(map[concentrationKey]concentration.Concentration)}
+	cliApp := &App{
+		App: &app.App{Domain: &app.Domain{
+			RawResponse:   rawResponses,
+			Concentration: concentration.NewService(repo, nil),
+		}},
+		logger: zap.NewNop(),
+	}
+	filter := raw_response.Filter{
+		From: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2025, 1, 11, 0, 0, 0, 0, time.UTC),
+	}
+
+	first, err := cliApp.reparseDataset(ctx, currency.Dataset_Concentration, filter)
+	if err == nil {
+		t.Fatal("reparseDataset() error is nil, want the error of the malformed response")
+	}
+	want := reparseResult{Dataset: currency.Dataset_Concentration, Responses: 4, Failed: 1, Rows: 5}
+	if *first != want {
+		t.Fatalf("the first run: got %+v, want %+v", *first, want)
+	}
+	d := func(s string) time.Time {
+		res, _ := time.Parse(time.DateOnly, s)
+		return res
+	}
+	wantRows := map[concentrationKey]float64{
+		{1, d("2025-01-08")}: 10,
+		{1, d("2025-01-09")}: 20,
+		{1, d("2025-01-10")}: 20,
+		{2, d("2025-01-09")}: 30,
+	}
+	if len(repo.rows) != len(wantRows) {
+		t.Fatalf("got %d rows, want %d", len(repo.rows), len(wantRows))
+	}
+	for key, whales := range wantRows {
+		if repo.rows[key].Whales != whales {
+			t.Errorf("%+v: got whales %v, want %v", key, repo.rows[key].Whales, whales)
+		}
+	}
+
+	rowsAfterFirst := make(map[concentrationKey]concentration.Concentration, len(repo.rows))
+	for key, item := range repo.rows {
+		rowsAfterFirst[key] = item
+	}
+	second, err := cliApp.reparseDataset(ctx, currency.Dataset_Concentration, filter)
+	if err == nil || errors.Is(err, apperror.ErrBadRequest) {
+		t.Fatalf("the second run: got error %v, want the error of the malformed response", err)
+	}
+	if *second != *first {
+		t.Fatalf("the second run: got %+v, want %+v", *second, *first)
+	}
+	if !reflect.DeepEqual(repo.rows, rowsAfterFirst) {
+		t.Fatalf("the second run changed the rows: got %+v, want %+v", repo.rows, rowsAfterFirst)
+	}
+}
+
+func TestReparseDataset_Unknown(t *testing.T) {
+	cliApp := &App{App: &app.App{Domain: &app.Domain{}}, logger: zap.NewNop()}
+	if _, err := cliApp.reparseDataset(context.Background(), "unknown", raw_response.Filter{}); !errors.Is(err, apperror.ErrBadRequest) {
+		t.Fatalf("got error %v, want %v", err, apperror.ErrBadRequest)
+	}
+}
@@ -0,0 +1,253 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"info/internal/domain/currency"
+	"info/internal/infrastructure/repository/redis"
+	"info/internal/pkg/apperror"
+	"info/internal/pkg/fencing"
+)
+
+const (
+	StageName_Currency      = "currency"
+	StageName_PriceAndCap   = "price_and_cap"
+	StageName_Concentration = "concentration"
+	StageName_Portfolio     = "portfolio"
+	StageName_Oracul        = "oracul"
+	StageName_TokenAddress  = "token_address"
+	StageName_Import        = "import" // currency, price_and_cap and concentration in one run
+	StageName_Reparse       = "reparse"
+	StageName_Reconcile     = "reconcile"
+	StageName_Discover      = "discover"
+	StageName_MarketPairs   = "market_pairs"
+	StageName_GlobalMetrics = "global_metrics"
+
+	stageLockPrefix         = "currency-collector:"
+	stageLockReleaseTimeout = 5 * time.Second
+)
+
+type stageExecFunc func(ctx context.Context) error
+
+type stageLocker interface {
+	Acquire(ctx context.Context, name string) (*redis.Lease, error)
+}
+
+// execLocked executes exec under the named locks of the stages, so only one instance executes a stage at a time.
+// It returns apperror.ErrLocked if a stage is executed by another instance. The context of exec is cancelled when a lease is lost
+// and carries the fencing tokens of the leases under the stage names, e.g. the checkpoint of the dataset of the same name rejects a stale token.
+func execLocked(ctx context.Context, locker stageLocker, names []string, exec stageExecFunc) (err error) {
+	leases := make([]*redis.Lease, 0, len(names))
+	defer func() {
+		releaseCtx, cancel := context.WithTimeout(context.Background(), stageLockReleaseTimeout)
+		defer cancel()
+		for i := len(leases) - 1; i >= 0; i-- {
+			err = errors.Join(err, leases[i].Release(releaseCtx))
+		}
+	}()
+
+	var lease *redis.Lease
+	var name string
+	for _, name = range names {
+		if lease, err = locker.Acquire(ctx, stageLockPrefix+name); err != nil {
+			return err
+		}
+		leases = append(leases, lease)
+		ctx = fencing.WithToken(lease.Context(), name, lease.Token())
+	}
+
+	return exec(ctx)
+}
+
+// newStageLocker returns the locker of the stages of the currency-collector, so a one-shot command does not run a stage together with its daemon
+func (app *App) newStageLocker() *redis.LockRepository {
+	var ttl time.Duration
+	if app.config.CurrencyCollector != nil {
+		ttl = app.config.CurrencyCollector.LockTTL
+	}
+	return redis.NewLockRepository(app.Infra.Redis.WriteRepo(), ttl)
+}
+
+// datasetStageNames returns the names of the stages which import the datasets
+func datasetStageNames(datasets []string) []string {
+	res := make([]string, 0, len(datasets))
+	var dataset string
+	for _, dataset = range datasets {
+		switch dataset {
+		case currency.Dataset_PriceAndCap:
+			res = append(res, StageName_PriceAndCap)
+		case currency.Dataset_Concentration:
+			res = append(res, StageName_Concentration)
+		case currency.Dataset_Oracul:
+			res = append(res, StageName_Oracul)
+		}
+	}
+	return res
+}
+
+// StageState is a state of a scheduled stage which is exposed through the probes server
+type StageState struct {
+	Name             string     `json:"name"`
+	Interval         string     `json:"interval"`
+	IsRunning        bool       `json:"isRunning"`
+	LastRunStartedAt *time.Time `json:"lastRunStartedAt"`
+	LastRunEndedAt   *time.Time `json:"lastRunEndedAt"`
+	LastRunError     string     `json:"lastRunError"`
+	NextRunAt        *time.Time `json:"nextRunAt"`
+}
+
+type stage struct {
+	name     string
+	interval time.Duration
+	exec     stageExecFunc
+	locker   stageLocker
+	mu       sync.RWMutex
+	state    StageState
+}
+
+func newStage(name string, interval time.Duration, exec stageExecFunc, locker stageLocker) *stage {
+	return &stage{
+		name:     name,
+		interval: interval,
+		exec:     exec,
+		locker:   locker,
+		state: StageState{
+			Name:     name,
+			Interval: interval.String(),
+		},
+	}
+}
+
+func (s *stage) State() StageState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state
+}
+
+func (s *stage) setNextRunAt(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.NextRunAt = &t
+}
+
+func (s *stage) run(ctx context.Context) (err error) {
+	const metricName = "cli.stage.run"
+	start := time.Now().UTC()
+
+	s.mu.Lock()
+	s.state.IsRunning = true
+	s.state.LastRunStartedAt = &start
+	s.state.NextRunAt = nil
+	s.mu.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Join(err, fmt.Errorf("[%w] "+metricName+" Recover from panic: %v; stacktrace from panic: %s", apperror.ErrInternal, r, string(debug.Stack())))
+		}
+		end := time.Now().UTC()
+
+		s.mu.Lock()
+		s.state.IsRunning = false
+		s.state.LastRunEndedAt = &end
+		s.state.LastRunError = ""
+		if err != nil {
+			s.state.LastRunError = err.Error()
+		}
+		s.mu.Unlock()
+	}()
+
+	if s.locker == nil {
+		return s.exec(ctx)
+	}
+	return execLocked(ctx, s.locker, []string{s.name}, s.exec)
+}
+
+// scheduler runs every stage by its own ticker until the context is cancelled; with a locker every stage runs under its own named lock
+type scheduler struct {
+	logger *zap.Logger
+	locker stageLocker
+	stages []*stage
+}
+
+func newScheduler(logger *zap.Logger, locker stageLocker) *scheduler {
+	return &scheduler{
+		logger: logger,
+		locker: locker,
+		stages: make([]*stage, 0, 5),
+	}
+}
+
+// Add adds a stage to the scheduler; a stage with a zero interval is disabled
+func (s *scheduler) Add(name string, interval time.Duration, exec stageExecFunc) *scheduler {
+	if interval <= 0 {
+		s.logger.Info("scheduler: stage is disabled", zap.String("stage", name))
+		return s
+	}
+	s.stages = append(s.stages, newStage(name, interval, exec, s.locker))
+	return s
+}
+
+func (s *scheduler) States() []StageState {
+	res := make([]StageState, 0, len(s.stages))
+	for _, item := range s.stages {
+		res = append(res, item.State())
+	}
+	return res
+}
+
+// Run runs all the stages once in order of addition, then every stage by its own ticker.
+// It blocks until the context is cancelled and the running stages are stopped.
+func (s *scheduler) Run(ctx context.Context) {
+	for _, item := range s.stages {
+		if ctx.Err() != nil {
+			return
+		}
+		s.runStageIteration(ctx, item)
+	}
+
+	wg := &sync.WaitGroup{}
+	for _, item := range s.stages {
+		wg.Add(1)
+		go func(st *stage) {
+			defer wg.Done()
+			s.runStage(ctx, st)
+		}(item)
+	}
+	wg.Wait()
+}
+
+func (s *scheduler) runStage(ctx context.Context, st *stage) {
+	ticker := time.NewTicker(st.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		s.runStageIteration(ctx, st)
+		ticker.Reset(st.interval)
+	}
+}
+
+func (s *scheduler) runStageIteration(ctx context.Context, st *stage) {
+	s.logger.Info("scheduler: stage starts iteration...", zap.String("stage", st.name))
+	if err := st.run(ctx); err != nil {
+		if errors.Is(err, apperror.ErrLocked) {
+			s.logger.Info("scheduler: stage iteration is skipped, it is executed by another instance", zap.String("stage", st.name), zap.Error(err))
+		} else {
+			s.logger.Error("scheduler: stage iteration completed with errors!", zap.String("stage", st.name), zap.Error(err))
+		}
+	} else {
+		s.logger.Info("scheduler: stage iteration completed successfully!", zap.String("stage", st.name))
+	}
+	st.setNextRunAt(time.Now().UTC().Add(st.interval))
+}
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"info/internal/domain/currency"
+	"info/internal/infrastructure/repository/redis"
+	"info/internal/pkg/apperror"
+)
+
+// lockedStageLocker refuses every lock as if the stages are executed by another instance
+type lockedStageLocker struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (l *lockedStageLocker) Acquire(ctx context.Context, name string) (*redis.Lease, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.names = append(l.names, name)
+	return nil, fmt.Errorf("[%w] lock %q is held by another instance", apperror.ErrLocked, name)
+}
+
+func TestExecLocked_Locked(t *testing.T) {
+	locker := &lockedStageLocker{}
+	executed := false
+	err := execLocked(context.Background(), locker, []string{StageName_Currency, StageName_PriceAndCap}, func(ctx context.Context) error {
+		executed = true
+		return nil
+	})
+	if !errors.Is(err, apperror.ErrLocked) {
+		t.Fatalf("execLocked() error = %v, want %v", err, apperror.ErrLocked)
+	}
+	if executed {
+		t.Fatal("exec is executed without the lock")
+	}
+	if len(locker.names) != 1 || locker.names[0] != stageLockPrefix+StageName_Currency {
+		t.Fatalf("got acquired locks %v, want only the first one", locker.names)
+	}
+}
+
+func TestDatasetStageNames(t *testing.T) {
+	got := datasetStageNames([]string{currency.Dataset_Oracul, currency.Dataset_PriceAndCap, currency.Dataset_Concentration})
+	want := []string{StageName_Oracul, StageName_PriceAndCap, StageName_Concentration}
+	if !slices.Equal(got, want) {
+		t.Fatalf("datasetStageNames() = %v, want %v", got, want)
+	}
+}
+
+func TestStage_Run(t *testing.T) {
+	errStage := errors.New("stage error")
+	tests := []struct {
+		name    string
+		exec    stageExecFunc
+		locker  stageLocker
+		wantErr error
+	}{
+		{
+			name:    "success",
+			exec:    func(ctx context.Context) error { return nil },
+			wantErr: nil,
+		},
+		{
+			name:    "error",
+			exec:    func(ctx context.Context) error { return errStage },
+			wantErr: errStage,
+		},
+		{
+			name:    "panic",
+			exec:    func(ctx context.Context) error { panic("stage panic") },
+			wantErr: apperror.ErrInternal,
+		},
+		{
+			name:    "locked",
+			exec:    func(ctx context.Context) error { return nil },
+			locker:  &lockedStageLocker{},
+			wantErr: apperror.ErrLocked,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newStage(StageName_Currency, time.Minute, tt.exec, tt.locker)
+			err := st.run(context.Background())
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("run() error = %v, want %v", err, tt.wantErr)
+			}
+
+			state := st.State()
+			if state.IsRunning || state.LastRunStartedAt == nil || state.LastRunEndedAt == nil {
+				t.Fatalf("got state %+v, want a completed run", state)
+			}
+			if (state.LastRunError != "") != (tt.wantErr != nil) {
+				t.Fatalf("got LastRunError %q, want the error %v", state.LastRunError, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduler_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	runs := make(map[string]int)
+	exec := func(name string) stageExecFunc {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			runs[name]++
+			mu.Unlock()
+			return errors.New("stage error") // an error of a stage does not stop the scheduler
+		}
+	}
+	s := newScheduler(zap.NewNop(), nil).
+		Add(StageName_Currency, 10*time.Millisecond, exec(StageName_Currency)).
+		Add(StageName_Oracul, 0, exec(StageName_Oracul)).
+		Add(StageName_PriceAndCap, time.Hour, exec(StageName_PriceAndCap))
+	if len(s.States()) != 2 {
+		t.Fatalf("got %d stages, want 2: a stage with a zero interval is disabled", len(s.States()))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run() is not stopped after the cancel")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if runs[StageName_Currency] < 2 {
+		t.Fatalf("got %d runs of %q, want it to run by its ticker after the errors", runs[StageName_Currency], StageName_Currency)
+	}
+	if runs[StageName_PriceAndCap] != 1 {
+		t.Fatalf("got %d runs of %q, want 1", runs[StageName_PriceAndCap], StageName_PriceAndCap)
+	}
+	if runs[StageName_Oracul] != 0 {
+		t.Fatalf("got %d runs of the disabled stage", runs[StageName_Oracul])
+	}
+	for _, state := range s.States() {
+		if state.IsRunning {
+			t.Fatalf("stage %q is still running after Run()", state.Name)
+		}
+	}
+}
+
+func TestScheduler_RunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var stageCtxErr error
+	second := false
+	s := newScheduler(zap.NewNop(), nil).
+		Add(StageName_Currency, time.Hour, func(ctx context.Context) error {
+			cancel()
+			stageCtxErr = ctx.Err()
+			return ctx.Err()
+		}).
+		Add(StageName_PriceAndCap, time.Hour, func(ctx context.Context) error {
+			second = true
+			return nil
+		})
+
+	s.Run(ctx)
+	if !errors.Is(stageCtxErr, context.Canceled) {
+		t.Fatalf("got the context error %v of the stage, want %v", stageCtxErr, context.Canceled)
+	}
+	if second {
+		t.Fatal("the next stage is started after the cancel")
+	}
+	if state := s.States()[0]; state.LastRunError == "" {
+		t.Fatalf("got state %+v, want the error of the cancelled run", state)
+	}
+}
@@ -0,0 +1,218 @@
+package mockapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	routing "github.com/qiangxue/fasthttp-routing"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	FailureMode_Status    = "status"     // the response with the status code
+	FailureMode_ErrorCode = "error_code" // 200 with an error in Status of the response
+	FailureMode_Malformed = "malformed"  // 200 with a broken JSON
+	FailureMode_Slow      = "slow"       // the normal response after the delay
+
+	defaultFailureStatus = fasthttp.StatusInternalServerError
+	defaultFailureDelay  = 10 * time.Second
+	failureRetryAfter    = "1"
+	failureErrorCode     = 500
+	failureErrorMessage  = "mock failure"
+	failureMalformedBody = `{"data": {"points": `
+)
+
+var FailureModeList = []interface{}{
+	FailureMode_Status,
+	FailureMode_ErrorCode,
+	FailureMode_Malformed,
+	FailureMode_Slow,
+}
+
+// Failure makes the requests of the route fail in the mode
+type Failure struct {
+	Mode   string        `json:"mode"`
+	Route  string        `json:"route"`  // empty - all the routes
+	Rate   float64       `json:"rate"`   // the part of the requests of the route which fail, evenly spread: 0.5 - every second request; 0 - every request
+	Status int           `json:"status"` // for the status mode; 500 by default
+	Delay  time.Duration `json:"delay"`  // for the slow mode; 10s by default
+}
+
+func (f Failure) Validate() error {
+	return validation.ValidateStruct(&f,
+		validation.Field(&f.Mode, validation.Required, validation.In(FailureModeList...)),
+		validation.Field(&f.Rate, validation.Min(0.0), validation.Max(1.0)),
+		validation.Field(&f.Status, validation.When(f.Status != 0, validation.Min(100), validation.Max(599))),
+	)
+}
+
+// ParseFailure parses a failure from the form "mode=status,route=holders_stats,rate=0.5,status=503,delay=5s"
+func ParseFailure(s string) (res Failure, err error) {
+	var pair string
+	for _, pair = range strings.Split(s, ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return res, fmt.Errorf("failure %q: %q is not a key=value pair", s, pair)
+		}
+		switch key {
+		case "mode":
+			res.Mode = val
+		case "route":
+			res.Route = val
+		case "rate":
+			res.Rate, err = strconv.ParseFloat(val, 64)
+		case "status":
+			res.Status, err = strconv.Atoi(val)
+		case "delay":
+			res.Delay, err = time.ParseDuration(val)
+		default:
+			return res, fmt.Errorf("failure %q: unknown key %q", s, key)
+		}
+		if err != nil {
+			return res, fmt.Errorf("failure %q: key %q: %w", s, key, err)
+		}
+	}
+	return res, res.Validate()
+}
+
+type failureRule struct {
+	Failure
+	count atomic.Uint64
+}
+
+// isFailed counts the request of the route and decides whether it fails; the failed requests are spread evenly by the rate, so the behaviour is deterministic
+func (r *failureRule) isFailed(route string) bool {
+	if r.Route != "" && r.Route != route {
+		return false
+	}
+	if r.Rate == 0 || r.Rate >= 1 {
+		return true
+	}
+	n := float64(r.count.Add(1))
+	return math.Floor(n*r.Rate) > math.Floor((n-1)*r.Rate)
+}
+
+// SetFailures replaces the failures; the counters of the rates start from zero
+func (m *MockAPI) SetFailures(failures []Failure) {
+	rules := make([]*failureRule, 0, len(failures))
+	var item Failure
+	for _, item = range failures {
+		rules = append(rules, &failureRule{Failure: item})
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failures = rules
+}
+
+func (m *MockAPI) Failures() []Failure {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	res := make([]Failure, 0, len(m.failures))
+	var item *failureRule
+	for _, item = range m.failures {
+		res = append(res, item.Failure)
+	}
+	return res
+}
+
+// failure returns the first failure of the request of the route
+func (m *MockAPI) failure(route string) *Failure {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var item *failureRule
+	for _, item = range m.failures {
+		if item.isFailed(route) {
+			return &item.Failure
+		}
+	}
+	return nil
+}
+
+// withFailures injects the failures into the handler of the route
+func (m *MockAPI) withFailures(route string, handler routing.Handler) routing.Handler {
+	return func(rctx *routing.Context) error {
+		f := m.failure(route)
+		if f == nil {
+			return handler(rctx)
+		}
+
+		switch f.Mode {
+		case FailureMode_Status:
+			status := f.Status
+			if status == 0 {
+				status = defaultFailureStatus
+			}
+			if status == fasthttp.StatusTooManyRequests {
+				rctx.Response.Header.Set("Retry-After", failureRetryAfter)
+			}
+			return writeError(rctx, status, failureErrorMessage)
+		case FailureMode_ErrorCode:
+			return writeJSON(rctx, fasthttp.StatusOK, errorCodeResponse(route))
+		case FailureMode_Malformed:
+			rctx.SetStatusCode(fasthttp.StatusOK)
+			rctx.SetBodyString(failureMalformedBody)
+			return nil
+		case FailureMode_Slow:
+			delay := f.Delay
+			if delay == 0 {
+				delay = defaultFailureDelay
+			}
+			time.Sleep(delay)
+		}
+		return handler(rctx)
+	}
+}
+
+// errorCodeResponse is a response with an error in Status; the Oracul API has no Status, so it gets an empty object which fails the conversion
+func errorCodeResponse(route string) interface{} {
+	switch route {
+	case Route_HoldersStats:
+		return map[string]interface{}{}
+	case Route_ProQuotes, Route_ProListings, Route_ProInfo, Route_ProGlobalLatest, Route_ProGlobalHistory:
+		return map[string]interface{}{
+			"status": map[string]interface{}{
+				"error_code":    failureErrorCode,
+				"error_message": failureErrorMessage,
+			},
+		}
+	}
+	return map[string]interface{}{
+		"status": map[string]interface{}{
+			"error_code":    strconv.Itoa(failureErrorCode),
+			"error_message": failureErrorMessage,
+		},
+	}
+}
+
+func (m *MockAPI) GetFailures(rctx *routing.Context) error {
+	return writeJSON(rctx, fasthttp.StatusOK, m.Failures())
+}
+
+func (m *MockAPI) PutFailures(rctx *routing.Context) error {
+	failures := make([]Failure, 0)
+	if err := json.Unmarshal(rctx.PostBody(), &failures); err != nil {
+		return writeError(rctx, fasthttp.StatusBadRequest, err.Error())
+	}
+	var item Failure
+	for _, item = range failures {
+		if err := item.Validate(); err != nil {
+			return writeError(rctx, fasthttp.StatusBadRequest, err.Error())
+		}
+	}
+	m.SetFailures(failures)
+	return writeJSON(rctx, fasthttp.StatusOK, m.Failures())
+}
+
+func (m *MockAPI) DeleteFailures(rctx *routing.Context) error {
+	m.SetFailures(nil)
+	return writeJSON(rctx, fasthttp.StatusOK, m.Failures())
+}
@@ -0,0 +1,604 @@
+package mockapi
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"info/internal/integration/cmc_api"
+	"info/internal/integration/cmc_pro_api"
+	"info/internal/integration/oracul_analytics_api"
+)
+
+const (
+	generatorAllDays       = 2000
+	generatorMaxCandles    = 3660
+	generatorPortfolioSize = 5
+)
+
+const (
+	// listingsTotal is the number of the currencies in the listings
+	listingsTotal    = 500
+	listingsMaxLimit = 5000
+)
+
+// knownCurrencies gives the real IDs to the popular slugs; the IDs of the other slugs are derived from the slug
+var knownCurrencies = map[string]uint{
+	"bitcoin":     1,
+	"ethereum":    1027,
+	"tether":      825,
+	"bnb":         1839,
+	"solana":      5426,
+	"chainlink":   1975,
+	"uniswap":     7083,
+	"shiba-inu":   5994,
+	"polygon-pos": 3890,
+}
+
+// generator builds the responses from the seed, the currency and the time, so the same request gets the same data
+// and the points of the same time are equal in the responses of all the ranges
+type generator struct {
+	seed int64
+}
+
+func newGenerator(seed int64) *generator {
+	return &generator{
+		seed: seed,
+	}
+}
+
+// noise returns a deterministic value in [-1, 1) for the currency, the time and the salt
+func (g *generator) noise(currencyID uint, t int64, salt string) float64 {
+	h := fnv.New64a()
+	var buf [24]byte
+	binary.LittleEndian.PutUint64(buf[0:], uint64(g.seed))
+	binary.LittleEndian.PutUint64(buf[8:], uint64(currencyID))
+	binary.LittleEndian.PutUint64(buf[16:], uint64(t))
+	h.Write(buf[:])
+	h.Write([]byte(salt))
+	return float64(h.Sum64()%1_000_000)/500_000 - 1
+}
+
+// wave is a smooth positive series with the noise around 1
+func (g *generator) wave(currencyID uint, t int64, salt string) float64 {
+	days := float64(t) / 86400
+	return 1 + 0.3*math.Sin(days/30+float64(currencyID)) + 0.1*math.Sin(days/7) + 0.03*g.noise(currencyID, t, salt)
+}
+
+func (g *generator) basePrice(currencyID uint) float64 {
+	return 0.01 + float64(currencyID%997)*(1+g.noise(currencyID, 0, "base"))
+}
+
+func slugID(slug string) uint {
+	if ID, ok := knownCurrencies[slug]; ok {
+		return ID
+	}
+	return uint(crc32.ChecksumIEEE([]byte(slug))%900_000) + 100_000
+}
+
+func idSlug(ID uint) string {
+	var slug string
+	var knownID uint
+	for slug, knownID = range knownCurrencies {
+		if knownID == ID {
+			return slug
+		}
+	}
+	return "currency-" + strconv.FormatUint(uint64(ID), 10)
+}
+
+// tokenAddress is an ERC-20 like address derived from the currency; the native coins have no address
+func tokenAddress(ID uint) string {
+	if ID == 1 || ID == 1027 {
+		return ""
+	}
+	sum := sha1.Sum([]byte(strconv.FormatUint(uint64(ID), 10)))
+	return "0x" + hex.EncodeToString(sum[:])
+}
+
+func parseID(s string) (uint, error) {
+	ID, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || ID == 0 {
+		return 0, fmt.Errorf("wrong currency ID %q", s)
+	}
+	return uint(ID), nil
+}
+
+func cmcStatus() cmc_api.Status {
+	return cmc_api.Status{
+		Timestamp:    time.Now().UTC().Format(time.RFC3339),
+		ErrorCode:    "0",
+		ErrorMessage: cmc_api.ErrorMessage_Success,
+		Elapsed:      "1",
+		CreditCount:  1,
+	}
+}
+
+func (g *generator) DetailChart(id string, chartRange string) (*cmc_api.DetailChartResponse, error) {
+	currencyID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err = cmc_api.ChartRangeValidate(chartRange); err != nil {
+		return nil, err
+	}
+
+	step, nb := time.Hour*24, 0
+	switch chartRange {
+	case cmc_api.ChartRange_1D:
+		step, nb = time.Hour, 24
+	case cmc_api.ChartRange_7D:
+		step, nb = time.Hour, 24*7
+	case cmc_api.ChartRange_1M:
+		nb = 30
+	case cmc_api.ChartRange_1Y:
+		nb = 365
+	case cmc_api.ChartRange_All:
+		nb = generatorAllDays
+	}
+
+	end := time.Now().UTC().Truncate(step)
+	base := g.basePrice(currencyID)
+	points := make(cmc_api.DetailChartPoints, nb)
+	var t int64
+	var price float64
+	for i := 0; i < nb; i++ {
+		t = end.Add(-step * time.Duration(i)).Unix()
+		price = base * g.wave(currencyID, t, "price")
+		points[strconv.FormatInt(t, 10)] = cmc_api.DetailChartPoint{
+			V: []float64{price, price * 1e6 * g.wave(currencyID, t, "volume"), price * 1e8},
+		}
+	}
+
+	return &cmc_api.DetailChartResponse{
+		Data:   &cmc_api.DetailChartData{Points: &points},
+		Status: cmcStatus(),
+	}, nil
+}
+
+func (g *generator) Analytics(id string, analyticsRange string) (*cmc_api.GetAnalyticsResponse, error) {
+	currencyID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err = cmc_api.AnalyticsRangeValidate(analyticsRange); err != nil {
+		return nil, err
+	}
+
+	nb := generatorAllDays
+	switch analyticsRange {
+	case cmc_api.AnalyticsRange_1M:
+		nb = 30
+	case cmc_api.AnalyticsRange_1Y:
+		nb = 365
+	}
+
+	end := time.Now().UTC().Truncate(time.Hour * 24)
+	details := make([]cmc_api.HistoricalConcentrationDetailsPoint, 0, nb)
+	var d time.Time
+	var whales, investors float64
+	for i := 0; i < nb; i++ {
+		d = end.AddDate(0, 0, -i)
+		whales = 40 * g.wave(currencyID, d.Unix(), "whales")
+		investors = 30 * g.wave(currencyID, d.Unix(), "investors")
+		details = append(details, cmc_api.HistoricalConcentrationDetailsPoint{
+			Date:      d.Format(time.DateOnly),
+			Whales:    whales,
+			Investors: investors,
+			Retail:    100 - whales - investors,
+		})
+	}
+
+	return &cmc_api.GetAnalyticsResponse{
+		Data: &cmc_api.GetAnalyticsData{
+			HistoricalConcentration: &cmc_api.HistoricalConcentration{
+				HistoricalConcentrationDetails: &details,
+			},
+		},
+		Status: cmcStatus(),
+	}, nil
+}
+
+func (g *generator) CurrencySimple(slug string, category string, limit uint) (*cmc_api.GetCurrencyResponse, error) {
+	if slug == "" {
+		return nil, fmt.Errorf("slug is required")
+	}
+	ID := slugID(slug)
+	pairs := g.marketPairs(ID, slug, category, limit, time.Now().UTC())
+	return &cmc_api.GetCurrencyResponse{
+		Data: &cmc_api.CurrencyData{
+			ID:             ID,
+			Symbol:         symbol(slug),
+			Slug:           slug,
+			Name:           slug,
+			NumMarketPairs: uint(len(mockExchanges) * len(mockQuoteSymbols)),
+			MarketPairs:    pairs,
+		},
+		Status: cmcStatus(),
+	}, nil
+}
+
+// mockExchanges are the venues of the market pairs; ID, name, slug
+var mockExchanges = []struct {
+	ID   uint
+	Name string
+	Slug string
+}{
+	{270, "Binance", "binance"},
+	{294, "OKX", "okx"},
+	{311, "Bybit", "bybit"},
+	{89, "Coinbase Exchange", "coinbase-exchange"},
+	{24, "Kraken", "kraken"},
+}
+
+var mockQuoteSymbols = []string{"USDT", "USDC"}
+
+// marketPairs returns the pairs of the category sorted by the volume, the spot is the default category
+func (g *generator) marketPairs(ID uint, slug string, category string, limit uint, now time.Time) []cmc_api.MarketPairData {
+	if category == "" {
+		category = "spot"
+	}
+	t := now.Truncate(time.Hour).Unix()
+	price := g.basePrice(ID) * g.wave(ID, t, "price")
+	base := symbol(slug)
+	// the markets of the categories have different IDs
+	var marketOffset uint
+	if category == "spot" {
+		marketOffset = 500
+	}
+	res := make([]cmc_api.MarketPairData, 0, len(mockExchanges)*len(mockQuoteSymbols))
+	for i, exchange := range mockExchanges {
+		for j, quote := range mockQuoteSymbols {
+			salt := category + exchange.Slug + quote
+			volume := 1e6 * float64(ID%97+1) * (1 + g.noise(ID, t, salt)) / float64(i+j+1)
+			pair := cmc_api.MarketPairData{
+				ExchangeID:   exchange.ID,
+				ExchangeName: exchange.Name,
+				ExchangeSlug: exchange.Slug,
+				MarketID:     ID*1000 + marketOffset + exchange.ID*2 + uint(j),
+				MarketPair:   base + "/" + quote,
+				Category:     category,
+				BaseSymbol:   base,
+				QuoteSymbol:  quote,
+				Price:        price * (1 + 0.001*g.noise(ID, t, salt+"price")),
+				VolumeUsd:    volume,
+			}
+			if category == "spot" {
+				pair.DepthUsdNegativeTwo = volume * 0.01 * (1 + 0.5*g.noise(ID, t, salt+"neg"))
+				pair.DepthUsdPositiveTwo = volume * 0.01 * (1 + 0.5*g.noise(ID, t, salt+"pos"))
+			}
+			res = append(res, pair)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].VolumeUsd > res[j].VolumeUsd
+	})
+	if limit > 0 && uint(len(res)) > limit {
+		res = res[:limit]
+	}
+	return res
+}
+
+func symbol(slug string) string {
+	res := make([]byte, 0, 4)
+	var i int
+	for i = 0; i < len(slug) && len(res) < 4; i++ {
+		if c := slug[i]; c >= 'a' && c <= 'z' {
+			res = append(res, c-'a'+'A')
+		}
+	}
+	return string(res)
+}
+
+func (g *generator) PortfolioSummary(portfolioSourceID string) *cmc_api.GetPortfolioSummaryResponse {
+	now := time.Now().UTC().Truncate(time.Hour)
+	list := make(cmc_api.PortfolioItemList, 0, generatorPortfolioSize)
+	IDs := []uint{1, 1027, 825, 1839, 5426}
+	var ID uint
+	var price, amount float64
+	for _, ID = range IDs[:generatorPortfolioSize] {
+		price = g.basePrice(ID) * g.wave(ID, now.Unix(), "price")
+		amount = 10 * (1 + g.noise(ID, int64(crc32.ChecksumIEEE([]byte(portfolioSourceID))), "amount"))
+		list = append(list, cmc_api.PortfolioItem{
+			PortfolioSourceID: portfolioSourceID,
+			CurrencyID:        ID,
+			Amount:            amount,
+			CurrentPrice:      price,
+			CryptoHoldings:    amount * price,
+			BuyAvgPrice:       price * 0.9,
+			PlPercentValue:    10,
+			PlValue:           amount * price * 0.1,
+			TotalBuySpent:     amount * price * 0.9,
+			UpdatedAt:         now,
+		})
+	}
+
+	return &cmc_api.GetPortfolioSummaryResponse{
+		Data: &cmc_api.PortfolioSummary{
+			PortfolioType: "manual",
+			ManualSummary: []cmc_api.PortfolioContent{{CurrentPage: 1, List: list}},
+		},
+		Status: cmcStatus(),
+	}
+}
+
+func (g *generator) ProQuotes(IDs []string, slugs []string) (*cmc_pro_api.CurrencyQuotesResponse, error) {
+	currencyIDs := make([]uint, 0, len(IDs)+len(slugs))
+	var item string
+	for _, item = range IDs {
+		ID, err := parseID(item)
+		if err != nil {
+			return nil, err
+		}
+		currencyIDs = append(currencyIDs, ID)
+	}
+	for _, item = range slugs {
+		currencyIDs = append(currencyIDs, slugID(item))
+	}
+	if len(currencyIDs) == 0 {
+		return nil, fmt.Errorf("id or slug is required")
+	}
+
+	now := time.Now().UTC()
+	data := make(cmc_pro_api.CurrencyQuoteMap, len(currencyIDs))
+	var ID uint
+	for _, ID = range currencyIDs {
+		data[strconv.FormatUint(uint64(ID), 10)] = g.proQuote(ID, ID%500+1, now)
+	}
+
+	return &cmc_pro_api.CurrencyQuotesResponse{
+		Data:   data,
+		Status: proStatus(now),
+	}, nil
+}
+
+// ProListings lists the known currencies sorted by ID and then the derived ones up to listingsTotal;
+// the market cap falls with the rank, tether is the stablecoin
+func (g *generator) ProListings(start uint, limit uint) (*cmc_pro_api.ListingsResponse, error) {
+	if start == 0 {
+		return nil, fmt.Errorf("wrong start %d", start)
+	}
+	if limit == 0 || limit > listingsMaxLimit {
+		return nil, fmt.Errorf("wrong limit %d", limit)
+	}
+
+	known := make([]uint, 0, len(knownCurrencies))
+	var knownID uint
+	for _, knownID = range knownCurrencies {
+		known = append(known, knownID)
+	}
+	sort.Slice(known, func(i, j int) bool { return known[i] < known[j] })
+
+	now := time.Now().UTC()
+	data := make([]cmc_pro_api.CurrencyQuote, 0, limit)
+	var rank, ID uint
+	for rank = start; rank < start+limit && rank <= listingsTotal; rank++ {
+		ID = rank + 100_000
+		if int(rank) <= len(known) {
+			ID = known[rank-1]
+		}
+		quote := g.proQuote(ID, rank, now)
+		quote.Quote.USD.MarketCap = 1e12 / float64(rank)
+		quote.Quote.USD.Volume24h = quote.Quote.USD.MarketCap * 0.05 * (1 + g.noise(ID, now.Truncate(time.Hour).Unix(), "volume"))
+		if ID == knownCurrencies["tether"] {
+			quote.Tags = []string{"stablecoin"}
+		}
+		data = append(data, quote)
+	}
+
+	return &cmc_pro_api.ListingsResponse{
+		Data:   data,
+		Status: proStatus(now),
+	}, nil
+}
+
+// ProInfo gives the tokens the address on Ethereum and every second token the address on BNB Smart Chain
+func (g *generator) ProInfo(IDs []string) (*cmc_pro_api.InfoResponse, error) {
+	if len(IDs) == 0 {
+		return nil, fmt.Errorf("id is required")
+	}
+
+	data := make(cmc_pro_api.InfoMap, len(IDs))
+	var item string
+	for _, item = range IDs {
+		ID, err := parseID(item)
+		if err != nil {
+			return nil, err
+		}
+		slug := idSlug(ID)
+		info := cmc_pro_api.Info{
+			ID:              ID,
+			Symbol:          symbol(slug),
+			Slug:            slug,
+			ContractAddress: []cmc_pro_api.ContractAddress{},
+		}
+		if address := tokenAddress(ID); address != "" {
+			info.ContractAddress = append(info.ContractAddress, cmc_pro_api.ContractAddress{
+				ContractAddress: address,
+				Platform: cmc_pro_api.ContractPlatform{
+					Name: "Ethereum",
+					Coin: cmc_pro_api.PlatformCoin{ID: "1027", Name: "Ethereum", Symbol: "ETH", Slug: "ethereum"},
+				},
+			})
+			if ID%2 == 0 {
+				info.ContractAddress = append(info.ContractAddress, cmc_pro_api.ContractAddress{
+					ContractAddress: tokenAddress(ID + 1),
+					Platform: cmc_pro_api.ContractPlatform{
+						Name: "BNB Smart Chain (BEP20)",
+						Coin: cmc_pro_api.PlatformCoin{ID: "1839", Name: "BNB", Symbol: "BNB", Slug: "bnb"},
+					},
+				})
+			}
+		}
+		data[item] = info
+	}
+
+	return &cmc_pro_api.InfoResponse{
+		Data:   data,
+		Status: proStatus(time.Now().UTC()),
+	}, nil
+}
+
+func (g *generator) proQuote(ID uint, rank uint, now time.Time) cmc_pro_api.CurrencyQuote {
+	slug := idSlug(ID)
+	supply := 1e8 * (1 + g.noise(ID, 0, "supply"))
+	quote := cmc_pro_api.CurrencyQuote{
+		ID:                ID,
+		Symbol:            symbol(slug),
+		Slug:              slug,
+		Name:              slug,
+		CirculatingSupply: supply * 0.8,
+		TotalSupply:       supply,
+		MaxSupply:         &supply,
+		CmcRank:           rank,
+		AddedAt:           time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(ID%1000)),
+		Quote: cmc_pro_api.Quote{
+			USD: cmc_pro_api.QuoteUSD{Price: g.basePrice(ID) * g.wave(ID, now.Truncate(time.Hour).Unix(), "price")},
+		},
+	}
+	if address := tokenAddress(ID); address != "" {
+		quote.Platform = &cmc_pro_api.QuoteCurrencyPlatform{
+			ID:           1027,
+			Symbol:       "ETH",
+			Slug:         "ethereum",
+			Name:         "Ethereum",
+			TokenAddress: address,
+		}
+	}
+	return quote
+}
+
+// ProGlobalLatest returns the global metrics of the current hour
+func (g *generator) ProGlobalLatest() *cmc_pro_api.GlobalMetricsLatestResponse {
+	now := time.Now().UTC()
+	quote := g.proGlobalQuote(now.Truncate(time.Hour))
+	stablecoinCap := quote.Quote.USD.TotalMarketCap * (0.07 + 0.01*g.noise(0, quote.Timestamp.Unix(), "stablecoin"))
+	defiCap := quote.Quote.USD.TotalMarketCap * (0.04 + 0.01*g.noise(0, quote.Timestamp.Unix(), "defi"))
+	return &cmc_pro_api.GlobalMetricsLatestResponse{
+		Data: &cmc_pro_api.GlobalMetricsLatest{
+			BtcDominance:        quote.BtcDominance,
+			EthDominance:        quote.EthDominance,
+			StablecoinMarketCap: &stablecoinCap,
+			DefiMarketCap:       &defiCap,
+			LastUpdated:         now,
+			Quote:               quote.Quote,
+		},
+		Status: proStatus(now),
+	}
+}
+
+// ProGlobalHistory returns the hourly global metrics in [from, to] up to count points; the future is not generated
+func (g *generator) ProGlobalHistory(from time.Time, to time.Time, count uint) (*cmc_pro_api.GlobalMetricsHistoricalResponse, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("time_start must be before time_end")
+	}
+	now := time.Now().UTC()
+	if to.After(now) {
+		to = now
+	}
+	quotes := make([]cmc_pro_api.GlobalMetricsHistoricalQuote, 0, count)
+	for t := from.Truncate(time.Hour); !t.After(to) && uint(len(quotes)) < count; t = t.Add(time.Hour) {
+		if t.Before(from) {
+			continue
+		}
+		quotes = append(quotes, g.proGlobalQuote(t))
+	}
+	return &cmc_pro_api.GlobalMetricsHistoricalResponse{
+		Data:   &cmc_pro_api.GlobalMetricsHistorical{Quotes: quotes},
+		Status: proStatus(now),
+	}, nil
+}
+
+// proGlobalQuote is the state of the market at the time; the market has the currency ID 0 in the noise
+func (g *generator) proGlobalQuote(t time.Time) cmc_pro_api.GlobalMetricsHistoricalQuote {
+	ts := t.Unix()
+	return cmc_pro_api.GlobalMetricsHistoricalQuote{
+		Timestamp:    t,
+		BtcDominance: 50 + 5*g.noise(0, ts, "btc_dominance"),
+		EthDominance: 15 + 2*g.noise(0, ts, "eth_dominance"),
+		Quote: cmc_pro_api.GlobalMetricsQuote{
+			USD: cmc_pro_api.GlobalMetricsQuoteUSD{
+				TotalMarketCap: 2.5e12 * g.wave(0, ts, "total_cap"),
+				TotalVolume24h: 1e11 * g.wave(0, ts, "total_volume"),
+				Timestamp:      t,
+			},
+		},
+	}
+}
+
+func proStatus(now time.Time) cmc_pro_api.Status {
+	return cmc_pro_api.Status{
+		Timestamp:    now.Format(time.RFC3339),
+		ErrorMessage: cmc_pro_api.ErrorMessage_Success,
+		CreditCount:  1,
+	}
+}
+
+func (g *generator) HoldersStats(coinAddress string, startAt string, endAt string) (*oracul_analytics_api.GetHoldersStatsResponse, error) {
+	if coinAddress == "" {
+		return nil, fmt.Errorf("coin_address is required")
+	}
+	start, err := time.Parse(time.DateOnly, startAt)
+	if err != nil {
+		return nil, fmt.Errorf("start_at: %w", err)
+	}
+	end, err := time.Parse(time.DateOnly, endAt)
+	if err != nil {
+		return nil, fmt.Errorf("end_at: %w", err)
+	}
+
+	// the coin address stands for the currency in the generator
+	ID := uint(crc32.ChecksumIEEE([]byte(coinAddress)))
+	now := time.Now().UTC().Truncate(time.Hour).Unix()
+	f := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', 4, 64)
+	}
+
+	stats := make(oracul_analytics_api.DailyBalanceStatsList)
+	var d time.Time
+	for d = start; !d.After(end) && len(stats) < generatorMaxCandles; d = d.AddDate(0, 0, 1) {
+		stats[d.Format(time.DateOnly)] = oracul_analytics_api.DailyBalanceStats{
+			Whales:    &oracul_analytics_api.DailyBalanceStatsItem{Balance: f(1e7 * g.wave(ID, d.Unix(), "whales")), TotalHolders: 100},
+			Investors: &oracul_analytics_api.DailyBalanceStatsItem{Balance: f(1e6 * g.wave(ID, d.Unix(), "investors")), TotalHolders: 1000},
+			Retailers: &oracul_analytics_api.DailyBalanceStatsItem{Balance: f(1e5 * g.wave(ID, d.Unix(), "retailers")), TotalHolders: 10000},
+		}
+	}
+
+	speedometersItem := func(salt string) *oracul_analytics_api.SpeedometersItem {
+		return &oracul_analytics_api.SpeedometersItem{
+			BuyRate:  f(50 + 50*g.noise(ID, now, salt+"buy")),
+			SellRate: f(50 + 50*g.noise(ID, now, salt+"sell")),
+			Volume:   f(1e6 * g.wave(ID, now, salt+"volume")),
+		}
+	}
+	holderStatsItem := func(salt string, holders uint) *oracul_analytics_api.HolderStatsItem {
+		return &oracul_analytics_api.HolderStatsItem{
+			Volume:       f(1e6 * g.wave(ID, now, salt+"volume")),
+			TotalHolders: holders,
+		}
+	}
+
+	return &oracul_analytics_api.GetHoldersStatsResponse{
+		WhalesConcentration: f(40 * g.wave(ID, now, "concentration")),
+		WormIndex:           f(50 + 50*g.noise(ID, now, "worm")),
+		GrowthFuel:          f(50 + 50*g.noise(ID, now, "fuel")),
+		Speedometers: &oracul_analytics_api.Speedometers{
+			Whales:    speedometersItem("whales"),
+			Investors: speedometersItem("investors"),
+			Retailers: speedometersItem("retailers"),
+		},
+		HolderStats: &oracul_analytics_api.HolderStats{
+			Whales:    holderStatsItem("whales", 100),
+			Investors: holderStatsItem("investors", 1000),
+			Retailers: holderStatsItem("retailers", 10000),
+		},
+		DailyBalanceStats: stats,
+	}, nil
+}
@@ -0,0 +1,286 @@
+package mockapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	routing "github.com/qiangxue/fasthttp-routing"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+
+	"info/internal/integration/cmc_api"
+	"info/internal/integration/cmc_pro_api"
+	"info/internal/integration/oracul_analytics_api"
+)
+
+const (
+	ContentType = "application/json; charset=utf-8"
+
+	Route_DetailChart      = "detail_chart"
+	Route_Analytics        = "analytics"
+	Route_CurrencySimple   = "currency_simple"
+	Route_PortfolioSummary = "portfolio_summary"
+	Route_ProQuotes        = "pro_quotes"
+	Route_ProListings      = "pro_listings"
+	Route_ProInfo          = "pro_info"
+	Route_ProGlobalLatest  = "pro_global_latest"
+	Route_ProGlobalHistory = "pro_global_history"
+	Route_HoldersStats     = "holders_stats"
+
+	URI_Failures = "/mock/failures"
+
+	fixtureName_Default = "default"
+	shutdownTimeout     = 5 * time.Second
+)
+
+// Config of the mock server. The fixture of a request is read from FixturesDir/<route>/<key>.json,
+// where the key is the currency ID, the slug, the portfolio source ID or the coin address of the request, then from FixturesDir/<route>/default.json;
+// without a fixture the response is built by the deterministic generator seeded by Seed.
+type Config struct {
+	Addr        string
+	FixturesDir string
+	Seed        int64
+	Failures    []Failure
+}
+
+// MockAPI emulates the routes of the CMC, CMC Pro and Oracul APIs used by the integration clients
+type MockAPI struct {
+	config    *Config
+	logger    *zap.Logger
+	server    *fasthttp.Server
+	generator *generator
+	mu        sync.RWMutex
+	failures  []*failureRule
+}
+
+func New(cfg *Config, logger *zap.Logger) *MockAPI {
+	m := &MockAPI{
+		config:    cfg,
+		logger:    logger,
+		generator: newGenerator(cfg.Seed),
+		server: &fasthttp.Server{
+			Name:            "mockapi",
+			CloseOnShutdown: true,
+		},
+	}
+	m.SetFailures(cfg.Failures)
+	m.buildHandler()
+	return m
+}
+
+func (m *MockAPI) buildHandler() {
+	r := routing.New()
+	r.Use(m.SetContentTypeMiddleware)
+
+	r.Get(cmc_api.URI_GetDetailChart, m.withFailures(Route_DetailChart, m.DetailChart))
+	r.Get(cmc_api.URI_GetAnalytics, m.withFailures(Route_Analytics, m.Analytics))
+	r.Get(cmc_api.URI_GetCurrencySimple, m.withFailures(Route_CurrencySimple, m.CurrencySimple))
+	r.Post(cmc_api.URI_GetPortfolioSummary, m.withFailures(Route_PortfolioSummary, m.PortfolioSummary))
+	r.Get(cmc_pro_api.URI_GetCurrencies, m.withFailures(Route_ProQuotes, m.ProQuotes))
+	r.Get(cmc_pro_api.URI_GetListings, m.withFailures(Route_ProListings, m.ProListings))
+	r.Get(cmc_pro_api.URI_GetInfo, m.withFailures(Route_ProInfo, m.ProInfo))
+	r.Get(cmc_pro_api.URI_GetGlobalMetricsLatest, m.withFailures(Route_ProGlobalLatest, m.ProGlobalLatest))
+	r.Get(cmc_pro_api.URI_GetGlobalMetricsHistorical, m.withFailures(Route_ProGlobalHistory, m.ProGlobalHistory))
+	r.Get(oracul_analytics_api.URI_GetHoldersStats, m.withFailures(Route_HoldersStats, m.HoldersStats))
+
+	r.Get(URI_Failures, m.GetFailures)
+	r.Put(URI_Failures, m.PutFailures)
+	r.Delete(URI_Failures, m.DeleteFailures)
+
+	m.server.Handler = r.HandleRequest
+}
+
+// Run serves the mock API on the configured address until the context is cancelled
+func (m *MockAPI) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", m.config.Addr)
+	if err != nil {
+		return err
+	}
+	return m.Serve(ctx, ln)
+}
+
+// Serve serves the mock API on the listener until the context is cancelled
+func (m *MockAPI) Serve(ctx context.Context, ln net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := m.server.ShutdownWithContext(shutdownCtx); err != nil {
+			m.logger.Error("mockapi: server shutdown error", zap.Error(err))
+		}
+	}()
+
+	m.logger.Info("mockapi: server is started", zap.String("addr", ln.Addr().String()))
+	return m.server.Serve(ln)
+}
+
+func (m *MockAPI) SetContentTypeMiddleware(rctx *routing.Context) error {
+	rctx.Response.Header.Set("Content-Type", ContentType)
+	return nil
+}
+
+// fixture returns the fixture of the route for the key or the default fixture of the route
+func (m *MockAPI) fixture(route string, key string) ([]byte, bool) {
+	if m.config.FixturesDir == "" {
+		return nil, false
+	}
+
+	names := []string{fixtureName_Default}
+	if key != "" {
+		names = []string{key, fixtureName_Default}
+	}
+	var name string
+	for _, name = range names {
+		data, err := os.ReadFile(filepath.Join(m.config.FixturesDir, route, filepath.Base(name)+".json"))
+		if err == nil {
+			return data, true
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			m.logger.Error("mockapi: fixture read error", zap.String("route", route), zap.String("name", name), zap.Error(err))
+		}
+	}
+	return nil, false
+}
+
+// write writes the fixture of the route for the key if it exists, otherwise the generated response
+func (m *MockAPI) write(rctx *routing.Context, route string, key string, generate func() (interface{}, error)) error {
+	if data, ok := m.fixture(route, key); ok {
+		rctx.SetStatusCode(fasthttp.StatusOK)
+		rctx.SetBody(data)
+		return nil
+	}
+
+	resp, err := generate()
+	if err != nil {
+		return writeError(rctx, fasthttp.StatusBadRequest, err.Error())
+	}
+	return writeJSON(rctx, fasthttp.StatusOK, resp)
+}
+
+func writeJSON(rctx *routing.Context, status int, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	rctx.SetStatusCode(status)
+	rctx.SetBody(data)
+	return nil
+}
+
+func writeError(rctx *routing.Context, status int, message string) error {
+	return writeJSON(rctx, status, map[string]string{"error": message})
+}
+
+func (m *MockAPI) DetailChart(rctx *routing.Context) error {
+	id := string(rctx.QueryArgs().Peek("id"))
+	return m.write(rctx, Route_DetailChart, id, func() (interface{}, error) {
+		return m.generator.DetailChart(id, string(rctx.QueryArgs().Peek("range")))
+	})
+}
+
+func (m *MockAPI) Analytics(rctx *routing.Context) error {
+	id := string(rctx.QueryArgs().Peek("cryptoId"))
+	return m.write(rctx, Route_Analytics, id, func() (interface{}, error) {
+		return m.generator.Analytics(id, string(rctx.QueryArgs().Peek("timeRangeType")))
+	})
+}
+
+func (m *MockAPI) CurrencySimple(rctx *routing.Context) error {
+	slug := string(rctx.QueryArgs().Peek("slug"))
+	return m.write(rctx, Route_CurrencySimple, slug, func() (interface{}, error) {
+		limit, _ := strconv.ParseUint(string(rctx.QueryArgs().Peek("limit")), 10, 64)
+		return m.generator.CurrencySimple(slug, string(rctx.QueryArgs().Peek("category")), uint(limit))
+	})
+}
+
+func (m *MockAPI) PortfolioSummary(rctx *routing.Context) error {
+	req := &cmc_api.GetPortfolioSummaryRequest{}
+	if err := json.Unmarshal(rctx.PostBody(), req); err != nil {
+		return writeError(rctx, fasthttp.StatusBadRequest, err.Error())
+	}
+	return m.write(rctx, Route_PortfolioSummary, req.PortfolioSourceId, func() (interface{}, error) {
+		return m.generator.PortfolioSummary(req.PortfolioSourceId), nil
+	})
+}
+
+func (m *MockAPI) ProQuotes(rctx *routing.Context) error {
+	IDs := splitQueryArg(rctx, "id")
+	slugs := splitQueryArg(rctx, "slug")
+	key := strings.Join(append(IDs, slugs...), ",")
+	return m.write(rctx, Route_ProQuotes, key, func() (interface{}, error) {
+		return m.generator.ProQuotes(IDs, slugs)
+	})
+}
+
+func (m *MockAPI) ProListings(rctx *routing.Context) error {
+	args := rctx.QueryArgs()
+	start, limit := string(args.Peek("start")), string(args.Peek("limit"))
+	return m.write(rctx, Route_ProListings, start+"-"+limit, func() (interface{}, error) {
+		startNb, err := strconv.ParseUint(start, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		limitNb, err := strconv.ParseUint(limit, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return m.generator.ProListings(uint(startNb), uint(limitNb))
+	})
+}
+
+func (m *MockAPI) ProInfo(rctx *routing.Context) error {
+	IDs := splitQueryArg(rctx, "id")
+	return m.write(rctx, Route_ProInfo, strings.Join(IDs, ","), func() (interface{}, error) {
+		return m.generator.ProInfo(IDs)
+	})
+}
+
+func (m *MockAPI) ProGlobalLatest(rctx *routing.Context) error {
+	return m.write(rctx, Route_ProGlobalLatest, "", func() (interface{}, error) {
+		return m.generator.ProGlobalLatest(), nil
+	})
+}
+
+func (m *MockAPI) ProGlobalHistory(rctx *routing.Context) error {
+	args := rctx.QueryArgs()
+	timeStart, timeEnd, count := string(args.Peek("time_start")), string(args.Peek("time_end")), string(args.Peek("count"))
+	return m.write(rctx, Route_ProGlobalHistory, timeStart+"-"+timeEnd+"-"+count, func() (interface{}, error) {
+		from, err := strconv.ParseInt(timeStart, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		to, err := strconv.ParseInt(timeEnd, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		countNb, err := strconv.ParseUint(count, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return m.generator.ProGlobalHistory(time.Unix(from, 0).UTC(), time.Unix(to, 0).UTC(), uint(countNb))
+	})
+}
+
+func (m *MockAPI) HoldersStats(rctx *routing.Context) error {
+	args := rctx.QueryArgs()
+	coinAddress := string(args.Peek("coin_address"))
+	return m.write(rctx, Route_HoldersStats, coinAddress, func() (interface{}, error) {
+		return m.generator.HoldersStats(coinAddress, string(args.Peek("start_at")), string(args.Peek("end_at")))
+	})
+}
+
+func splitQueryArg(rctx *routing.Context, name string) []string {
+	val := string(rctx.QueryArgs().Peek(name))
+	if val == "" {
+		return nil
+	}
+	return strings.Split(val, ",")
+}
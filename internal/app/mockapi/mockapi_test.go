@@ -0,0 +1,218 @@
+package mockapi
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/minipkg/httpclient"
+	"go.uber.org/zap"
+
+	"info/internal/integration/cmc_api"
+	"info/internal/integration/cmc_pro_api"
+	"info/internal/integration/oracul_analytics_api"
+)
+
+var clientNb atomic.Uint64
+
+// startMockAPI serves the mock API on a random local port until the end of the test
+func startMockAPI(t *testing.T, cfg *Config) (*MockAPI, httpclient.Config) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m := New(cfg, zap.NewNop())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.Serve(ctx, ln)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return m, httpclient.Config{
+		// every client registers its own metrics, so the names have to differ
+		Name:    "mockapi_test_" + strconv.FormatUint(clientNb.Add(1), 10),
+		Host:    "http://" + ln.Addr().String(),
+		Timeout: time.Second,
+	}
+}
+
+func newCmcClient(httpConfig httpclient.Config) *cmc_api.CmcApiClient {
+	return cmc_api.New(&cmc_api.AppConfig{NameSpace: "test", Subsystem: "mockapi", Service: "test"}, &cmc_api.Config{Httpconfig: httpConfig}, zap.NewNop())
+}
+
+func TestMockAPI_Generator(t *testing.T) {
+	_, httpConfig := startMockAPI(t, &Config{Seed: 1})
+	ctx := context.Background()
+	cmcClient := newCmcClient(httpConfig)
+
+	chart, err := cmcClient.GetDetailChart(ctx, 1, cmc_api.ChartRange_1M)
+	if err != nil {
+		t.Fatalf("GetDetailChart error: %v", err)
+	}
+	if len(*chart) != 30 {
+		t.Errorf("GetDetailChart: got %d points, want 30", len(*chart))
+	}
+	chartAgain, err := cmcClient.GetDetailChart(ctx, 1, cmc_api.ChartRange_1M)
+	if err != nil {
+		t.Fatalf("GetDetailChart error: %v", err)
+	}
+	if chart.MaxTime().Unix() != chartAgain.MaxTime().Unix() || (*chart.Slice())[0].CurrencyID != 1 {
+		t.Errorf("GetDetailChart: the responses are not deterministic")
+	}
+
+	concentrations, err := cmcClient.GetAnalytics(ctx, 1027, cmc_api.ChartRange_1Y)
+	if err != nil {
+		t.Fatalf("GetAnalytics error: %v", err)
+	}
+	if len(*concentrations) != 365 {
+		t.Errorf("GetAnalytics: got %d points, want 365", len(*concentrations))
+	}
+
+	item, err := cmcClient.GetCurrency(ctx, "bitcoin")
+	if err != nil {
+		t.Fatalf("GetCurrency error: %v", err)
+	}
+	if item.ID != 1 || item.Slug != "bitcoin" {
+		t.Errorf("GetCurrency: got ID %d slug %q, want 1 bitcoin", item.ID, item.Slug)
+	}
+
+	portfolio, err := cmcClient.GetPortfolioSummary(ctx, "source")
+	if err != nil {
+		t.Fatalf("GetPortfolioSummary error: %v", err)
+	}
+	if len(*portfolio) != generatorPortfolioSize {
+		t.Errorf("GetPortfolioSummary: got %d items, want %d", len(*portfolio), generatorPortfolioSize)
+	}
+
+	httpConfig.Name += "_pro"
+	proClient := cmc_pro_api.New(&cmc_pro_api.AppConfig{NameSpace: "test", Subsystem: "mockapi", Service: "test"}, &cmc_pro_api.Config{Httpconfig: httpConfig}, zap.NewNop())
+	currencyMap, err := proClient.GetCurrenciesBySlugs(ctx, &[]string{"bitcoin", "chainlink"})
+	if err != nil {
+		t.Fatalf("GetCurrenciesBySlugs error: %v", err)
+	}
+	if len(currencyMap) != 2 || currencyMap[1975].Platform == nil || currencyMap[1].Platform != nil {
+		t.Errorf("GetCurrenciesBySlugs: unexpected result %+v", currencyMap)
+	}
+
+	httpConfig.Name += "_oracul"
+	oraculClient := oracul_analytics_api.New(&oracul_analytics_api.AppConfig{NameSpace: "test", Subsystem: "mockapi", Service: "test"}, &oracul_analytics_api.Config{Httpconfig: httpConfig}, zap.NewNop())
+	startAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	importData, err := oraculClient.GetHoldersStats(ctx, 1975, "ETH", tokenAddress(1975), startAt, startAt.AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatalf("GetHoldersStats error: %v", err)
+	}
+	if len(*importData.OraculDailyBalanceStatsList) != 3 || importData.OraculAnalytics == nil {
+		t.Errorf("GetHoldersStats: unexpected result %+v", importData)
+	}
+}
+
+func TestMockAPI_Failures(t *testing.T) {
+	m, httpConfig := startMockAPI(t, &Config{Seed: 1})
+	httpConfig.Timeout = 300 * time.Millisecond
+	ctx := context.Background()
+	cmcClient := newCmcClient(httpConfig)
+
+	tests := []struct {
+		name    string
+		failure Failure
+	}{
+		{name: "status", failure: Failure{Mode: FailureMode_Status, Route: Route_DetailChart, Status: 503}},
+		{name: "error_code", failure: Failure{Mode: FailureMode_ErrorCode, Route: Route_DetailChart}},
+		{name: "malformed", failure: Failure{Mode: FailureMode_Malformed}},
+		{name: "slow", failure: Failure{Mode: FailureMode_Slow, Route: Route_DetailChart, Delay: time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.SetFailures([]Failure{tt.failure})
+			defer m.SetFailures(nil)
+			if _, err := cmcClient.GetDetailChart(ctx, 1, cmc_api.ChartRange_1M); err == nil {
+				t.Errorf("GetDetailChart: got no error with the %s failure", tt.name)
+			}
+		})
+	}
+
+	t.Run("other route", func(t *testing.T) {
+		m.SetFailures([]Failure{{Mode: FailureMode_Status, Route: Route_HoldersStats}})
+		defer m.SetFailures(nil)
+		if _, err := cmcClient.GetDetailChart(ctx, 1, cmc_api.ChartRange_1M); err != nil {
+			t.Errorf("GetDetailChart: got error %v with the failure of another route", err)
+		}
+	})
+
+	t.Run("rate", func(t *testing.T) {
+		m.SetFailures([]Failure{{Mode: FailureMode_Status, Route: Route_DetailChart, Rate: 0.5}})
+		defer m.SetFailures(nil)
+		for i := 1; i <= 4; i++ {
+			_, err := cmcClient.GetDetailChart(ctx, 1, cmc_api.ChartRange_1M)
+			if wantErr := i%2 == 0; (err != nil) != wantErr {
+				t.Errorf("request %d: got error %v, want error %v", i, err, wantErr)
+			}
+		}
+	})
+}
+
+func TestMockAPI_Fixtures(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, Route_DetailChart), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	fixture := `{"data":{"points":{"1735689600":{"v":[100,2000,30000]}}},"status":{"error_code":"0","error_message":"SUCCESS"}}`
+	if err := os.WriteFile(filepath.Join(dir, Route_DetailChart, "5.json"), []byte(fixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, httpConfig := startMockAPI(t, &Config{FixturesDir: dir})
+	cmcClient := newCmcClient(httpConfig)
+
+	chart, err := cmcClient.GetDetailChart(context.Background(), 5, cmc_api.ChartRange_All)
+	if err != nil {
+		t.Fatalf("GetDetailChart error: %v", err)
+	}
+	if len(*chart) != 1 || (*chart)[0].Price != 100 {
+		t.Errorf("GetDetailChart: got %+v, want the fixture", *chart)
+	}
+
+	// a currency without a fixture gets the generated response
+	chart, err = cmcClient.GetDetailChart(context.Background(), 6, cmc_api.ChartRange_1M)
+	if err != nil {
+		t.Fatalf("GetDetailChart error: %v", err)
+	}
+	if len(*chart) != 30 {
+		t.Errorf("GetDetailChart: got %d points, want 30", len(*chart))
+	}
+}
+
+func TestParseFailure(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Failure
+		wantErr bool
+	}{
+		{in: "mode=status,route=holders_stats,rate=0.5,status=503", want: Failure{Mode: FailureMode_Status, Route: Route_HoldersStats, Rate: 0.5, Status: 503}},
+		{in: "mode=slow,delay=2s", want: Failure{Mode: FailureMode_Slow, Delay: 2 * time.Second}},
+		{in: "mode=unknown", wantErr: true},
+		{in: "mode=status,rate=2", wantErr: true},
+		{in: "mode", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFailure(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFailure(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseFailure(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -2,13 +2,16 @@ package controller
 
 import (
 	"errors"
+	"fmt"
 	routing "github.com/qiangxue/fasthttp-routing"
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
 	"info/internal/domain/currency"
+	"info/internal/domain/trend"
 	"info/internal/pkg/apperror"
 	"info/internal/pkg/fasthttp_tools"
 	"info/internal/pkg/log_key"
+	"time"
 )
 
 const (
@@ -29,82 +32,318 @@ func NewCmcController(logger *zap.Logger, router *routing.Router, service *curre
 	}
 }
 
+// Report_BiggestFall returns the latest falls of the cohort with the biggest first; see parseTrendQuery for the params
 func (c *cmcController) Report_BiggestFall(rctx *routing.Context) (err error) {
-	const metricName = "transitTariffController.Report_BiggestFall"
+	const metricName = "cmcController.Report_BiggestFall"
 	ctx := rctx.RequestCtx
-	var res *fasthttp_tools.Response
 
-	limit, err := fasthttp_tools.ParseQueryArgUint(ctx, "limit")
+	query, limit, ok := c.parseReportArgs(ctx, metricName, trend.Direction_Fall)
+	if !ok {
+		return nil
+	}
+
+	report, err := c.service.Report_BiggestFall(ctx, query, limit)
 	if err != nil {
-		if !errors.Is(err, apperror.ErrNotFound) {
-			errMsg := "Parse params error "
-			c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
-			res = fasthttp_tools.NewResponse_ErrBadRequest(errMsg + err.Error())
-			fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusBadRequest, *res)
-			return nil
-		}
-		limit = defaultLimit4Report
+		c.writeReportError(ctx, metricName, "Report_BiggestFall", err)
+		return nil
 	}
+	c.writeReport(ctx, metricName, *report)
+	return nil
+}
+
+// Report_LongestFall returns the latest falls of the cohort with the longest first; see parseTrendQuery for the params
+func (c *cmcController) Report_LongestFall(rctx *routing.Context) (err error) {
+	const metricName = "cmcController.Report_LongestFall"
+	ctx := rctx.RequestCtx
 
-	report, err := c.service.Report_BiggestFall(ctx, limit)
+	query, limit, ok := c.parseReportArgs(ctx, metricName, trend.Direction_Fall)
+	if !ok {
+		return nil
+	}
+
+	report, err := c.service.Report_LongestFall(ctx, query, limit)
 	if err != nil {
-		if errors.Is(err, apperror.ErrNotFound) {
-			errMsg := "Data for Report_BiggestFall was not found"
-			c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
-			res = fasthttp_tools.NewResponse_ErrNotFound(errMsg)
-			fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusNotFound, *res)
-			return nil
-		}
-		errMsg := "Failed to get Report_BiggestFall"
-		c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
-		res = fasthttp_tools.NewResponse_ErrInternal()
-		fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusInternalServerError, *res)
+		c.writeReportError(ctx, metricName, "Report_LongestFall", err)
 		return nil
 	}
+	c.writeReport(ctx, metricName, *report)
+	return nil
+}
 
-	res = fasthttp_tools.NewResponse_Success(*report)
-	if err = fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusOK, *report); err != nil {
-		c.logger.Error("fasthttp_tools.FastHTTPWriteResult error", zap.String(log_key.Func, metricName), zap.Error(err))
+// Report_BiggestRise returns the latest rises of the cohort with the biggest first; see parseTrendQuery for the params
+func (c *cmcController) Report_BiggestRise(rctx *routing.Context) (err error) {
+	const metricName = "cmcController.Report_BiggestRise"
+	ctx := rctx.RequestCtx
+
+	query, limit, ok := c.parseReportArgs(ctx, metricName, trend.Direction_Rise)
+	if !ok {
+		return nil
 	}
+
+	report, err := c.service.Report_BiggestRise(ctx, query, limit)
+	if err != nil {
+		c.writeReportError(ctx, metricName, "Report_BiggestRise", err)
+		return nil
+	}
+	c.writeReport(ctx, metricName, *report)
 	return nil
 }
 
-func (c *cmcController) Report_LongestFall(rctx *routing.Context) (err error) {
-	const metricName = "transitTariffController.Report_BiggestFall"
+// Report_LongestRise returns the latest rises of the cohort with the longest first; see parseTrendQuery for the params
+func (c *cmcController) Report_LongestRise(rctx *routing.Context) (err error) {
+	const metricName = "cmcController.Report_LongestRise"
+	ctx := rctx.RequestCtx
+
+	query, limit, ok := c.parseReportArgs(ctx, metricName, trend.Direction_Rise)
+	if !ok {
+		return nil
+	}
+
+	report, err := c.service.Report_LongestRise(ctx, query, limit)
+	if err != nil {
+		c.writeReportError(ctx, metricName, "Report_LongestRise", err)
+		return nil
+	}
+	c.writeReport(ctx, metricName, *report)
+	return nil
+}
+
+// Report_BiggestTrend returns the latest trends of the direction (fall by default) with the biggest change first
+func (c *cmcController) Report_BiggestTrend(rctx *routing.Context) (err error) {
+	const metricName = "cmcController.Report_BiggestTrend"
+	ctx := rctx.RequestCtx
+
+	direction, ok := c.parseDirection(ctx, metricName)
+	if !ok {
+		return nil
+	}
+	query, limit, ok := c.parseReportArgs(ctx, metricName, direction)
+	if !ok {
+		return nil
+	}
+
+	report, err := c.service.Report_BiggestTrend(ctx, query, limit)
+	if err != nil {
+		c.writeReportError(ctx, metricName, "Report_BiggestTrend", err)
+		return nil
+	}
+	c.writeReport(ctx, metricName, *report)
+	return nil
+}
+
+// Report_LongestTrend returns the latest trends of the direction (fall by default) with the longest one first
+func (c *cmcController) Report_LongestTrend(rctx *routing.Context) (err error) {
+	const metricName = "cmcController.Report_LongestTrend"
 	ctx := rctx.RequestCtx
-	var res *fasthttp_tools.Response
 
+	direction, ok := c.parseDirection(ctx, metricName)
+	if !ok {
+		return nil
+	}
+	query, limit, ok := c.parseReportArgs(ctx, metricName, direction)
+	if !ok {
+		return nil
+	}
+
+	report, err := c.service.Report_LongestTrend(ctx, query, limit)
+	if err != nil {
+		c.writeReportError(ctx, metricName, "Report_LongestTrend", err)
+		return nil
+	}
+	c.writeReport(ctx, metricName, *report)
+	return nil
+}
+
+// Report_AthAtl returns the distance of the last price from the extremes of the period (all the time by default);
+// observing is true (by default), false or all, sort is the column, order is asc (by default) or desc
+func (c *cmcController) Report_AthAtl(rctx *routing.Context) (err error) {
+	const metricName = "cmcController.Report_AthAtl"
+	ctx := rctx.RequestCtx
+
+	query, err := c.parseAthAtlQuery(ctx)
+	if err != nil {
+		c.writeBadRequest(ctx, metricName, err)
+		return nil
+	}
+	limit, ok := c.parseReportLimit(ctx, metricName)
+	if !ok {
+		return nil
+	}
+
+	report, err := c.service.Report_AthAtl(ctx, query, limit)
+	if err != nil {
+		c.writeReportError(ctx, metricName, "Report_AthAtl", err)
+		return nil
+	}
+	c.writeReport(ctx, metricName, *report)
+	return nil
+}
+
+// Report_Risk returns the volatility, the drawdowns and the ratios of the observed currencies with the whale metrics;
+// period is in days (365 by default), rf is the annual risk-free rate in percent, sort is the column, order is asc or desc
+func (c *cmcController) Report_Risk(rctx *routing.Context) (err error) {
+	const metricName = "cmcController.Report_Risk"
+	ctx := rctx.RequestCtx
+
+	query, err := c.parseRiskQuery(ctx)
+	if err != nil {
+		c.writeBadRequest(ctx, metricName, err)
+		return nil
+	}
+	limit, ok := c.parseReportLimit(ctx, metricName)
+	if !ok {
+		return nil
+	}
+
+	report, err := c.service.Report_Risk(ctx, query, limit)
+	if err != nil {
+		c.writeReportError(ctx, metricName, "Report_Risk", err)
+		return nil
+	}
+	c.writeReport(ctx, metricName, *report)
+	return nil
+}
+
+func (c *cmcController) parseRiskQuery(ctx *fasthttp.RequestCtx) (*currency.RiskQuery, error) {
+	period, err := fasthttp_tools.ParseQueryArgUint(ctx, "period")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+	riskFreeRate, err := fasthttp_tools.ParseQueryArgFloat(ctx, "rf")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+	sortBy, err := fasthttp_tools.ParseQueryArgString(ctx, "sort")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+	order, err := fasthttp_tools.ParseQueryArgString(ctx, "order")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+
+	return currency.NewRiskQuery(time.Duration(period)*time.Hour*24, riskFreeRate, sortBy, order)
+}
+
+func (c *cmcController) parseAthAtlQuery(ctx *fasthttp.RequestCtx) (*currency.AthAtlQuery, error) {
+	period, err := fasthttp_tools.ParseQueryArgUint(ctx, "period")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+	observing, err := fasthttp_tools.ParseQueryArgString(ctx, "observing")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+	sortBy, err := fasthttp_tools.ParseQueryArgString(ctx, "sort")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+	order, err := fasthttp_tools.ParseQueryArgString(ctx, "order")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+	if order != "" && order != "asc" && order != "desc" {
+		return nil, fmt.Errorf("[%w] the order must be asc or desc", apperror.ErrBadRequest)
+	}
+
+	return currency.NewAthAtlQuery(time.Duration(period)*time.Hour*24, observing, sortBy, order == "desc")
+}
+
+// parseDirection returns the direction of the trend, the fall by default; a bad direction is written as the bad request
+func (c *cmcController) parseDirection(ctx *fasthttp.RequestCtx, metricName string) (string, bool) {
+	direction, err := fasthttp_tools.ParseQueryArgString(ctx, "direction")
+	if err != nil {
+		return trend.Direction_Fall, true
+	}
+	if err = trend.DirectionValidate(direction); err != nil {
+		c.writeBadRequest(ctx, metricName, err)
+		return "", false
+	}
+	return direction, true
+}
+
+// parseReportArgs returns the query of the trend and the limit of the report; the bad args are written as the bad request
+func (c *cmcController) parseReportArgs(ctx *fasthttp.RequestCtx, metricName string, direction string) (*currency.TrendQuery, uint, bool) {
+	query, err := c.parseTrendQuery(ctx, direction)
+	if err != nil {
+		c.writeBadRequest(ctx, metricName, err)
+		return nil, 0, false
+	}
+	limit, ok := c.parseReportLimit(ctx, metricName)
+	if !ok {
+		return nil, 0, false
+	}
+	return query, limit, true
+}
+
+// parseTrendQuery parses the optional args of the trend: source (concentration, oracul), cohort (whales, investors, retail),
+// period and break in days (61 and 5 by default) and min, the min absolute change of the value
+func (c *cmcController) parseTrendQuery(ctx *fasthttp.RequestCtx, direction string) (*currency.TrendQuery, error) {
+	source, err := fasthttp_tools.ParseQueryArgString(ctx, "source")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+	cohort, err := fasthttp_tools.ParseQueryArgString(ctx, "cohort")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+	period, err := fasthttp_tools.ParseQueryArgUint(ctx, "period")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+	maxBreak, err := fasthttp_tools.ParseQueryArgUint(ctx, "break")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+	minMagnitude, err := fasthttp_tools.ParseQueryArgFloat(ctx, "min")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+
+	params, err := trend.NewParams(direction, time.Duration(period)*time.Hour*24, time.Duration(maxBreak)*time.Hour*24, minMagnitude)
+	if err != nil {
+		return nil, err
+	}
+	return currency.NewTrendQuery(source, cohort, params)
+}
+
+func (c *cmcController) writeBadRequest(ctx *fasthttp.RequestCtx, metricName string, err error) {
+	errMsg := "Parse params error "
+	c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+	res := fasthttp_tools.NewResponse_ErrBadRequest(errMsg + err.Error())
+	fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusBadRequest, *res)
+}
+
+// parseReportLimit returns the limit of the report, defaultLimit4Report without it; a bad limit is written as the bad request
+func (c *cmcController) parseReportLimit(ctx *fasthttp.RequestCtx, metricName string) (uint, bool) {
 	limit, err := fasthttp_tools.ParseQueryArgUint(ctx, "limit")
 	if err != nil {
 		if !errors.Is(err, apperror.ErrNotFound) {
-			errMsg := "Parse params error "
-			c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
-			res = fasthttp_tools.NewResponse_ErrBadRequest(errMsg + err.Error())
-			fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusBadRequest, *res)
-			return nil
+			c.writeBadRequest(ctx, metricName, err)
+			return 0, false
 		}
 		limit = defaultLimit4Report
 	}
+	return limit, true
+}
 
-	report, err := c.service.Report_LongestFall(ctx, limit)
-	if err != nil {
-		if errors.Is(err, apperror.ErrNotFound) {
-			errMsg := "Data for Report_BiggestFall was not found"
-			c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
-			res = fasthttp_tools.NewResponse_ErrNotFound(errMsg)
-			fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusNotFound, *res)
-			return nil
-		}
-		errMsg := "Failed to get Report_BiggestFall"
+func (c *cmcController) writeReportError(ctx *fasthttp.RequestCtx, metricName string, reportName string, err error) {
+	if errors.Is(err, apperror.ErrNotFound) {
+		errMsg := "Data for " + reportName + " was not found"
 		c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
-		res = fasthttp_tools.NewResponse_ErrInternal()
-		fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusInternalServerError, *res)
-		return nil
+		res := fasthttp_tools.NewResponse_ErrNotFound(errMsg)
+		fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusNotFound, *res)
+		return
 	}
+	errMsg := "Failed to get " + reportName
+	c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+	res := fasthttp_tools.NewResponse_ErrInternal()
+	fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusInternalServerError, *res)
+}
 
-	res = fasthttp_tools.NewResponse_Success(*report)
-	if err = fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusOK, *res); err != nil {
+func (c *cmcController) writeReport(ctx *fasthttp.RequestCtx, metricName string, data interface{}) {
+	res := fasthttp_tools.NewResponse_Success(data)
+	if err := fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusOK, *res); err != nil {
 		c.logger.Error("fasthttp_tools.FastHTTPWriteResult error", zap.String(log_key.Func, metricName), zap.Error(err))
 	}
-	return nil
 }
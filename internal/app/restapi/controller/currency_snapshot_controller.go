@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	routing "github.com/qiangxue/fasthttp-routing"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"info/internal/domain/currency_snapshot"
+	"info/internal/pkg/apperror"
+	"info/internal/pkg/fasthttp_tools"
+	"info/internal/pkg/log_key"
+	"time"
+)
+
+type currencySnapshotController struct {
+	logger  *zap.Logger
+	router  *routing.Router
+	service *currency_snapshot.Service
+}
+
+func NewCurrencySnapshotController(logger *zap.Logger, router *routing.Router, service *currency_snapshot.Service) *currencySnapshotController {
+	return &currencySnapshotController{
+		logger:  logger,
+		router:  router,
+		service: service,
+	}
+}
+
+// currencySnapshotArgs are the common args of the reports: the currency ID is required, the days [from, to], YYYY-MM-DD, are optional
+type currencySnapshotArgs struct {
+	currencyID uint
+	from       time.Time
+	to         time.Time
+}
+
+// RankChanges returns the changes of CmcRank of the currency sorted by the time
+func (c *currencySnapshotController) RankChanges(rctx *routing.Context) (err error) {
+	const metricName = "currencySnapshotController.RankChanges"
+	ctx := rctx.RequestCtx
+
+	args, err := c.parseArgs(ctx)
+	if err != nil {
+		c.writeBadRequest(ctx, metricName, err)
+		return nil
+	}
+
+	list, err := c.service.RankChanges(ctx, args.currencyID, args.from, args.to)
+	if err != nil {
+		c.writeError(ctx, metricName, "Rank changes", err)
+		return nil
+	}
+	c.writeSuccess(ctx, metricName, *list)
+	return nil
+}
+
+// SupplyInflation returns the growth of the circulating supply of the currency for every period: day (by default), week or month
+func (c *currencySnapshotController) SupplyInflation(rctx *routing.Context) (err error) {
+	const metricName = "currencySnapshotController.SupplyInflation"
+	ctx := rctx.RequestCtx
+
+	args, err := c.parseArgs(ctx)
+	if err != nil {
+		c.writeBadRequest(ctx, metricName, err)
+		return nil
+	}
+	period, err := fasthttp_tools.ParseQueryArgString(ctx, "period")
+	if err != nil {
+		if !errors.Is(err, apperror.ErrNotFound) {
+			c.writeBadRequest(ctx, metricName, err)
+			return nil
+		}
+		period = currency_snapshot.Period_Day
+	}
+
+	list, err := c.service.SupplyInflation(ctx, args.currencyID, args.from, args.to, period)
+	if err != nil {
+		if errors.Is(err, apperror.ErrBadRequest) {
+			c.writeBadRequest(ctx, metricName, err)
+			return nil
+		}
+		c.writeError(ctx, metricName, "Supply inflation", err)
+		return nil
+	}
+	c.writeSuccess(ctx, metricName, *list)
+	return nil
+}
+
+// Dilution returns the dilution of the currency toward MaxSupply sorted by the time
+func (c *currencySnapshotController) Dilution(rctx *routing.Context) (err error) {
+	const metricName = "currencySnapshotController.Dilution"
+	ctx := rctx.RequestCtx
+
+	args, err := c.parseArgs(ctx)
+	if err != nil {
+		c.writeBadRequest(ctx, metricName, err)
+		return nil
+	}
+
+	list, err := c.service.Dilution(ctx, args.currencyID, args.from, args.to)
+	if err != nil {
+		c.writeError(ctx, metricName, "Dilution", err)
+		return nil
+	}
+	c.writeSuccess(ctx, metricName, *list)
+	return nil
+}
+
+func (c *currencySnapshotController) parseArgs(ctx *fasthttp.RequestCtx) (*currencySnapshotArgs, error) {
+	args := &currencySnapshotArgs{
+		to: time.Now().UTC().Truncate(time.Hour * 24).Add(time.Hour * 24),
+	}
+
+	currencyID, err := fasthttp_tools.ParseQueryArgUint(ctx, "currency")
+	if err != nil {
+		return nil, err
+	}
+	if currencyID == 0 {
+		return nil, fmt.Errorf("[%w] the currency is required", apperror.ErrBadRequest)
+	}
+	args.currencyID = currencyID
+
+	if args.from, err = fasthttp_tools.ParseQueryArgDate(ctx, "from"); err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+	to, err := fasthttp_tools.ParseQueryArgDate(ctx, "to")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		// the last day is included
+		args.to = to.Add(time.Hour * 24)
+	}
+	return args, nil
+}
+
+func (c *currencySnapshotController) writeBadRequest(ctx *fasthttp.RequestCtx, metricName string, err error) {
+	errMsg := "Parse params error "
+	c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+	res := fasthttp_tools.NewResponse_ErrBadRequest(errMsg + err.Error())
+	fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusBadRequest, *res)
+}
+
+func (c *currencySnapshotController) writeError(ctx *fasthttp.RequestCtx, metricName string, subject string, err error) {
+	if errors.Is(err, apperror.ErrNotFound) {
+		errMsg := subject + " was not found"
+		c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+		res := fasthttp_tools.NewResponse_ErrNotFound(errMsg)
+		fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusNotFound, *res)
+		return
+	}
+	errMsg := "Failed to get " + subject
+	c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+	res := fasthttp_tools.NewResponse_ErrInternal()
+	fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusInternalServerError, *res)
+}
+
+func (c *currencySnapshotController) writeSuccess(ctx *fasthttp.RequestCtx, metricName string, data interface{}) {
+	res := fasthttp_tools.NewResponse_Success(data)
+	if err := fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusOK, *res); err != nil {
+		c.logger.Error("fasthttp_tools.FastHTTPWriteResult error", zap.String(log_key.Func, metricName), zap.Error(err))
+	}
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"errors"
+	routing "github.com/qiangxue/fasthttp-routing"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"info/internal/domain/global_metrics"
+	"info/internal/pkg/apperror"
+	"info/internal/pkg/fasthttp_tools"
+	"info/internal/pkg/log_key"
+	"time"
+)
+
+// globalMetricsDefaultDays is the window of the series without from
+const globalMetricsDefaultDays = 30
+
+type globalMetricsController struct {
+	logger  *zap.Logger
+	router  *routing.Router
+	service *global_metrics.Service
+}
+
+func NewGlobalMetricsController(logger *zap.Logger, router *routing.Router, service *global_metrics.Service) *globalMetricsController {
+	return &globalMetricsController{
+		logger:  logger,
+		router:  router,
+		service: service,
+	}
+}
+
+// GetList returns the series of the global metrics for the days [from, to], YYYY-MM-DD; the last 30 days by default
+func (c *globalMetricsController) GetList(rctx *routing.Context) (err error) {
+	const metricName = "globalMetricsController.GetList"
+	ctx := rctx.RequestCtx
+
+	to := time.Now().UTC().Truncate(time.Hour * 24).Add(time.Hour * 24)
+	day, err := fasthttp_tools.ParseQueryArgDate(ctx, "to")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		c.writeBadRequest(ctx, metricName, err)
+		return nil
+	}
+	if err == nil {
+		// the last day is included
+		to = day.Add(time.Hour * 24)
+	}
+	from, err := fasthttp_tools.ParseQueryArgDate(ctx, "from")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		c.writeBadRequest(ctx, metricName, err)
+		return nil
+	}
+	if err != nil {
+		from = to.AddDate(0, 0, -globalMetricsDefaultDays)
+	}
+
+	list, err := c.service.GetList(ctx, from, to)
+	if err != nil {
+		c.writeError(ctx, metricName, err)
+		return nil
+	}
+	c.writeSuccess(ctx, metricName, *list)
+	return nil
+}
+
+// GetLast returns the latest stored global metrics
+func (c *globalMetricsController) GetLast(rctx *routing.Context) (err error) {
+	const metricName = "globalMetricsController.GetLast"
+	ctx := rctx.RequestCtx
+
+	entity, err := c.service.GetLast(ctx)
+	if err != nil {
+		c.writeError(ctx, metricName, err)
+		return nil
+	}
+	c.writeSuccess(ctx, metricName, *entity)
+	return nil
+}
+
+func (c *globalMetricsController) writeBadRequest(ctx *fasthttp.RequestCtx, metricName string, err error) {
+	errMsg := "Parse params error "
+	c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+	res := fasthttp_tools.NewResponse_ErrBadRequest(errMsg + err.Error())
+	fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusBadRequest, *res)
+}
+
+func (c *globalMetricsController) writeError(ctx *fasthttp.RequestCtx, metricName string, err error) {
+	if errors.Is(err, apperror.ErrNotFound) {
+		errMsg := "Global metrics were not found"
+		c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+		res := fasthttp_tools.NewResponse_ErrNotFound(errMsg)
+		fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusNotFound, *res)
+		return
+	}
+	errMsg := "Failed to get global metrics"
+	c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+	res := fasthttp_tools.NewResponse_ErrInternal()
+	fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusInternalServerError, *res)
+}
+
+func (c *globalMetricsController) writeSuccess(ctx *fasthttp.RequestCtx, metricName string, data interface{}) {
+	res := fasthttp_tools.NewResponse_Success(data)
+	if err := fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusOK, *res); err != nil {
+		c.logger.Error("fasthttp_tools.FastHTTPWriteResult error", zap.String(log_key.Func, metricName), zap.Error(err))
+	}
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"errors"
+	routing "github.com/qiangxue/fasthttp-routing"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"info/internal/domain/import_run"
+	"info/internal/pkg/apperror"
+	"info/internal/pkg/fasthttp_tools"
+	"info/internal/pkg/log_key"
+)
+
+type importRunController struct {
+	logger  *zap.Logger
+	router  *routing.Router
+	service *import_run.Service
+}
+
+func NewImportRunController(logger *zap.Logger, router *routing.Router, service *import_run.Service) *importRunController {
+	return &importRunController{
+		logger:  logger,
+		router:  router,
+		service: service,
+	}
+}
+
+// GetList returns the history of the import runs, newest first; it can be filtered by the stage
+func (c *importRunController) GetList(rctx *routing.Context) (err error) {
+	const metricName = "importRunController.GetList"
+	ctx := rctx.RequestCtx
+	var res *fasthttp_tools.Response
+
+	stage, err := fasthttp_tools.ParseQueryArgString(ctx, "stage")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		errMsg := "Parse params error "
+		c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+		res = fasthttp_tools.NewResponse_ErrBadRequest(errMsg + err.Error())
+		fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusBadRequest, *res)
+		return nil
+	}
+
+	// without the limit the service uses its default one
+	limit, err := fasthttp_tools.ParseQueryArgUint(ctx, "limit")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		errMsg := "Parse params error "
+		c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+		res = fasthttp_tools.NewResponse_ErrBadRequest(errMsg + err.Error())
+		fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusBadRequest, *res)
+		return nil
+	}
+
+	list, err := c.service.GetList(ctx, stage, limit)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			errMsg := "Import runs were not found"
+			c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+			res = fasthttp_tools.NewResponse_ErrNotFound(errMsg)
+			fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusNotFound, *res)
+			return nil
+		}
+		errMsg := "Failed to get import runs"
+		c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+		res = fasthttp_tools.NewResponse_ErrInternal()
+		fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusInternalServerError, *res)
+		return nil
+	}
+
+	res = fasthttp_tools.NewResponse_Success(*list)
+	if err = fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusOK, *res); err != nil {
+		c.logger.Error("fasthttp_tools.FastHTTPWriteResult error", zap.String(log_key.Func, metricName), zap.Error(err))
+	}
+	return nil
+}
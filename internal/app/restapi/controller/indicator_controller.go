@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	routing "github.com/qiangxue/fasthttp-routing"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"info/internal/domain/indicator"
+	"info/internal/pkg/apperror"
+	"info/internal/pkg/fasthttp_tools"
+	"info/internal/pkg/log_key"
+	"time"
+)
+
+// indicatorDefaultCandles is the window of the series without from
+const indicatorDefaultCandles = 200
+
+type indicatorController struct {
+	logger  *zap.Logger
+	router  *routing.Router
+	service *indicator.Service
+}
+
+func NewIndicatorController(logger *zap.Logger, router *routing.Router, service *indicator.Service) *indicatorController {
+	return &indicatorController{
+		logger:  logger,
+		router:  router,
+		service: service,
+	}
+}
+
+// Get returns the series of the indicators of the currency for the days [from, to], YYYY-MM-DD;
+// resolution is 1h, 4h, 1d (by default) or 1w, the last 200 candles by default; the periods of the indicators are optional
+func (c *indicatorController) Get(rctx *routing.Context) (err error) {
+	const metricName = "indicatorController.Get"
+	ctx := rctx.RequestCtx
+
+	currencyID, err := fasthttp_tools.ParseQueryArgUint(ctx, "currency")
+	if err == nil && currencyID == 0 {
+		err = fmt.Errorf("[%w] the currency is required", apperror.ErrBadRequest)
+	}
+	if err != nil {
+		c.writeBadRequest(ctx, metricName, err)
+		return nil
+	}
+	resolution, err := fasthttp_tools.ParseQueryArgString(ctx, "resolution")
+	if err != nil {
+		resolution = indicator.Resolution_1D
+	}
+	if err = indicator.ResolutionValidate(resolution); err != nil {
+		c.writeBadRequest(ctx, metricName, fmt.Errorf("[%w] resolution: %w", apperror.ErrBadRequest, err))
+		return nil
+	}
+
+	to := time.Now().UTC().Truncate(time.Hour * 24).Add(time.Hour * 24)
+	day, err := fasthttp_tools.ParseQueryArgDate(ctx, "to")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		c.writeBadRequest(ctx, metricName, err)
+		return nil
+	}
+	if err == nil {
+		// the last day is included
+		to = day.Add(time.Hour * 24)
+	}
+	from, err := fasthttp_tools.ParseQueryArgDate(ctx, "from")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		c.writeBadRequest(ctx, metricName, err)
+		return nil
+	}
+	if err != nil {
+		from = to.Add(-indicator.ResolutionDuration(resolution) * indicatorDefaultCandles)
+	}
+
+	params, err := c.parseParams(ctx)
+	if err != nil {
+		c.writeBadRequest(ctx, metricName, err)
+		return nil
+	}
+
+	res, err := c.service.Get(ctx, currencyID, resolution, from, to, params)
+	if err != nil {
+		c.writeError(ctx, metricName, err)
+		return nil
+	}
+	c.writeSuccess(ctx, metricName, *res)
+	return nil
+}
+
+// parseParams returns the default params with the periods of the query: sma, ema, rsi, macd_fast, macd_slow, macd_signal, bb, bb_k, atr, zscore
+func (c *indicatorController) parseParams(ctx *fasthttp.RequestCtx) (*indicator.Params, error) {
+	params := indicator.DefaultParams
+	periods := []struct {
+		name   string
+		period *uint
+	}{
+		{"sma", &params.SmaPeriod},
+		{"ema", &params.EmaPeriod},
+		{"rsi", &params.RsiPeriod},
+		{"macd_fast", &params.MacdFast},
+		{"macd_slow", &params.MacdSlow},
+		{"macd_signal", &params.MacdSignal},
+		{"bb", &params.BollingerPeriod},
+		{"atr", &params.AtrPeriod},
+		{"zscore", &params.VolumePeriod},
+	}
+	for _, item := range periods {
+		period, err := fasthttp_tools.ParseQueryArgUint(ctx, item.name)
+		if err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		*item.period = period
+	}
+	k, err := fasthttp_tools.ParseQueryArgFloat(ctx, "bb_k")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		params.BollingerK = k
+	}
+
+	if err = params.Validate(); err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+func (c *indicatorController) writeBadRequest(ctx *fasthttp.RequestCtx, metricName string, err error) {
+	errMsg := "Parse params error "
+	c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+	res := fasthttp_tools.NewResponse_ErrBadRequest(errMsg + err.Error())
+	fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusBadRequest, *res)
+}
+
+func (c *indicatorController) writeError(ctx *fasthttp.RequestCtx, metricName string, err error) {
+	if errors.Is(err, apperror.ErrBadRequest) {
+		c.writeBadRequest(ctx, metricName, err)
+		return
+	}
+	if errors.Is(err, apperror.ErrNotFound) {
+		errMsg := "Prices were not found"
+		c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+		res := fasthttp_tools.NewResponse_ErrNotFound(errMsg)
+		fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusNotFound, *res)
+		return
+	}
+	errMsg := "Failed to get indicators"
+	c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+	res := fasthttp_tools.NewResponse_ErrInternal()
+	fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusInternalServerError, *res)
+}
+
+func (c *indicatorController) writeSuccess(ctx *fasthttp.RequestCtx, metricName string, data interface{}) {
+	res := fasthttp_tools.NewResponse_Success(data)
+	if err := fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusOK, *res); err != nil {
+		c.logger.Error("fasthttp_tools.FastHTTPWriteResult error", zap.String(log_key.Func, metricName), zap.Error(err))
+	}
+}
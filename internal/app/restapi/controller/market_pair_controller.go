@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	routing "github.com/qiangxue/fasthttp-routing"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"info/internal/domain/market_pair"
+	"info/internal/pkg/apperror"
+	"info/internal/pkg/fasthttp_tools"
+	"info/internal/pkg/log_key"
+)
+
+type marketPairController struct {
+	logger  *zap.Logger
+	router  *routing.Router
+	service *market_pair.Service
+}
+
+func NewMarketPairController(logger *zap.Logger, router *routing.Router, service *market_pair.Service) *marketPairController {
+	return &marketPairController{
+		logger:  logger,
+		router:  router,
+		service: service,
+	}
+}
+
+// Liquidity returns the liquidity of the latest snapshot of the market pairs of the currency; the currency is required, top is the number of the venues
+func (c *marketPairController) Liquidity(rctx *routing.Context) (err error) {
+	const metricName = "marketPairController.Liquidity"
+	ctx := rctx.RequestCtx
+
+	currencyID, err := fasthttp_tools.ParseQueryArgUint(ctx, "currency")
+	if err == nil && currencyID == 0 {
+		err = fmt.Errorf("[%w] the currency is required", apperror.ErrBadRequest)
+	}
+	if err != nil {
+		c.writeBadRequest(ctx, metricName, err)
+		return nil
+	}
+	top, err := fasthttp_tools.ParseQueryArgUint(ctx, "top")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		c.writeBadRequest(ctx, metricName, err)
+		return nil
+	}
+
+	res, err := c.service.Liquidity(ctx, currencyID, top)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			errMsg := "Market pairs were not found"
+			c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+			res := fasthttp_tools.NewResponse_ErrNotFound(errMsg)
+			fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusNotFound, *res)
+			return nil
+		}
+		errMsg := "Failed to get Liquidity"
+		c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+		res := fasthttp_tools.NewResponse_ErrInternal()
+		fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusInternalServerError, *res)
+		return nil
+	}
+
+	resp := fasthttp_tools.NewResponse_Success(*res)
+	if err := fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusOK, *resp); err != nil {
+		c.logger.Error("fasthttp_tools.FastHTTPWriteResult error", zap.String(log_key.Func, metricName), zap.Error(err))
+	}
+	return nil
+}
+
+func (c *marketPairController) writeBadRequest(ctx *fasthttp.RequestCtx, metricName string, err error) {
+	errMsg := "Parse params error "
+	c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+	res := fasthttp_tools.NewResponse_ErrBadRequest(errMsg + err.Error())
+	fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusBadRequest, *res)
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	routing "github.com/qiangxue/fasthttp-routing"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"info/internal/domain/price_and_cap"
+	"info/internal/pkg/apperror"
+	"info/internal/pkg/fasthttp_tools"
+	"info/internal/pkg/log_key"
+	"time"
+)
+
+// candlesDefaultNb is the window of the candles without from
+const candlesDefaultNb = 200
+
+type priceAndCapController struct {
+	logger  *zap.Logger
+	router  *routing.Router
+	service *price_and_cap.Service
+}
+
+func NewPriceAndCapController(logger *zap.Logger, router *routing.Router, service *price_and_cap.Service) *priceAndCapController {
+	return &priceAndCapController{
+		logger:  logger,
+		router:  router,
+		service: service,
+	}
+}
+
+// GetCandles returns the OHLCV candles of the currency for the days [from, to], YYYY-MM-DD;
+// interval is 1h, 1d (by default) or 1w, the last 200 candles by default
+func (c *priceAndCapController) GetCandles(rctx *routing.Context) (err error) {
+	const metricName = "priceAndCapController.GetCandles"
+	ctx := rctx.RequestCtx
+
+	currencyID, err := fasthttp_tools.ParseQueryArgUint(ctx, "currency")
+	if err == nil && currencyID == 0 {
+		err = fmt.Errorf("[%w] the currency is required", apperror.ErrBadRequest)
+	}
+	if err != nil {
+		c.writeBadRequest(ctx, metricName, err)
+		return nil
+	}
+	interval, err := fasthttp_tools.ParseQueryArgString(ctx, "interval")
+	if err != nil {
+		interval = price_and_cap.Interval_1D
+	}
+	if err = price_and_cap.IntervalValidate(interval); err != nil {
+		c.writeBadRequest(ctx, metricName, fmt.Errorf("[%w] interval: %w", apperror.ErrBadRequest, err))
+		return nil
+	}
+
+	to := time.Now().UTC().Truncate(time.Hour * 24).Add(time.Hour * 24)
+	day, err := fasthttp_tools.ParseQueryArgDate(ctx, "to")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		c.writeBadRequest(ctx, metricName, err)
+		return nil
+	}
+	if err == nil {
+		// the last day is included
+		to = day.Add(time.Hour * 24)
+	}
+	from, err := fasthttp_tools.ParseQueryArgDate(ctx, "from")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		c.writeBadRequest(ctx, metricName, err)
+		return nil
+	}
+	if err != nil {
+		from = to.Add(-price_and_cap.IntervalDuration(interval) * candlesDefaultNb)
+	}
+
+	list, err := c.service.GetCandles(ctx, currencyID, interval, from, to)
+	if err != nil {
+		c.writeError(ctx, metricName, err)
+		return nil
+	}
+	c.writeSuccess(ctx, metricName, *list)
+	return nil
+}
+
+func (c *priceAndCapController) writeBadRequest(ctx *fasthttp.RequestCtx, metricName string, err error) {
+	errMsg := "Parse params error "
+	c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+	res := fasthttp_tools.NewResponse_ErrBadRequest(errMsg + err.Error())
+	fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusBadRequest, *res)
+}
+
+func (c *priceAndCapController) writeError(ctx *fasthttp.RequestCtx, metricName string, err error) {
+	if errors.Is(err, apperror.ErrBadRequest) {
+		c.writeBadRequest(ctx, metricName, err)
+		return
+	}
+	if errors.Is(err, apperror.ErrNotFound) {
+		errMsg := "Candles were not found"
+		c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+		res := fasthttp_tools.NewResponse_ErrNotFound(errMsg)
+		fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusNotFound, *res)
+		return
+	}
+	errMsg := "Failed to get candles"
+	c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+	res := fasthttp_tools.NewResponse_ErrInternal()
+	fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusInternalServerError, *res)
+}
+
+func (c *priceAndCapController) writeSuccess(ctx *fasthttp.RequestCtx, metricName string, data interface{}) {
+	res := fasthttp_tools.NewResponse_Success(data)
+	if err := fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusOK, *res); err != nil {
+		c.logger.Error("fasthttp_tools.FastHTTPWriteResult error", zap.String(log_key.Func, metricName), zap.Error(err))
+	}
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"errors"
+	routing "github.com/qiangxue/fasthttp-routing"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"info/internal/domain/price_divergence"
+	"info/internal/pkg/apperror"
+	"info/internal/pkg/fasthttp_tools"
+	"info/internal/pkg/log_key"
+	"time"
+)
+
+type priceDivergenceController struct {
+	logger  *zap.Logger
+	router  *routing.Router
+	service *price_divergence.Service
+}
+
+func NewPriceDivergenceController(logger *zap.Logger, router *routing.Router, service *price_divergence.Service) *priceDivergenceController {
+	return &priceDivergenceController{
+		logger:  logger,
+		router:  router,
+		service: service,
+	}
+}
+
+// Report returns the days when the stored price or cap diverged from the reference source, the biggest divergence first.
+// It can be filtered by the currency ID and the days [from, to], YYYY-MM-DD.
+func (c *priceDivergenceController) Report(rctx *routing.Context) (err error) {
+	const metricName = "priceDivergenceController.Report"
+	ctx := rctx.RequestCtx
+	var res *fasthttp_tools.Response
+
+	filter, limit, err := c.parseReportArgs(ctx)
+	if err != nil {
+		errMsg := "Parse params error "
+		c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+		res = fasthttp_tools.NewResponse_ErrBadRequest(errMsg + err.Error())
+		fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusBadRequest, *res)
+		return nil
+	}
+
+	list, err := c.service.GetList(ctx, filter, limit)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			errMsg := "Price divergences were not found"
+			c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+			res = fasthttp_tools.NewResponse_ErrNotFound(errMsg)
+			fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusNotFound, *res)
+			return nil
+		}
+		errMsg := "Failed to get price divergences"
+		c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+		res = fasthttp_tools.NewResponse_ErrInternal()
+		fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusInternalServerError, *res)
+		return nil
+	}
+
+	res = fasthttp_tools.NewResponse_Success(*list)
+	if err = fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusOK, *res); err != nil {
+		c.logger.Error("fasthttp_tools.FastHTTPWriteResult error", zap.String(log_key.Func, metricName), zap.Error(err))
+	}
+	return nil
+}
+
+func (c *priceDivergenceController) parseReportArgs(ctx *fasthttp.RequestCtx) (*price_divergence.Filter, uint, error) {
+	filter := &price_divergence.Filter{
+		To: time.Now().UTC().Truncate(time.Hour * 24).Add(time.Hour * 24),
+	}
+
+	currencyID, err := fasthttp_tools.ParseQueryArgUint(ctx, "currency")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, 0, err
+	}
+	if currencyID > 0 {
+		filter.CurrencyIDs = []uint{currencyID}
+	}
+
+	if filter.From, err = fasthttp_tools.ParseQueryArgDate(ctx, "from"); err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, 0, err
+	}
+	to, err := fasthttp_tools.ParseQueryArgDate(ctx, "to")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, 0, err
+	}
+	if err == nil {
+		// the last day is included
+		filter.To = to.Add(time.Hour * 24)
+	}
+
+	// without the limit the service uses its default one
+	limit, err := fasthttp_tools.ParseQueryArgUint(ctx, "limit")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, 0, err
+	}
+	return filter, limit, nil
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	routing "github.com/qiangxue/fasthttp-routing"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"info/internal/domain/universe"
+	"info/internal/pkg/apperror"
+	"info/internal/pkg/fasthttp_tools"
+	"info/internal/pkg/log_key"
+	"time"
+)
+
+type universeController struct {
+	logger  *zap.Logger
+	router  *routing.Router
+	service *universe.Service
+}
+
+func NewUniverseController(logger *zap.Logger, router *routing.Router, service *universe.Service) *universeController {
+	return &universeController{
+		logger:  logger,
+		router:  router,
+		service: service,
+	}
+}
+
+// GetChanges returns the audit trail of the universe, newest first.
+// It can be filtered by the action (added, retired) and the days [from, to], YYYY-MM-DD.
+func (c *universeController) GetChanges(rctx *routing.Context) (err error) {
+	const metricName = "universeController.GetChanges"
+	ctx := rctx.RequestCtx
+	var res *fasthttp_tools.Response
+
+	filter, limit, err := c.parseChangesArgs(ctx)
+	if err != nil {
+		errMsg := "Parse params error "
+		c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+		res = fasthttp_tools.NewResponse_ErrBadRequest(errMsg + err.Error())
+		fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusBadRequest, *res)
+		return nil
+	}
+
+	list, err := c.service.GetList(ctx, filter, limit)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			errMsg := "Universe changes were not found"
+			c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+			res = fasthttp_tools.NewResponse_ErrNotFound(errMsg)
+			fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusNotFound, *res)
+			return nil
+		}
+		errMsg := "Failed to get universe changes"
+		c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+		res = fasthttp_tools.NewResponse_ErrInternal()
+		fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusInternalServerError, *res)
+		return nil
+	}
+
+	res = fasthttp_tools.NewResponse_Success(*list)
+	if err = fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusOK, *res); err != nil {
+		c.logger.Error("fasthttp_tools.FastHTTPWriteResult error", zap.String(log_key.Func, metricName), zap.Error(err))
+	}
+	return nil
+}
+
+func (c *universeController) parseChangesArgs(ctx *fasthttp.RequestCtx) (*universe.Filter, uint, error) {
+	filter := &universe.Filter{
+		To: time.Now().UTC().Truncate(time.Hour * 24).Add(time.Hour * 24),
+	}
+
+	action, err := fasthttp_tools.ParseQueryArgString(ctx, "action")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, 0, err
+	}
+	if action != "" && action != universe.Action_Added && action != universe.Action_Retired {
+		return nil, 0, fmt.Errorf("[%w] wrong action %q", apperror.ErrBadRequest, action)
+	}
+	filter.Action = action
+
+	if filter.From, err = fasthttp_tools.ParseQueryArgDate(ctx, "from"); err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, 0, err
+	}
+	to, err := fasthttp_tools.ParseQueryArgDate(ctx, "to")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, 0, err
+	}
+	if err == nil {
+		// the last day is included
+		filter.To = to.Add(time.Hour * 24)
+	}
+
+	// without the limit the service uses its default one
+	limit, err := fasthttp_tools.ParseQueryArgUint(ctx, "limit")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, 0, err
+	}
+	return filter, limit, nil
+}
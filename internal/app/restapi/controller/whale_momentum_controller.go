@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	routing "github.com/qiangxue/fasthttp-routing"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"info/internal/domain/whale_momentum"
+	"info/internal/pkg/apperror"
+	"info/internal/pkg/fasthttp_tools"
+	"info/internal/pkg/log_key"
+	"time"
+)
+
+type whaleMomentumController struct {
+	logger  *zap.Logger
+	router  *routing.Router
+	service *whale_momentum.Service
+}
+
+func NewWhaleMomentumController(logger *zap.Logger, router *routing.Router, service *whale_momentum.Service) *whaleMomentumController {
+	return &whaleMomentumController{
+		logger:  logger,
+		router:  router,
+		service: service,
+	}
+}
+
+// Report returns the change of the share of the whales over the windows (1w,2w,1m,2m by default);
+// lag is the lag of the last day in days (2 by default), sort is the name of the window, order is asc (by default) or desc
+func (c *whaleMomentumController) Report(rctx *routing.Context) (err error) {
+	const metricName = "whaleMomentumController.Report"
+	ctx := rctx.RequestCtx
+
+	query, err := c.parseQuery(ctx)
+	if err != nil {
+		c.writeBadRequest(ctx, metricName, err)
+		return nil
+	}
+
+	res, err := c.service.Report(ctx, query)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			errMsg := "Whale momentum was not found"
+			c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+			res := fasthttp_tools.NewResponse_ErrNotFound(errMsg)
+			fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusNotFound, *res)
+			return nil
+		}
+		errMsg := "Failed to get Report"
+		c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+		res := fasthttp_tools.NewResponse_ErrInternal()
+		fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusInternalServerError, *res)
+		return nil
+	}
+
+	resp := fasthttp_tools.NewResponse_Success(*res)
+	if err := fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusOK, *resp); err != nil {
+		c.logger.Error("fasthttp_tools.FastHTTPWriteResult error", zap.String(log_key.Func, metricName), zap.Error(err))
+	}
+	return nil
+}
+
+func (c *whaleMomentumController) parseQuery(ctx *fasthttp.RequestCtx) (*whale_momentum.Query, error) {
+	var windows whale_momentum.WindowList
+	windowsArg, err := fasthttp_tools.ParseQueryArgString(ctx, "windows")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		if windows, err = whale_momentum.ParseWindowList(windowsArg); err != nil {
+			return nil, err
+		}
+	}
+
+	var lag *time.Duration
+	lagDays, err := fasthttp_tools.ParseQueryArgUint(ctx, "lag")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		d := time.Duration(lagDays) * time.Hour * 24
+		lag = &d
+	}
+
+	sortBy, err := fasthttp_tools.ParseQueryArgString(ctx, "sort")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+
+	order, err := fasthttp_tools.ParseQueryArgString(ctx, "order")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+	if order != "" && order != "asc" && order != "desc" {
+		return nil, fmt.Errorf("[%w] the order must be asc or desc", apperror.ErrBadRequest)
+	}
+
+	limit, err := fasthttp_tools.ParseQueryArgUint(ctx, "limit")
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+
+	return whale_momentum.NewQuery(windows, lag, sortBy, order == "desc", limit)
+}
+
+func (c *whaleMomentumController) writeBadRequest(ctx *fasthttp.RequestCtx, metricName string, err error) {
+	errMsg := "Parse params error "
+	c.logger.Error(errMsg, zap.String(log_key.Func, metricName), zap.Error(err))
+	res := fasthttp_tools.NewResponse_ErrBadRequest(errMsg + err.Error())
+	fasthttp_tools.FastHTTPWriteResult(ctx, fasthttp.StatusBadRequest, *res)
+}
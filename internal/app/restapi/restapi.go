@@ -96,6 +96,42 @@ func (a *RestAPI) buildHandler() {
 	cmcController := controller.NewCmcController(a.logger, r, a.Domain.Currency)
 	api.Get("/cmc/report/whale-biggest-fall", cmcController.Report_BiggestFall)
 	api.Get("/cmc/report/whale-longest-fall", cmcController.Report_LongestFall)
+	api.Get("/cmc/report/whale-biggest-rise", cmcController.Report_BiggestRise)
+	api.Get("/cmc/report/whale-longest-rise", cmcController.Report_LongestRise)
+	api.Get("/cmc/report/whale-biggest-trend", cmcController.Report_BiggestTrend)
+	api.Get("/cmc/report/whale-longest-trend", cmcController.Report_LongestTrend)
+	api.Get("/cmc/report/ath-atl", cmcController.Report_AthAtl)
+	api.Get("/cmc/report/risk", cmcController.Report_Risk)
+
+	priceDivergenceController := controller.NewPriceDivergenceController(a.logger, r, a.Domain.PriceDivergence)
+	api.Get("/cmc/report/price-divergence", priceDivergenceController.Report)
+
+	currencySnapshotController := controller.NewCurrencySnapshotController(a.logger, r, a.Domain.CurrencySnapshot)
+	api.Get("/cmc/report/rank-changes", currencySnapshotController.RankChanges)
+	api.Get("/cmc/report/supply-inflation", currencySnapshotController.SupplyInflation)
+	api.Get("/cmc/report/dilution", currencySnapshotController.Dilution)
+
+	whaleMomentumController := controller.NewWhaleMomentumController(a.logger, r, a.Domain.WhaleMomentum)
+	api.Get("/cmc/report/whale-momentum", whaleMomentumController.Report)
+
+	marketPairController := controller.NewMarketPairController(a.logger, r, a.Domain.MarketPair)
+	api.Get("/cmc/report/liquidity", marketPairController.Liquidity)
+
+	priceAndCapController := controller.NewPriceAndCapController(a.logger, r, a.Domain.PriceAndCap)
+	api.Get("/cmc/candles", priceAndCapController.GetCandles)
+
+	indicatorController := controller.NewIndicatorController(a.logger, r, a.Domain.Indicator)
+	api.Get("/cmc/indicators", indicatorController.Get)
+
+	globalMetricsController := controller.NewGlobalMetricsController(a.logger, r, a.Domain.GlobalMetrics)
+	api.Get("/cmc/global-metrics", globalMetricsController.GetList)
+	api.Get("/cmc/global-metrics/latest", globalMetricsController.GetLast)
+
+	universeController := controller.NewUniverseController(a.logger, r, a.Domain.Universe)
+	api.Get("/universe/changes", universeController.GetChanges)
+
+	importRunController := controller.NewImportRunController(a.logger, r, a.Domain.ImportRun)
+	api.Get("/imports", importRunController.GetList)
 
 	a.serverRestAPI.Handler = r.HandleRequest
 }
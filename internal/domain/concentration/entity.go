@@ -26,6 +26,21 @@ func (l *ConcentrationList) Slice() *[]Concentration {
 	return &res
 }
 
+// FilterByTime returns the items inside the window [from, to)
+func (l *ConcentrationList) FilterByTime(from time.Time, to time.Time) *ConcentrationList {
+	if l == nil {
+		return nil
+	}
+	res := make(ConcentrationList, 0, len(*l))
+	var item Concentration
+	for _, item = range *l {
+		if !item.D.Before(from) && item.D.Before(to) {
+			res = append(res, item)
+		}
+	}
+	return &res
+}
+
 func (l *ConcentrationList) MaxTime() *time.Time {
 	if l == nil || len(*l) == 0 {
 		return nil
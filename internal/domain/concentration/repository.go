@@ -13,6 +13,7 @@ type ReplicaSet interface {
 type WriteRepository interface {
 	Upsert(ctx context.Context, entity *Concentration) (err error)
 	MUpsertTx(ctx context.Context, tx domain.Tx, entities *[]Concentration) error
+	MUpsertWithStats(ctx context.Context, entities *[]Concentration) (*domain.UpsertStats, error)
 }
 
 type ReadRepository interface {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"info/internal/domain"
+	"info/internal/domain/import_run"
 	"info/internal/pkg/apperror"
 	"runtime/debug"
 	"time"
@@ -46,6 +47,18 @@ func TimeRangeValidate(s string) error {
 	return validation.Validate(s, validation.Required, validation.In(TimeRangeList...))
 }
 
+// TimeRangeForWindow returns the shortest time range of the analytics which contains the window starting from the time
+func TimeRangeForWindow(from time.Time) string {
+	now := time.Now()
+	switch {
+	case from.After(now.Add(-time.Hour * 24 * 30)):
+		return TimeRange_1M
+	case from.After(now.Add(-time.Hour * 24 * 365)):
+		return TimeRange_1Y
+	}
+	return TimeRange_All
+}
+
 func (s *Service) MGet(ctx context.Context, currencyIDs *[]uint) (ConcentrationMap, error) {
 	return s.replicaSet.ReadRepo().MGet(ctx, currencyIDs)
 }
@@ -113,5 +126,37 @@ func (s *Service) importTx(ctx context.Context, tx domain.Tx, currencyID uint, t
 	if err = s.replicaSet.WriteRepo().MUpsertTx(ctx, tx, item.Slice()); err != nil {
 		return nil, err
 	}
+	import_run.AddRows(ctx, len(*item))
+
 	return item.MaxTime(), nil
 }
+
+// Backfill imports the analytics of the time range containing the window and upserts only the items inside the window [from, to)
+func (s *Service) Backfill(ctx context.Context, currencyID uint, from time.Time, to time.Time) (stats *domain.UpsertStats, err error) {
+	const metricName = "concentration.Service.Backfill"
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Join(err, fmt.Errorf("[%w] "+metricName+" Recover from panic: %v; stacktrace from panic: %s", apperror.ErrInternal, r, string(debug.Stack())))
+		}
+	}()
+
+	item, err := s.cmcApi.GetAnalytics(ctx, currencyID, TimeRangeForWindow(from))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.replicaSet.WriteRepo().MUpsertWithStats(ctx, item.FilterByTime(from, to).Slice())
+}
+
+// Reparse upserts the items converted again from an archived response
+func (s *Service) Reparse(ctx context.Context, list *ConcentrationList) (*domain.UpsertStats, error) {
+	if list == nil || len(*list) == 0 {
+		return &domain.UpsertStats{}, nil
+	}
+	stats, err := s.replicaSet.WriteRepo().MUpsertWithStats(ctx, list.Slice())
+	if err != nil {
+		return nil, err
+	}
+	import_run.AddRows(ctx, len(*list))
+	return stats, nil
+}
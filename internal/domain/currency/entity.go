@@ -3,9 +3,13 @@ package currency
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"info/internal/domain"
 	"info/internal/pkg/apperror"
 	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 const ()
@@ -14,6 +18,22 @@ type ImportMaxTime struct {
 	CurrencyID    uint
 	PriceAndCap   *time.Time
 	Concentration *time.Time
+	// the fencing tokens of the stage locks of the last import of the datasets; the checkpoint is not moved by a smaller token
+	PriceAndCapFencingToken   uint64
+	ConcentrationFencingToken uint64
+}
+
+// SetFencingToken sets the fencing token of the dataset; the zero token is not fenced, so it keeps the token of the last import
+func (e *ImportMaxTime) SetFencingToken(dataset string, token uint64) {
+	if token == 0 {
+		return
+	}
+	switch dataset {
+	case Dataset_PriceAndCap:
+		e.PriceAndCapFencingToken = token
+	case Dataset_Concentration:
+		e.ConcentrationFencingToken = token
+	}
 }
 
 type Currency struct {
@@ -104,3 +124,55 @@ type TokenAddress struct {
 }
 
 type TokenAddressList []TokenAddress
+
+// Map groups the token addresses by the currency
+func (l *TokenAddressList) Map() map[uint]TokenAddressList {
+	res := make(map[uint]TokenAddressList)
+	if l == nil {
+		return res
+	}
+	var item TokenAddress
+	for _, item = range *l {
+		res[item.CurrencyID] = append(res[item.CurrencyID], item)
+	}
+	return res
+}
+
+const (
+	Dataset_PriceAndCap   = "price_and_cap"
+	Dataset_Concentration = "concentration"
+	Dataset_Oracul        = "oracul"
+)
+
+var DatasetList = []interface{}{
+	Dataset_PriceAndCap,
+	Dataset_Concentration,
+	Dataset_Oracul,
+}
+
+func DatasetValidate(s string) error {
+	return validation.Validate(s, validation.Required, validation.In(DatasetList...))
+}
+
+// BackfillResult is the result of the backfill of a dataset of a currency
+type BackfillResult struct {
+	CurrencyID uint
+	Symbol     string
+	Dataset    string
+	domain.UpsertStats
+	Err error
+}
+
+type BackfillResultList []BackfillResult
+
+// Err joins the errors of the results
+func (l BackfillResultList) Err() error {
+	var errs error
+	var item BackfillResult
+	for _, item = range l {
+		if item.Err != nil {
+			errs = errors.Join(errs, fmt.Errorf("currency %d (%s), dataset %s: %w", item.CurrencyID, item.Symbol, item.Dataset, item.Err))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,266 @@
+package currency
+
+import (
+	"fmt"
+	"info/internal/domain/concentration"
+	"info/internal/domain/price_and_cap"
+	"info/internal/pkg/apperror"
+	"slices"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	AthAtlSort_Symbol        = "symbol"
+	AthAtlSort_Rank          = "rank"
+	AthAtlSort_WhalesPercent = "whales_percent"
+	AthAtlSort_Price         = "price"
+	AthAtlSort_MinPrice      = "min_price"
+	AthAtlSort_MinTs         = "min_ts"
+	AthAtlSort_MaxPrice      = "max_price"
+	AthAtlSort_MaxTs         = "max_ts"
+	AthAtlSort_ToAth         = "to_ath"
+	AthAtlSort_FromAtl       = "from_atl"
+
+	Observing_True  = "true"
+	Observing_False = "false"
+	Observing_All   = "all"
+
+	maxAthAtlPeriod = time.Hour * 24 * 365 * 20
+)
+
+var ObservingList = []interface{}{
+	Observing_True,
+	Observing_False,
+	Observing_All,
+}
+
+var AthAtlSortList = []interface{}{
+	AthAtlSort_Symbol,
+	AthAtlSort_Rank,
+	AthAtlSort_WhalesPercent,
+	AthAtlSort_Price,
+	AthAtlSort_MinPrice,
+	AthAtlSort_MinTs,
+	AthAtlSort_MaxPrice,
+	AthAtlSort_MaxTs,
+	AthAtlSort_ToAth,
+	AthAtlSort_FromAtl,
+}
+
+// AthAtlQuery is the params of the ATH/ATL report: Period is the lookback window of the extremes, 0 - all the time;
+// IsForObserving filters the currencies, nil - all of them
+type AthAtlQuery struct {
+	Period         time.Duration
+	IsForObserving *bool
+	SortBy         string
+	Desc           bool
+}
+
+// NewAthAtlQuery returns the query of the params; the observed currencies by the rank by default
+func NewAthAtlQuery(period time.Duration, observing string, sortBy string, desc bool) (*AthAtlQuery, error) {
+	if observing == "" {
+		observing = Observing_True
+	}
+	if sortBy == "" {
+		sortBy = AthAtlSort_Rank
+	}
+	if err := validation.Validate(observing, validation.In(ObservingList...)); err != nil {
+		return nil, fmt.Errorf("[%w] observing: %w", apperror.ErrBadRequest, err)
+	}
+	if err := validation.Validate(sortBy, validation.In(AthAtlSortList...)); err != nil {
+		return nil, fmt.Errorf("[%w] sort: %w", apperror.ErrBadRequest, err)
+	}
+	if period < 0 || period > maxAthAtlPeriod {
+		return nil, fmt.Errorf("[%w] the period must be in [0, %s]", apperror.ErrBadRequest, maxAthAtlPeriod)
+	}
+	var isForObserving *bool
+	if observing != Observing_All {
+		v := observing == Observing_True
+		isForObserving = &v
+	}
+	return &AthAtlQuery{
+		Period:         period,
+		IsForObserving: isForObserving,
+		SortBy:         sortBy,
+		Desc:           desc,
+	}, nil
+}
+
+// AthAtl is the distance of the last price of a currency from the extremes of the period by the daily candles;
+// ToAth is how many times the price must grow to reach the max, FromAtl is how many times it has grown from the min.
+// Ts, MinTs and MaxTs are the days of the candles
+type AthAtl struct {
+	CurrencyID     uint
+	Symbol         string
+	CmcRank        uint
+	IsForObserving bool
+	WhalesPercent  *float64 // the share of the whales of the last concentration, nil without it
+	WhalesD        *time.Time
+	Price          float64
+	Ts             time.Time
+	MinPrice       float64
+	MinTs          time.Time
+	MaxPrice       float64
+	MaxTs          time.Time
+	ToAth          float64
+	FromAtl        float64
+}
+
+type AthAtlList []AthAtl
+
+// Sort sorts by the column of the query; the items without the share of the whales are the last ones
+func (l *AthAtlList) Sort(sortBy string, desc bool) *AthAtlList {
+	if l == nil {
+		return nil
+	}
+	slices.SortStableFunc(*l, func(a, b AthAtl) int {
+		var res int
+		switch sortBy {
+		case AthAtlSort_Symbol:
+			res = strings.Compare(a.Symbol, b.Symbol)
+		case AthAtlSort_Rank:
+			res = compareFloat(float64(a.CmcRank), float64(b.CmcRank))
+		case AthAtlSort_WhalesPercent:
+			switch {
+			case a.WhalesPercent == nil && b.WhalesPercent == nil:
+				return 0
+			case a.WhalesPercent == nil:
+				return 1
+			case b.WhalesPercent == nil:
+				return -1
+			}
+			res = compareFloat(*a.WhalesPercent, *b.WhalesPercent)
+		case AthAtlSort_Price:
+			res = compareFloat(a.Price, b.Price)
+		case AthAtlSort_MinPrice:
+			res = compareFloat(a.MinPrice, b.MinPrice)
+		case AthAtlSort_MinTs:
+			res = a.MinTs.Compare(b.MinTs)
+		case AthAtlSort_MaxPrice:
+			res = compareFloat(a.MaxPrice, b.MaxPrice)
+		case AthAtlSort_MaxTs:
+			res = a.MaxTs.Compare(b.MaxTs)
+		case AthAtlSort_ToAth:
+			res = compareFloat(a.ToAth, b.ToAth)
+		case AthAtlSort_FromAtl:
+			res = compareFloat(a.FromAtl, b.FromAtl)
+		}
+		if desc {
+			return -res
+		}
+		return res
+	})
+	return l
+}
+
+func (l *AthAtlList) Limit(limit uint) *AthAtlList {
+	if l == nil {
+		return nil
+	}
+	if uint(len(*l)) < limit {
+		limit = uint(len(*l))
+	}
+	newList := (*l)[:limit]
+	return &newList
+}
+
+// CalcAthAtl returns the extremes of the daily candles of the period or nil without the candles;
+// the first day of an extreme is taken if the price repeats
+func CalcAthAtl(item *Currency, candles *price_and_cap.CandleList, concentrations *concentration.ConcentrationList) *AthAtl {
+	if item == nil || candles == nil {
+		return nil
+	}
+	var last, low, high *price_and_cap.Candle
+	for i := range *candles {
+		c := &(*candles)[i]
+		if c.Close <= 0 || c.Low <= 0 || c.High <= 0 {
+			continue
+		}
+		if last == nil || c.Ts.After(last.Ts) {
+			last = c
+		}
+		if low == nil || c.Low < low.Low || (c.Low == low.Low && c.Ts.Before(low.Ts)) {
+			low = c
+		}
+		if high == nil || c.High > high.High || (c.High == high.High && c.Ts.Before(high.Ts)) {
+			high = c
+		}
+	}
+	if last == nil {
+		return nil
+	}
+
+	res := &AthAtl{
+		CurrencyID:     item.ID,
+		Symbol:         item.Symbol,
+		CmcRank:        item.CmcRank,
+		IsForObserving: item.IsForObserving,
+		Price:          last.Close,
+		Ts:             last.Ts,
+		MinPrice:       low.Low,
+		MinTs:          low.Ts,
+		MaxPrice:       high.High,
+		MaxTs:          high.Ts,
+		ToAth:          round(high.High / last.Close),
+		FromAtl:        round(last.Close / low.Low),
+	}
+	if c := lastConcentration(concentrations); c != nil {
+		if total := c.Whales + c.Investors + c.Retail; total > 0 {
+			whalesPercent := percentOf(c.Whales, total)
+			res.WhalesPercent = &whalesPercent
+			res.WhalesD = &c.D
+		}
+	}
+	return res
+}
+
+// CalcAthAtlList returns the extremes of the currencies which have the candles
+func CalcAthAtlList(currencyList *CurrencyList, candleMap price_and_cap.CandleMap, concentrationMap concentration.ConcentrationMap) *AthAtlList {
+	res := make(AthAtlList, 0)
+	if currencyList == nil {
+		return &res
+	}
+	var item Currency
+	for _, item = range *currencyList {
+		candles, ok := candleMap[item.ID]
+		if !ok {
+			continue
+		}
+		var concentrations *concentration.ConcentrationList
+		if l, ok := concentrationMap[item.ID]; ok {
+			concentrations = &l
+		}
+		if e := CalcAthAtl(&item, &candles, concentrations); e != nil {
+			res = append(res, *e)
+		}
+	}
+	return &res
+}
+
+// lastConcentration returns the item of the list with the latest day or nil
+func lastConcentration(list *concentration.ConcentrationList) *concentration.Concentration {
+	if list == nil {
+		return nil
+	}
+	var res *concentration.Concentration
+	for i := range *list {
+		if res == nil || (*list)[i].D.After(res.D) {
+			res = &(*list)[i]
+		}
+	}
+	return res
+}
+
+func compareFloat(a float64, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
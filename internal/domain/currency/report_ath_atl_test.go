@@ -0,0 +1,119 @@
+package currency
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"info/internal/domain/concentration"
+	"info/internal/domain/price_and_cap"
+	"info/internal/pkg/apperror"
+)
+
+func TestCalcAthAtl(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := func(ago int) time.Time {
+		return now.AddDate(0, 0, -ago)
+	}
+	candle := func(ago int, low float64, high float64, close float64) price_and_cap.Candle {
+		return price_and_cap.Candle{Open: close, High: high, Low: low, Close: close, Ts: day(ago)}
+	}
+	item := Currency{ID: 1, Symbol: "BTC", CmcRank: 1, IsForObserving: true}
+	candles := price_and_cap.CandleList{
+		candle(500, 5, 8, 6),
+		candle(400, 20, 25, 22),
+		candle(30, 0, 0, 0), // a glitch of the data
+		candle(20, 90, 100, 95),
+		candle(10, 80, 100, 85),
+		candle(0, 45, 55, 50),
+	}
+	concentrations := concentration.ConcentrationList{
+		{Whales: 60, Investors: 30, Retail: 10, D: day(1)},
+		{Whales: 10, Investors: 30, Retail: 60, D: day(5)},
+	}
+
+	tests := []struct {
+		name        string
+		candles     price_and_cap.CandleList
+		wantMin     float64
+		wantMinTs   time.Time
+		wantFromAtl float64
+	}{
+		{name: "all the time", candles: candles, wantMin: 5, wantMinTs: day(500), wantFromAtl: 10},
+		{name: "the year", candles: candles[2:], wantMin: 45, wantMinTs: day(0), wantFromAtl: 1.11},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := CalcAthAtl(&item, &tt.candles, &concentrations)
+			if res == nil {
+				t.Fatal("got nil")
+			}
+			if res.Price != 50 || !res.Ts.Equal(day(0)) || res.MaxPrice != 100 || !res.MaxTs.Equal(day(20)) || res.ToAth != 2 {
+				t.Errorf("got %+v", *res)
+			}
+			if res.MinPrice != tt.wantMin || !res.MinTs.Equal(tt.wantMinTs) || res.FromAtl != tt.wantFromAtl {
+				t.Errorf("got %+v", *res)
+			}
+			if res.WhalesPercent == nil || *res.WhalesPercent != 60 || !res.WhalesD.Equal(day(1)) {
+				t.Errorf("whales: got %v %v", res.WhalesPercent, res.WhalesD)
+			}
+		})
+	}
+
+	if res := CalcAthAtl(&item, &candles, nil); res == nil || res.WhalesPercent != nil {
+		t.Errorf("without the concentration: got %+v", res)
+	}
+	glitch := candles[2:3]
+	if res := CalcAthAtl(&item, &glitch, nil); res != nil {
+		t.Errorf("without the prices of the period: got %+v", *res)
+	}
+}
+
+func TestAthAtlList_Sort(t *testing.T) {
+	whales := func(v float64) *float64 { return &v }
+	list := AthAtlList{
+		{Symbol: "B", CmcRank: 2, ToAth: 3, WhalesPercent: whales(10)},
+		{Symbol: "A", CmcRank: 3, ToAth: 1},
+		{Symbol: "C", CmcRank: 1, ToAth: 2, WhalesPercent: whales(20)},
+	}
+
+	tests := []struct {
+		sortBy string
+		desc   bool
+		want   []string
+	}{
+		{sortBy: AthAtlSort_Rank, want: []string{"C", "B", "A"}},
+		{sortBy: AthAtlSort_Symbol, desc: true, want: []string{"C", "B", "A"}},
+		{sortBy: AthAtlSort_ToAth, desc: true, want: []string{"B", "C", "A"}},
+		{sortBy: AthAtlSort_WhalesPercent, want: []string{"B", "C", "A"}},
+		{sortBy: AthAtlSort_WhalesPercent, desc: true, want: []string{"C", "B", "A"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sortBy, func(t *testing.T) {
+			l := make(AthAtlList, len(list))
+			copy(l, list)
+			res := l.Sort(tt.sortBy, tt.desc).Limit(10)
+			for i := range tt.want {
+				if (*res)[i].Symbol != tt.want[i] {
+					t.Fatalf("got %+v, want %v", *res, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewAthAtlQuery(t *testing.T) {
+	q, err := NewAthAtlQuery(0, "", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.IsForObserving == nil || !*q.IsForObserving || q.SortBy != AthAtlSort_Rank {
+		t.Errorf("got %+v", *q)
+	}
+	if q, err = NewAthAtlQuery(0, Observing_All, "", false); err != nil || q.IsForObserving != nil {
+		t.Errorf("all: got %+v, %v", q, err)
+	}
+	if _, err = NewAthAtlQuery(0, "", "volume", false); !errors.Is(err, apperror.ErrBadRequest) {
+		t.Errorf("bad sort: got %v", err)
+	}
+}
@@ -0,0 +1,361 @@
+package currency
+
+import (
+	"fmt"
+	"info/internal/domain/concentration"
+	"info/internal/domain/price_and_cap"
+	"info/internal/pkg/apperror"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	RiskSort_Symbol            = "symbol"
+	RiskSort_Volatility30d     = "volatility_30d"
+	RiskSort_Volatility90d     = "volatility_90d"
+	RiskSort_MaxDrawdown       = "max_drawdown"
+	RiskSort_CurrentDrawdown   = "current_drawdown"
+	RiskSort_DownsideDeviation = "downside_deviation"
+	RiskSort_Sharpe            = "sharpe"
+	RiskSort_Sortino           = "sortino"
+	RiskSort_WhalesPercent     = "whales_percent"
+	RiskSort_WhalesChange30d   = "whales_change_30d"
+	RiskSort_WhalesChange90d   = "whales_change_90d"
+
+	DefaultRiskPeriod = time.Hour * 24 * 365
+	minRiskPeriod     = time.Hour * 24 * 30
+	maxRiskPeriod     = time.Hour * 24 * 365 * 10
+	// the crypto is traded every day
+	tradingDaysInYear = 365
+)
+
+var RiskSortList = []interface{}{
+	RiskSort_Symbol,
+	RiskSort_Volatility30d,
+	RiskSort_Volatility90d,
+	RiskSort_MaxDrawdown,
+	RiskSort_CurrentDrawdown,
+	RiskSort_DownsideDeviation,
+	RiskSort_Sharpe,
+	RiskSort_Sortino,
+	RiskSort_WhalesPercent,
+	RiskSort_WhalesChange30d,
+	RiskSort_WhalesChange90d,
+}
+
+// RiskQuery is the params of the risk report: Period is the window of the daily candles, RiskFreeRate is the annual rate in percent
+type RiskQuery struct {
+	Period       time.Duration
+	RiskFreeRate float64
+	SortBy       string
+	Desc         bool
+}
+
+// NewRiskQuery returns the query of the params; the year and the biggest 30 days volatility first by default
+func NewRiskQuery(period time.Duration, riskFreeRate float64, sortBy string, order string) (*RiskQuery, error) {
+	if period == 0 {
+		period = DefaultRiskPeriod
+	}
+	if sortBy == "" {
+		sortBy = RiskSort_Volatility30d
+		if order == "" {
+			order = "desc"
+		}
+	}
+	if err := validation.Validate(sortBy, validation.In(RiskSortList...)); err != nil {
+		return nil, fmt.Errorf("[%w] sort: %w", apperror.ErrBadRequest, err)
+	}
+	if order != "" && order != "asc" && order != "desc" {
+		return nil, fmt.Errorf("[%w] the order must be asc or desc", apperror.ErrBadRequest)
+	}
+	if period < minRiskPeriod || period > maxRiskPeriod {
+		return nil, fmt.Errorf("[%w] the period must be in [%s, %s]", apperror.ErrBadRequest, minRiskPeriod, maxRiskPeriod)
+	}
+	if riskFreeRate < 0 || riskFreeRate >= 100 {
+		return nil, fmt.Errorf("[%w] the risk-free rate must be in [0, 100)", apperror.ErrBadRequest)
+	}
+	return &RiskQuery{
+		Period:       period,
+		RiskFreeRate: riskFreeRate,
+		SortBy:       sortBy,
+		Desc:         order == "desc",
+	}, nil
+}
+
+// Risk is the risk of a currency calculated on the daily close prices of the period with the whale metrics of the concentration;
+// the volatilities, the downside deviation and the ratios are annualized, the drawdowns are in percent;
+// a pointer is nil if there is not enough data
+type Risk struct {
+	CurrencyID        uint
+	Symbol            string
+	CmcRank           uint
+	Price             float64
+	D                 time.Time
+	DaysNb            uint // the days with a price
+	Volatility30d     *float64
+	Volatility90d     *float64
+	MaxDrawdown       float64
+	MaxDrawdownFrom   time.Time // the day of the peak
+	MaxDrawdownTo     time.Time // the day of the trough
+	CurrentDrawdown   float64
+	PeakD             time.Time
+	DownsideDeviation float64
+	Sharpe            *float64
+	Sortino           *float64
+	WhalesPercent     *float64
+	WhalesChange30d   *float64 // the change of the share of the whales in percentage points
+	WhalesChange90d   *float64
+}
+
+type RiskList []Risk
+
+// Sort sorts by the column; the items without the value are the last ones
+func (l *RiskList) Sort(sortBy string, desc bool) *RiskList {
+	if l == nil {
+		return nil
+	}
+	slices.SortStableFunc(*l, func(a, b Risk) int {
+		var res int
+		switch sortBy {
+		case RiskSort_Symbol:
+			res = strings.Compare(a.Symbol, b.Symbol)
+		case RiskSort_MaxDrawdown:
+			res = compareFloat(a.MaxDrawdown, b.MaxDrawdown)
+		case RiskSort_CurrentDrawdown:
+			res = compareFloat(a.CurrentDrawdown, b.CurrentDrawdown)
+		case RiskSort_DownsideDeviation:
+			res = compareFloat(a.DownsideDeviation, b.DownsideDeviation)
+		default:
+			x, y := a.optional(sortBy), b.optional(sortBy)
+			switch {
+			case x == nil && y == nil:
+				return 0
+			case x == nil:
+				return 1
+			case y == nil:
+				return -1
+			}
+			res = compareFloat(*x, *y)
+		}
+		if desc {
+			return -res
+		}
+		return res
+	})
+	return l
+}
+
+// optional returns the optional value of the column of the sort
+func (e *Risk) optional(sortBy string) *float64 {
+	switch sortBy {
+	case RiskSort_Volatility30d:
+		return e.Volatility30d
+	case RiskSort_Volatility90d:
+		return e.Volatility90d
+	case RiskSort_Sharpe:
+		return e.Sharpe
+	case RiskSort_Sortino:
+		return e.Sortino
+	case RiskSort_WhalesPercent:
+		return e.WhalesPercent
+	case RiskSort_WhalesChange30d:
+		return e.WhalesChange30d
+	case RiskSort_WhalesChange90d:
+		return e.WhalesChange90d
+	}
+	return nil
+}
+
+func (l *RiskList) Limit(limit uint) *RiskList {
+	if l == nil {
+		return nil
+	}
+	if uint(len(*l)) < limit {
+		limit = uint(len(*l))
+	}
+	newList := (*l)[:limit]
+	return &newList
+}
+
+// CalcRisk returns the risk of the daily candles sorted by the time or nil if there are less than 2 candles with a price.
+// The candles without a price are skipped; a missing day repeats the close of the previous one, so the move over a gap
+// is a single return and the windows of the volatility are the calendar days
+func CalcRisk(item *Currency, candles *price_and_cap.CandleList, concentrations *concentration.ConcentrationList, riskFreeRate float64) *Risk {
+	if item == nil || candles == nil {
+		return nil
+	}
+	c, daysNb := dailyCloses(*candles)
+	if daysNb < 2 {
+		return nil
+	}
+	last := c[len(c)-1]
+	res := &Risk{
+		CurrencyID: item.ID,
+		Symbol:     item.Symbol,
+		CmcRank:    item.CmcRank,
+		Price:      last.Close,
+		D:          last.Ts,
+		DaysNb:     daysNb,
+	}
+
+	returns := make([]float64, 0, len(c)-1)
+	peak := c[0]
+	for i := 1; i < len(c); i++ {
+		returns = append(returns, c[i].Close/c[i-1].Close-1)
+		if c[i].Close > peak.Close {
+			peak = c[i]
+		}
+		if drawdown := (peak.Close - c[i].Close) * 100 / peak.Close; drawdown > res.MaxDrawdown {
+			res.MaxDrawdown = drawdown
+			res.MaxDrawdownFrom = peak.Ts
+			res.MaxDrawdownTo = c[i].Ts
+		}
+	}
+	res.MaxDrawdown = round(res.MaxDrawdown)
+	res.CurrentDrawdown = round((peak.Close - last.Close) * 100 / peak.Close)
+	res.PeakD = peak.Ts
+
+	res.Volatility30d = volatility(returns, 30)
+	res.Volatility90d = volatility(returns, 90)
+
+	annual := math.Sqrt(tradingDaysInYear)
+	dailyRate := riskFreeRate / 100 / tradingDaysInYear
+	mean, std := meanStd(returns)
+	var downside float64
+	for _, r := range returns {
+		if r < dailyRate {
+			downside += (r - dailyRate) * (r - dailyRate)
+		}
+	}
+	downside = math.Sqrt(downside / float64(len(returns)))
+	res.DownsideDeviation = round(downside * annual * 100)
+	if std > 0 {
+		sharpe := round((mean - dailyRate) / std * annual)
+		res.Sharpe = &sharpe
+	}
+	if downside > 0 {
+		sortino := round((mean - dailyRate) / downside * annual)
+		res.Sortino = &sortino
+	}
+
+	if l := lastConcentration(concentrations); l != nil {
+		if share := whalesShare(l); share != nil {
+			whalesPercent := round(*share)
+			res.WhalesPercent = &whalesPercent
+			res.WhalesChange30d = whalesChange(concentrations, l, *share, 30)
+			res.WhalesChange90d = whalesChange(concentrations, l, *share, 90)
+		}
+	}
+	return res
+}
+
+// CalcRiskList returns the risk of the currencies which have the candles
+func CalcRiskList(currencyList *CurrencyList, candleMap price_and_cap.CandleMap, concentrationMap concentration.ConcentrationMap, riskFreeRate float64) *RiskList {
+	res := make(RiskList, 0)
+	if currencyList == nil {
+		return &res
+	}
+	var item Currency
+	for _, item = range *currencyList {
+		candles, ok := candleMap[item.ID]
+		if !ok {
+			continue
+		}
+		var concentrations *concentration.ConcentrationList
+		if l, ok := concentrationMap[item.ID]; ok {
+			concentrations = &l
+		}
+		if e := CalcRisk(&item, &candles, concentrations, riskFreeRate); e != nil {
+			res = append(res, *e)
+		}
+	}
+	return &res
+}
+
+// dailyCloses returns the candles with a positive close with the gaps filled by the previous close and the number of the days with a price
+func dailyCloses(candles price_and_cap.CandleList) (price_and_cap.CandleList, uint) {
+	res := make(price_and_cap.CandleList, 0, len(candles))
+	var daysNb uint
+	var item price_and_cap.Candle
+	for _, item = range candles {
+		if item.Close <= 0 {
+			continue
+		}
+		if len(res) > 0 {
+			prev := res[len(res)-1]
+			for d := prev.Ts.Add(time.Hour * 24); d.Before(item.Ts); d = d.Add(time.Hour * 24) {
+				prev.Ts = d
+				res = append(res, prev)
+			}
+		}
+		res = append(res, item)
+		daysNb++
+	}
+	return res, daysNb
+}
+
+// volatility returns the annualized standard deviation of the last days returns in percent or nil if there are less returns
+func volatility(returns []float64, days int) *float64 {
+	if len(returns) < days {
+		return nil
+	}
+	_, std := meanStd(returns[len(returns)-days:])
+	res := round(std * math.Sqrt(tradingDaysInYear) * 100)
+	return &res
+}
+
+// meanStd returns the mean and the sample standard deviation
+func meanStd(xs []float64) (float64, float64) {
+	if len(xs) == 0 {
+		return 0, 0
+	}
+	var mean, variance float64
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	if len(xs) == 1 {
+		return mean, 0
+	}
+	for _, x := range xs {
+		variance += (x - mean) * (x - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(xs)-1))
+}
+
+// whalesShare returns the share of the whales in percent or nil without the holders
+func whalesShare(c *concentration.Concentration) *float64 {
+	total := c.Whales + c.Investors + c.Retail
+	if total <= 0 {
+		return nil
+	}
+	res := c.Whales * 100 / total
+	return &res
+}
+
+// whalesChange returns the change of the share of the whales from the last concentration not later than the days before the last one
+func whalesChange(list *concentration.ConcentrationList, last *concentration.Concentration, share float64, days int) *float64 {
+	d := last.D.AddDate(0, 0, -days)
+	var base *concentration.Concentration
+	for i := range *list {
+		if (*list)[i].D.After(d) {
+			continue
+		}
+		if base == nil || (*list)[i].D.After(base.D) {
+			base = &(*list)[i]
+		}
+	}
+	if base == nil {
+		return nil
+	}
+	baseShare := whalesShare(base)
+	if baseShare == nil {
+		return nil
+	}
+	res := round(share - *baseShare)
+	return &res
+}
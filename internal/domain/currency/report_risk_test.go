@@ -0,0 +1,168 @@
+package currency
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"info/internal/domain/concentration"
+	"info/internal/domain/price_and_cap"
+	"info/internal/pkg/apperror"
+)
+
+func TestCalcRisk(t *testing.T) {
+	day0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := func(i int) time.Time {
+		return day0.AddDate(0, 0, i)
+	}
+	candles := func(closes ...float64) *price_and_cap.CandleList {
+		res := make(price_and_cap.CandleList, 0, len(closes))
+		for i, c := range closes {
+			res = append(res, price_and_cap.Candle{Close: c, Ts: day(i)})
+		}
+		return &res
+	}
+	item := Currency{ID: 1, Symbol: "BTC", CmcRank: 1}
+
+	t.Run("drawdowns and ratios", func(t *testing.T) {
+		res := CalcRisk(&item, candles(100, 110, 99, 121, 110), nil, 0)
+		if res == nil {
+			t.Fatal("got nil")
+		}
+		if res.Price != 110 || !res.D.Equal(day(4)) || res.DaysNb != 5 {
+			t.Errorf("got %+v", *res)
+		}
+		if res.MaxDrawdown != 10 || !res.MaxDrawdownFrom.Equal(day(1)) || !res.MaxDrawdownTo.Equal(day(2)) {
+			t.Errorf("max drawdown: got %v from %v to %v", res.MaxDrawdown, res.MaxDrawdownFrom, res.MaxDrawdownTo)
+		}
+		if res.CurrentDrawdown != 9.09 || !res.PeakD.Equal(day(3)) {
+			t.Errorf("current drawdown: got %v from %v", res.CurrentDrawdown, res.PeakD)
+		}
+		if res.Volatility30d != nil || res.Volatility90d != nil {
+			t.Errorf("volatility without 30 returns: got %v %v", res.Volatility30d, res.Volatility90d)
+		}
+		// the returns: 0.1, -0.1, 0.2222, -0.0909
+		returns := []float64{0.1, -0.1, 121.0/99 - 1, 110.0/121 - 1}
+		mean, std := meanStd(returns)
+		downside := math.Sqrt((0.01 + returns[3]*returns[3]) / 4)
+		if res.Sharpe == nil || *res.Sharpe != round(mean/std*math.Sqrt(365)) {
+			t.Errorf("sharpe: got %v", res.Sharpe)
+		}
+		if res.Sortino == nil || *res.Sortino != round(mean/downside*math.Sqrt(365)) {
+			t.Errorf("sortino: got %v", res.Sortino)
+		}
+		if res.DownsideDeviation != round(downside*math.Sqrt(365)*100) {
+			t.Errorf("downside deviation: got %v", res.DownsideDeviation)
+		}
+	})
+
+	t.Run("the risk-free rate", func(t *testing.T) {
+		withoutRate := CalcRisk(&item, candles(100, 110, 99, 121, 110), nil, 0)
+		withRate := CalcRisk(&item, candles(100, 110, 99, 121, 110), nil, 50)
+		if *withRate.Sharpe >= *withoutRate.Sharpe || withRate.DownsideDeviation <= withoutRate.DownsideDeviation {
+			t.Errorf("got %v %v, without the rate %v %v", *withRate.Sharpe, withRate.DownsideDeviation, *withoutRate.Sharpe, withoutRate.DownsideDeviation)
+		}
+	})
+
+	t.Run("flat prices and whales", func(t *testing.T) {
+		closes := make([]float64, 31)
+		for i := range closes {
+			closes[i] = 10
+		}
+		concentrations := concentration.ConcentrationList{
+			{Whales: 60, Investors: 30, Retail: 10, D: day(30)},
+			{Whales: 50, Investors: 30, Retail: 20, D: day(0)},
+		}
+		res := CalcRisk(&item, candles(closes...), &concentrations, 0)
+		if res.Volatility30d == nil || *res.Volatility30d != 0 || res.Volatility90d != nil {
+			t.Errorf("volatility: got %v %v", res.Volatility30d, res.Volatility90d)
+		}
+		if res.MaxDrawdown != 0 || res.Sharpe != nil || res.Sortino != nil {
+			t.Errorf("got %+v", *res)
+		}
+		if res.WhalesPercent == nil || *res.WhalesPercent != 60 || res.WhalesChange30d == nil || *res.WhalesChange30d != 10 || res.WhalesChange90d != nil {
+			t.Errorf("whales: got %v %v %v", res.WhalesPercent, res.WhalesChange30d, res.WhalesChange90d)
+		}
+	})
+
+	t.Run("the candles without a price", func(t *testing.T) {
+		res := CalcRisk(&item, candles(0, 100, 110, -1, 99, 121, 110), nil, 0)
+		if res == nil {
+			t.Fatal("got nil")
+		}
+		if res.DaysNb != 5 || res.MaxDrawdown != 10 || res.CurrentDrawdown != 9.09 || math.IsInf(*res.Sharpe, 0) || math.IsNaN(*res.Sharpe) {
+			t.Errorf("got %+v", *res)
+		}
+		if res := CalcRisk(&item, candles(0, 100, 0), nil, 0); res != nil {
+			t.Errorf("a single candle with a price: got %+v", *res)
+		}
+	})
+
+	t.Run("a gap of the data", func(t *testing.T) {
+		// 31 days with the days 10-19 missing: the gap repeats the close, so there are 30 returns of 30 days
+		list := make(price_and_cap.CandleList, 0, 21)
+		for i := 0; i <= 30; i++ {
+			if i >= 10 && i < 20 {
+				continue
+			}
+			list = append(list, price_and_cap.Candle{Close: 100 + float64(i%2), Ts: day(i)})
+		}
+		res := CalcRisk(&item, &list, nil, 0)
+		if res == nil || res.DaysNb != 21 || !res.D.Equal(day(30)) {
+			t.Fatalf("got %+v", res)
+		}
+		if res.Volatility30d == nil {
+			t.Errorf("volatility of the 30 days: got nil")
+		}
+	})
+
+	if res := CalcRisk(&item, candles(100), nil, 0); res != nil {
+		t.Errorf("a single candle: got %+v", *res)
+	}
+}
+
+func TestRiskList_Sort(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+	list := RiskList{
+		{Symbol: "A", MaxDrawdown: 50, Sharpe: value(1)},
+		{Symbol: "B", MaxDrawdown: 10},
+		{Symbol: "C", MaxDrawdown: 30, Sharpe: value(2)},
+	}
+
+	tests := []struct {
+		sortBy string
+		desc   bool
+		want   []string
+	}{
+		{sortBy: RiskSort_MaxDrawdown, want: []string{"B", "C", "A"}},
+		{sortBy: RiskSort_Sharpe, desc: true, want: []string{"C", "A", "B"}},
+		{sortBy: RiskSort_Sharpe, want: []string{"A", "C", "B"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sortBy, func(t *testing.T) {
+			l := make(RiskList, len(list))
+			copy(l, list)
+			res := l.Sort(tt.sortBy, tt.desc).Limit(2)
+			if len(*res) != 2 || (*res)[0].Symbol != tt.want[0] || (*res)[1].Symbol != tt.want[1] {
+				t.Errorf("got %+v, want %v", *res, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRiskQuery(t *testing.T) {
+	q, err := NewRiskQuery(0, 0, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Period != DefaultRiskPeriod || q.SortBy != RiskSort_Volatility30d || !q.Desc {
+		t.Errorf("got %+v", *q)
+	}
+	if _, err = NewRiskQuery(time.Hour*24, 0, "", ""); !errors.Is(err, apperror.ErrBadRequest) {
+		t.Errorf("short period: got %v", err)
+	}
+	if _, err = NewRiskQuery(0, 0, "beta", ""); !errors.Is(err, apperror.ErrBadRequest) {
+		t.Errorf("bad sort: got %v", err)
+	}
+}
@@ -1,40 +1,104 @@
 package currency
 
 import (
+	"fmt"
+	"info/internal/domain/concentration"
+	"info/internal/domain/oracul_daily_balance_stats"
+	"info/internal/domain/trend"
+	"info/internal/pkg/apperror"
+	"math"
 	"slices"
 	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
-type WhaleFall struct {
-	Symbol           string
-	FallDuration     time.Duration
-	DayFrom          time.Time
-	DayTo            time.Time
-	FallValue        float64
-	ValueFrom        float64
-	ValueTo          float64
-	FallValuePercent float64
-	FallCap          float64
-	CapFrom          float64
-	CapTo            float64
-	FallCapPercent   float64
-	FallPrice        float64
-	PriceFrom        float64
-	PriceTo          float64
-	FallPricePercent float64
+const (
+	TrendSource_Concentration = "concentration" // the shares of the holders of cmc.concentration
+	TrendSource_Oracul        = "oracul"        // the balances of the holders of oracul.daily_balance_stats
+
+	Cohort_Whales    = "whales"
+	Cohort_Investors = "investors"
+	Cohort_Retail    = "retail" // the retailers of oracul
+)
+
+var TrendSourceList = []interface{}{
+	TrendSource_Concentration,
+	TrendSource_Oracul,
 }
 
-type WhaleFallList []WhaleFall
+var CohortList = []interface{}{
+	Cohort_Whales,
+	Cohort_Investors,
+	Cohort_Retail,
+}
+
+// TrendQuery is the series of the whale reports and the params of the detection of the trends on it
+type TrendQuery struct {
+	Source string
+	Cohort string
+	Params trend.Params
+}
 
-func (l *WhaleFallList) SortByFallValueDesc() *WhaleFallList {
+// NewTrendQuery returns the query of the params; the whales of the concentration by default
+func NewTrendQuery(source string, cohort string, params *trend.Params) (*TrendQuery, error) {
+	if source == "" {
+		source = TrendSource_Concentration
+	}
+	if cohort == "" {
+		cohort = Cohort_Whales
+	}
+	if err := validation.Validate(source, validation.In(TrendSourceList...)); err != nil {
+		return nil, fmt.Errorf("[%w] source: %w", apperror.ErrBadRequest, err)
+	}
+	if err := validation.Validate(cohort, validation.In(CohortList...)); err != nil {
+		return nil, fmt.Errorf("[%w] cohort: %w", apperror.ErrBadRequest, err)
+	}
+	if params == nil {
+		return nil, fmt.Errorf("[%w] the params of the trend are required", apperror.ErrBadRequest)
+	}
+	return &TrendQuery{
+		Source: source,
+		Cohort: cohort,
+		Params: *params,
+	}, nil
+}
+
+// WhaleTrend is the latest trend of the cohort of a currency with the price and the cap of its first and last days
+type WhaleTrend struct {
+	Symbol             string
+	Source             string
+	Cohort             string
+	Direction          string
+	Duration           time.Duration
+	DayFrom            time.Time
+	DayTo              time.Time
+	Change             float64
+	ValueFrom          float64
+	ValueTo            float64
+	ChangePercent      float64
+	CapChange          float64
+	CapFrom            float64
+	CapTo              float64
+	CapChangePercent   float64
+	PriceChange        float64
+	PriceFrom          float64
+	PriceTo            float64
+	PriceChangePercent float64
+}
+
+type WhaleTrendList []WhaleTrend
+
+// SortByChangeDesc sorts by the absolute change of the value, so the biggest fall or rise is the first
+func (l *WhaleTrendList) SortByChangeDesc() *WhaleTrendList {
 	if l == nil {
 		return nil
 	}
-	slices.SortFunc(*l, func(a, b WhaleFall) int {
+	slices.SortFunc(*l, func(a, b WhaleTrend) int {
 		switch {
-		case a.FallValue < b.FallValue:
+		case math.Abs(a.Change) < math.Abs(b.Change):
 			return 1
-		case a.FallValue > b.FallValue:
+		case math.Abs(a.Change) > math.Abs(b.Change):
 			return -1
 		default:
 			return 0
@@ -43,15 +107,15 @@ func (l *WhaleFallList) SortByFallValueDesc() *WhaleFallList {
 	return l
 }
 
-func (l *WhaleFallList) SortByFallDurationDesc() *WhaleFallList {
+func (l *WhaleTrendList) SortByDurationDesc() *WhaleTrendList {
 	if l == nil {
 		return nil
 	}
-	slices.SortFunc(*l, func(a, b WhaleFall) int {
+	slices.SortFunc(*l, func(a, b WhaleTrend) int {
 		switch {
-		case a.FallDuration < b.FallDuration:
+		case a.Duration < b.Duration:
 			return 1
-		case a.FallDuration > b.FallDuration:
+		case a.Duration > b.Duration:
 			return -1
 		default:
 			return 0
@@ -60,7 +124,7 @@ func (l *WhaleFallList) SortByFallDurationDesc() *WhaleFallList {
 	return l
 }
 
-func (l *WhaleFallList) Limit(limit uint) *WhaleFallList {
+func (l *WhaleTrendList) Limit(limit uint) *WhaleTrendList {
 	if l == nil {
 		return nil
 	}
@@ -70,3 +134,154 @@ func (l *WhaleFallList) Limit(limit uint) *WhaleFallList {
 	newList := (*l)[:limit]
 	return &newList
 }
+
+func (l *WhaleTrendList) WhaleFallList() *WhaleFallList {
+	if l == nil {
+		return nil
+	}
+	res := make(WhaleFallList, 0, len(*l))
+	for i := range *l {
+		res = append(res, *(*l)[i].WhaleFall())
+	}
+	return &res
+}
+
+func (l *WhaleTrendList) WhaleRiseList() *WhaleRiseList {
+	if l == nil {
+		return nil
+	}
+	res := make(WhaleRiseList, 0, len(*l))
+	for i := range *l {
+		res = append(res, *(*l)[i].WhaleRise())
+	}
+	return &res
+}
+
+func (e *WhaleTrend) WhaleFall() *WhaleFall {
+	return &WhaleFall{
+		Symbol:           e.Symbol,
+		FallDuration:     e.Duration,
+		DayFrom:          e.DayFrom,
+		DayTo:            e.DayTo,
+		FallValue:        -e.Change,
+		ValueFrom:        e.ValueFrom,
+		ValueTo:          e.ValueTo,
+		FallValuePercent: e.ChangePercent,
+		FallCap:          -e.CapChange,
+		CapFrom:          e.CapFrom,
+		CapTo:            e.CapTo,
+		FallCapPercent:   e.CapChangePercent,
+		FallPrice:        -e.PriceChange,
+		PriceFrom:        e.PriceFrom,
+		PriceTo:          e.PriceTo,
+		FallPricePercent: e.PriceChangePercent,
+	}
+}
+
+func (e *WhaleTrend) WhaleRise() *WhaleRise {
+	return &WhaleRise{
+		Symbol:           e.Symbol,
+		RiseDuration:     e.Duration,
+		DayFrom:          e.DayFrom,
+		DayTo:            e.DayTo,
+		RiseValue:        e.Change,
+		ValueFrom:        e.ValueFrom,
+		ValueTo:          e.ValueTo,
+		RiseValuePercent: e.ChangePercent,
+		RiseCap:          e.CapChange,
+		CapFrom:          e.CapFrom,
+		CapTo:            e.CapTo,
+		RiseCapPercent:   e.CapChangePercent,
+		RisePrice:        e.PriceChange,
+		PriceFrom:        e.PriceFrom,
+		PriceTo:          e.PriceTo,
+		RisePricePercent: e.PriceChangePercent,
+	}
+}
+
+// ConcentrationPoints returns the series of the cohort of the concentration
+func ConcentrationPoints(l *concentration.ConcentrationList, cohort string) *trend.PointList {
+	if l == nil {
+		return nil
+	}
+	res := make(trend.PointList, 0, len(*l))
+	var item concentration.Concentration
+	for _, item = range *l {
+		point := trend.Point{D: item.D}
+		switch cohort {
+		case Cohort_Whales:
+			point.Value = item.Whales
+		case Cohort_Investors:
+			point.Value = item.Investors
+		case Cohort_Retail:
+			point.Value = item.Retail
+		}
+		res = append(res, point)
+	}
+	return &res
+}
+
+// OraculDailyBalanceStatsPoints returns the series of the balance of the cohort of the oracul stats
+func OraculDailyBalanceStatsPoints(l *oracul_daily_balance_stats.OraculDailyBalanceStatsList, cohort string) *trend.PointList {
+	if l == nil {
+		return nil
+	}
+	res := make(trend.PointList, 0, len(*l))
+	var item oracul_daily_balance_stats.OraculDailyBalanceStats
+	for _, item = range *l {
+		point := trend.Point{D: item.D}
+		switch cohort {
+		case Cohort_Whales:
+			point.Value = item.WhalesBalance
+		case Cohort_Investors:
+			point.Value = item.InvestorsBalance
+		case Cohort_Retail:
+			point.Value = item.RetailersBalance
+		}
+		res = append(res, point)
+	}
+	return &res
+}
+
+type WhaleFall struct {
+	Symbol           string
+	FallDuration     time.Duration
+	DayFrom          time.Time
+	DayTo            time.Time
+	FallValue        float64
+	ValueFrom        float64
+	ValueTo          float64
+	FallValuePercent float64
+	FallCap          float64
+	CapFrom          float64
+	CapTo            float64
+	FallCapPercent   float64
+	FallPrice        float64
+	PriceFrom        float64
+	PriceTo          float64
+	FallPricePercent float64
+}
+
+type WhaleFallList []WhaleFall
+
+// WhaleRise is the latest sustained rise of the whales of a currency (the accumulation) with the price and the cap of its first and last days
+type WhaleRise struct {
+	Symbol           string
+	RiseDuration     time.Duration
+	DayFrom          time.Time
+	DayTo            time.Time
+	RiseValue        float64
+	ValueFrom        float64
+	ValueTo          float64
+	RiseValuePercent float64
+	RiseCap          float64
+	CapFrom          float64
+	CapTo            float64
+	RiseCapPercent   float64
+	RisePrice        float64
+	PriceFrom        float64
+	PriceTo          float64
+	RisePricePercent float64
+}
+
+type WhaleRiseList []WhaleRise
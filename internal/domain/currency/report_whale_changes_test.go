@@ -0,0 +1,127 @@
+package currency
+
+import (
+	"testing"
+	"time"
+
+	"info/internal/domain/concentration"
+	"info/internal/domain/oracul_daily_balance_stats"
+	"info/internal/domain/price_and_cap"
+	"info/internal/domain/trend"
+)
+
+func TestCalcWhaleTrend(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Hour * 24)
+	day := func(ago int) time.Time {
+		return now.AddDate(0, 0, -ago)
+	}
+	// concentration is sorted by the time desc
+	concentrationList := concentration.ConcentrationList{
+		{Whales: 60, Investors: 20, Retail: 20, D: day(0)},
+		{Whales: 55, Investors: 25, Retail: 20, D: day(1)},
+		{Whales: 40, Investors: 30, Retail: 30, D: day(3)},
+		{Whales: 70, Investors: 10, Retail: 20, D: day(10)},
+	}
+	statsList := oracul_daily_balance_stats.OraculDailyBalanceStatsList{
+		{WhalesBalance: 1000, InvestorsBalance: 500, RetailersBalance: 100, D: day(0)},
+		{WhalesBalance: 900, InvestorsBalance: 600, RetailersBalance: 200, D: day(2)},
+	}
+	priceAndCapList := price_and_cap.PriceAndCapList{
+		{Price: 2, Cap: 200, Ts: day(0)},
+		{Price: 1, Cap: 100, Ts: day(3)},
+	}
+	params := func(direction string) trend.Params {
+		return trend.Params{Direction: direction, Period: trend.DefaultPeriod, MaxBreak: trend.DefaultMaxBreak}
+	}
+
+	tests := []struct {
+		name      string
+		points    *trend.PointList
+		query     TrendQuery
+		wantNil   bool
+		wantFrom  float64
+		wantTo    float64
+		wantPrice float64
+	}{
+		{
+			name:      "the rise of the whales",
+			points:    ConcentrationPoints(&concentrationList, Cohort_Whales),
+			query:     TrendQuery{Source: TrendSource_Concentration, Cohort: Cohort_Whales, Params: params(trend.Direction_Rise)},
+			wantFrom:  40,
+			wantTo:    60,
+			wantPrice: 1,
+		},
+		{
+			name:      "the fall of the investors",
+			points:    ConcentrationPoints(&concentrationList, Cohort_Investors),
+			query:     TrendQuery{Source: TrendSource_Concentration, Cohort: Cohort_Investors, Params: params(trend.Direction_Fall)},
+			wantFrom:  30,
+			wantTo:    20,
+			wantPrice: 1,
+		},
+		{
+			name:     "the latest fall of the whales is before their rise",
+			points:   ConcentrationPoints(&concentrationList, Cohort_Whales),
+			query:    TrendQuery{Source: TrendSource_Concentration, Cohort: Cohort_Whales, Params: params(trend.Direction_Fall)},
+			wantFrom: 70,
+			wantTo:   40,
+		},
+		{
+			name:    "no rise of the retailers of oracul",
+			points:  OraculDailyBalanceStatsPoints(&statsList, Cohort_Retail),
+			query:   TrendQuery{Source: TrendSource_Oracul, Cohort: Cohort_Retail, Params: params(trend.Direction_Rise)},
+			wantNil: true,
+		},
+		{
+			name:     "the fall of the retailers of oracul",
+			points:   OraculDailyBalanceStatsPoints(&statsList, Cohort_Retail),
+			query:    TrendQuery{Source: TrendSource_Oracul, Cohort: Cohort_Retail, Params: params(trend.Direction_Fall)},
+			wantFrom: 200,
+			wantTo:   100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := calcWhaleTrend(&Currency{Symbol: "BTC"}, &priceAndCapList, tt.points, &tt.query, now)
+			if tt.wantNil {
+				if res != nil {
+					t.Errorf("got %+v, want nil", *res)
+				}
+				return
+			}
+			if res == nil {
+				t.Fatal("got nil")
+			}
+			if res.ValueFrom != tt.wantFrom || res.ValueTo != tt.wantTo || res.Change != tt.wantTo-tt.wantFrom {
+				t.Errorf("got %+v", *res)
+			}
+			if res.Source != tt.query.Source || res.Cohort != tt.query.Cohort || res.Direction != tt.query.Params.Direction {
+				t.Errorf("got %+v", *res)
+			}
+			// without the price of the day the price is 0
+			if res.PriceFrom != tt.wantPrice {
+				t.Errorf("PriceFrom: got %v, want %v", res.PriceFrom, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestWhaleTrend_WhaleFall(t *testing.T) {
+	e := WhaleTrend{Symbol: "BTC", Duration: time.Hour, Change: -10, ValueFrom: 50, ValueTo: 40, ChangePercent: 80, CapChange: -5, CapFrom: 100, CapTo: 95, PriceChange: 1, PriceFrom: 1, PriceTo: 2}
+	fall := e.WhaleFall()
+	if fall.FallValue != 10 || fall.FallCap != 5 || fall.FallPrice != -1 || fall.FallValuePercent != 80 || fall.FallDuration != time.Hour {
+		t.Errorf("got %+v", *fall)
+	}
+	rise := e.WhaleRise()
+	if rise.RiseValue != -10 || rise.RiseCap != -5 || rise.RisePrice != 1 {
+		t.Errorf("got %+v", *rise)
+	}
+}
+
+func TestWhaleTrendList_SortByChangeDesc(t *testing.T) {
+	l := WhaleTrendList{{Symbol: "A", Change: -5}, {Symbol: "B", Change: -15}, {Symbol: "C", Change: 10}}
+	res := *l.SortByChangeDesc().Limit(2)
+	if len(res) != 2 || res[0].Symbol != "B" || res[1].Symbol != "C" {
+		t.Errorf("got %+v", res)
+	}
+}
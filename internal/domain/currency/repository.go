@@ -17,9 +17,13 @@ type WriteRepository interface {
 	MUpsert(ctx context.Context, entities *CurrencyList) error
 	Update(ctx context.Context, entity *Currency) error
 	Delete(ctx context.Context, ID uint) error
+	MSetObserving(ctx context.Context, IDs *[]uint, isForObserving bool) error
 	MCreateImportMaxTime(ctx context.Context, entities *[]ImportMaxTime) error
 	MUpsertImportMaxTimeTx(ctx context.Context, tx domain.Tx, entities *[]ImportMaxTime) error
 	MUpsertImportMaxTimeMapTx(ctx context.Context, tx domain.Tx, entities map[uint]ImportMaxTime) error
+	UpsertImportError(ctx context.Context, currencyID uint, datasets []string, errText string) error
+	DeleteImportErrorTx(ctx context.Context, tx domain.Tx, currencyID uint, datasets []string) error
+	MUpsertTokenAddress(ctx context.Context, entities *TokenAddressList) error
 }
 
 type ReadRepository interface {
@@ -28,5 +32,6 @@ type ReadRepository interface {
 	MGet(ctx context.Context, IDs *[]uint) (*CurrencyList, error)
 	MGetBySlug(ctx context.Context, slugs *[]string) (*CurrencyList, error)
 	GetAll(ctx context.Context) (*CurrencyList, error)
+	GetList(ctx context.Context, isForObserving *bool) (*CurrencyList, error)
 	MGetTokenAddress(ctx context.Context, IDs *[]uint) (*TokenAddressList, error)
 }
@@ -2,21 +2,34 @@ package currency
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"info/internal/domain"
 	"info/internal/domain/concentration"
+	"info/internal/domain/currency_snapshot"
+	"info/internal/domain/import_run"
 	"info/internal/domain/oracul_analytics"
+	"info/internal/domain/oracul_daily_balance_stats"
 	"info/internal/domain/price_and_cap"
+	"info/internal/domain/trend"
 	"info/internal/pkg/apperror"
+	"info/internal/pkg/fencing"
+	"info/internal/pkg/workerpool"
 	"math"
 	"runtime/debug"
+	"sync/atomic"
 	"time"
 )
 
 const (
 	defaultCapacity = 100
+
+	workerPoolJob_Import      = "currency.import"
+	workerPoolJob_ImportRetry = "currency.import_retry"
+	workerPoolJob_Backfill    = "currency.backfill"
+
+	// tokenAddressBatchSize is the number of the currencies of one request of the token addresses
+	tokenAddressBatchSize = 100
 )
 
 type CmcApi interface {
@@ -25,25 +38,32 @@ type CmcApi interface {
 
 type CmcProApi interface {
 	GetCurrenciesBySlugs(ctx context.Context, slugs *[]string) (currencyMap CurrencyMap, err error)
+	GetTokenAddresses(ctx context.Context, currencyIDs *[]uint) (*TokenAddressList, error)
 }
 
 type Service struct {
-	replicaSet      ReplicaSet
-	priceAndCap     *price_and_cap.Service
-	concentration   *concentration.Service
-	oraculAnalytics *oracul_analytics.Service
-	cmcApi          CmcApi
-	cmcProApi       CmcProApi
+	replicaSet              ReplicaSet
+	priceAndCap             *price_and_cap.Service
+	concentration           *concentration.Service
+	oraculAnalytics         *oracul_analytics.Service
+	oraculDailyBalanceStats *oracul_daily_balance_stats.Service
+	currencySnapshot        *currency_snapshot.Service
+	cmcApi                  CmcApi
+	cmcProApi               CmcProApi
+	workerPool              *workerpool.Pool
 }
 
-func NewService(replicaSet ReplicaSet, priceAndCap *price_and_cap.Service, concentration *concentration.Service, oraculAnalytics *oracul_analytics.Service, cmcApi CmcApi, cmcProApi CmcProApi) *Service {
+func NewService(replicaSet ReplicaSet, priceAndCap *price_and_cap.Service, concentration *concentration.Service, oraculAnalytics *oracul_analytics.Service, oraculDailyBalanceStats *oracul_daily_balance_stats.Service, currencySnapshot *currency_snapshot.Service, cmcApi CmcApi, cmcProApi CmcProApi, workerPool *workerpool.Pool) *Service {
 	return &Service{
-		replicaSet:      replicaSet,
-		priceAndCap:     priceAndCap,
-		concentration:   concentration,
-		oraculAnalytics: oraculAnalytics,
-		cmcApi:          cmcApi,
-		cmcProApi:       cmcProApi,
+		replicaSet:              replicaSet,
+		priceAndCap:             priceAndCap,
+		concentration:           concentration,
+		oraculAnalytics:         oraculAnalytics,
+		oraculDailyBalanceStats: oraculDailyBalanceStats,
+		currencySnapshot:        currencySnapshot,
+		cmcApi:                  cmcApi,
+		cmcProApi:               cmcProApi,
+		workerPool:              workerPool,
 	}
 }
 
@@ -59,6 +79,19 @@ func (s *Service) Delete(ctx context.Context, ID uint) error {
 	return s.replicaSet.WriteRepo().Delete(ctx, ID)
 }
 
+// MUpsert creates or updates the currencies; the new ones get the empty import max time
+func (s *Service) MUpsert(ctx context.Context, list *CurrencyList) error {
+	if err := s.replicaSet.WriteRepo().MUpsert(ctx, list); err != nil {
+		return err
+	}
+	return s.createEmptyImportMaxTime(ctx, list.IDs())
+}
+
+// MSetObserving switches on or off the observing of the currencies
+func (s *Service) MSetObserving(ctx context.Context, IDs *[]uint, isForObserving bool) error {
+	return s.replicaSet.WriteRepo().MSetObserving(ctx, IDs, isForObserving)
+}
+
 func (s *Service) Get(ctx context.Context, ID uint) (*Currency, error) {
 	return s.replicaSet.ReadRepo().Get(ctx, ID)
 }
@@ -68,14 +101,308 @@ func (s *Service) GetAll(ctx context.Context) (*CurrencyList, error) {
 }
 
 func (s *Service) Import(ctx context.Context, listOfCurrencySlugs *[]string) (err error) {
-	const metricName = "currency.Service.ImportTx"
-	var tx domain.Tx
-
 	currencyList, err := s.baseImport(ctx, listOfCurrencySlugs)
 	if err != nil {
 		return err
 	}
 
+	return s.importTx(ctx, currencyList, Dataset_PriceAndCap, Dataset_Concentration)
+}
+
+// ImportCurrencies refreshes the metadata of the currencies from the list
+func (s *Service) ImportCurrencies(ctx context.Context, listOfCurrencySlugs *[]string) (*CurrencyList, error) {
+	return s.baseImport(ctx, listOfCurrencySlugs)
+}
+
+// ImportPriceAndCap imports price_and_cap of the already known currencies from the list
+func (s *Service) ImportPriceAndCap(ctx context.Context, listOfCurrencySlugs *[]string) error {
+	currencyList, err := s.getBySlugs(ctx, listOfCurrencySlugs)
+	if err != nil {
+		return err
+	}
+
+	return s.importTx(ctx, currencyList, Dataset_PriceAndCap)
+}
+
+// ImportConcentration imports concentration of the already known currencies from the list
+func (s *Service) ImportConcentration(ctx context.Context, listOfCurrencySlugs *[]string) error {
+	currencyList, err := s.getBySlugs(ctx, listOfCurrencySlugs)
+	if err != nil {
+		return err
+	}
+
+	return s.importTx(ctx, currencyList, Dataset_Concentration)
+}
+
+// ImportOracul imports oracul analytics for token addresses of the already known currencies from the list; an empty list means all the observed currencies
+func (s *Service) ImportOracul(ctx context.Context, listOfCurrencySlugs *[]string) (err error) {
+	var currencyList *CurrencyList
+	if listOfCurrencySlugs == nil || len(*listOfCurrencySlugs) == 0 {
+		currencyList, err = s.replicaSet.ReadRepo().GetAll(ctx)
+	} else {
+		currencyList, err = s.getBySlugs(ctx, listOfCurrencySlugs)
+	}
+	if err != nil {
+		return err
+	}
+
+	tokenAddressList, err := s.replicaSet.ReadRepo().MGetTokenAddress(ctx, currencyList.IDs())
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return s.oraculAnalytics.Import(ctx, TokenAddressList2OraculAnalyticsTokenAddressList(tokenAddressList))
+}
+
+// ImportTokenAddresses imports the contract addresses of every blockchain of the already known currencies from the list; an empty list means all the observed currencies
+func (s *Service) ImportTokenAddresses(ctx context.Context, listOfCurrencySlugs *[]string) (err error) {
+	var currencyList *CurrencyList
+	if listOfCurrencySlugs == nil || len(*listOfCurrencySlugs) == 0 {
+		currencyList, err = s.replicaSet.ReadRepo().GetAll(ctx)
+	} else {
+		currencyList, err = s.getBySlugs(ctx, listOfCurrencySlugs)
+	}
+	if err != nil {
+		return err
+	}
+
+	IDs := *currencyList.IDs()
+	var batch []uint
+	var tokenAddressList *TokenAddressList
+	for i := 0; i < len(IDs); i += tokenAddressBatchSize {
+		batch = IDs[i:]
+		if len(batch) > tokenAddressBatchSize {
+			batch = batch[:tokenAddressBatchSize]
+		}
+		if tokenAddressList, err = s.cmcProApi.GetTokenAddresses(ctx, &batch); err != nil {
+			return err
+		}
+		if err = s.replicaSet.WriteRepo().MUpsertTokenAddress(ctx, tokenAddressList); err != nil {
+			return err
+		}
+		import_run.AddRows(ctx, len(*tokenAddressList))
+	}
+	return nil
+}
+
+// GetBySlugsAndIDs returns the known currencies with the slugs or the IDs
+func (s *Service) GetBySlugsAndIDs(ctx context.Context, slugs *[]string, IDs *[]uint) (*CurrencyList, error) {
+	res := make(CurrencyList, 0, defaultCapacity)
+	exists := make(map[uint]struct{}, defaultCapacity)
+	var ok bool
+	var item Currency
+	var l *CurrencyList
+	var err error
+
+	if slugs != nil && len(*slugs) > 0 {
+		if l, err = s.replicaSet.ReadRepo().MGetBySlug(ctx, slugs); err != nil && !errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		if l != nil {
+			for _, item = range *l {
+				exists[item.ID] = struct{}{}
+				res = append(res, item)
+			}
+		}
+	}
+
+	if IDs != nil && len(*IDs) > 0 {
+		if l, err = s.replicaSet.ReadRepo().MGet(ctx, IDs); err != nil && !errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		if l != nil {
+			for _, item = range *l {
+				if _, ok = exists[item.ID]; !ok {
+					res = append(res, item)
+				}
+			}
+		}
+	}
+
+	if len(res) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+	return &res, nil
+}
+
+// Backfill reloads the datasets of the currencies for the window [from, to); only the rows inside the window are upserted.
+// The currencies are processed by the worker pool in parallel; the result contains the stats or the error of every dataset of every currency.
+func (s *Service) Backfill(ctx context.Context, currencyList *CurrencyList, from time.Time, to time.Time, datasets *[]string) (*BackfillResultList, error) {
+	if currencyList == nil || len(*currencyList) == 0 || datasets == nil || len(*datasets) == 0 {
+		return &BackfillResultList{}, nil
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("[%w] the start of the window %s must be before the end %s", apperror.ErrBadRequest, from.Format(time.DateOnly), to.Format(time.DateOnly))
+	}
+	var dataset string
+	for _, dataset = range *datasets {
+		if err := DatasetValidate(dataset); err != nil {
+			return nil, fmt.Errorf("[%w] dataset %q: %w", apperror.ErrBadRequest, dataset, err)
+		}
+	}
+
+	var tokenAddressMap map[uint]TokenAddressList
+	for _, dataset = range *datasets {
+		if dataset != Dataset_Oracul {
+			continue
+		}
+		tokenAddressList, err := s.replicaSet.ReadRepo().MGetTokenAddress(ctx, currencyList.IDs())
+		if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		tokenAddressMap = tokenAddressList.Map()
+	}
+
+	results := make([]BackfillResultList, len(*currencyList))
+	err := s.workerPool.Run(ctx, workerPoolJob_Backfill, len(*currencyList), func(ctx context.Context, i int) error {
+		results[i] = s.backfillItem(ctx, &(*currencyList)[i], from, to, datasets, tokenAddressMap[(*currencyList)[i].ID])
+		return results[i].Err()
+	})
+
+	res := make(BackfillResultList, 0, len(*currencyList)*len(*datasets))
+	var l BackfillResultList
+	for _, l = range results {
+		res = append(res, l...)
+	}
+	return &res, err
+}
+
+func (s *Service) backfillItem(ctx context.Context, currency *Currency, from time.Time, to time.Time, datasets *[]string, tokenAddressList TokenAddressList) BackfillResultList {
+	res := make(BackfillResultList, 0, len(*datasets))
+	var dataset string
+	var stats *domain.UpsertStats
+	var err error
+
+	for _, dataset = range *datasets {
+		item := BackfillResult{
+			CurrencyID: currency.ID,
+			Symbol:     currency.Symbol,
+			Dataset:    dataset,
+		}
+
+		switch dataset {
+		case Dataset_PriceAndCap:
+			stats, err = s.priceAndCap.Backfill(ctx, currency.ID, from, to)
+		case Dataset_Concentration:
+			stats, err = s.concentration.Backfill(ctx, currency.ID, from, to)
+		case Dataset_Oracul:
+			stats, err = s.backfillOracul(ctx, tokenAddressList, from, to)
+		}
+		item.Err = err
+		item.UpsertStats.Add(stats)
+		res = append(res, item)
+	}
+	return res
+}
+
+// backfillOracul backfills the canonical token address of the currency; it returns apperror.ErrNotFound if the currency has no token address on a supported blockchain
+func (s *Service) backfillOracul(ctx context.Context, tokenAddressList TokenAddressList, from time.Time, to time.Time) (*domain.UpsertStats, error) {
+	res := &domain.UpsertStats{}
+	var errs error
+	var tokenAddress oracul_analytics.TokenAddress
+
+	canonical := TokenAddressList2OraculAnalyticsTokenAddressList(&tokenAddressList).Canonical()
+	if len(canonical) == 0 {
+		return res, fmt.Errorf("[%w] no token address on a supported blockchain", apperror.ErrNotFound)
+	}
+	for _, tokenAddress = range canonical {
+		stats, err := s.oraculAnalytics.BackfillItem(ctx, &tokenAddress, from, to)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		res.Add(stats)
+	}
+	return res, errs
+}
+
+func (s *Service) getBySlugs(ctx context.Context, listOfCurrencySlugs *[]string) (*CurrencyList, error) {
+	if listOfCurrencySlugs == nil || len(*listOfCurrencySlugs) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+	return s.replicaSet.ReadRepo().MGetBySlug(ctx, listOfCurrencySlugs)
+}
+
+type importItemTxFunc func(ctx context.Context, tx domain.Tx, importMaxTimeItem *ImportMaxTime) error
+
+func (s *Service) importItemTxFunc(dataset string) importItemTxFunc {
+	switch dataset {
+	case Dataset_PriceAndCap:
+		return s.importPriceAndCapTx
+	case Dataset_Concentration:
+		return s.importConcentrationTx
+	}
+	return nil
+}
+
+func (s *Service) importPriceAndCapTx(ctx context.Context, tx domain.Tx, importMaxTimeItem *ImportMaxTime) (err error) {
+	importMaxTimeItem.PriceAndCap, err = s.priceAndCap.ImportTx(ctx, tx, importMaxTimeItem.CurrencyID, importMaxTimeItem.PriceAndCap)
+	return err
+}
+
+func (s *Service) importConcentrationTx(ctx context.Context, tx domain.Tx, importMaxTimeItem *ImportMaxTime) (err error) {
+	importMaxTimeItem.Concentration, err = s.concentration.ImportTx(ctx, tx, importMaxTimeItem.CurrencyID, importMaxTimeItem.Concentration)
+	return err
+}
+
+// importTx imports every currency in its own short transaction together with its checkpoint; the currencies are imported by the worker pool in parallel.
+// A failed currency is recorded with the error of every dataset of the run and skipped; failed currencies are retried once at the end of the run and again on the next run.
+func (s *Service) importTx(ctx context.Context, currencyList *CurrencyList, datasets ...string) (err error) {
+	if currencyList == nil || len(*currencyList) == 0 {
+		return nil
+	}
+
+	isFailed := make([]bool, len(*currencyList))
+	_ = s.workerPool.Run(ctx, workerPoolJob_Import, len(*currencyList), func(ctx context.Context, i int) error {
+		if err := s.importItemTx(ctx, (*currencyList)[i].ID, datasets...); err != nil {
+			isFailed[i] = true
+			return err
+		}
+		return nil
+	})
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	failedList := make(CurrencyList, 0, len(*currencyList))
+	var i int
+	for i = range isFailed {
+		if isFailed[i] {
+			failedList = append(failedList, (*currencyList)[i])
+		}
+	}
+
+	var failedNb atomic.Uint64
+	err = s.workerPool.Run(ctx, workerPoolJob_ImportRetry, len(failedList), func(ctx context.Context, i int) error {
+		if err := s.importItemTx(ctx, failedList[i].ID, datasets...); err != nil {
+			failedNb.Add(1)
+			return fmt.Errorf("currency %d (%s): %w", failedList[i].ID, failedList[i].Symbol, err)
+		}
+		return nil
+	})
+	import_run.AddCurrencies(ctx, uint(len(*currencyList)), uint(failedNb.Load()))
+
+	return err
+}
+
+// importItemTx imports the datasets of the currency in one transaction; a success clears the errors of these datasets only.
+// The checkpoint of a dataset is fenced by the token of the stage lock of the same name carried by the context:
+// the import under a lost lease returns apperror.ErrLocked and is rolled back.
+func (s *Service) importItemTx(ctx context.Context, currencyID uint, datasets ...string) (err error) {
+	const metricName = "currency.Service.importItemTx"
+	var tx domain.Tx
+
+	defer func() {
+		if err != nil && ctx.Err() == nil && !errors.Is(err, apperror.ErrLocked) {
+			if err2 := s.replicaSet.WriteRepo().UpsertImportError(ctx, currencyID, datasets, err.Error()); err2 != nil {
+				err = errors.Join(err, err2)
+			}
+		}
+	}()
+
 	tx, err = s.replicaSet.WriteRepo().Begin(ctx)
 	if err != nil {
 		return err
@@ -95,56 +422,40 @@ func (s *Service) Import(ctx context.Context, listOfCurrencySlugs *[]string) (er
 
 		if tx != nil {
 			if err2 := tx.Rollback(ctx); err2 != nil {
-				err = errors.Join(err, fmt.Errorf("[%w] "+metricName+" Rollback error: %w", apperror.ErrInternal, err))
+				err = errors.Join(err, fmt.Errorf("[%w] "+metricName+" Rollback error: %w", apperror.ErrInternal, err2))
 			}
 		}
 
 	}()
 
-	importMaxTimeMap, err := s.replicaSet.WriteRepo().GetImportMaxTimeForUpdateTx(ctx, tx, currencyList.IDs())
+	importMaxTimeItem := ImportMaxTime{
+		CurrencyID: currencyID,
+	}
+	importMaxTimeMap, err := s.replicaSet.WriteRepo().GetImportMaxTimeForUpdateTx(ctx, tx, &[]uint{currencyID})
 	if err != nil {
-		return err
+		if !errors.Is(err, apperror.ErrNotFound) {
+			return err
+		}
+	} else if item, ok := importMaxTimeMap[currencyID]; ok {
+		importMaxTimeItem = item
 	}
 
-	var importMaxTimeItem ImportMaxTime
-	var currency Currency
-	var ok bool
-	var i int
-	for i, currency = range *currencyList {
-		importMaxTimeItem, ok = importMaxTimeMap[currency.ID]
-		if !ok {
-			importMaxTimeItem = ImportMaxTime{
-				CurrencyID: currency.ID,
-			}
+	var dataset string
+	for _, dataset = range datasets {
+		importFunc := s.importItemTxFunc(dataset)
+		if importFunc == nil {
+			return fmt.Errorf("[%w] "+metricName+" dataset %q can not be imported", apperror.ErrInternal, dataset)
 		}
-		fmt.Printf("%d. %s\n", i, currency.Symbol)
-		time.Sleep(6 * time.Second)
-		if importMaxTimeItem.PriceAndCap, err = s.priceAndCap.ImportTx(ctx, tx, importMaxTimeItem.CurrencyID, importMaxTimeItem.PriceAndCap); err != nil {
+		if err = importFunc(ctx, tx, &importMaxTimeItem); err != nil {
 			return err
 		}
-		time.Sleep(12 * time.Second)
-		if importMaxTimeItem.Concentration, err = s.concentration.ImportTx(ctx, tx, importMaxTimeItem.CurrencyID, importMaxTimeItem.Concentration); err != nil {
-			return err
-		}
-
-		importMaxTimeMap[currency.ID] = importMaxTimeItem
+		importMaxTimeItem.SetFencingToken(dataset, fencing.Token(ctx, dataset))
 	}
 
-	if err = s.replicaSet.WriteRepo().MUpsertImportMaxTimeMapTx(ctx, tx, importMaxTimeMap); err != nil {
+	if err = s.replicaSet.WriteRepo().MUpsertImportMaxTimeTx(ctx, tx, &[]ImportMaxTime{importMaxTimeItem}); err != nil {
 		return err
 	}
-
-	//tokenAddressList, err := s.replicaSet.ReadRepo().MGetTokenAddress(ctx, currencyList.IDs())
-	//if err != nil {
-	//	return err
-	//}
-	//
-	//fmt.Println("OraculAnalytics.Import")
-	//if err = s.oraculAnalytics.Import(ctx, TokenAddressList2OraculAnalyticsTokenAddressList(tokenAddressList)); err != nil {
-	//	return err
-	//}
-
-	return nil
+	return s.replicaSet.WriteRepo().DeleteImportErrorTx(ctx, tx, currencyID, datasets)
 }
 
 func (s *Service) baseImport(ctx context.Context, listOfCurrencySlugs *[]string) (currencyList *CurrencyList, err error) {
@@ -164,8 +475,18 @@ func (s *Service) baseImport(ctx context.Context, listOfCurrencySlugs *[]string)
 		return nil, err
 	}
 	l := currencyMap.List()
+	if err = s.replicaSet.WriteRepo().MUpsert(ctx, l); err != nil {
+		return nil, err
+	}
+	import_run.AddRows(ctx, len(*l))
+
+	// the currency keeps only the latest metadata, so its history is kept by the snapshots; one snapshot an hour
+	if err = s.currencySnapshot.MUpsert(ctx, CurrencyList2CurrencySnapshotList(l, time.Now().UTC().Truncate(time.Hour))); err != nil {
+		return nil, err
+	}
+	import_run.AddRows(ctx, len(*l))
 
-	return l, s.replicaSet.WriteRepo().MUpsert(ctx, l)
+	return l, nil
 }
 
 func (s *Service) baseSimpleImport(ctx context.Context, listOfCurrencySlugs *[]string) (currencyList *CurrencyList, err error) {
@@ -251,23 +572,134 @@ func (s *Service) createEmptyImportMaxTime(ctx context.Context, IDs *[]uint) err
 	return s.replicaSet.WriteRepo().MCreateImportMaxTime(ctx, &maxTimeList)
 }
 
-func (s *Service) Report_BiggestFall(ctx context.Context, limit uint) (*WhaleFallList, error) {
-	l, err := s.getWhaleFallList(ctx)
+func (s *Service) Report_BiggestFall(ctx context.Context, query *TrendQuery, limit uint) (*WhaleFallList, error) {
+	l, err := s.getWhaleTrendList(ctx, query, trend.Direction_Fall)
+	if err != nil {
+		return nil, err
+	}
+	return l.SortByChangeDesc().Limit(limit).WhaleFallList(), nil
+}
+
+func (s *Service) Report_LongestFall(ctx context.Context, query *TrendQuery, limit uint) (*WhaleFallList, error) {
+	l, err := s.getWhaleTrendList(ctx, query, trend.Direction_Fall)
+	if err != nil {
+		return nil, err
+	}
+	return l.SortByDurationDesc().Limit(limit).WhaleFallList(), nil
+}
+
+func (s *Service) Report_BiggestRise(ctx context.Context, query *TrendQuery, limit uint) (*WhaleRiseList, error) {
+	l, err := s.getWhaleTrendList(ctx, query, trend.Direction_Rise)
+	if err != nil {
+		return nil, err
+	}
+	return l.SortByChangeDesc().Limit(limit).WhaleRiseList(), nil
+}
+
+func (s *Service) Report_LongestRise(ctx context.Context, query *TrendQuery, limit uint) (*WhaleRiseList, error) {
+	l, err := s.getWhaleTrendList(ctx, query, trend.Direction_Rise)
+	if err != nil {
+		return nil, err
+	}
+	return l.SortByDurationDesc().Limit(limit).WhaleRiseList(), nil
+}
+
+// Report_BiggestTrend returns the trends of the direction of the query with the biggest change first
+func (s *Service) Report_BiggestTrend(ctx context.Context, query *TrendQuery, limit uint) (*WhaleTrendList, error) {
+	l, err := s.getWhaleTrendList(ctx, query, query.Params.Direction)
+	if err != nil {
+		return nil, err
+	}
+	return l.SortByChangeDesc().Limit(limit), nil
+}
+
+// Report_LongestTrend returns the trends of the direction of the query with the longest one first
+func (s *Service) Report_LongestTrend(ctx context.Context, query *TrendQuery, limit uint) (*WhaleTrendList, error) {
+	l, err := s.getWhaleTrendList(ctx, query, query.Params.Direction)
+	if err != nil {
+		return nil, err
+	}
+	return l.SortByDurationDesc().Limit(limit), nil
+}
+
+// Report_AthAtl returns the distance of the last price of every currency of the query from the extremes of its period by the daily candles
+func (s *Service) Report_AthAtl(ctx context.Context, query *AthAtlQuery, limit uint) (*AthAtlList, error) {
+	if query == nil {
+		return nil, fmt.Errorf("[%w] the query of the report is required", apperror.ErrBadRequest)
+	}
+	to := time.Now().UTC().Truncate(time.Hour * 24).Add(time.Hour * 24)
+	from := time.Unix(0, 0).UTC()
+	if query.Period > 0 {
+		from = to.Add(-query.Period)
+	}
+
+	currencyList, err := s.replicaSet.ReadRepo().GetList(ctx, query.IsForObserving)
+	if err != nil {
+		return nil, err
+	}
+	currencyIDs := currencyList.IDs()
+
+	candleMap, err := s.priceAndCap.MGetCandles(ctx, currencyIDs, price_and_cap.Interval_1D, from, to)
 	if err != nil {
 		return nil, err
 	}
-	return l.SortByFallValueDesc().Limit(limit), nil
+
+	// the share of the whales is optional
+	concentrationMap, err := s.concentration.MGet(ctx, currencyIDs)
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+
+	res := CalcAthAtlList(currencyList, candleMap, concentrationMap)
+	if len(*res) == 0 {
+		return nil, fmt.Errorf("[%w] there are no prices of the period", apperror.ErrNotFound)
+	}
+	return res.Sort(query.SortBy, query.Desc).Limit(limit), nil
 }
 
-func (s *Service) Report_LongestFall(ctx context.Context, limit uint) (*WhaleFallList, error) {
-	l, err := s.getWhaleFallList(ctx)
+// Report_Risk returns the risk of the observed currencies on the daily candles of the period with the whale metrics
+func (s *Service) Report_Risk(ctx context.Context, query *RiskQuery, limit uint) (*RiskList, error) {
+	if query == nil {
+		return nil, fmt.Errorf("[%w] the query of the report is required", apperror.ErrBadRequest)
+	}
+	to := time.Now().UTC().Truncate(time.Hour * 24).Add(time.Hour * 24)
+
+	currencyList, err := s.replicaSet.ReadRepo().GetAll(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return l.SortByFallDurationDesc().Limit(limit), nil
+	currencyIDs := currencyList.IDs()
+
+	candleMap, err := s.priceAndCap.MGetCandles(ctx, currencyIDs, price_and_cap.Interval_1D, to.Add(-query.Period), to)
+	if err != nil {
+		return nil, err
+	}
+
+	// the whale metrics are optional
+	concentrationMap, err := s.concentration.MGet(ctx, currencyIDs)
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+
+	res := CalcRiskList(currencyList, candleMap, concentrationMap, query.RiskFreeRate)
+	if len(*res) == 0 {
+		return nil, fmt.Errorf("[%w] there are no candles of the period", apperror.ErrNotFound)
+	}
+	return res.Sort(query.SortBy, query.Desc).Limit(limit), nil
 }
 
-func (s *Service) getWhaleFallList(ctx context.Context) (*WhaleFallList, error) {
+// getWhaleTrendList returns the latest trend of the direction of every currency which has one
+func (s *Service) getWhaleTrendList(ctx context.Context, query *TrendQuery, direction string) (*WhaleTrendList, error) {
+	if query == nil {
+		return nil, fmt.Errorf("[%w] the query of the trend is required", apperror.ErrBadRequest)
+	}
+	q := *query
+	q.Params.Direction = direction
+	if err := trend.DirectionValidate(q.Params.Direction); err != nil {
+		return nil, fmt.Errorf("[%w] direction: %w", apperror.ErrBadRequest, err)
+	}
+	now := time.Now()
+
 	currencyList, err := s.replicaSet.ReadRepo().GetAll(ctx)
 	if err != nil {
 		return nil, err
@@ -279,40 +711,58 @@ func (s *Service) getWhaleFallList(ctx context.Context) (*WhaleFallList, error)
 		return nil, err
 	}
 
-	concentrationMap, err := s.concentration.MGet(ctx, currencyIDs)
+	seriesMap, err := s.getTrendSeriesMap(ctx, &q, currencyIDs, now.Add(-q.Params.Period))
 	if err != nil {
 		return nil, err
 	}
 
-	return s.calcWhaleFallList(currencyList, priceAndCapMap, concentrationMap), nil
+	return calcWhaleTrendList(currencyList, priceAndCapMap, seriesMap, &q, now), nil
 }
 
-func (s *Service) calcWhaleFallList(currencyList *CurrencyList, priceAndCapMap price_and_cap.PriceAndCapMap, concentrationMap concentration.ConcentrationMap) *WhaleFallList {
-	if currencyList == nil || priceAndCapMap == nil || concentrationMap == nil {
+// getTrendSeriesMap returns the series of the cohort of the source of every currency
+func (s *Service) getTrendSeriesMap(ctx context.Context, query *TrendQuery, currencyIDs *[]uint, from time.Time) (map[uint]*trend.PointList, error) {
+	res := make(map[uint]*trend.PointList, len(*currencyIDs))
+	switch query.Source {
+	case TrendSource_Oracul:
+		statsMap, err := s.oraculDailyBalanceStats.MGet(ctx, currencyIDs, from)
+		if err != nil {
+			return nil, err
+		}
+		for ID, list := range statsMap {
+			res[ID] = OraculDailyBalanceStatsPoints(&list, query.Cohort)
+		}
+	default:
+		concentrationMap, err := s.concentration.MGet(ctx, currencyIDs)
+		if err != nil {
+			return nil, err
+		}
+		for ID, list := range concentrationMap {
+			res[ID] = ConcentrationPoints(&list, query.Cohort)
+		}
+	}
+	return res, nil
+}
+
+func calcWhaleTrendList(currencyList *CurrencyList, priceAndCapMap price_and_cap.PriceAndCapMap, seriesMap map[uint]*trend.PointList, query *TrendQuery, now time.Time) *WhaleTrendList {
+	if currencyList == nil || priceAndCapMap == nil || seriesMap == nil {
 		return nil
 	}
 	var ok bool
-	var i int
 	var currency Currency
 	var priceAndCapList price_and_cap.PriceAndCapList
-	var concentrationList concentration.ConcentrationList
-	var item *WhaleFall
-	res := make(WhaleFallList, 0, len(*currencyList))
+	var points *trend.PointList
+	var item *WhaleTrend
+	res := make(WhaleTrendList, 0, len(*currencyList))
 
-	for i, currency = range *currencyList {
-		if i == 91 {
-			fmt.Println(".")
-		}
+	for _, currency = range *currencyList {
 		if priceAndCapList, ok = priceAndCapMap[currency.ID]; !ok {
 			continue
 		}
-		if concentrationList, ok = concentrationMap[currency.ID]; !ok {
+		if points, ok = seriesMap[currency.ID]; !ok {
 			continue
 		}
-		item = s.calcWhaleFall(&currency, &priceAndCapList, &concentrationList)
-		if item == nil {
-			currencyJson, _ := json.Marshal(currency)
-			fmt.Printf("calcWhaleFall: empty result for currency: %s", string(currencyJson))
+		// нет тренда - нет записи в отчёте
+		if item = calcWhaleTrend(&currency, &priceAndCapList, points, query, now); item == nil {
 			continue
 		}
 		res = append(res, *item)
@@ -321,78 +771,46 @@ func (s *Service) calcWhaleFallList(currencyList *CurrencyList, priceAndCapMap p
 	return &res
 }
 
-func (s *Service) calcWhaleFall(currency *Currency, priceAndCapList *price_and_cap.PriceAndCapList, concentrationList *concentration.ConcentrationList) *WhaleFall {
-	if currency == nil || priceAndCapList == nil || concentrationList == nil {
+// calcWhaleTrend returns the latest trend of the series of the currency with the price and the cap of its first and last days or nil
+func calcWhaleTrend(currency *Currency, priceAndCapList *price_and_cap.PriceAndCapList, points *trend.PointList, query *TrendQuery, now time.Time) *WhaleTrend {
+	if currency == nil || priceAndCapList == nil || points == nil || query == nil {
 		return nil
 	}
-	const (
-		maxPeriod = time.Hour * 24 * 61 // Максимальный период времени, который смотрим
-		maxBreak  = time.Hour * 24 * 5  // Максимальный перерыв в тренде
-	)
-	now := time.Now()
-	// minTime: ограничение, дальше которого не смотрим
-	minTime := now.Add(-maxPeriod)
-	var inFall bool
-	var i int
-	var prev, next, valueFrom, valueTo, localStart *concentration.Concentration
-	// т.к. concentrationList отсортирован в порядке убывания по времени, в цикле next идёт перед prev
-	for i = range *concentrationList {
-		prev = &(*concentrationList)[i]
-		// первую итерацию просто пропустим
-		if i == 0 {
-			next = prev
-			continue
-		}
-		// дальше minTime не смотрим, останавливаем цикл
-		if prev.D.Before(minTime) {
-			break
-		}
-
-		if !inFall {
-			valueTo = next
-		}
-
-		// если это не спад, то пропустим
-		if valueTo.Whales >= prev.Whales {
-			// если уже нашли падение, то проверяем на maxBreak
-			if inFall && localStart != nil && localStart.D.Sub(prev.D) >= maxBreak {
-				break
-			}
-			next = prev
-			continue
-		}
-		inFall = true
-
-		localStart = prev
-		next = prev
-	}
-	if localStart != nil {
-		valueFrom = localStart
-	}
-	if !inFall || valueFrom == nil || valueTo == nil {
+	t := trend.Detect(points, &query.Params, now)
+	if t == nil {
 		return nil
 	}
 
-	priceAndCapFrom := priceAndCapList.AvgInDay(valueFrom.D)
-	priceAndCapTo := priceAndCapList.AvgInDay(valueTo.D)
-
-	return &WhaleFall{
-		Symbol:           currency.Symbol,
-		FallDuration:     valueTo.D.Sub(valueFrom.D),
-		DayFrom:          valueFrom.D,
-		DayTo:            valueTo.D,
-		FallValue:        valueFrom.Whales - valueTo.Whales,
-		ValueFrom:        valueFrom.Whales,
-		ValueTo:          valueTo.Whales,
-		FallValuePercent: round(((valueTo.Whales * 100) / valueFrom.Whales)),
-		FallCap:          priceAndCapFrom.Cap - priceAndCapTo.Cap,
-		CapFrom:          priceAndCapFrom.Cap,
-		CapTo:            priceAndCapTo.Cap,
-		FallCapPercent:   round(((priceAndCapTo.Cap * 100) / priceAndCapFrom.Cap)),
-		FallPrice:        priceAndCapFrom.Price - priceAndCapTo.Price,
-		PriceFrom:        priceAndCapFrom.Price,
-		PriceTo:          priceAndCapTo.Price,
-		FallPricePercent: round(((priceAndCapTo.Price * 100) / priceAndCapFrom.Price)),
+	// без цены за день контекст цены и капитализации нулевой, но сам тренд в отчёт попадает
+	priceAndCapFrom := priceAndCapList.AvgInDay(t.From.D)
+	if priceAndCapFrom == nil {
+		priceAndCapFrom = &price_and_cap.PriceAndCap{}
+	}
+	priceAndCapTo := priceAndCapList.AvgInDay(t.To.D)
+	if priceAndCapTo == nil {
+		priceAndCapTo = &price_and_cap.PriceAndCap{}
+	}
+
+	return &WhaleTrend{
+		Symbol:             currency.Symbol,
+		Source:             query.Source,
+		Cohort:             query.Cohort,
+		Direction:          t.Direction,
+		Duration:           t.Duration,
+		DayFrom:            t.From.D,
+		DayTo:              t.To.D,
+		Change:             t.Change,
+		ValueFrom:          t.From.Value,
+		ValueTo:            t.To.Value,
+		ChangePercent:      t.ChangePercent,
+		CapChange:          priceAndCapTo.Cap - priceAndCapFrom.Cap,
+		CapFrom:            priceAndCapFrom.Cap,
+		CapTo:              priceAndCapTo.Cap,
+		CapChangePercent:   percentOf(priceAndCapTo.Cap, priceAndCapFrom.Cap),
+		PriceChange:        priceAndCapTo.Price - priceAndCapFrom.Price,
+		PriceFrom:          priceAndCapFrom.Price,
+		PriceTo:            priceAndCapTo.Price,
+		PriceChangePercent: percentOf(priceAndCapTo.Price, priceAndCapFrom.Price),
 	}
 }
 
@@ -400,6 +818,35 @@ func round(v float64) float64 {
 	return math.Round(v*100) / 100
 }
 
+// percentOf returns v as the rounded percent of base; 0 without base
+func percentOf(v float64, base float64) float64 {
+	if base == 0 {
+		return 0
+	}
+	return round((v * 100) / base)
+}
+
+func CurrencyList2CurrencySnapshotList(l *CurrencyList, ts time.Time) *currency_snapshot.CurrencySnapshotList {
+	if l == nil {
+		return nil
+	}
+	res := make(currency_snapshot.CurrencySnapshotList, 0, len(*l))
+	var item Currency
+	for _, item = range *l {
+		res = append(res, currency_snapshot.CurrencySnapshot{
+			CurrencyID:                    item.ID,
+			CmcRank:                       item.CmcRank,
+			CirculatingSupply:             item.CirculatingSupply,
+			SelfReportedCirculatingSupply: item.SelfReportedCirculatingSupply,
+			TotalSupply:                   item.TotalSupply,
+			MaxSupply:                     item.MaxSupply,
+			LatestPrice:                   item.LatestPrice,
+			Ts:                            ts,
+		})
+	}
+	return &res
+}
+
 func TokenAddress2OraculAnalyticsTokenAddress(e *TokenAddress) *oracul_analytics.TokenAddress {
 	return &oracul_analytics.TokenAddress{
 		CurrencyID: e.CurrencyID,
@@ -0,0 +1,320 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"info/internal/domain"
+	"info/internal/domain/concentration"
+	"info/internal/domain/price_and_cap"
+	"info/internal/pkg/apperror"
+	"info/internal/pkg/fencing"
+	"info/internal/pkg/workerpool"
+)
+
+// upsertedDays counts the upserted rows: a row of a day which is upserted already is updated, otherwise it is inserted
+type upsertedDays struct {
+	mu   sync.Mutex
+	days map[uint]map[time.Time]struct{}
+}
+
+func (u *upsertedDays) upsert(currencyID uint, d time.Time) *domain.UpsertStats {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.days == nil {
+		u.days = make(map[uint]map[time.Time]struct{})
+	}
+	if u.days[currencyID] == nil {
+		u.days[currencyID] = make(map[time.Time]struct{})
+	}
+	if _, ok := u.days[currencyID][d]; ok {
+		return &domain.UpsertStats{Updated: 1}
+	}
+	u.days[currencyID][d] = struct{}{}
+	return &domain.UpsertStats{Inserted: 1}
+}
+
+type fakePriceAndCapRepo struct {
+	price_and_cap.WriteRepository
+	upsertedDays
+}
+
+func (r *fakePriceAndCapRepo) WriteRepo() price_and_cap.WriteRepository { return r }
+func (r *fakePriceAndCapRepo) ReadRepo() price_and_cap.ReadRepository   { return nil }
+
+func (r *fakePriceAndCapRepo) MUpsertTx(ctx context.Context, tx domain.Tx, entities *[]price_and_cap.PriceAndCap) error {
+	return nil
+}
+
+func (r *fakePriceAndCapRepo) MUpsertWithStats(ctx context.Context, entities *[]price_and_cap.PriceAndCap) (*domain.UpsertStats, error) {
+	res := &domain.UpsertStats{}
+	for _, item := range *entities {
+		res.Add(r.upsert(item.CurrencyID, item.Ts))
+	}
+	return res, nil
+}
+
+type fakeConcentrationRepo struct {
+	concentration.WriteRepository
+	upsertedDays
+}
+
+func (r *fakeConcentrationRepo) WriteRepo() concentration.WriteRepository { return r }
+func (r *fakeConcentrationRepo) ReadRepo() concentration.ReadRepository   { return nil }
+
+func (r *fakeConcentrationRepo) MUpsertWithStats(ctx context.Context, entities *[]concentration.Concentration) (*domain.UpsertStats, error) {
+	res := &domain.UpsertStats{}
+	for _, item := range *entities {
+		res.Add(r.upsert(item.CurrencyID, item.D))
+	}
+	return res, nil
+}
+
+// fakeBackfillApi returns an item of every day of [days[0], days[1]) or the error of the currency
+type fakeBackfillApi struct {
+	days [2]time.Time
+	err  map[uint]error
+}
+
+func (a *fakeBackfillApi) GetDetailChart(ctx context.Context, currencyID uint, tRange string) (*price_and_cap.PriceAndCapList, error) {
+	if err := a.err[currencyID]; err != nil {
+		return nil, err
+	}
+	res := make(price_and_cap.PriceAndCapList, 0)
+	for d := a.days[0]; d.Before(a.days[1]); d = d.AddDate(0, 0, 1) {
+		res = append(res, price_and_cap.PriceAndCap{CurrencyID: currencyID, Price: 1, Ts: d})
+	}
+	return &res, nil
+}
+
+func (a *fakeBackfillApi) GetAnalytics(ctx context.Context, currencyID uint, tRange string) (*concentration.ConcentrationList, error) {
+	if err := a.err[currencyID]; err != nil {
+		return nil, err
+	}
+	res := make(concentration.ConcentrationList, 0)
+	for d := a.days[0]; d.Before(a.days[1]); d = d.AddDate(0, 0, 1) {
+		res = append(res, concentration.Concentration{CurrencyID: currencyID, Whales: 1, D: d})
+	}
+	return &res, nil
+}
+
+func TestService_Backfill(t *testing.T) {
+	from := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 5)
+	errApi := errors.New("api error")
+	api := &fakeBackfillApi{
+		days: [2]time.Time{from.AddDate(0, 0, -3), to.AddDate(0, 0, 3)},
+		err:  map[uint]error{3: errApi},
+	}
+
+	priceAndCapRepo := &fakePriceAndCapRepo{}
+	// 2 days of the window of the currency 1 are loaded already
+	priceAndCapRepo.upsert(1, from)
+	priceAndCapRepo.upsert(1, from.AddDate(0, 0, 1))
+	concentrationRepo := &fakeConcentrationRepo{}
+
+	s := NewService(
+		nil,
+		price_and_cap.NewService(priceAndCapRepo, price_and_cap.Config{}, map[string]price_and_cap.Source{price_and_cap.Source_Cmc: api}),
+		concentration.NewService(concentrationRepo, api),
+		nil, nil, nil, nil, nil,
+		workerpool.New(workerpool.Config{WorkersNb: 2}, nil),
+	)
+
+	list := &CurrencyList{{ID: 1, Symbol: "BTC"}, {ID: 2, Symbol: "ETH"}, {ID: 3, Symbol: "ERR"}}
+	datasets := &[]string{Dataset_PriceAndCap, Dataset_Concentration}
+	res, err := s.Backfill(context.Background(), list, from, to, datasets)
+	if !errors.Is(err, errApi) {
+		t.Fatalf("Backfill() error = %v, want %v", err, errApi)
+	}
+
+	type key struct {
+		currencyID uint
+		dataset    string
+	}
+	want := map[key]domain.UpsertStats{
+		{1, Dataset_PriceAndCap}:   {Inserted: 3, Updated: 2},
+		{1, Dataset_Concentration}: {Inserted: 5},
+		{2, Dataset_PriceAndCap}:   {Inserted: 5},
+		{2, Dataset_Concentration}: {Inserted: 5},
+		{3, Dataset_PriceAndCap}:   {},
+		{3, Dataset_Concentration}: {},
+	}
+	if len(*res) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(*res), len(want), *res)
+	}
+	for _, item := range *res {
+		k := key{item.CurrencyID, item.Dataset}
+		stats, ok := want[k]
+		if !ok {
+			t.Fatalf("unexpected result %+v", item)
+		}
+		if item.UpsertStats != stats {
+			t.Errorf("%+v: got stats %+v, want %+v", k, item.UpsertStats, stats)
+		}
+		if (item.Err != nil) != (item.CurrencyID == 3) {
+			t.Errorf("%+v: got error %v", k, item.Err)
+		}
+	}
+
+	// the second run of the same window updates all the rows
+	res, err = s.Backfill(context.Background(), &CurrencyList{{ID: 1, Symbol: "BTC"}}, from, to, datasets)
+	if err != nil {
+		t.Fatalf("Backfill() error: %v", err)
+	}
+	for _, item := range *res {
+		if item.UpsertStats != (domain.UpsertStats{Updated: 5}) {
+			t.Errorf("the second run, %s: got stats %+v, want 5 updated", item.Dataset, item.UpsertStats)
+		}
+	}
+}
+
+// fakeTokenAddressRepo returns the token addresses of the currencies
+type fakeTokenAddressRepo struct {
+	ReadRepository
+	list TokenAddressList
+}
+
+func (r *fakeTokenAddressRepo) WriteRepo() WriteRepository { return nil }
+func (r *fakeTokenAddressRepo) ReadRepo() ReadRepository   { return r }
+
+func (r *fakeTokenAddressRepo) MGetTokenAddress(ctx context.Context, IDs *[]uint) (*TokenAddressList, error) {
+	if len(r.list) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+	return &r.list, nil
+}
+
+func TestService_BackfillOraculWithoutTokenAddress(t *testing.T) {
+	from := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	repo := &fakeTokenAddressRepo{list: TokenAddressList{{CurrencyID: 1, Blockchain: "SOL", Address: "1-sol"}}}
+	s := NewService(repo, nil, nil, nil, nil, nil, nil, nil, workerpool.New(workerpool.Config{WorkersNb: 1}, nil))
+
+	res, err := s.Backfill(context.Background(), &CurrencyList{{ID: 1, Symbol: "BTC"}, {ID: 2, Symbol: "ETH"}}, from, from.AddDate(0, 0, 1), &[]string{Dataset_Oracul})
+	if !errors.Is(err, apperror.ErrNotFound) {
+		t.Fatalf("Backfill() error = %v, want %v", err, apperror.ErrNotFound)
+	}
+	if len(*res) != 2 {
+		t.Fatalf("got %d results, want 2", len(*res))
+	}
+	for _, item := range *res {
+		if !errors.Is(item.Err, apperror.ErrNotFound) {
+			t.Errorf("currency %d: got error %v, want %v", item.CurrencyID, item.Err, apperror.ErrNotFound)
+		}
+	}
+}
+
+type fakeTx struct {
+	domain.Tx
+	isCommitted bool
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	tx.isCommitted = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error { return nil }
+
+// fakeImportMaxTimeRepo keeps the checkpoints and rejects a checkpoint with a stale fencing token like the upsert of the checkpoints
+type fakeImportMaxTimeRepo struct {
+	WriteRepository
+	tx            *fakeTx
+	importMaxTime map[uint]ImportMaxTime
+	importErrors  []string
+}
+
+func (r *fakeImportMaxTimeRepo) WriteRepo() WriteRepository { return r }
+func (r *fakeImportMaxTimeRepo) ReadRepo() ReadRepository   { return nil }
+
+func (r *fakeImportMaxTimeRepo) Begin(ctx context.Context) (domain.Tx, error) {
+	r.tx = &fakeTx{}
+	return r.tx, nil
+}
+
+func (r *fakeImportMaxTimeRepo) GetImportMaxTimeForUpdateTx(ctx context.Context, tx domain.Tx, currencyIDs *[]uint) (map[uint]ImportMaxTime, error) {
+	return r.importMaxTime, nil
+}
+
+func (r *fakeImportMaxTimeRepo) MUpsertImportMaxTimeTx(ctx context.Context, tx domain.Tx, entities *[]ImportMaxTime) error {
+	for _, item := range *entities {
+		stored := r.importMaxTime[item.CurrencyID]
+		if stored.PriceAndCapFencingToken > item.PriceAndCapFencingToken || stored.ConcentrationFencingToken > item.ConcentrationFencingToken {
+			return fmt.Errorf("[%w] stale fencing token", apperror.ErrLocked)
+		}
+		r.importMaxTime[item.CurrencyID] = item
+	}
+	return nil
+}
+
+func (r *fakeImportMaxTimeRepo) UpsertImportError(ctx context.Context, currencyID uint, datasets []string, errText string) error {
+	r.importErrors = append(r.importErrors, errText)
+	return nil
+}
+
+func (r *fakeImportMaxTimeRepo) DeleteImportErrorTx(ctx context.Context, tx domain.Tx, currencyID uint, datasets []string) error {
+	return nil
+}
+
+func TestService_ImportItemTxFencing(t *testing.T) {
+	now := time.Now().UTC().Truncate(24 * time.Hour)
+	api := &fakeBackfillApi{days: [2]time.Time{now.AddDate(0, 0, -3), now}}
+	repo := &fakeImportMaxTimeRepo{importMaxTime: map[uint]ImportMaxTime{1: {CurrencyID: 1, PriceAndCap: &now, PriceAndCapFencingToken: 5, ConcentrationFencingToken: 2}}}
+	s := NewService(
+		repo,
+		price_and_cap.NewService(&fakePriceAndCapRepo{}, price_and_cap.Config{}, map[string]price_and_cap.Source{price_and_cap.Source_Cmc: api}),
+		nil, nil, nil, nil, nil, nil,
+		workerpool.New(workerpool.Config{WorkersNb: 1}, nil),
+	)
+
+	// the import under a lost lease
+	err := s.importItemTx(fencing.WithToken(context.Background(), Dataset_PriceAndCap, 4), 1, Dataset_PriceAndCap)
+	if !errors.Is(err, apperror.ErrLocked) {
+		t.Fatalf("importItemTx() with a stale token error = %v, want %v", err, apperror.ErrLocked)
+	}
+	if repo.tx.isCommitted {
+		t.Error("the import with a stale token is committed")
+	}
+	if len(repo.importErrors) != 0 {
+		t.Errorf("the stale token is recorded as an import error: %v", repo.importErrors)
+	}
+
+	if err = s.importItemTx(fencing.WithToken(context.Background(), Dataset_PriceAndCap, 6), 1, Dataset_PriceAndCap); err != nil {
+		t.Fatalf("importItemTx() error: %v", err)
+	}
+	if !repo.tx.isCommitted {
+		t.Error("the import is not committed")
+	}
+	item := repo.importMaxTime[1]
+	if item.PriceAndCapFencingToken != 6 || item.ConcentrationFencingToken != 2 {
+		t.Errorf("fencing tokens = %d, %d, want 6, 2", item.PriceAndCapFencingToken, item.ConcentrationFencingToken)
+	}
+
+	// the import without a lock keeps the token of the last import
+	if err = s.importItemTx(context.Background(), 1, Dataset_PriceAndCap); err != nil {
+		t.Fatalf("importItemTx() without a token error: %v", err)
+	}
+	if item = repo.importMaxTime[1]; item.PriceAndCapFencingToken != 6 {
+		t.Errorf("fencing token = %d, want 6", item.PriceAndCapFencingToken)
+	}
+}
+
+func TestService_BackfillValidate(t *testing.T) {
+	s := NewService(nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	from := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	list := &CurrencyList{{ID: 1}}
+
+	if _, err := s.Backfill(context.Background(), list, from, from, &[]string{Dataset_PriceAndCap}); !errors.Is(err, apperror.ErrBadRequest) {
+		t.Errorf("empty window: got error %v, want %v", err, apperror.ErrBadRequest)
+	}
+	if _, err := s.Backfill(context.Background(), list, from, from.AddDate(0, 0, 1), &[]string{"unknown"}); !errors.Is(err, apperror.ErrBadRequest) {
+		t.Errorf("unknown dataset: got error %v, want %v", err, apperror.ErrBadRequest)
+	}
+	if res, err := s.Backfill(context.Background(), list, from, from.AddDate(0, 0, 1), &[]string{}); err != nil || len(*res) != 0 {
+		t.Errorf("no datasets: got %v, %v; want an empty result", res, err)
+	}
+}
@@ -0,0 +1,55 @@
+package currency_snapshot
+
+import "time"
+
+// CurrencySnapshot is the state of the metadata of a currency at the time of an import
+type CurrencySnapshot struct {
+	CurrencyID                    uint
+	CmcRank                       uint
+	CirculatingSupply             float64
+	SelfReportedCirculatingSupply float64
+	TotalSupply                   float64
+	MaxSupply                     *float64
+	LatestPrice                   float64
+	Ts                            time.Time
+}
+
+type CurrencySnapshotList []CurrencySnapshot
+
+// RankChange is a change of CmcRank between two consecutive snapshots; a positive Change means the rank is improved
+type RankChange struct {
+	CurrencyID uint
+	PrevRank   uint
+	CmcRank    uint
+	Change     int
+	Ts         time.Time
+}
+
+type RankChangeList []RankChange
+
+// SupplyInflation is the growth of the circulating supply during a period: Rate = (To - From) / From
+type SupplyInflation struct {
+	CurrencyID            uint
+	From                  time.Time
+	To                    time.Time
+	CirculatingSupplyFrom float64
+	CirculatingSupplyTo   float64
+	Rate                  float64
+}
+
+type SupplyInflationList []SupplyInflation
+
+// Dilution is the part of MaxSupply which is in circulation and the part which can still be issued
+type Dilution struct {
+	CurrencyID        uint
+	CirculatingSupply float64
+	TotalSupply       float64
+	MaxSupply         float64
+	CirculatingRatio  float64 // CirculatingSupply / MaxSupply
+	TotalRatio        float64 // TotalSupply / MaxSupply
+	// RemainingDilution is the growth of the circulating supply left until MaxSupply: (MaxSupply - CirculatingSupply) / CirculatingSupply
+	RemainingDilution float64
+	Ts                time.Time
+}
+
+type DilutionList []Dilution
@@ -0,0 +1,19 @@
+package currency_snapshot
+
+import (
+	"context"
+	"time"
+)
+
+type ReplicaSet interface {
+	WriteRepo() WriteRepository
+	ReadRepo() ReadRepository
+}
+
+type WriteRepository interface {
+	MUpsert(ctx context.Context, entities *CurrencySnapshotList) error
+}
+
+type ReadRepository interface {
+	GetList(ctx context.Context, currencyID uint, from time.Time, to time.Time) (*CurrencySnapshotList, error)
+}
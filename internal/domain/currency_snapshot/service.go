@@ -0,0 +1,178 @@
+package currency_snapshot
+
+import (
+	"context"
+	"fmt"
+	"info/internal/pkg/apperror"
+	"time"
+)
+
+const (
+	Period_Day   = "day"
+	Period_Week  = "week"
+	Period_Month = "month"
+)
+
+// periodStarts return the start of the calendar period of the supply inflation in UTC: a week is an ISO week from Monday, a month starts on the first day
+var periodStarts = map[string]func(t time.Time) time.Time{
+	Period_Day:   dayStart,
+	Period_Week:  weekStart,
+	Period_Month: monthStart,
+}
+
+func dayStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func weekStart(t time.Time) time.Time {
+	d := dayStart(t)
+	// Sunday is the last day of the ISO week
+	return d.AddDate(0, 0, -(int(d.Weekday())+6)%7)
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+type Service struct {
+	replicaSet ReplicaSet
+}
+
+func NewService(replicaSet ReplicaSet) *Service {
+	return &Service{
+		replicaSet: replicaSet,
+	}
+}
+
+// MUpsert stores the snapshots; a snapshot of the same currency and time is replaced
+func (s *Service) MUpsert(ctx context.Context, list *CurrencySnapshotList) error {
+	if list == nil || len(*list) == 0 {
+		return nil
+	}
+	return s.replicaSet.WriteRepo().MUpsert(ctx, list)
+}
+
+// GetList returns the snapshots of the currency in [from, to) sorted by the time
+func (s *Service) GetList(ctx context.Context, currencyID uint, from time.Time, to time.Time) (*CurrencySnapshotList, error) {
+	return s.replicaSet.ReadRepo().GetList(ctx, currencyID, from, to)
+}
+
+// RankChanges returns the changes of CmcRank of the currency in [from, to); the list is empty if the rank was not changed,
+// apperror.ErrNotFound means the currency has no snapshots in the window
+func (s *Service) RankChanges(ctx context.Context, currencyID uint, from time.Time, to time.Time) (*RankChangeList, error) {
+	list, err := s.GetList(ctx, currencyID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return CalcRankChanges(list), nil
+}
+
+// SupplyInflation returns the growth of the circulating supply of the currency for every period (day, week, month) in [from, to)
+func (s *Service) SupplyInflation(ctx context.Context, currencyID uint, from time.Time, to time.Time, period string) (*SupplyInflationList, error) {
+	periodStart, ok := periodStarts[period]
+	if !ok {
+		return nil, fmt.Errorf("[%w] unknown period %q", apperror.ErrBadRequest, period)
+	}
+	list, err := s.GetList(ctx, currencyID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	res := CalcSupplyInflation(list, periodStart)
+	if len(*res) == 0 {
+		return nil, fmt.Errorf("[%w] there are no two periods with the circulating supply of the currency %d", apperror.ErrNotFound, currencyID)
+	}
+	return res, nil
+}
+
+// Dilution returns the dilution of the currency toward MaxSupply in [from, to)
+func (s *Service) Dilution(ctx context.Context, currencyID uint, from time.Time, to time.Time) (*DilutionList, error) {
+	list, err := s.GetList(ctx, currencyID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	res := CalcDilution(list)
+	if len(*res) == 0 {
+		return nil, fmt.Errorf("[%w] the currency %d has no max supply", apperror.ErrNotFound, currencyID)
+	}
+	return res, nil
+}
+
+// CalcRankChanges returns the changes of the rank between the consecutive snapshots sorted by the time; the snapshots without the rank are skipped
+func CalcRankChanges(list *CurrencySnapshotList) *RankChangeList {
+	res := make(RankChangeList, 0)
+	var prev *CurrencySnapshot
+	for i := range *list {
+		item := &(*list)[i]
+		if item.CmcRank == 0 {
+			continue
+		}
+		if prev != nil && prev.CmcRank != item.CmcRank {
+			res = append(res, RankChange{
+				CurrencyID: item.CurrencyID,
+				PrevRank:   prev.CmcRank,
+				CmcRank:    item.CmcRank,
+				Change:     int(prev.CmcRank) - int(item.CmcRank),
+				Ts:         item.Ts,
+			})
+		}
+		prev = item
+	}
+	return &res
+}
+
+// CalcSupplyInflation compares the last snapshot of every period with the last snapshot of the previous one; periodStart returns the start of the period of a time.
+// The snapshots are sorted by the time, the ones without the circulating supply are skipped
+func CalcSupplyInflation(list *CurrencySnapshotList, periodStart func(t time.Time) time.Time) *SupplyInflationList {
+	// the last snapshot of every period in the order of the periods
+	last := make(CurrencySnapshotList, 0)
+	var item CurrencySnapshot
+	for _, item = range *list {
+		if item.CirculatingSupply <= 0 {
+			continue
+		}
+		if len(last) > 0 && periodStart(last[len(last)-1].Ts).Equal(periodStart(item.Ts)) {
+			last[len(last)-1] = item
+			continue
+		}
+		last = append(last, item)
+	}
+
+	res := make(SupplyInflationList, 0, len(last))
+	for i := 1; i < len(last); i++ {
+		prev, cur := last[i-1], last[i]
+		res = append(res, SupplyInflation{
+			CurrencyID:            cur.CurrencyID,
+			From:                  prev.Ts,
+			To:                    cur.Ts,
+			CirculatingSupplyFrom: prev.CirculatingSupply,
+			CirculatingSupplyTo:   cur.CirculatingSupply,
+			Rate:                  (cur.CirculatingSupply - prev.CirculatingSupply) / prev.CirculatingSupply,
+		})
+	}
+	return &res
+}
+
+// CalcDilution returns the dilution of every snapshot with MaxSupply and the circulating supply
+func CalcDilution(list *CurrencySnapshotList) *DilutionList {
+	res := make(DilutionList, 0, len(*list))
+	var item CurrencySnapshot
+	for _, item = range *list {
+		if item.MaxSupply == nil || *item.MaxSupply <= 0 || item.CirculatingSupply <= 0 {
+			continue
+		}
+		maxSupply := *item.MaxSupply
+		res = append(res, Dilution{
+			CurrencyID:        item.CurrencyID,
+			CirculatingSupply: item.CirculatingSupply,
+			TotalSupply:       item.TotalSupply,
+			MaxSupply:         maxSupply,
+			CirculatingRatio:  item.CirculatingSupply / maxSupply,
+			TotalRatio:        item.TotalSupply / maxSupply,
+			RemainingDilution: (maxSupply - item.CirculatingSupply) / item.CirculatingSupply,
+			Ts:                item.Ts,
+		})
+	}
+	return &res
+}
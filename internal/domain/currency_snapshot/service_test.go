@@ -0,0 +1,185 @@
+package currency_snapshot
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"info/internal/pkg/apperror"
+)
+
+var day1 = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func snapshot(ts time.Time, rank uint, circulating float64, maxSupply *float64) CurrencySnapshot {
+	return CurrencySnapshot{CurrencyID: 1, CmcRank: rank, CirculatingSupply: circulating, TotalSupply: circulating, MaxSupply: maxSupply, Ts: ts}
+}
+
+func TestCalcRankChanges(t *testing.T) {
+	list := CurrencySnapshotList{
+		snapshot(day1, 10, 100, nil),
+		snapshot(day1.Add(time.Hour), 10, 100, nil),
+		snapshot(day1.Add(2*time.Hour), 0, 100, nil),
+		snapshot(day1.Add(3*time.Hour), 8, 100, nil),
+		snapshot(day1.Add(4*time.Hour), 12, 100, nil),
+	}
+
+	res := CalcRankChanges(&list)
+	want := RankChangeList{
+		{CurrencyID: 1, PrevRank: 10, CmcRank: 8, Change: 2, Ts: day1.Add(3 * time.Hour)},
+		{CurrencyID: 1, PrevRank: 8, CmcRank: 12, Change: -4, Ts: day1.Add(4 * time.Hour)},
+	}
+	if len(*res) != len(want) {
+		t.Fatalf("got %+v, want %+v", *res, want)
+	}
+	for i := range want {
+		if (*res)[i] != want[i] {
+			t.Errorf("got %+v, want %+v", (*res)[i], want[i])
+		}
+	}
+}
+
+type fakeReplicaSet struct {
+	list CurrencySnapshotList
+}
+
+func (r *fakeReplicaSet) WriteRepo() WriteRepository { return r }
+func (r *fakeReplicaSet) ReadRepo() ReadRepository   { return r }
+
+func (r *fakeReplicaSet) MUpsert(ctx context.Context, entities *CurrencySnapshotList) error {
+	return nil
+}
+
+func (r *fakeReplicaSet) GetList(ctx context.Context, currencyID uint, from time.Time, to time.Time) (*CurrencySnapshotList, error) {
+	if len(r.list) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+	return &r.list, nil
+}
+
+func TestService_RankChanges(t *testing.T) {
+	to := day1.AddDate(0, 0, 1)
+
+	s := NewService(&fakeReplicaSet{list: CurrencySnapshotList{snapshot(day1, 10, 100, nil), snapshot(day1.Add(time.Hour), 10, 100, nil)}})
+	res, err := s.RankChanges(context.Background(), 1, day1, to)
+	if err != nil || res == nil || len(*res) != 0 {
+		t.Fatalf("the rank is not changed: got %v, %v; want an empty list", res, err)
+	}
+
+	s = NewService(&fakeReplicaSet{})
+	if _, err = s.RankChanges(context.Background(), 1, day1, to); !errors.Is(err, apperror.ErrNotFound) {
+		t.Fatalf("no snapshots: got error %v, want %v", err, apperror.ErrNotFound)
+	}
+}
+
+func TestCalcSupplyInflation(t *testing.T) {
+	tests := []struct {
+		name      string
+		list      CurrencySnapshotList
+		period    string
+		wantRates []float64
+	}{
+		{
+			name: "the last snapshot of the day",
+			list: CurrencySnapshotList{
+				snapshot(day1, 1, 90, nil),
+				snapshot(day1.Add(23*time.Hour), 1, 100, nil),
+				snapshot(day1.Add(30*time.Hour), 1, 110, nil),
+				snapshot(day1.Add(50*time.Hour), 1, 99, nil),
+			},
+			period:    Period_Day,
+			wantRates: []float64{0.1, -0.1},
+		},
+		{
+			name: "without the circulating supply",
+			list: CurrencySnapshotList{
+				snapshot(day1, 1, 100, nil),
+				snapshot(day1.Add(24*time.Hour), 1, 0, nil),
+				snapshot(day1.Add(48*time.Hour), 1, 150, nil),
+			},
+			period:    Period_Day,
+			wantRates: []float64{0.5},
+		},
+		{
+			name:   "one period",
+			list:   CurrencySnapshotList{snapshot(day1, 1, 100, nil), snapshot(day1.Add(time.Hour), 1, 200, nil)},
+			period: Period_Week,
+		},
+		{
+			// 2025-01-01 is Wednesday: the week of 30 Dec - 5 Jan, the next one starts on Monday 6 Jan
+			name: "the ISO weeks",
+			list: CurrencySnapshotList{
+				snapshot(day1.AddDate(0, 0, -2), 1, 50, nil),
+				snapshot(day1.AddDate(0, 0, 4).Add(23*time.Hour), 1, 100, nil),
+				snapshot(day1.AddDate(0, 0, 5), 1, 120, nil),
+				snapshot(day1.AddDate(0, 0, 11).Add(23*time.Hour), 1, 150, nil),
+			},
+			period:    Period_Week,
+			wantRates: []float64{0.5},
+		},
+		{
+			name: "the calendar months",
+			list: CurrencySnapshotList{
+				snapshot(day1, 1, 100, nil),
+				snapshot(day1.AddDate(0, 0, 30), 1, 110, nil),
+				snapshot(day1.AddDate(0, 1, 0), 1, 120, nil),
+				snapshot(day1.AddDate(0, 1, 27), 1, 150, nil),
+				snapshot(day1.AddDate(0, 2, 0), 1, 180, nil),
+			},
+			period:    Period_Month,
+			wantRates: []float64{150.0/110 - 1, 0.2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := CalcSupplyInflation(&tt.list, periodStarts[tt.period])
+			if len(*res) != len(tt.wantRates) {
+				t.Fatalf("got %+v, want the rates %v", *res, tt.wantRates)
+			}
+			for i, rate := range tt.wantRates {
+				if math.Abs((*res)[i].Rate-rate) > 1e-9 {
+					t.Errorf("got rate %v, want %v", (*res)[i].Rate, rate)
+				}
+			}
+		})
+	}
+}
+
+func TestPeriodStarts(t *testing.T) {
+	ts := time.Date(2025, 3, 2, 15, 4, 5, 0, time.UTC) // Sunday
+	tests := []struct {
+		period string
+		ts     time.Time
+		want   time.Time
+	}{
+		{Period_Day, ts, time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{Period_Week, ts, time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC)},
+		{Period_Week, time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)},
+		{Period_Month, ts, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{Period_Day, ts.In(time.FixedZone("UTC+10", 10*3600)), time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := periodStarts[tt.period](tt.ts); !got.Equal(tt.want) {
+			t.Errorf("%s of %v: got %v, want %v", tt.period, tt.ts, got, tt.want)
+		}
+	}
+}
+
+func TestCalcDilution(t *testing.T) {
+	maxSupply := 200.0
+	zero := 0.0
+	list := CurrencySnapshotList{
+		snapshot(day1, 1, 100, &maxSupply),
+		snapshot(day1.Add(time.Hour), 1, 100, nil),
+		snapshot(day1.Add(2*time.Hour), 1, 100, &zero),
+	}
+
+	res := CalcDilution(&list)
+	if len(*res) != 1 {
+		t.Fatalf("got %+v, want only the snapshot with the max supply", *res)
+	}
+	if item := (*res)[0]; item.CirculatingRatio != 0.5 || item.RemainingDilution != 1 || item.MaxSupply != maxSupply {
+		t.Errorf("unexpected dilution %+v", item)
+	}
+}
@@ -0,0 +1,31 @@
+package global_metrics
+
+import "time"
+
+// GlobalMetrics is the state of the whole market at the time in USD; the stablecoin and DeFi caps are not available in the history of CMC
+type GlobalMetrics struct {
+	TotalMarketCap      float64
+	TotalVolume24h      float64
+	BtcDominance        float64
+	EthDominance        float64
+	StablecoinMarketCap *float64
+	DefiMarketCap       *float64
+	Ts                  time.Time
+}
+
+type GlobalMetricsList []GlobalMetrics
+
+// Unique returns the global metrics without the duplicates of the time; the first one is kept
+func (l *GlobalMetricsList) Unique() *GlobalMetricsList {
+	res := make(GlobalMetricsList, 0, len(*l))
+	exists := make(map[time.Time]struct{}, len(*l))
+	var item GlobalMetrics
+	for _, item = range *l {
+		if _, ok := exists[item.Ts]; ok {
+			continue
+		}
+		exists[item.Ts] = struct{}{}
+		res = append(res, item)
+	}
+	return &res
+}
@@ -0,0 +1,20 @@
+package global_metrics
+
+import (
+	"context"
+	"time"
+)
+
+type ReplicaSet interface {
+	WriteRepo() WriteRepository
+	ReadRepo() ReadRepository
+}
+
+type WriteRepository interface {
+	MUpsert(ctx context.Context, entities *GlobalMetricsList) error
+}
+
+type ReadRepository interface {
+	GetLast(ctx context.Context) (*GlobalMetrics, error)
+	GetList(ctx context.Context, from time.Time, to time.Time) (*GlobalMetricsList, error)
+}
@@ -0,0 +1,94 @@
+package global_metrics
+
+import (
+	"context"
+	"fmt"
+	"info/internal/domain/import_run"
+	"info/internal/pkg/apperror"
+	"time"
+)
+
+// maxHistoricalPoints is the max number of the hourly points of a request of the history
+const maxHistoricalPoints = 10000
+
+// GlobalMetricsApi returns the latest global metrics and the hourly history of them
+type GlobalMetricsApi interface {
+	GetGlobalMetricsLatest(ctx context.Context) (*GlobalMetrics, error)
+	GetGlobalMetricsHistorical(ctx context.Context, from time.Time, to time.Time, count uint) (*GlobalMetricsList, error)
+}
+
+type Service struct {
+	replicaSet       ReplicaSet
+	globalMetricsApi GlobalMetricsApi
+}
+
+func NewService(replicaSet ReplicaSet, globalMetricsApi GlobalMetricsApi) *Service {
+	return &Service{
+		replicaSet:       replicaSet,
+		globalMetricsApi: globalMetricsApi,
+	}
+}
+
+// Import stores the latest global metrics; the metrics of the same hour are replaced
+func (s *Service) Import(ctx context.Context) error {
+	if s.globalMetricsApi == nil {
+		return fmt.Errorf("[%w] global_metrics.Service: there is no global metrics API", apperror.ErrInternal)
+	}
+	entity, err := s.globalMetricsApi.GetGlobalMetricsLatest(ctx)
+	if err != nil {
+		return err
+	}
+	entity.Ts = entity.Ts.UTC().Truncate(time.Hour)
+	list := GlobalMetricsList{*entity}
+	if err = s.replicaSet.WriteRepo().MUpsert(ctx, &list); err != nil {
+		return err
+	}
+	import_run.AddRows(ctx, 1)
+	return nil
+}
+
+// Backfill loads the hourly history in [from, to) by the windows of maxHistoricalPoints hours and returns the number of the stored points
+func (s *Service) Backfill(ctx context.Context, from time.Time, to time.Time) (uint, error) {
+	if s.globalMetricsApi == nil {
+		return 0, fmt.Errorf("[%w] global_metrics.Service: there is no global metrics API", apperror.ErrInternal)
+	}
+	if !from.Before(to) {
+		return 0, fmt.Errorf("[%w] from %s must be before to %s", apperror.ErrBadRequest, from.Format(time.DateOnly), to.Format(time.DateOnly))
+	}
+
+	var res uint
+	const window = time.Hour * maxHistoricalPoints
+	for lbound := from; lbound.Before(to); lbound = lbound.Add(window) {
+		hbound := lbound.Add(window)
+		if hbound.After(to) {
+			hbound = to
+		}
+		list, err := s.globalMetricsApi.GetGlobalMetricsHistorical(ctx, lbound, hbound, maxHistoricalPoints)
+		if err != nil {
+			return res, err
+		}
+		for i := range *list {
+			(*list)[i].Ts = (*list)[i].Ts.UTC().Truncate(time.Hour)
+		}
+		list = list.Unique()
+		if len(*list) == 0 {
+			continue
+		}
+		if err = s.replicaSet.WriteRepo().MUpsert(ctx, list); err != nil {
+			return res, err
+		}
+		res += uint(len(*list))
+		import_run.AddRows(ctx, len(*list))
+	}
+	return res, nil
+}
+
+// GetLast returns the latest stored global metrics
+func (s *Service) GetLast(ctx context.Context) (*GlobalMetrics, error) {
+	return s.replicaSet.ReadRepo().GetLast(ctx)
+}
+
+// GetList returns the global metrics in [from, to) sorted by the time
+func (s *Service) GetList(ctx context.Context, from time.Time, to time.Time) (*GlobalMetricsList, error) {
+	return s.replicaSet.ReadRepo().GetList(ctx, from, to)
+}
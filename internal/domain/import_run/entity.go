@@ -0,0 +1,27 @@
+package import_run
+
+import (
+	"time"
+)
+
+const ()
+
+// ImportRun is a record of the journal of the import stage runs
+type ImportRun struct {
+	ID                  uint
+	Stage               string
+	StartedAt           time.Time
+	EndedAt             *time.Time
+	CurrenciesAttempted uint
+	CurrenciesSucceeded uint
+	CurrenciesFailed    uint
+	RowsUpserted        uint
+	CreditsUsed         uint
+	Error               string
+}
+
+func (e *ImportRun) Validate() error {
+	return nil
+}
+
+type ImportRunList []ImportRun
@@ -0,0 +1,19 @@
+package import_run
+
+import (
+	"context"
+)
+
+type ReplicaSet interface {
+	WriteRepo() WriteRepository
+	ReadRepo() ReadRepository
+}
+
+type WriteRepository interface {
+	Create(ctx context.Context, entity *ImportRun) (ID uint, err error)
+	Update(ctx context.Context, entity *ImportRun) error
+}
+
+type ReadRepository interface {
+	GetList(ctx context.Context, stage string, limit uint) (*ImportRunList, error)
+}
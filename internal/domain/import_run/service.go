@@ -0,0 +1,60 @@
+package import_run
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 1000
+)
+
+type Service struct {
+	replicaSet ReplicaSet
+}
+
+func NewService(replicaSet ReplicaSet) *Service {
+	return &Service{
+		replicaSet: replicaSet,
+	}
+}
+
+// Start records the start of the stage run
+func (s *Service) Start(ctx context.Context, stage string) (*ImportRun, error) {
+	entity := &ImportRun{
+		Stage:     stage,
+		StartedAt: time.Now().UTC(),
+	}
+	ID, err := s.replicaSet.WriteRepo().Create(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
+	entity.ID = ID
+	return entity, nil
+}
+
+// Finish records the end of the stage run with the stats, the used credits and the error of the run
+func (s *Service) Finish(ctx context.Context, entity *ImportRun, stats *Stats, creditsUsed uint, runErr error) error {
+	endedAt := time.Now().UTC()
+	entity.EndedAt = &endedAt
+	entity.CreditsUsed = creditsUsed
+	if stats != nil {
+		stats.Apply(entity)
+	}
+	if runErr != nil {
+		entity.Error = runErr.Error()
+	}
+	return s.replicaSet.WriteRepo().Update(ctx, entity)
+}
+
+// GetList returns the last runs, newest first; an empty stage means all the stages
+func (s *Service) GetList(ctx context.Context, stage string, limit uint) (*ImportRunList, error) {
+	if limit == 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	return s.replicaSet.ReadRepo().GetList(ctx, stage, limit)
+}
@@ -0,0 +1,51 @@
+package import_run
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type statsCtxKey struct{}
+
+// Stats collects the counters of a run; the importers add to the stats from the context, so the stats are safe for the concurrent use
+type Stats struct {
+	currenciesAttempted atomic.Uint64
+	currenciesFailed    atomic.Uint64
+	rowsUpserted        atomic.Uint64
+}
+
+// WithStats returns the context carrying the stats
+func WithStats(ctx context.Context, stats *Stats) context.Context {
+	return context.WithValue(ctx, statsCtxKey{}, stats)
+}
+
+func statsFromContext(ctx context.Context) *Stats {
+	stats, _ := ctx.Value(statsCtxKey{}).(*Stats)
+	return stats
+}
+
+// AddCurrencies adds the attempted and the failed currencies to the stats from the context, if any
+func AddCurrencies(ctx context.Context, attempted uint, failed uint) {
+	if stats := statsFromContext(ctx); stats != nil {
+		stats.currenciesAttempted.Add(uint64(attempted))
+		stats.currenciesFailed.Add(uint64(failed))
+	}
+}
+
+// AddRows adds the upserted rows to the stats from the context, if any
+func AddRows(ctx context.Context, rows int) {
+	if stats := statsFromContext(ctx); stats != nil && rows > 0 {
+		stats.rowsUpserted.Add(uint64(rows))
+	}
+}
+
+// Apply writes the counters of the stats to the run
+func (s *Stats) Apply(run *ImportRun) {
+	run.CurrenciesAttempted = uint(s.currenciesAttempted.Load())
+	run.CurrenciesFailed = uint(s.currenciesFailed.Load())
+	run.CurrenciesSucceeded = 0
+	if run.CurrenciesAttempted > run.CurrenciesFailed {
+		run.CurrenciesSucceeded = run.CurrenciesAttempted - run.CurrenciesFailed
+	}
+	run.RowsUpserted = uint(s.rowsUpserted.Load())
+}
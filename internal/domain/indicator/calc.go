@@ -0,0 +1,303 @@
+package indicator
+
+import (
+	"info/internal/domain/price_and_cap"
+	"math"
+	"slices"
+	"time"
+)
+
+// Resample returns the candles of the resolution made of the items with the positive price; the weeks start on Monday
+func Resample(list *price_and_cap.PriceAndCapList, resolution string) *CandleList {
+	res := make(CandleList, 0)
+	d := ResolutionDuration(resolution)
+	if list == nil || d == 0 {
+		return &res
+	}
+	items := make(price_and_cap.PriceAndCapList, 0, len(*list))
+	for _, item := range *list {
+		if item.Price > 0 {
+			items = append(items, item)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b price_and_cap.PriceAndCap) int {
+		return a.Ts.Compare(b.Ts)
+	})
+
+	var volumeNb int
+	for _, item := range items {
+		ts := candleTime(item.Ts.UTC(), resolution, d)
+		if len(res) == 0 || !res[len(res)-1].Ts.Equal(ts) {
+			res = append(res, Candle{Open: item.Price, High: item.Price, Low: item.Price, Ts: ts})
+			volumeNb = 0
+		}
+		c := &res[len(res)-1]
+		c.Close = item.Price
+		c.High = math.Max(c.High, item.Price)
+		c.Low = math.Min(c.Low, item.Price)
+		volumeNb++
+		c.Volume += (item.DailyVolume - c.Volume) / float64(volumeNb)
+	}
+	return &res
+}
+
+// FromPriceAndCapCandles returns the candles of the continuous aggregate of price_and_cap
+func FromPriceAndCapCandles(list *price_and_cap.CandleList) *CandleList {
+	res := make(CandleList, 0)
+	if list == nil {
+		return &res
+	}
+	for _, item := range *list {
+		res = append(res, Candle{Open: item.Open, High: item.High, Low: item.Low, Close: item.Close, Volume: item.Volume, Ts: item.Ts})
+	}
+	return &res
+}
+
+// candleTime returns the start of the candle of the time
+func candleTime(t time.Time, resolution string, d time.Duration) time.Time {
+	if resolution == Resolution_1W {
+		day := t.Truncate(time.Hour * 24)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	}
+	return t.Truncate(d)
+}
+
+// Sma returns the simple moving average of the close prices
+func Sma(candles *CandleList, period uint) ValueList {
+	return values(candles, sma(closes(candles), int(period)))
+}
+
+// Ema returns the exponential moving average of the close prices; the first value is the SMA of the period
+func Ema(candles *CandleList, period uint) ValueList {
+	return values(candles, ema(closes(candles), int(period)))
+}
+
+// Rsi returns the relative strength index of the close prices with the smoothing of Wilder
+func Rsi(candles *CandleList, period uint) ValueList {
+	xs := closes(candles)
+	n := int(period)
+	res := nanList(len(xs))
+	if n == 0 || len(xs) <= n {
+		return values(candles, res)
+	}
+	var gain, loss float64
+	for i := 1; i <= n; i++ {
+		gain += math.Max(xs[i]-xs[i-1], 0)
+		loss += math.Max(xs[i-1]-xs[i], 0)
+	}
+	gain /= float64(n)
+	loss /= float64(n)
+	res[n] = rsi(gain, loss)
+	for i := n + 1; i < len(xs); i++ {
+		gain = (gain*float64(n-1) + math.Max(xs[i]-xs[i-1], 0)) / float64(n)
+		loss = (loss*float64(n-1) + math.Max(xs[i-1]-xs[i], 0)) / float64(n)
+		res[i] = rsi(gain, loss)
+	}
+	return values(candles, res)
+}
+
+func rsi(gain float64, loss float64) float64 {
+	switch {
+	case gain == 0 && loss == 0:
+		return 50
+	case loss == 0:
+		return 100
+	}
+	return 100 - 100/(1+gain/loss)
+}
+
+// MacdSeries returns the MACD of the close prices; the fast period must be less than the slow one
+func MacdSeries(candles *CandleList, fast uint, slow uint, signal uint) MacdList {
+	res := make(MacdList, 0)
+	if candles == nil || fast == 0 || fast >= slow || signal == 0 {
+		return res
+	}
+	xs := closes(candles)
+	fastEma := ema(xs, int(fast))
+	slowEma := ema(xs, int(slow))
+	start := int(slow) - 1
+	if len(xs) <= start {
+		return res
+	}
+	line := make([]float64, 0, len(xs)-start)
+	for i := start; i < len(xs); i++ {
+		line = append(line, fastEma[i]-slowEma[i])
+	}
+	signalEma := ema(line, int(signal))
+	for i := range line {
+		if math.IsNaN(signalEma[i]) {
+			continue
+		}
+		res = append(res, Macd{
+			Macd:      line[i],
+			Signal:    signalEma[i],
+			Histogram: line[i] - signalEma[i],
+			Ts:        (*candles)[start+i].Ts,
+		})
+	}
+	return res
+}
+
+// Bollinger returns the bands of the SMA of the close prices -/+ k standard deviations of the period
+func Bollinger(candles *CandleList, period uint, k float64) BandList {
+	res := make(BandList, 0)
+	xs := closes(candles)
+	n := int(period)
+	if n == 0 {
+		return res
+	}
+	for i := n - 1; i < len(xs); i++ {
+		mean, std := meanStd(xs[i-n+1 : i+1])
+		res = append(res, Band{
+			Lower:  mean - k*std,
+			Middle: mean,
+			Upper:  mean + k*std,
+			Ts:     (*candles)[i].Ts,
+		})
+	}
+	return res
+}
+
+// Atr returns the average true range with the smoothing of Wilder; the first value is the average of the period
+func Atr(candles *CandleList, period uint) ValueList {
+	return values(candles, atr(candles, int(period)))
+}
+
+// AtrPercent returns ATR as the percent of the close price
+func AtrPercent(candles *CandleList, period uint) ValueList {
+	res := atr(candles, int(period))
+	for i := range res {
+		res[i] = res[i] * 100 / (*candles)[i].Close
+	}
+	return values(candles, res)
+}
+
+func atr(candles *CandleList, n int) []float64 {
+	if candles == nil {
+		return nil
+	}
+	c := *candles
+	res := nanList(len(c))
+	if n == 0 || len(c) <= n {
+		return res
+	}
+	tr := func(i int) float64 {
+		return math.Max(c[i].High-c[i].Low, math.Max(math.Abs(c[i].High-c[i-1].Close), math.Abs(c[i].Low-c[i-1].Close)))
+	}
+	var v float64
+	for i := 1; i <= n; i++ {
+		v += tr(i)
+	}
+	v /= float64(n)
+	res[n] = v
+	for i := n + 1; i < len(c); i++ {
+		v = (v*float64(n-1) + tr(i)) / float64(n)
+		res[i] = v
+	}
+	return res
+}
+
+// VolumeZScore returns the z-score of the volume of the candle against the previous period candles; 0 if the volume did not change
+func VolumeZScore(candles *CandleList, period uint) ValueList {
+	if candles == nil {
+		return values(candles, nil)
+	}
+	xs := make([]float64, 0, len(*candles))
+	for _, c := range *candles {
+		xs = append(xs, c.Volume)
+	}
+	n := int(period)
+	res := nanList(len(xs))
+	if n > 1 {
+		for i := n; i < len(xs); i++ {
+			mean, std := meanStd(xs[i-n : i])
+			if std == 0 {
+				res[i] = 0
+				continue
+			}
+			res[i] = (xs[i] - mean) / std
+		}
+	}
+	return values(candles, res)
+}
+
+func sma(xs []float64, n int) []float64 {
+	res := nanList(len(xs))
+	if n == 0 {
+		return res
+	}
+	var sum float64
+	for i := range xs {
+		sum += xs[i]
+		if i >= n {
+			sum -= xs[i-n]
+		}
+		if i >= n-1 {
+			res[i] = sum / float64(n)
+		}
+	}
+	return res
+}
+
+func ema(xs []float64, n int) []float64 {
+	res := nanList(len(xs))
+	if n == 0 || len(xs) < n {
+		return res
+	}
+	var v float64
+	for i := 0; i < n; i++ {
+		v += xs[i]
+	}
+	v /= float64(n)
+	res[n-1] = v
+	alpha := 2 / float64(n+1)
+	for i := n; i < len(xs); i++ {
+		v += alpha * (xs[i] - v)
+		res[i] = v
+	}
+	return res
+}
+
+// meanStd returns the mean and the population standard deviation
+func meanStd(xs []float64) (float64, float64) {
+	var mean, variance float64
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	for _, x := range xs {
+		variance += (x - mean) * (x - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(xs)))
+}
+
+func closes(candles *CandleList) []float64 {
+	if candles == nil {
+		return nil
+	}
+	res := make([]float64, 0, len(*candles))
+	for _, c := range *candles {
+		res = append(res, c.Close)
+	}
+	return res
+}
+
+func nanList(n int) []float64 {
+	res := make([]float64, n)
+	for i := range res {
+		res[i] = math.NaN()
+	}
+	return res
+}
+
+// values returns the values of the candles skipping the undefined ones
+func values(candles *CandleList, xs []float64) ValueList {
+	res := make(ValueList, 0, len(xs))
+	for i, x := range xs {
+		if math.IsNaN(x) {
+			continue
+		}
+		res = append(res, Value{Value: x, Ts: (*candles)[i].Ts})
+	}
+	return res
+}
@@ -0,0 +1,138 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"info/internal/domain/price_and_cap"
+)
+
+var day0 = time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC) // Monday
+
+// dailyCandles returns the candles of the days from day0 with the close prices, high = close + 1, low = close - 1 and the volumes
+func dailyCandles(closes []float64, volumes []float64) *CandleList {
+	res := make(CandleList, 0, len(closes))
+	for i, c := range closes {
+		item := Candle{Open: c, High: c + 1, Low: c - 1, Close: c, Ts: day0.AddDate(0, 0, i)}
+		if volumes != nil {
+			item.Volume = volumes[i]
+		}
+		res = append(res, item)
+	}
+	return &res
+}
+
+func assertValues(t *testing.T, got ValueList, want []float64, firstDay int) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %v", got, want)
+	}
+	for i := range want {
+		if math.Abs(got[i].Value-want[i]) > 1e-9 || !got[i].Ts.Equal(day0.AddDate(0, 0, firstDay+i)) {
+			t.Errorf("item %d: got %+v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestResample(t *testing.T) {
+	h := func(hours int) time.Time {
+		return day0.Add(time.Hour * time.Duration(hours))
+	}
+	// sorted by the time desc like price_and_cap
+	list := price_and_cap.PriceAndCapList{
+		{Price: 7, DailyVolume: 300, Ts: h(24 * 7)},
+		{Price: 5, DailyVolume: 100, Ts: h(30)},
+		{Price: 0, DailyVolume: 100, Ts: h(29)},
+		{Price: 4, DailyVolume: 300, Ts: h(26)},
+		{Price: 2, DailyVolume: 10, Ts: h(12)},
+		{Price: 3, DailyVolume: 30, Ts: h(6)},
+		{Price: 1, DailyVolume: 20, Ts: h(1)},
+	}
+
+	tests := []struct {
+		name       string
+		resolution string
+		want       CandleList
+	}{
+		{name: "days", resolution: Resolution_1D, want: CandleList{
+			{Open: 1, High: 3, Low: 1, Close: 2, Volume: 20, Ts: day0},
+			{Open: 4, High: 5, Low: 4, Close: 5, Volume: 200, Ts: day0.AddDate(0, 0, 1)},
+			{Open: 7, High: 7, Low: 7, Close: 7, Volume: 300, Ts: day0.AddDate(0, 0, 7)},
+		}},
+		{name: "weeks", resolution: Resolution_1W, want: CandleList{
+			{Open: 1, High: 5, Low: 1, Close: 5, Volume: 92, Ts: day0},
+			{Open: 7, High: 7, Low: 7, Close: 7, Volume: 300, Ts: day0.AddDate(0, 0, 7)},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Resample(&list, tt.resolution)
+			if len(*res) != len(tt.want) {
+				t.Fatalf("got %+v", *res)
+			}
+			for i := range tt.want {
+				if (*res)[i] != tt.want[i] {
+					t.Errorf("candle %d: got %+v, want %+v", i, (*res)[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIndicators(t *testing.T) {
+	rising := dailyCandles([]float64{1, 2, 3, 4, 5}, nil)
+
+	assertValues(t, Sma(rising, 3), []float64{2, 3, 4}, 2)
+	assertValues(t, Ema(rising, 3), []float64{2, 3, 4}, 2)
+	assertValues(t, Rsi(rising, 2), []float64{100, 100, 100}, 2)
+	// the true range is 2: high - low and the gap to the previous close are less
+	assertValues(t, Atr(rising, 2), []float64{2, 2, 2}, 2)
+	assertValues(t, AtrPercent(rising, 2), []float64{200.0 / 3, 50, 40}, 2)
+
+	mixed := dailyCandles([]float64{10, 12, 11, 13, 12}, nil)
+	// the average gain and loss of the first 2 days are 1 and 0.5, then they are smoothed: (1*1 + 2) / 2 and (0.5*1 + 0) / 2
+	assertValues(t, Rsi(mixed, 2), []float64{100 - 100/(1+1/0.5), 100 - 100/(1+1.5/0.25), 100 - 100/(1+0.75/0.625)}, 2)
+
+	flat := dailyCandles([]float64{5, 5, 5, 5}, []float64{3, 3, 3, 3})
+	bands := Bollinger(flat, 2, 2)
+	if len(bands) != 3 || bands[0].Lower != 5 || bands[0].Upper != 5 || !bands[0].Ts.Equal(day0.AddDate(0, 0, 1)) {
+		t.Errorf("bollinger: got %+v", bands)
+	}
+	spike := dailyCandles([]float64{5, 5, 5, 5, 5}, []float64{1, 2, 1, 2, 10})
+	// the mean of the previous 4 volumes is 1.5, the deviation is 0.5
+	assertValues(t, VolumeZScore(spike, 4), []float64{17}, 4)
+	assertValues(t, VolumeZScore(flat, 2), []float64{0, 0}, 2)
+}
+
+func TestMacdSeries(t *testing.T) {
+	candles := dailyCandles([]float64{1, 2, 3, 4, 5, 6}, nil)
+	// the fast EMA(2) of the line x is x - 0.5, the slow EMA(3) is x - 1, so MACD is 0.5 from the 3rd day
+	res := MacdSeries(candles, 2, 3, 2)
+	if len(res) != 3 {
+		t.Fatalf("got %+v", res)
+	}
+	for i, item := range res {
+		if math.Abs(item.Macd-0.5) > 1e-9 || math.Abs(item.Signal-0.5) > 1e-9 || math.Abs(item.Histogram) > 1e-9 || !item.Ts.Equal(day0.AddDate(0, 0, 3+i)) {
+			t.Errorf("item %d: got %+v", i, item)
+		}
+	}
+	if res := MacdSeries(candles, 3, 2, 2); len(res) != 0 {
+		t.Errorf("the fast period is greater: got %+v", res)
+	}
+}
+
+func TestIndicators_Trim(t *testing.T) {
+	list := make(price_and_cap.PriceAndCapList, 0, 40)
+	for i := 0; i < 40; i++ {
+		list = append(list, price_and_cap.PriceAndCap{Price: float64(i + 1), DailyVolume: float64(i % 3), Ts: day0.AddDate(0, 0, i)})
+	}
+	from := day0.AddDate(0, 0, 30)
+	res := Calc(&list, Resolution_1D, &DefaultParams).Trim(from)
+	if len(res.Candles) != 10 || !res.Candles[0].Ts.Equal(from) {
+		t.Fatalf("got %+v", res.Candles)
+	}
+	if len(res.Sma) != 10 || len(res.Macd) != 7 || len(res.VolumeZScore) != 10 {
+		t.Errorf("got %d %d %d", len(res.Sma), len(res.Macd), len(res.VolumeZScore))
+	}
+}
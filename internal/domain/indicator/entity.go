@@ -0,0 +1,111 @@
+package indicator
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	Resolution_1H = "1h"
+	Resolution_4H = "4h"
+	Resolution_1D = "1d"
+	Resolution_1W = "1w"
+)
+
+var ResolutionList = []interface{}{
+	Resolution_1H,
+	Resolution_4H,
+	Resolution_1D,
+	Resolution_1W,
+}
+
+func ResolutionValidate(s string) error {
+	return validation.Validate(s, validation.Required, validation.In(ResolutionList...))
+}
+
+// ResolutionDuration returns the duration of the candle of the resolution or 0 for an unknown resolution
+func ResolutionDuration(resolution string) time.Duration {
+	switch resolution {
+	case Resolution_1H:
+		return time.Hour
+	case Resolution_4H:
+		return time.Hour * 4
+	case Resolution_1D:
+		return time.Hour * 24
+	case Resolution_1W:
+		return time.Hour * 24 * 7
+	}
+	return 0
+}
+
+// Candle is the prices of the items of price_and_cap inside [Ts, Ts + resolution);
+// Volume is the average of the daily volumes, because the daily volume of price_and_cap is the rolling volume of 24 hours
+type Candle struct {
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume float64
+	Ts     time.Time
+}
+
+type CandleList []Candle
+
+// Value is a value of an indicator at the time of the candle
+type Value struct {
+	Value float64
+	Ts    time.Time
+}
+
+type ValueList []Value
+
+// Macd is the difference of the fast and the slow EMA, the EMA of the difference and the histogram of them
+type Macd struct {
+	Macd      float64
+	Signal    float64
+	Histogram float64
+	Ts        time.Time
+}
+
+type MacdList []Macd
+
+// Band is the Bollinger band: the SMA and the SMA -/+ K standard deviations
+type Band struct {
+	Lower  float64
+	Middle float64
+	Upper  float64
+	Ts     time.Time
+}
+
+type BandList []Band
+
+// Params are the periods of the indicators in candles
+type Params struct {
+	SmaPeriod       uint
+	EmaPeriod       uint
+	RsiPeriod       uint
+	MacdFast        uint
+	MacdSlow        uint
+	MacdSignal      uint
+	BollingerPeriod uint
+	BollingerK      float64
+	AtrPeriod       uint
+	VolumePeriod    uint // the window of the z-score of the volume
+}
+
+// Indicators are the series of the indicators of a currency; the first items of a series are skipped until its period is filled
+type Indicators struct {
+	CurrencyID   uint
+	Resolution   string
+	Params       Params
+	Candles      CandleList
+	Sma          ValueList
+	Ema          ValueList
+	Rsi          ValueList
+	Macd         MacdList
+	Bollinger    BandList
+	Atr          ValueList
+	AtrPercent   ValueList // ATR as the percent of the close price
+	VolumeZScore ValueList
+}
@@ -0,0 +1,181 @@
+package indicator
+
+import (
+	"context"
+	"fmt"
+	"info/internal/domain/price_and_cap"
+	"info/internal/pkg/apperror"
+	"time"
+)
+
+const (
+	maxPeriod  = 500
+	maxCandles = 5000
+	// the EMA and the smoothing of Wilder depend on all the previous values, so the history of the warmup is longer than the period
+	warmupFactor = 3
+)
+
+var DefaultParams = Params{
+	SmaPeriod:       20,
+	EmaPeriod:       20,
+	RsiPeriod:       14,
+	MacdFast:        12,
+	MacdSlow:        26,
+	MacdSignal:      9,
+	BollingerPeriod: 20,
+	BollingerK:      2,
+	AtrPeriod:       14,
+	VolumePeriod:    20,
+}
+
+// Validate checks that the periods are in [1, 500] (2 for the volume) and the fast period of MACD is less than the slow one
+func (p *Params) Validate() error {
+	periods := []struct {
+		name   string
+		period uint
+	}{
+		{"sma", p.SmaPeriod},
+		{"ema", p.EmaPeriod},
+		{"rsi", p.RsiPeriod},
+		{"macd fast", p.MacdFast},
+		{"macd slow", p.MacdSlow},
+		{"macd signal", p.MacdSignal},
+		{"bollinger", p.BollingerPeriod},
+		{"atr", p.AtrPeriod},
+		{"volume", p.VolumePeriod},
+	}
+	for _, item := range periods {
+		if item.period == 0 || item.period > maxPeriod {
+			return fmt.Errorf("[%w] the period of %s must be in [1, %d]", apperror.ErrBadRequest, item.name, maxPeriod)
+		}
+	}
+	if p.VolumePeriod < 2 {
+		return fmt.Errorf("[%w] the period of the volume must be at least 2", apperror.ErrBadRequest)
+	}
+	if p.MacdFast >= p.MacdSlow {
+		return fmt.Errorf("[%w] the fast period of macd must be less than the slow one", apperror.ErrBadRequest)
+	}
+	if p.BollingerK <= 0 {
+		return fmt.Errorf("[%w] k of bollinger must be positive", apperror.ErrBadRequest)
+	}
+	return nil
+}
+
+// warmup returns the number of the candles before the first returned one which are needed to fill the periods
+func (p *Params) warmup() uint {
+	res := p.MacdSlow + p.MacdSignal
+	for _, period := range []uint{p.SmaPeriod, p.EmaPeriod, p.RsiPeriod + 1, p.BollingerPeriod, p.AtrPeriod + 1, p.VolumePeriod + 1} {
+		if period > res {
+			res = period
+		}
+	}
+	return res * warmupFactor
+}
+
+type Service struct {
+	priceAndCap *price_and_cap.Service
+}
+
+func NewService(priceAndCap *price_and_cap.Service) *Service {
+	return &Service{
+		priceAndCap: priceAndCap,
+	}
+}
+
+// Get returns the indicators of the currency of the resolution on the candles inside [from, to); the history before from is used to fill the periods
+func (s *Service) Get(ctx context.Context, currencyID uint, resolution string, from time.Time, to time.Time, params *Params) (*Indicators, error) {
+	if err := ResolutionValidate(resolution); err != nil {
+		return nil, fmt.Errorf("[%w] resolution: %w", apperror.ErrBadRequest, err)
+	}
+	if params == nil {
+		params = &DefaultParams
+	}
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("[%w] from must be before to", apperror.ErrBadRequest)
+	}
+	d := ResolutionDuration(resolution)
+	if to.Sub(from)/d > maxCandles {
+		return nil, fmt.Errorf("[%w] too many candles; max: %d", apperror.ErrBadRequest, maxCandles)
+	}
+
+	candles, err := s.getCandles(ctx, currencyID, resolution, from.Add(-d*time.Duration(params.warmup())), to)
+	if err != nil {
+		return nil, err
+	}
+
+	res := CalcCandles(candles, resolution, params).Trim(candleTime(from.UTC(), resolution, d))
+	if len(res.Candles) == 0 {
+		return nil, fmt.Errorf("[%w] there are no prices of the period", apperror.ErrNotFound)
+	}
+	res.CurrencyID = currencyID
+	return res, nil
+}
+
+// getCandles returns the candles of the continuous aggregate of the resolution or resamples the prices if there is no aggregate
+func (s *Service) getCandles(ctx context.Context, currencyID uint, resolution string, from time.Time, to time.Time) (*CandleList, error) {
+	if price_and_cap.IntervalValidate(resolution) == nil {
+		list, err := s.priceAndCap.GetCandles(ctx, currencyID, resolution, from, to)
+		if err != nil {
+			return nil, err
+		}
+		return FromPriceAndCapCandles(list), nil
+	}
+	list, err := s.priceAndCap.GetList(ctx, currencyID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return Resample(list, resolution), nil
+}
+
+// Calc returns all the indicators of the list resampled to the resolution
+func Calc(list *price_and_cap.PriceAndCapList, resolution string, params *Params) *Indicators {
+	return CalcCandles(Resample(list, resolution), resolution, params)
+}
+
+// CalcCandles returns all the indicators of the candles of the resolution
+func CalcCandles(candles *CandleList, resolution string, params *Params) *Indicators {
+	return &Indicators{
+		Resolution:   resolution,
+		Params:       *params,
+		Candles:      *candles,
+		Sma:          Sma(candles, params.SmaPeriod),
+		Ema:          Ema(candles, params.EmaPeriod),
+		Rsi:          Rsi(candles, params.RsiPeriod),
+		Macd:         MacdSeries(candles, params.MacdFast, params.MacdSlow, params.MacdSignal),
+		Bollinger:    Bollinger(candles, params.BollingerPeriod, params.BollingerK),
+		Atr:          Atr(candles, params.AtrPeriod),
+		AtrPercent:   AtrPercent(candles, params.AtrPeriod),
+		VolumeZScore: VolumeZScore(candles, params.VolumePeriod),
+	}
+}
+
+// Trim drops the items of the series before the time
+func (e *Indicators) Trim(from time.Time) *Indicators {
+	e.Candles = trim(e.Candles, from, func(c Candle) time.Time { return c.Ts })
+	e.Sma = trim(e.Sma, from, valueTime)
+	e.Ema = trim(e.Ema, from, valueTime)
+	e.Rsi = trim(e.Rsi, from, valueTime)
+	e.Macd = trim(e.Macd, from, func(m Macd) time.Time { return m.Ts })
+	e.Bollinger = trim(e.Bollinger, from, func(b Band) time.Time { return b.Ts })
+	e.Atr = trim(e.Atr, from, valueTime)
+	e.AtrPercent = trim(e.AtrPercent, from, valueTime)
+	e.VolumeZScore = trim(e.VolumeZScore, from, valueTime)
+	return e
+}
+
+func valueTime(v Value) time.Time {
+	return v.Ts
+}
+
+// trim returns the tail of the series sorted by the time which starts from the time
+func trim[S ~[]E, E any](s S, from time.Time, ts func(E) time.Time) S {
+	for i := range s {
+		if !ts(s[i]).Before(from) {
+			return s[i:]
+		}
+	}
+	return s[len(s):]
+}
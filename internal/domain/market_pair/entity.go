@@ -0,0 +1,123 @@
+package market_pair
+
+import "time"
+
+const (
+	Category_Spot      = "spot"
+	Category_Perpetual = "perpetual"
+	Category_Futures   = "futures"
+)
+
+// IsDerivative returns true for the categories of the derivatives
+func IsDerivative(category string) bool {
+	return category == Category_Perpetual || category == Category_Futures
+}
+
+type Exchange struct {
+	ID   uint
+	Name string
+	Slug string
+}
+
+type ExchangeList []Exchange
+
+// MarketPair is a snapshot of a market of the currency on an exchange; the depth is the USD volume of the orders within ±2% of the price
+type MarketPair struct {
+	CurrencyID       uint
+	ExchangeID       uint
+	MarketID         uint
+	Pair             string
+	Category         string
+	BaseSymbol       string
+	QuoteSymbol      string
+	Price            float64
+	VolumeUsd        float64
+	DepthNegativeTwo float64
+	DepthPositiveTwo float64
+	Ts               time.Time
+}
+
+type MarketPairList []MarketPair
+
+// ImportData is the market pairs of a currency with their exchanges
+type ImportData struct {
+	ExchangeList   ExchangeList
+	MarketPairList MarketPairList
+}
+
+// Venue is the liquidity of the currency on an exchange
+type Venue struct {
+	ExchangeID       uint
+	ExchangeName     string
+	ExchangeSlug     string
+	PairsNb          uint
+	VolumeUsd        float64
+	VolumeShare      float64 // the share of the volume of the currency on all the exchanges
+	SpotVolumeUsd    float64
+	DepthNegativeTwo float64 // of the spot markets
+	DepthPositiveTwo float64 // of the spot markets
+}
+
+type VenueList []Venue
+
+// Liquidity is the report of the latest snapshot of the market pairs of the currency
+type Liquidity struct {
+	CurrencyID             uint
+	Ts                     time.Time
+	PairsNb                uint
+	VenuesNb               uint
+	VolumeUsd              float64
+	SpotVolumeUsd          float64
+	DerivativesVolumeUsd   float64
+	SpotVolumeShare        float64
+	DerivativesVolumeShare float64
+	DepthNegativeTwo       float64 // of the spot markets
+	DepthPositiveTwo       float64 // of the spot markets
+	TopVenuesVolumeShare   float64 // the share of the volume of TopVenues
+	TopVenues              VenueList
+}
+
+// Unique returns the exchanges without the duplicates
+func (l *ExchangeList) Unique() *ExchangeList {
+	res := make(ExchangeList, 0, len(*l))
+	exists := make(map[uint]struct{}, len(*l))
+	var item Exchange
+	for _, item = range *l {
+		if _, ok := exists[item.ID]; ok {
+			continue
+		}
+		exists[item.ID] = struct{}{}
+		res = append(res, item)
+	}
+	return &res
+}
+
+// ExchangeIDs returns the IDs of the exchanges of the market pairs without the duplicates
+func (l *MarketPairList) ExchangeIDs() *[]uint {
+	res := make([]uint, 0, len(*l))
+	exists := make(map[uint]struct{}, len(*l))
+	var item MarketPair
+	for _, item = range *l {
+		if _, ok := exists[item.ExchangeID]; ok {
+			continue
+		}
+		exists[item.ExchangeID] = struct{}{}
+		res = append(res, item.ExchangeID)
+	}
+	return &res
+}
+
+// Unique returns the market pairs without the duplicates of the market
+func (l *MarketPairList) Unique() *MarketPairList {
+	res := make(MarketPairList, 0, len(*l))
+	exists := make(map[uint]struct{}, len(*l))
+	var item MarketPair
+	for _, item = range *l {
+		if _, ok := exists[item.MarketID]; ok {
+			continue
+		}
+		exists[item.MarketID] = struct{}{}
+		res = append(res, item)
+	}
+	return &res
+}
@@ -0,0 +1,21 @@
+package market_pair
+
+import (
+	"context"
+)
+
+type ReplicaSet interface {
+	WriteRepo() WriteRepository
+	ReadRepo() ReadRepository
+}
+
+type WriteRepository interface {
+	MUpsertExchange(ctx context.Context, entities *ExchangeList) error
+	MUpsert(ctx context.Context, entities *MarketPairList) error
+}
+
+type ReadRepository interface {
+	// GetLatest returns the market pairs of the latest snapshot of the currency
+	GetLatest(ctx context.Context, currencyID uint) (*MarketPairList, error)
+	MGetExchange(ctx context.Context, IDs *[]uint) (*ExchangeList, error)
+}
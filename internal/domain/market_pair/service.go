@@ -0,0 +1,206 @@
+package market_pair
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"info/internal/domain/currency"
+	"info/internal/domain/import_run"
+	"info/internal/pkg/apperror"
+	"info/internal/pkg/workerpool"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	workerPoolJob_Import = "market_pair.import"
+
+	defaultPairsLimit = 100
+	maxPairsLimit     = 1000
+	defaultTopVenues  = 10
+	maxTopVenues      = 100
+)
+
+// DefaultCategoryList is the categories which are imported by default
+var DefaultCategoryList = []string{Category_Spot, Category_Perpetual}
+
+// MarketPairsApi returns the top market pairs of the currency of the category
+type MarketPairsApi interface {
+	GetMarketPairs(ctx context.Context, currencyID uint, slug string, category string, limit uint) (*ImportData, error)
+}
+
+type Config struct {
+	Categories []string // empty - DefaultCategoryList
+	PairsLimit uint     // the number of the imported pairs of a category; 0 - 100
+}
+
+type Service struct {
+	replicaSet     ReplicaSet
+	config         Config
+	marketPairsApi MarketPairsApi
+	workerPool     *workerpool.Pool
+}
+
+func NewService(replicaSet ReplicaSet, cfg Config, marketPairsApi MarketPairsApi, workerPool *workerpool.Pool) *Service {
+	if len(cfg.Categories) == 0 {
+		cfg.Categories = DefaultCategoryList
+	}
+	if cfg.PairsLimit == 0 {
+		cfg.PairsLimit = defaultPairsLimit
+	}
+	if cfg.PairsLimit > maxPairsLimit {
+		cfg.PairsLimit = maxPairsLimit
+	}
+	return &Service{
+		replicaSet:     replicaSet,
+		config:         cfg,
+		marketPairsApi: marketPairsApi,
+		workerPool:     workerPool,
+	}
+}
+
+// Import stores the snapshot of the market pairs of every category of the currencies by the worker pool in parallel; the errors of every currency are joined
+func (s *Service) Import(ctx context.Context, currencyList *currency.CurrencyList) error {
+	if s.marketPairsApi == nil {
+		return fmt.Errorf("[%w] market_pair.Service: there is no market pairs API", apperror.ErrInternal)
+	}
+	if currencyList == nil || len(*currencyList) == 0 {
+		return nil
+	}
+
+	// all the categories of a run are one snapshot
+	ts := time.Now().UTC().Truncate(time.Hour)
+	var failedNb atomic.Uint64
+	err := s.workerPool.Run(ctx, workerPoolJob_Import, len(*currencyList), func(ctx context.Context, i int) error {
+		item := &(*currencyList)[i]
+		if err := s.importItem(ctx, item, ts); err != nil {
+			failedNb.Add(1)
+			return fmt.Errorf("currency %d (%s): %w", item.ID, item.Slug, err)
+		}
+		return nil
+	})
+	import_run.AddCurrencies(ctx, uint(len(*currencyList)), uint(failedNb.Load()))
+	return err
+}
+
+func (s *Service) importItem(ctx context.Context, item *currency.Currency, ts time.Time) error {
+	exchanges := make(ExchangeList, 0)
+	pairs := make(MarketPairList, 0)
+	var category string
+	for _, category = range s.config.Categories {
+		data, err := s.marketPairsApi.GetMarketPairs(ctx, item.ID, item.Slug, category, s.config.PairsLimit)
+		if err != nil {
+			return err
+		}
+		exchanges = append(exchanges, data.ExchangeList...)
+		pairs = append(pairs, data.MarketPairList...)
+	}
+	pairs = *pairs.Unique()
+	for i := range pairs {
+		pairs[i].Ts = ts
+	}
+
+	exchanges = *exchanges.Unique()
+	if err := s.replicaSet.WriteRepo().MUpsertExchange(ctx, &exchanges); err != nil {
+		return err
+	}
+	if err := s.replicaSet.WriteRepo().MUpsert(ctx, &pairs); err != nil {
+		return err
+	}
+	import_run.AddRows(ctx, len(pairs))
+	return nil
+}
+
+// Liquidity returns the liquidity report of the latest snapshot of the currency with the top venues by the volume
+func (s *Service) Liquidity(ctx context.Context, currencyID uint, topVenues uint) (*Liquidity, error) {
+	if topVenues == 0 {
+		topVenues = defaultTopVenues
+	}
+	if topVenues > maxTopVenues {
+		topVenues = maxTopVenues
+	}
+
+	pairs, err := s.replicaSet.ReadRepo().GetLatest(ctx, currencyID)
+	if err != nil {
+		return nil, err
+	}
+	exchanges, err := s.replicaSet.ReadRepo().MGetExchange(ctx, pairs.ExchangeIDs())
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return nil, err
+	}
+	return CalcLiquidity(currencyID, pairs, exchanges, topVenues), nil
+}
+
+// CalcLiquidity groups the market pairs by the exchanges; the depth is summed only for the spot markets
+func CalcLiquidity(currencyID uint, pairs *MarketPairList, exchanges *ExchangeList, topVenues uint) *Liquidity {
+	res := &Liquidity{
+		CurrencyID: currencyID,
+		PairsNb:    uint(len(*pairs)),
+		TopVenues:  VenueList{},
+	}
+
+	venues := make(map[uint]*Venue)
+	var item MarketPair
+	for _, item = range *pairs {
+		if item.Ts.After(res.Ts) {
+			res.Ts = item.Ts
+		}
+		venue, ok := venues[item.ExchangeID]
+		if !ok {
+			venue = &Venue{ExchangeID: item.ExchangeID}
+			venues[item.ExchangeID] = venue
+		}
+		venue.PairsNb++
+		venue.VolumeUsd += item.VolumeUsd
+		res.VolumeUsd += item.VolumeUsd
+		if IsDerivative(item.Category) {
+			res.DerivativesVolumeUsd += item.VolumeUsd
+			continue
+		}
+		venue.SpotVolumeUsd += item.VolumeUsd
+		venue.DepthNegativeTwo += item.DepthNegativeTwo
+		venue.DepthPositiveTwo += item.DepthPositiveTwo
+		res.SpotVolumeUsd += item.VolumeUsd
+		res.DepthNegativeTwo += item.DepthNegativeTwo
+		res.DepthPositiveTwo += item.DepthPositiveTwo
+	}
+	res.VenuesNb = uint(len(venues))
+	if res.VolumeUsd > 0 {
+		res.SpotVolumeShare = res.SpotVolumeUsd / res.VolumeUsd
+		res.DerivativesVolumeShare = res.DerivativesVolumeUsd / res.VolumeUsd
+	}
+
+	if exchanges != nil {
+		var exchange Exchange
+		for _, exchange = range *exchanges {
+			if venue, ok := venues[exchange.ID]; ok {
+				venue.ExchangeName = exchange.Name
+				venue.ExchangeSlug = exchange.Slug
+			}
+		}
+	}
+
+	list := make(VenueList, 0, len(venues))
+	for _, venue := range venues {
+		if res.VolumeUsd > 0 {
+			venue.VolumeShare = venue.VolumeUsd / res.VolumeUsd
+		}
+		list = append(list, *venue)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].VolumeUsd != list[j].VolumeUsd {
+			return list[i].VolumeUsd > list[j].VolumeUsd
+		}
+		return list[i].ExchangeID < list[j].ExchangeID
+	})
+	if uint(len(list)) > topVenues {
+		list = list[:topVenues]
+	}
+	res.TopVenues = list
+	var venue Venue
+	for _, venue = range list {
+		res.TopVenuesVolumeShare += venue.VolumeShare
+	}
+	return res
+}
@@ -0,0 +1,151 @@
+package market_pair
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"info/internal/domain/currency"
+	"info/internal/pkg/workerpool"
+)
+
+var ts1 = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+func pair(exchangeID uint, marketID uint, category string, volume float64, depthNeg float64, depthPos float64) MarketPair {
+	return MarketPair{CurrencyID: 1, ExchangeID: exchangeID, MarketID: marketID, Category: category, VolumeUsd: volume, DepthNegativeTwo: depthNeg, DepthPositiveTwo: depthPos, Ts: ts1}
+}
+
+func TestCalcLiquidity(t *testing.T) {
+	pairs := MarketPairList{
+		pair(1, 10, Category_Spot, 100, 5, 6),
+		pair(1, 11, Category_Perpetual, 300, 50, 60),
+		pair(2, 20, Category_Spot, 200, 7, 8),
+		pair(3, 30, Category_Spot, 50, 1, 1),
+		pair(3, 31, Category_Futures, 350, 0, 0),
+	}
+	exchanges := ExchangeList{
+		{ID: 1, Name: "Binance", Slug: "binance"},
+		{ID: 3, Name: "OKX", Slug: "okx"},
+	}
+
+	tests := []struct {
+		name       string
+		topVenues  uint
+		wantIDs    []uint
+		wantShare  float64
+		wantSlugs  []string
+		wantVenues uint
+	}{
+		{name: "all the venues", topVenues: 10, wantIDs: []uint{1, 3, 2}, wantShare: 1, wantSlugs: []string{"binance", "okx", ""}, wantVenues: 3},
+		{name: "the top venue", topVenues: 1, wantIDs: []uint{1}, wantShare: 0.4, wantSlugs: []string{"binance"}, wantVenues: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := CalcLiquidity(1, &pairs, &exchanges, tt.topVenues)
+			if res.PairsNb != 5 || res.VenuesNb != tt.wantVenues || !res.Ts.Equal(ts1) {
+				t.Fatalf("got %+v", *res)
+			}
+			if res.VolumeUsd != 1000 || res.SpotVolumeUsd != 350 || res.DerivativesVolumeUsd != 650 {
+				t.Errorf("volumes: got %v %v %v", res.VolumeUsd, res.SpotVolumeUsd, res.DerivativesVolumeUsd)
+			}
+			// the depth of the derivatives is not summed
+			if res.DepthNegativeTwo != 13 || res.DepthPositiveTwo != 15 {
+				t.Errorf("depth: got %v %v", res.DepthNegativeTwo, res.DepthPositiveTwo)
+			}
+			if len(res.TopVenues) != len(tt.wantIDs) {
+				t.Fatalf("got %+v, want %v", res.TopVenues, tt.wantIDs)
+			}
+			for i := range tt.wantIDs {
+				if res.TopVenues[i].ExchangeID != tt.wantIDs[i] || res.TopVenues[i].ExchangeSlug != tt.wantSlugs[i] {
+					t.Errorf("venue %d: got %+v", i, res.TopVenues[i])
+				}
+			}
+			if math.Abs(res.TopVenuesVolumeShare-tt.wantShare) > 1e-9 {
+				t.Errorf("top venues share: got %v, want %v", res.TopVenuesVolumeShare, tt.wantShare)
+			}
+		})
+	}
+}
+
+func TestCalcLiquidity_Empty(t *testing.T) {
+	res := CalcLiquidity(1, &MarketPairList{}, nil, 10)
+	if res.VolumeUsd != 0 || res.SpotVolumeShare != 0 || len(res.TopVenues) != 0 {
+		t.Errorf("got %+v", *res)
+	}
+}
+
+type fakeMarketPairRepo struct {
+	mu        sync.Mutex
+	pairs     MarketPairList
+	exchanges ExchangeList
+}
+
+func (r *fakeMarketPairRepo) WriteRepo() WriteRepository { return r }
+func (r *fakeMarketPairRepo) ReadRepo() ReadRepository   { return r }
+
+func (r *fakeMarketPairRepo) MUpsertExchange(ctx context.Context, entities *ExchangeList) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = append(r.exchanges, *entities...)
+	return nil
+}
+
+func (r *fakeMarketPairRepo) MUpsert(ctx context.Context, entities *MarketPairList) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pairs = append(r.pairs, *entities...)
+	return nil
+}
+
+func (r *fakeMarketPairRepo) GetLatest(ctx context.Context, currencyID uint) (*MarketPairList, error) {
+	return nil, nil
+}
+
+func (r *fakeMarketPairRepo) MGetExchange(ctx context.Context, IDs *[]uint) (*ExchangeList, error) {
+	return nil, nil
+}
+
+// fakeMarketPairsApi returns the same market pair in every category, so the pairs of the categories are deduplicated
+type fakeMarketPairsApi struct {
+	err map[uint]error
+}
+
+func (a *fakeMarketPairsApi) GetMarketPairs(ctx context.Context, currencyID uint, slug string, category string, limit uint) (*ImportData, error) {
+	if err := a.err[currencyID]; err != nil {
+		return nil, err
+	}
+	return &ImportData{
+		ExchangeList:   ExchangeList{{ID: 1, Name: "Binance", Slug: "binance"}},
+		MarketPairList: MarketPairList{{CurrencyID: currencyID, ExchangeID: 1, MarketID: currencyID * 10, Category: category}},
+	}, nil
+}
+
+func TestService_Import(t *testing.T) {
+	errApi := errors.New("api error")
+	repo := &fakeMarketPairRepo{}
+	s := NewService(repo, Config{}, &fakeMarketPairsApi{err: map[uint]error{2: errApi}}, workerpool.New(workerpool.Config{WorkersNb: 2}, nil))
+
+	err := s.Import(context.Background(), &currency.CurrencyList{{ID: 1, Slug: "bitcoin"}, {ID: 2, Slug: "ethereum"}, {ID: 3, Slug: "tether"}})
+	if !errors.Is(err, errApi) {
+		t.Fatalf("Import() error = %v, want %v", err, errApi)
+	}
+	if len(repo.pairs) != 2 {
+		t.Fatalf("got %d pairs, want one pair of the currencies 1 and 3: %+v", len(repo.pairs), repo.pairs)
+	}
+	ts := repo.pairs[0].Ts
+	for _, item := range repo.pairs {
+		if item.CurrencyID == 2 {
+			t.Fatalf("the pair of the failed currency is stored: %+v", item)
+		}
+		if !item.Ts.Equal(ts) || ts.IsZero() {
+			t.Fatalf("the pairs of a run are not one snapshot: %+v", repo.pairs)
+		}
+	}
+
+	if err = NewService(repo, Config{}, nil, nil).Import(context.Background(), &currency.CurrencyList{{ID: 1}}); err == nil {
+		t.Fatal("Import() without the API: error is nil")
+	}
+}
@@ -4,7 +4,18 @@ import (
 	"time"
 )
 
-const ()
+// the codes of the blockchains supported by the Oracul API
+const (
+	Blockchain_ETH = "ETH"
+	Blockchain_BNB = "BNB"
+	Blockchain_POL = "POL"
+	Blockchain_FTM = "FTM"
+	Blockchain_OP  = "OP"
+)
+
+// blockchainPriority is the order of the blockchains to choose the canonical token address of a currency;
+// the analytics tables are unique by the currency, so only one blockchain of a currency is imported
+var blockchainPriority = []string{Blockchain_ETH, Blockchain_BNB, Blockchain_POL, Blockchain_FTM, Blockchain_OP}
 
 type OraculAnalytics struct {
 	CurrencyID          uint
@@ -25,3 +36,71 @@ type TokenAddress struct {
 }
 
 type TokenAddressList []TokenAddress
+
+// ImportMaxTime is the checkpoint of the import of a token address: the last imported day of the daily balance stats
+type ImportMaxTime struct {
+	CurrencyID        uint
+	Blockchain        string
+	DailyBalanceStats *time.Time
+}
+
+type ImportMaxTimeKey struct {
+	CurrencyID uint
+	Blockchain string
+}
+
+type ImportMaxTimeMap map[ImportMaxTimeKey]ImportMaxTime
+
+func (e *TokenAddress) ImportMaxTimeKey() ImportMaxTimeKey {
+	return ImportMaxTimeKey{
+		CurrencyID: e.CurrencyID,
+		Blockchain: e.Blockchain,
+	}
+}
+
+func (l *TokenAddressList) CurrencyIDs() *[]uint {
+	res := make([]uint, 0, len(*l))
+	exists := make(map[uint]struct{}, len(*l))
+	var ok bool
+	var item TokenAddress
+	for _, item = range *l {
+		if _, ok = exists[item.CurrencyID]; ok {
+			continue
+		}
+		exists[item.CurrencyID] = struct{}{}
+		res = append(res, item.CurrencyID)
+	}
+	return &res
+}
+
+// Canonical returns one token address of every currency: the one on the blockchain with the highest priority; the blockchains out of the priority list are skipped
+func (l *TokenAddressList) Canonical() TokenAddressList {
+	if l == nil || len(*l) == 0 {
+		return nil
+	}
+
+	rank := make(map[string]int, len(blockchainPriority))
+	for i, blockchain := range blockchainPriority {
+		rank[blockchain] = i
+	}
+
+	res := make(TokenAddressList, 0, len(*l))
+	index := make(map[uint]int, len(*l))
+	var item TokenAddress
+	for _, item = range *l {
+		r, ok := rank[item.Blockchain]
+		if !ok {
+			continue
+		}
+		i, ok := index[item.CurrencyID]
+		if !ok {
+			index[item.CurrencyID] = len(res)
+			res = append(res, item)
+			continue
+		}
+		if r < rank[res[i].Blockchain] {
+			res[i] = item
+		}
+	}
+	return res
+}
@@ -0,0 +1,29 @@
+package oracul_analytics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenAddressList_Canonical(t *testing.T) {
+	l := TokenAddressList{
+		{CurrencyID: 1, Blockchain: Blockchain_BNB, Address: "1-bnb"},
+		{CurrencyID: 2, Blockchain: "SOL", Address: "2-sol"},
+		{CurrencyID: 1, Blockchain: Blockchain_ETH, Address: "1-eth"},
+		{CurrencyID: 3, Blockchain: Blockchain_OP, Address: "3-op"},
+		{CurrencyID: 3, Blockchain: Blockchain_POL, Address: "3-pol"},
+		{CurrencyID: 1, Blockchain: Blockchain_FTM, Address: "1-ftm"},
+	}
+	want := TokenAddressList{
+		{CurrencyID: 1, Blockchain: Blockchain_ETH, Address: "1-eth"},
+		{CurrencyID: 3, Blockchain: Blockchain_POL, Address: "3-pol"},
+	}
+	if got := l.Canonical(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Canonical() = %+v, want %+v", got, want)
+	}
+
+	var empty *TokenAddressList
+	if got := empty.Canonical(); got != nil {
+		t.Fatalf("Canonical() of nil = %+v, want nil", got)
+	}
+}
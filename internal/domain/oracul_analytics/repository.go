@@ -11,7 +11,9 @@ type ReplicaSet interface {
 
 type WriteRepository interface {
 	Upsert(ctx context.Context, entity *OraculAnalytics) error
+	UpsertImportMaxTime(ctx context.Context, entity *ImportMaxTime) error
 }
 
 type ReadRepository interface {
+	MGetImportMaxTime(ctx context.Context, currencyIDs *[]uint) (ImportMaxTimeMap, error)
 }
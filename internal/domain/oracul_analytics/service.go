@@ -2,16 +2,28 @@ package oracul_analytics
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"info/internal/domain"
+	"info/internal/domain/import_run"
 	"info/internal/domain/oracul_daily_balance_stats"
 	"info/internal/domain/oracul_holder_stats"
 	"info/internal/domain/oracul_speedometers"
+	"info/internal/pkg/apperror"
+	"info/internal/pkg/workerpool"
 	"sort"
+	"sync/atomic"
 	"time"
 )
 
+const (
+	workerPoolJob_Import = "oracul_analytics.import"
+
+	defaultImportPeriod = time.Hour * 24 * 365 // the window of the first import of a token address
+)
+
 type OraculAnalyticsAPIClient interface {
-	GetHoldersStats(ctx context.Context, currencyID uint, blockchain string, coinAddress string) (*ImportData, error)
+	GetHoldersStats(ctx context.Context, currencyID uint, blockchain string, coinAddress string, startAt time.Time, endAt time.Time) (*ImportData, error)
 }
 
 type ImportData struct {
@@ -21,6 +33,24 @@ type ImportData struct {
 	OraculDailyBalanceStatsList *oracul_daily_balance_stats.OraculDailyBalanceStatsList
 }
 
+// RowsNb returns the number of the rows of all the tables in the import data
+func (d *ImportData) RowsNb() int {
+	var res int
+	if d.OraculAnalytics != nil {
+		res++
+	}
+	if d.OraculSpeedometers != nil {
+		res++
+	}
+	if d.OraculHolderStats != nil {
+		res++
+	}
+	if d.OraculDailyBalanceStatsList != nil {
+		res += len(*d.OraculDailyBalanceStatsList)
+	}
+	return res
+}
+
 type Service struct {
 	replicaSet               ReplicaSet
 	oraculAnalyticsAPIClient OraculAnalyticsAPIClient
@@ -28,10 +58,11 @@ type Service struct {
 	oraculHolderStats        *oracul_holder_stats.Service
 	oraculDailyBalanceStats  *oracul_daily_balance_stats.Service
 	supportedBlockchains     *sort.StringSlice
+	workerPool               *workerpool.Pool
 }
 
-func NewService(replicaSet ReplicaSet, oraculAnalyticsAPIClient OraculAnalyticsAPIClient, oraculSpeedometers *oracul_speedometers.Service, oraculHolderStats *oracul_holder_stats.Service, oraculDailyBalanceStats *oracul_daily_balance_stats.Service) *Service {
-	s := sort.StringSlice([]string{"ETH", "BNB", "POL", "FTM", "OP"})
+func NewService(replicaSet ReplicaSet, oraculAnalyticsAPIClient OraculAnalyticsAPIClient, oraculSpeedometers *oracul_speedometers.Service, oraculHolderStats *oracul_holder_stats.Service, oraculDailyBalanceStats *oracul_daily_balance_stats.Service, workerPool *workerpool.Pool) *Service {
+	s := sort.StringSlice(append([]string(nil), blockchainPriority...))
 	s.Sort()
 	return &Service{
 		replicaSet:               replicaSet,
@@ -40,6 +71,7 @@ func NewService(replicaSet ReplicaSet, oraculAnalyticsAPIClient OraculAnalyticsA
 		oraculHolderStats:        oraculHolderStats,
 		oraculDailyBalanceStats:  oraculDailyBalanceStats,
 		supportedBlockchains:     &s,
+		workerPool:               workerPool,
 	}
 }
 
@@ -55,33 +87,92 @@ func (s *Service) IsBlockchainSupported(blockchain string) bool {
 	return i < len(*s.supportedBlockchains) && (*s.supportedBlockchains)[i] == blockchain
 }
 
+// Import imports the analytics of the canonical token address of every currency by the worker pool in parallel; the errors of every token address are joined.
+// The daily balance stats are requested only for the window after the checkpoint of the token address.
 func (s *Service) Import(ctx context.Context, tokenAddressList *TokenAddressList) (err error) {
 	if tokenAddressList == nil || len(*tokenAddressList) == 0 {
 		return nil
 	}
-	var tokenAddress TokenAddress
-	var importData *ImportData
 
-	fmt.Println("oracul_analytics.Service.Import - Started:")
-	for _, tokenAddress = range *tokenAddressList {
-		if !s.IsBlockchainSupported(tokenAddress.Blockchain) {
-			continue
-		}
-		fmt.Printf("	%d	...\n", tokenAddress.CurrencyID)
+	supported := tokenAddressList.Canonical()
+	if len(supported) == 0 {
+		return nil
+	}
 
-		time.Sleep(5 * time.Second)
-		importData, err = s.oraculAnalyticsAPIClient.GetHoldersStats(ctx, tokenAddress.CurrencyID, tokenAddress.Blockchain, tokenAddress.Address)
-		if err != nil {
-			fmt.Printf("oraculAnalyticsAPIClient.GetHoldersStats error CurrencyID: %d, error: %w\n", tokenAddress.CurrencyID, err)
-			continue
+	importMaxTimeMap, err := s.replicaSet.ReadRepo().MGetImportMaxTime(ctx, supported.CurrencyIDs())
+	if err != nil {
+		if !errors.Is(err, apperror.ErrNotFound) {
+			return err
 		}
-		if err = s.upsertImportData(ctx, importData); err != nil {
+		importMaxTimeMap = make(ImportMaxTimeMap)
+	}
+
+	var failedNb atomic.Uint64
+	err = s.workerPool.Run(ctx, workerPoolJob_Import, len(supported), func(ctx context.Context, i int) error {
+		importMaxTime := importMaxTimeMap[supported[i].ImportMaxTimeKey()]
+		if err := s.importItem(ctx, &supported[i], importMaxTime.DailyBalanceStats); err != nil {
+			failedNb.Add(1)
 			return err
 		}
+		return nil
+	})
+	import_run.AddCurrencies(ctx, uint(len(supported)), uint(failedNb.Load()))
+
+	return err
+}
+
+func (s *Service) importItem(ctx context.Context, tokenAddress *TokenAddress, maxTime *time.Time) error {
+	endAt := time.Now().UTC().Truncate(time.Hour * 24)
+	startAt := endAt.Add(-defaultImportPeriod)
+	if maxTime != nil {
+		// the last imported day is requested again because it might be not finished at the time of the previous import
+		startAt = maxTime.UTC().Truncate(time.Hour * 24)
+	}
+	if startAt.After(endAt) {
+		startAt = endAt
 	}
-	fmt.Println("oracul_analytics.Service.Import - Complited!")
 
-	return nil
+	importData, err := s.oraculAnalyticsAPIClient.GetHoldersStats(ctx, tokenAddress.CurrencyID, tokenAddress.Blockchain, tokenAddress.Address, startAt, endAt)
+	if err != nil {
+		return fmt.Errorf("oraculAnalyticsAPIClient.GetHoldersStats error CurrencyID: %d, blockchain: %s, error: %w", tokenAddress.CurrencyID, tokenAddress.Blockchain, err)
+	}
+	if err = s.upsertImportData(ctx, importData); err != nil {
+		return fmt.Errorf("upsertImportData error CurrencyID: %d, blockchain: %s, error: %w", tokenAddress.CurrencyID, tokenAddress.Blockchain, err)
+	}
+
+	newMaxTime := importData.OraculDailyBalanceStatsList.MaxD()
+	if newMaxTime == nil || (maxTime != nil && !newMaxTime.After(*maxTime)) {
+		return nil
+	}
+
+	return s.replicaSet.WriteRepo().UpsertImportMaxTime(ctx, &ImportMaxTime{
+		CurrencyID:        tokenAddress.CurrencyID,
+		Blockchain:        tokenAddress.Blockchain,
+		DailyBalanceStats: newMaxTime,
+	})
+}
+
+// BackfillItem imports the daily balance stats of the token address for the window [from, to) and upserts only the items inside the window
+func (s *Service) BackfillItem(ctx context.Context, tokenAddress *TokenAddress, from time.Time, to time.Time) (*domain.UpsertStats, error) {
+	if !s.IsBlockchainSupported(tokenAddress.Blockchain) {
+		return nil, fmt.Errorf("[%w] blockchain %q is not supported", apperror.ErrBadRequest, tokenAddress.Blockchain)
+	}
+
+	endAt := to.Add(-time.Hour * 24)
+	if endAt.Before(from) {
+		endAt = from
+	}
+	importData, err := s.oraculAnalyticsAPIClient.GetHoldersStats(ctx, tokenAddress.CurrencyID, tokenAddress.Blockchain, tokenAddress.Address, from, endAt)
+	if err != nil {
+		return nil, fmt.Errorf("oraculAnalyticsAPIClient.GetHoldersStats error CurrencyID: %d, blockchain: %s, error: %w", tokenAddress.CurrencyID, tokenAddress.Blockchain, err)
+	}
+
+	return s.oraculDailyBalanceStats.MUpsertWithStats(ctx, importData.OraculDailyBalanceStatsList.FilterByTime(from, to))
+}
+
+// Reparse upserts the data converted again from an archived response
+func (s *Service) Reparse(ctx context.Context, importData *ImportData) error {
+	return s.upsertImportData(ctx, importData)
 }
 
 func (s *Service) upsertImportData(ctx context.Context, importData *ImportData) (err error) {
@@ -90,24 +181,28 @@ func (s *Service) upsertImportData(ctx context.Context, importData *ImportData)
 		if err = s.replicaSet.WriteRepo().Upsert(ctx, importData.OraculAnalytics); err != nil {
 			return err
 		}
+		import_run.AddRows(ctx, 1)
 	}
 
 	if importData.OraculSpeedometers != nil {
 		if err = s.oraculSpeedometers.Create(ctx, importData.OraculSpeedometers); err != nil {
 			return err
 		}
+		import_run.AddRows(ctx, 1)
 	}
 
 	if importData.OraculHolderStats != nil {
 		if err = s.oraculHolderStats.Create(ctx, importData.OraculHolderStats); err != nil {
 			return err
 		}
+		import_run.AddRows(ctx, 1)
 	}
 
 	if importData.OraculDailyBalanceStatsList != nil && len(*importData.OraculDailyBalanceStatsList) > 0 {
 		if err = s.oraculDailyBalanceStats.MCreate(ctx, importData.OraculDailyBalanceStatsList); err != nil {
 			return err
 		}
+		import_run.AddRows(ctx, len(*importData.OraculDailyBalanceStatsList))
 	}
 
 	return nil
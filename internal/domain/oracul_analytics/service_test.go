@@ -0,0 +1,224 @@
+package oracul_analytics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"info/internal/domain"
+	"info/internal/domain/oracul_daily_balance_stats"
+	"info/internal/pkg/workerpool"
+)
+
+type fakeReplicaSet struct {
+	mu           sync.Mutex
+	maxTimeMap   ImportMaxTimeMap
+	upsertErr    error
+	upserted     []OraculAnalytics
+	maxTimeCalls []ImportMaxTime
+}
+
+func (r *fakeReplicaSet) WriteRepo() WriteRepository { return r }
+func (r *fakeReplicaSet) ReadRepo() ReadRepository   { return r }
+
+func (r *fakeReplicaSet) Upsert(ctx context.Context, entity *OraculAnalytics) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.upsertErr != nil {
+		return r.upsertErr
+	}
+	r.upserted = append(r.upserted, *entity)
+	return nil
+}
+
+func (r *fakeReplicaSet) UpsertImportMaxTime(ctx context.Context, entity *ImportMaxTime) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxTimeCalls = append(r.maxTimeCalls, *entity)
+	return nil
+}
+
+func (r *fakeReplicaSet) MGetImportMaxTime(ctx context.Context, currencyIDs *[]uint) (ImportMaxTimeMap, error) {
+	return r.maxTimeMap, nil
+}
+
+type fakeDailyBalanceStatsReplicaSet struct{}
+
+func (r *fakeDailyBalanceStatsReplicaSet) WriteRepo() oracul_daily_balance_stats.WriteRepository {
+	return r
+}
+
+func (r *fakeDailyBalanceStatsReplicaSet) ReadRepo() oracul_daily_balance_stats.ReadRepository {
+	return r
+}
+
+func (r *fakeDailyBalanceStatsReplicaSet) Begin(ctx context.Context) (domain.Tx, error) {
+	return nil, nil
+}
+
+func (r *fakeDailyBalanceStatsReplicaSet) MUpsert(ctx context.Context, entities *oracul_daily_balance_stats.OraculDailyBalanceStatsList) error {
+	return nil
+}
+
+func (r *fakeDailyBalanceStatsReplicaSet) MUpsertWithStats(ctx context.Context, entities *oracul_daily_balance_stats.OraculDailyBalanceStatsList) (*domain.UpsertStats, error) {
+	return &domain.UpsertStats{Inserted: uint(len(*entities))}, nil
+}
+
+func (r *fakeDailyBalanceStatsReplicaSet) MGet(ctx context.Context, currencyIDs *[]uint, from time.Time) (oracul_daily_balance_stats.OraculDailyBalanceStatsMap, error) {
+	return nil, nil
+}
+
+// fakeOraculAnalyticsAPIClient returns the daily balance stats of the days of the response of the currency
+type fakeOraculAnalyticsAPIClient struct {
+	mu      sync.Mutex
+	days    map[uint][]time.Time
+	err     map[uint]error
+	startAt map[uint]time.Time
+}
+
+func (c *fakeOraculAnalyticsAPIClient) GetHoldersStats(ctx context.Context, currencyID uint, blockchain string, coinAddress string, startAt time.Time, endAt time.Time) (*ImportData, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.startAt[currencyID] = startAt
+	if err := c.err[currencyID]; err != nil {
+		return nil, err
+	}
+	list := make(oracul_daily_balance_stats.OraculDailyBalanceStatsList, 0, len(c.days[currencyID]))
+	for _, d := range c.days[currencyID] {
+		list = append(list, oracul_daily_balance_stats.OraculDailyBalanceStats{CurrencyID: currencyID, D: d})
+	}
+	return &ImportData{
+		OraculAnalytics:             &OraculAnalytics{CurrencyID: currencyID, Ts: endAt},
+		OraculDailyBalanceStatsList: &list,
+	}, nil
+}
+
+func TestService_ImportCheckpoint(t *testing.T) {
+	today := time.Now().UTC().Truncate(time.Hour * 24)
+	day := func(n int) time.Time { return today.AddDate(0, 0, n) }
+	ptr := func(t time.Time) *time.Time { return &t }
+	errAPI := errors.New("api error")
+	errUpsert := errors.New("upsert error")
+
+	tests := []struct {
+		name        string
+		maxTime     *time.Time
+		days        []time.Time
+		apiErr      error
+		upsertErr   error
+		wantStartAt time.Time
+		wantMaxTime *time.Time
+		wantErr     error
+	}{
+		{
+			name:        "the first import",
+			days:        []time.Time{day(-3), day(-1), day(-2)},
+			wantStartAt: today.Add(-defaultImportPeriod),
+			wantMaxTime: ptr(day(-1)),
+		},
+		{
+			name:        "the checkpoint is advanced",
+			maxTime:     ptr(day(-3)),
+			days:        []time.Time{day(-3), day(-2), day(0)},
+			wantStartAt: day(-3),
+			wantMaxTime: ptr(day(0)),
+		},
+		{
+			name:        "no new days",
+			maxTime:     ptr(day(-1)),
+			days:        []time.Time{day(-1)},
+			wantStartAt: day(-1),
+		},
+		{
+			name:        "no days",
+			wantStartAt: today.Add(-defaultImportPeriod),
+		},
+		{
+			name:        "the error of the api",
+			maxTime:     ptr(day(-3)),
+			apiErr:      errAPI,
+			wantStartAt: day(-3),
+			wantErr:     errAPI,
+		},
+		{
+			name:        "the error of the upsert",
+			maxTime:     ptr(day(-3)),
+			days:        []time.Time{day(-2), day(-1)},
+			upsertErr:   errUpsert,
+			wantStartAt: day(-3),
+			wantErr:     errUpsert,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const currencyID = 1
+			repo := &fakeReplicaSet{maxTimeMap: ImportMaxTimeMap{}, upsertErr: tt.upsertErr}
+			if tt.maxTime != nil {
+				key := ImportMaxTimeKey{CurrencyID: currencyID, Blockchain: Blockchain_ETH}
+				repo.maxTimeMap[key] = ImportMaxTime{CurrencyID: currencyID, Blockchain: Blockchain_ETH, DailyBalanceStats: tt.maxTime}
+			}
+			client := &fakeOraculAnalyticsAPIClient{
+				days:    map[uint][]time.Time{currencyID: tt.days},
+				err:     map[uint]error{currencyID: tt.apiErr},
+				startAt: map[uint]time.Time{},
+			}
+			s := NewService(repo, client, nil, nil, oracul_daily_balance_stats.NewService(&fakeDailyBalanceStatsReplicaSet{}), workerpool.New(workerpool.Config{WorkersNb: 2}, nil))
+
+			err := s.Import(context.Background(), &TokenAddressList{
+				{CurrencyID: currencyID, Blockchain: Blockchain_BNB, Address: "1-bnb"},
+				{CurrencyID: currencyID, Blockchain: Blockchain_ETH, Address: "1-eth"},
+			})
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("Import() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := client.startAt[currencyID]; !got.Equal(tt.wantStartAt) {
+				t.Fatalf("got startAt %v, want %v", got, tt.wantStartAt)
+			}
+
+			if tt.wantMaxTime == nil {
+				if len(repo.maxTimeCalls) != 0 {
+					t.Fatalf("got the checkpoint upserts %+v, want none", repo.maxTimeCalls)
+				}
+				return
+			}
+			if len(repo.maxTimeCalls) != 1 {
+				t.Fatalf("got %d checkpoint upserts, want 1", len(repo.maxTimeCalls))
+			}
+			got := repo.maxTimeCalls[0]
+			if got.CurrencyID != currencyID || got.Blockchain != Blockchain_ETH || got.DailyBalanceStats == nil || !got.DailyBalanceStats.Equal(*tt.wantMaxTime) {
+				t.Fatalf("got the checkpoint %+v, want %v of %s", got, *tt.wantMaxTime, Blockchain_ETH)
+			}
+		})
+	}
+}
+
+func TestService_ImportErrors(t *testing.T) {
+	errAPI := errors.New("api error")
+	day := time.Now().UTC().Truncate(time.Hour*24).AddDate(0, 0, -1)
+	repo := &fakeReplicaSet{maxTimeMap: ImportMaxTimeMap{}}
+	client := &fakeOraculAnalyticsAPIClient{
+		days:    map[uint][]time.Time{1: {day}, 3: {day}},
+		err:     map[uint]error{2: errAPI},
+		startAt: map[uint]time.Time{},
+	}
+	s := NewService(repo, client, nil, nil, oracul_daily_balance_stats.NewService(&fakeDailyBalanceStatsReplicaSet{}), workerpool.New(workerpool.Config{WorkersNb: 1}, nil))
+
+	err := s.Import(context.Background(), &TokenAddressList{
+		{CurrencyID: 1, Blockchain: Blockchain_ETH, Address: "1-eth"},
+		{CurrencyID: 2, Blockchain: Blockchain_ETH, Address: "2-eth"},
+		{CurrencyID: 3, Blockchain: Blockchain_BNB, Address: "3-bnb"},
+	})
+	if !errors.Is(err, errAPI) {
+		t.Fatalf("Import() error = %v, want %v", err, errAPI)
+	}
+	if len(repo.maxTimeCalls) != 2 {
+		t.Fatalf("got the checkpoint upserts %+v, want the checkpoints of the currencies 1 and 3", repo.maxTimeCalls)
+	}
+	for _, item := range repo.maxTimeCalls {
+		if item.CurrencyID == 2 {
+			t.Fatalf("the checkpoint of the failed currency is advanced: %+v", item)
+		}
+	}
+}
@@ -22,3 +22,35 @@ func (e *OraculDailyBalanceStats) Validate() error {
 }
 
 type OraculDailyBalanceStatsList []OraculDailyBalanceStats
+
+// MaxD returns the last day of the list or nil for an empty list
+func (l *OraculDailyBalanceStatsList) MaxD() *time.Time {
+	if l == nil || len(*l) == 0 {
+		return nil
+	}
+	res := (*l)[0].D
+	var item OraculDailyBalanceStats
+	for _, item = range *l {
+		if item.D.After(res) {
+			res = item.D
+		}
+	}
+	return &res
+}
+
+// FilterByTime returns the items inside the window [from, to)
+func (l *OraculDailyBalanceStatsList) FilterByTime(from time.Time, to time.Time) *OraculDailyBalanceStatsList {
+	if l == nil {
+		return nil
+	}
+	res := make(OraculDailyBalanceStatsList, 0, len(*l))
+	var item OraculDailyBalanceStats
+	for _, item = range *l {
+		if !item.D.Before(from) && item.D.Before(to) {
+			res = append(res, item)
+		}
+	}
+	return &res
+}
+
+type OraculDailyBalanceStatsMap map[uint]OraculDailyBalanceStatsList
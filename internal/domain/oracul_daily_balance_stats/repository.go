@@ -3,6 +3,7 @@ package oracul_daily_balance_stats
 import (
 	"context"
 	"info/internal/domain"
+	"time"
 )
 
 type ReplicaSet interface {
@@ -13,7 +14,9 @@ type ReplicaSet interface {
 type WriteRepository interface {
 	Begin(ctx context.Context) (domain.Tx, error)
 	MUpsert(ctx context.Context, entities *OraculDailyBalanceStatsList) error
+	MUpsertWithStats(ctx context.Context, entities *OraculDailyBalanceStatsList) (*domain.UpsertStats, error)
 }
 
 type ReadRepository interface {
+	MGet(ctx context.Context, currencyIDs *[]uint, from time.Time) (OraculDailyBalanceStatsMap, error)
 }
@@ -2,6 +2,8 @@ package oracul_daily_balance_stats
 
 import (
 	"context"
+	"info/internal/domain"
+	"time"
 )
 
 type Service struct {
@@ -17,3 +19,12 @@ func NewService(replicaSet ReplicaSet) *Service {
 func (s *Service) MCreate(ctx context.Context, entities *OraculDailyBalanceStatsList) error {
 	return s.replicaSet.WriteRepo().MUpsert(ctx, entities)
 }
+
+func (s *Service) MUpsertWithStats(ctx context.Context, entities *OraculDailyBalanceStatsList) (*domain.UpsertStats, error) {
+	return s.replicaSet.WriteRepo().MUpsertWithStats(ctx, entities)
+}
+
+// MGet returns the stats of the currencies from the day sorted by the day desc
+func (s *Service) MGet(ctx context.Context, currencyIDs *[]uint, from time.Time) (OraculDailyBalanceStatsMap, error) {
+	return s.replicaSet.ReadRepo().MGet(ctx, currencyIDs, from)
+}
@@ -4,9 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"info/internal/domain/import_run"
 	"info/internal/pkg/apperror"
 	"runtime/debug"
-	"time"
 )
 
 type CmcApi interface {
@@ -73,11 +73,15 @@ func (s *Service) importItem(ctx context.Context, portfolioSourceID string) (err
 		}
 	}()
 
-	time.Sleep(3 * time.Second)
 	l, err := s.cmcApi.GetPortfolioSummary(ctx, portfolioSourceID)
 	if err != nil {
 		return fmt.Errorf("[%w] cmcApi.GetPortfolioSummary error: %w", apperror.ErrInternal, err)
 	}
 
-	return s.replicaSet.WriteRepo().MUpsert(ctx, l)
+	if err = s.replicaSet.WriteRepo().MUpsert(ctx, l); err != nil {
+		return err
+	}
+	import_run.AddRows(ctx, len(*l))
+
+	return nil
 }
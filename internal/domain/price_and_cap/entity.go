@@ -1,15 +1,53 @@
 package price_and_cap
 
-import "time"
+import (
+	"time"
 
-const ()
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	Source_Cmc     = "cmc"
+	Source_Binance = "binance"
+	// Source_Coingecko is only a reference for the reconciliation, it is not stored
+	Source_Coingecko = "coingecko"
+
+	// the intervals of the candles of the continuous aggregates cmc.price_and_cap_1h, _1d and _1w
+	Interval_1H = "1h"
+	Interval_1D = "1d"
+	Interval_1W = "1w"
+)
+
+var IntervalList = []interface{}{
+	Interval_1H,
+	Interval_1D,
+	Interval_1W,
+}
+
+func IntervalValidate(s string) error {
+	return validation.Validate(s, validation.Required, validation.In(IntervalList...))
+}
+
+// IntervalDuration returns the duration of the candle of the interval or 0 for an unknown interval
+func IntervalDuration(interval string) time.Duration {
+	switch interval {
+	case Interval_1H:
+		return time.Hour
+	case Interval_1D:
+		return time.Hour * 24
+	case Interval_1W:
+		return time.Hour * 24 * 7
+	}
+	return 0
+}
 
 type PriceAndCap struct {
 	CurrencyID  uint
 	Price       float64
 	DailyVolume float64
-	Cap         float64
+	Cap         float64 // 0 - unknown, it is stored as NULL
 	Ts          time.Time
+	Source      string // the API the item was received from
 }
 
 func (e *PriceAndCap) Validate() error {
@@ -26,6 +64,32 @@ func (l *PriceAndCapList) Slice() *[]PriceAndCap {
 	return &res
 }
 
+// SetSource sets the source of all the items
+func (l *PriceAndCapList) SetSource(source string) *PriceAndCapList {
+	if l == nil {
+		return nil
+	}
+	for i := range *l {
+		(*l)[i].Source = source
+	}
+	return l
+}
+
+// FilterByTime returns the items inside the window [from, to)
+func (l *PriceAndCapList) FilterByTime(from time.Time, to time.Time) *PriceAndCapList {
+	if l == nil {
+		return nil
+	}
+	res := make(PriceAndCapList, 0, len(*l))
+	var item PriceAndCap
+	for _, item = range *l {
+		if !item.Ts.Before(from) && item.Ts.Before(to) {
+			res = append(res, item)
+		}
+	}
+	return &res
+}
+
 func (l *PriceAndCapList) MaxTime() *time.Time {
 	if l == nil || len(*l) == 0 {
 		return nil
@@ -40,6 +104,22 @@ func (l *PriceAndCapList) MaxTime() *time.Time {
 	return &max
 }
 
+// TimeRange returns the min and the max time of the items; zero times for the empty list
+func (l *PriceAndCapList) TimeRange() (from time.Time, to time.Time) {
+	if l == nil {
+		return
+	}
+	for i, item := range *l {
+		if i == 0 || item.Ts.Before(from) {
+			from = item.Ts
+		}
+		if i == 0 || item.Ts.After(to) {
+			to = item.Ts
+		}
+	}
+	return
+}
+
 func (l *PriceAndCapList) AvgInDay(d time.Time) *PriceAndCap {
 	if l == nil || len(*l) == 0 || d.IsZero() {
 		return nil
@@ -82,3 +162,20 @@ func (l *PriceAndCapList) Avg() *PriceAndCap {
 }
 
 type PriceAndCapMap map[uint]PriceAndCapList
+
+// Candle is the OHLCV of the points of a currency inside [Ts, Ts + interval); the weeks start on Monday;
+// Volume is the average of the daily volumes, because the daily volume is the rolling volume of 24 hours
+type Candle struct {
+	CurrencyID uint
+	Open       float64
+	High       float64
+	Low        float64
+	Close      float64
+	Volume     float64
+	Cap        float64
+	Ts         time.Time
+}
+
+type CandleList []Candle
+
+type CandleMap map[uint]CandleList
@@ -0,0 +1,24 @@
+package price_and_cap
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPriceAndCapList_TimeRange(t *testing.T) {
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	list := PriceAndCapList{
+		{Price: 1, Ts: ts.Add(time.Hour)},
+		{Price: 2, Ts: ts.Add(time.Hour * 3)},
+		{Price: 3, Ts: ts},
+	}
+	from, to := list.TimeRange()
+	if !from.Equal(ts) || !to.Equal(ts.Add(time.Hour*3)) {
+		t.Errorf("got [%v, %v]", from, to)
+	}
+
+	empty := PriceAndCapList{}
+	if from, to = empty.TimeRange(); !from.IsZero() || !to.IsZero() {
+		t.Errorf("empty: got [%v, %v]", from, to)
+	}
+}
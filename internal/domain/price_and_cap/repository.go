@@ -3,6 +3,7 @@ package price_and_cap
 import (
 	"context"
 	"info/internal/domain"
+	"time"
 )
 
 type ReplicaSet interface {
@@ -13,8 +14,13 @@ type ReplicaSet interface {
 type WriteRepository interface {
 	Upsert(ctx context.Context, entity *PriceAndCap) (err error)
 	MUpsertTx(ctx context.Context, tx domain.Tx, entities *[]PriceAndCap) error
+	MUpsertWithStats(ctx context.Context, entities *[]PriceAndCap) (*domain.UpsertStats, error)
+	RefreshCandles(ctx context.Context, from time.Time, to time.Time) error
 }
 
 type ReadRepository interface {
 	MGet(ctx context.Context, currencyIDs *[]uint) (PriceAndCapMap, error)
+	GetList(ctx context.Context, currencyID uint, from time.Time, to time.Time) (*PriceAndCapList, error)
+	GetCandles(ctx context.Context, currencyID uint, interval string, from time.Time, to time.Time) (*CandleList, error)
+	MGetCandles(ctx context.Context, currencyIDs *[]uint, interval string, from time.Time, to time.Time) (CandleMap, error)
 }
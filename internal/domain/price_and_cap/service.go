@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"info/internal/domain"
+	"info/internal/domain/import_run"
 	"info/internal/pkg/apperror"
 	"runtime/debug"
 	"time"
@@ -12,30 +13,56 @@ import (
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
-type CmcApi interface {
+// Source is an API which provides the chart of the price, the volume and the cap of a currency
+type Source interface {
 	GetDetailChart(ctx context.Context, CurrencyID uint, Range string) (*PriceAndCapList, error)
 }
 
+// Config sets the order in which the sources are requested; the next source is a fallback for the previous one
+type Config struct {
+	Sources []string // empty - DefaultSourceList
+}
+
 type Service struct {
 	replicaSet ReplicaSet
-	cmcApi     CmcApi
+	sources    []string
+	sourceMap  map[string]Source
 }
 
-func NewService(replicaSet ReplicaSet, cmcApi CmcApi) *Service {
+// NewService creates the service; the sources of the config which are absent in the sourceMap are skipped
+func NewService(replicaSet ReplicaSet, cfg Config, sourceMap map[string]Source) *Service {
+	names := cfg.Sources
+	if len(names) == 0 {
+		names = DefaultSourceList
+	}
+	sources := make([]string, 0, len(names))
+	var name string
+	for _, name = range names {
+		if _, ok := sourceMap[name]; ok {
+			sources = append(sources, name)
+		}
+	}
 	return &Service{
 		replicaSet: replicaSet,
-		cmcApi:     cmcApi,
+		sources:    sources,
+		sourceMap:  sourceMap,
 	}
 }
 
 const (
 	defaultCapacity = 100
+	maxCandles      = 10000
 
 	TimeRange_1M  = "1M"
 	TimeRange_1Y  = "1Y"
 	TimeRange_All = "All"
 )
 
+var DefaultSourceList = []string{
+	Source_Cmc,
+	Source_Binance,
+}
+
 var TimeRangeList = []interface{}{
 	TimeRange_1M,
 	TimeRange_1Y,
@@ -46,10 +73,47 @@ func TimeRangeValidate(s string) error {
 	return validation.Validate(s, validation.Required, validation.In(TimeRangeList...))
 }
 
+// TimeRangeForWindow returns the shortest time range of the chart which contains the window starting from the time
+func TimeRangeForWindow(from time.Time) string {
+	now := time.Now()
+	switch {
+	case from.After(now.Add(-time.Hour * 24 * 30)):
+		return TimeRange_1M
+	case from.After(now.Add(-time.Hour * 24 * 365)):
+		return TimeRange_1Y
+	}
+	return TimeRange_All
+}
+
+// getDetailChart requests the chart from the sources in the order of the fallback and returns the first received one; the items are marked with the source
+func (s *Service) getDetailChart(ctx context.Context, currencyID uint, timeRange string) (*PriceAndCapList, error) {
+	if len(s.sources) == 0 {
+		return nil, fmt.Errorf("[%w] price_and_cap.Service: there is no source", apperror.ErrInternal)
+	}
+	errs := make([]error, 0, len(s.sources))
+	var name string
+	for _, name = range s.sources {
+		item, err := s.sourceMap[name].GetDetailChart(ctx, currencyID, timeRange)
+		if err == nil {
+			return item.SetSource(name), nil
+		}
+		errs = append(errs, fmt.Errorf("source %s: %w", name, err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
 func (s *Service) MGet(ctx context.Context, currencyIDs *[]uint) (PriceAndCapMap, error) {
 	return s.replicaSet.ReadRepo().MGet(ctx, currencyIDs)
 }
 
+// GetList returns the stored items of the currency inside the window [from, to) in order of the time
+func (s *Service) GetList(ctx context.Context, currencyID uint, from time.Time, to time.Time) (*PriceAndCapList, error) {
+	return s.replicaSet.ReadRepo().GetList(ctx, currencyID, from, to)
+}
+
 func (s *Service) Upsert(ctx context.Context, entity *PriceAndCap) error {
 	return s.replicaSet.WriteRepo().Upsert(ctx, entity)
 }
@@ -105,7 +169,7 @@ func (s *Service) importTx(ctx context.Context, tx domain.Tx, currencyID uint, t
 		return nil, err
 	}
 
-	item, err := s.cmcApi.GetDetailChart(ctx, currencyID, timeRange)
+	item, err := s.getDetailChart(ctx, currencyID, timeRange)
 	if err != nil {
 		return nil, err
 	}
@@ -113,5 +177,65 @@ func (s *Service) importTx(ctx context.Context, tx domain.Tx, currencyID uint, t
 	if err = s.replicaSet.WriteRepo().MUpsertTx(ctx, tx, item.Slice()); err != nil {
 		return nil, err
 	}
+	import_run.AddRows(ctx, len(*item))
+
 	return item.MaxTime(), nil
 }
+
+// Backfill imports the chart of the time range containing the window and upserts only the items inside the window [from, to)
+func (s *Service) Backfill(ctx context.Context, currencyID uint, from time.Time, to time.Time) (stats *domain.UpsertStats, err error) {
+	const metricName = "price_and_cap.Service.Backfill"
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Join(err, fmt.Errorf("[%w] "+metricName+" Recover from panic: %v; stacktrace from panic: %s", apperror.ErrInternal, r, string(debug.Stack())))
+		}
+	}()
+
+	item, err := s.getDetailChart(ctx, currencyID, TimeRangeForWindow(from))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.replicaSet.WriteRepo().MUpsertWithStats(ctx, item.FilterByTime(from, to).Slice())
+}
+
+// Reparse upserts the items converted again from an archived response
+func (s *Service) Reparse(ctx context.Context, list *PriceAndCapList) (*domain.UpsertStats, error) {
+	if list == nil || len(*list) == 0 {
+		return &domain.UpsertStats{}, nil
+	}
+	stats, err := s.replicaSet.WriteRepo().MUpsertWithStats(ctx, list.Slice())
+	if err != nil {
+		return nil, err
+	}
+	import_run.AddRows(ctx, len(*list))
+	return stats, nil
+}
+
+// RefreshCandles recalculates the candles of all the intervals in the window [from, to);
+// the refresh policies look only a few days back, so it is called after the backfill and the reparse of the older items
+func (s *Service) RefreshCandles(ctx context.Context, from time.Time, to time.Time) error {
+	return s.replicaSet.WriteRepo().RefreshCandles(ctx, from, to)
+}
+
+// GetCandles returns the candles of the interval which start inside the window [from, to)
+func (s *Service) GetCandles(ctx context.Context, currencyID uint, interval string, from time.Time, to time.Time) (*CandleList, error) {
+	if err := IntervalValidate(interval); err != nil {
+		return nil, fmt.Errorf("[%w] interval: %w", apperror.ErrBadRequest, err)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("[%w] from must be before to", apperror.ErrBadRequest)
+	}
+	if to.Sub(from)/IntervalDuration(interval) > maxCandles {
+		return nil, fmt.Errorf("[%w] too many candles; max: %d", apperror.ErrBadRequest, maxCandles)
+	}
+	return s.replicaSet.ReadRepo().GetCandles(ctx, currencyID, interval, from, to)
+}
+
+// MGetCandles returns the candles of the interval of the currencies which start inside the window [from, to)
+func (s *Service) MGetCandles(ctx context.Context, currencyIDs *[]uint, interval string, from time.Time, to time.Time) (CandleMap, error) {
+	if err := IntervalValidate(interval); err != nil {
+		return nil, fmt.Errorf("[%w] interval: %w", apperror.ErrBadRequest, err)
+	}
+	return s.replicaSet.ReadRepo().MGetCandles(ctx, currencyIDs, interval, from, to)
+}
@@ -0,0 +1,73 @@
+package price_and_cap
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"info/internal/pkg/apperror"
+)
+
+// fakeSource returns one item or the error and counts the calls
+type fakeSource struct {
+	err   error
+	calls int
+}
+
+func (s *fakeSource) GetDetailChart(ctx context.Context, currencyID uint, tRange string) (*PriceAndCapList, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &PriceAndCapList{{CurrencyID: currencyID, Price: 1, Ts: time.Now()}}, nil
+}
+
+func TestService_getDetailChart(t *testing.T) {
+	tests := []struct {
+		name       string
+		sources    []string
+		cmcErr     error
+		binanceErr error
+		noBinance  bool
+		wantSource string
+		wantErr    error
+		wantCalls  [2]int // cmc, binance
+	}{
+		{name: "the first source", wantSource: Source_Cmc, wantCalls: [2]int{1, 0}},
+		{name: "fallback", cmcErr: apperror.ErrUpstreamDown, wantSource: Source_Binance, wantCalls: [2]int{1, 1}},
+		{name: "the chosen order", sources: []string{Source_Binance, Source_Cmc}, wantSource: Source_Binance, wantCalls: [2]int{0, 1}},
+		{name: "the only chosen source", sources: []string{Source_Cmc}, cmcErr: apperror.ErrRateLimited, wantErr: apperror.ErrRateLimited, wantCalls: [2]int{1, 0}},
+		{name: "all the sources fail", cmcErr: apperror.ErrUpstreamDown, binanceErr: apperror.ErrNotFound, wantErr: apperror.ErrNotFound, wantCalls: [2]int{1, 1}},
+		{name: "not configured source is skipped", cmcErr: apperror.ErrUpstreamDown, noBinance: true, wantErr: apperror.ErrUpstreamDown, wantCalls: [2]int{1, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmc := &fakeSource{err: tt.cmcErr}
+			binance := &fakeSource{err: tt.binanceErr}
+			sourceMap := map[string]Source{Source_Cmc: cmc}
+			if !tt.noBinance {
+				sourceMap[Source_Binance] = binance
+			}
+			s := NewService(nil, Config{Sources: tt.sources}, sourceMap)
+
+			list, err := s.getDetailChart(context.Background(), 1, TimeRange_1M)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("got error %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("getDetailChart error: %v", err)
+			} else if (*list)[0].Source != tt.wantSource {
+				t.Errorf("got source %q, want %q", (*list)[0].Source, tt.wantSource)
+			}
+			if cmc.calls != tt.wantCalls[0] || binance.calls != tt.wantCalls[1] {
+				t.Errorf("got calls cmc: %d, binance: %d; want %v", cmc.calls, binance.calls, tt.wantCalls)
+			}
+		})
+	}
+
+	if _, err := NewService(nil, Config{}, nil).getDetailChart(context.Background(), 1, TimeRange_1M); !errors.Is(err, apperror.ErrInternal) {
+		t.Errorf("without sources: got error %v, want %v", err, apperror.ErrInternal)
+	}
+}
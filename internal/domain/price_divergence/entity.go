@@ -0,0 +1,37 @@
+package price_divergence
+
+import "time"
+
+// PriceDivergence is a day when the stored price or cap differs from the reference source beyond the threshold
+type PriceDivergence struct {
+	CurrencyID uint
+	Day        time.Time
+	Source     string // the reference source
+	Price      float64
+	RefPrice   float64
+	PriceDiff  float64 // |Price - RefPrice| / RefPrice
+	Cap        float64
+	RefCap     float64
+	CapDiff    float64 // 0 - one of the caps is unknown
+	CheckedAt  time.Time
+}
+
+type PriceDivergenceList []PriceDivergence
+
+// Filter selects the divergences of the days in [From, To); empty CurrencyIDs means all the currencies
+type Filter struct {
+	CurrencyIDs []uint
+	From        time.Time
+	To          time.Time
+}
+
+// ReconcileResult is the result of the reconciliation of a currency
+type ReconcileResult struct {
+	CurrencyID uint
+	Slug       string
+	Compared   uint // the days found in both series
+	Diverged   uint
+	Err        error
+}
+
+type ReconcileResultList []ReconcileResult
@@ -0,0 +1,20 @@
+package price_divergence
+
+import (
+	"context"
+	"time"
+)
+
+type ReplicaSet interface {
+	WriteRepo() WriteRepository
+	ReadRepo() ReadRepository
+}
+
+type WriteRepository interface {
+	// Replace replaces the divergences of the currency and the source for the days in [from, to)
+	Replace(ctx context.Context, currencyID uint, source string, from time.Time, to time.Time, entities *PriceDivergenceList) error
+}
+
+type ReadRepository interface {
+	GetList(ctx context.Context, filter *Filter, limit uint) (*PriceDivergenceList, error)
+}
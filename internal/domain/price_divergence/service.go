@@ -0,0 +1,229 @@
+package price_divergence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"info/internal/domain/currency"
+	"info/internal/domain/import_run"
+	"info/internal/domain/price_and_cap"
+	"info/internal/pkg/apperror"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	defaultDays           = 30
+	maxDays               = 3650 // 10 fields of the divergences of 3650 days fit the max number of the params of one insert
+	defaultPriceThreshold = 0.03
+	defaultCapThreshold   = 0.05
+	defaultMaxTimeGap     = 2 * time.Hour
+	defaultLimit          = 50
+	maxLimit              = 1000
+	day                   = 24 * time.Hour
+)
+
+// ReferenceSource is the second opinion about the price and the cap
+type ReferenceSource interface {
+	GetMarketChart(ctx context.Context, currencyID uint, slug string, days uint) (*price_and_cap.PriceAndCapList, error)
+}
+
+type Config struct {
+	Days           uint          // the number of the last days which are checked; 0 - 30, max 3650
+	PriceThreshold float64       // the relative difference of the price which is a divergence; 0 - 0.03
+	CapThreshold   float64       // the relative difference of the cap which is a divergence; 0 - 0.05
+	MaxTimeGap     time.Duration // the max distance between the stored point and the reference one; 0 - 2h
+}
+
+type Service struct {
+	replicaSet  ReplicaSet
+	config      Config
+	priceAndCap *price_and_cap.Service
+	reference   ReferenceSource
+}
+
+// NewService creates the service; without the reference source the reconciliation is unavailable, but the report works
+func NewService(replicaSet ReplicaSet, cfg Config, priceAndCap *price_and_cap.Service, reference ReferenceSource) *Service {
+	if cfg.Days == 0 {
+		cfg.Days = defaultDays
+	}
+	if cfg.Days > maxDays {
+		cfg.Days = maxDays
+	}
+	if cfg.PriceThreshold == 0 {
+		cfg.PriceThreshold = defaultPriceThreshold
+	}
+	if cfg.CapThreshold == 0 {
+		cfg.CapThreshold = defaultCapThreshold
+	}
+	if cfg.MaxTimeGap == 0 {
+		cfg.MaxTimeGap = defaultMaxTimeGap
+	}
+	return &Service{
+		replicaSet:  replicaSet,
+		config:      cfg,
+		priceAndCap: priceAndCap,
+		reference:   reference,
+	}
+}
+
+// GetList returns the divergences, the biggest first
+func (s *Service) GetList(ctx context.Context, filter *Filter, limit uint) (*PriceDivergenceList, error) {
+	if limit == 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	return s.replicaSet.ReadRepo().GetList(ctx, filter, limit)
+}
+
+// Reconcile compares the stored series of the currencies with the reference source; an error of a currency does not stop the others
+func (s *Service) Reconcile(ctx context.Context, currencyList *currency.CurrencyList) (*ReconcileResultList, error) {
+	if s.reference == nil {
+		return nil, fmt.Errorf("[%w] price_divergence.Service: there is no reference source", apperror.ErrInternal)
+	}
+	if currencyList == nil {
+		return &ReconcileResultList{}, nil
+	}
+
+	res := make(ReconcileResultList, 0, len(*currencyList))
+	var errs error
+	for i := range *currencyList {
+		if ctx.Err() != nil {
+			return &res, errors.Join(errs, ctx.Err())
+		}
+		item := s.reconcileItem(ctx, &(*currencyList)[i])
+		errs = errors.Join(errs, item.Err)
+		res = append(res, item)
+	}
+	return &res, errs
+}
+
+// reconcileItem replaces the divergences of the checked days of the currency
+func (s *Service) reconcileItem(ctx context.Context, item *currency.Currency) ReconcileResult {
+	res := ReconcileResult{
+		CurrencyID: item.ID,
+		Slug:       item.Slug,
+	}
+
+	reference, err := s.reference.GetMarketChart(ctx, item.ID, item.Slug, s.config.Days)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	if reference == nil || len(*reference) == 0 {
+		return res
+	}
+	fromDay := (*reference)[0].Ts.UTC().Truncate(day)
+	toDay := fromDay
+	var point price_and_cap.PriceAndCap
+	for _, point = range *reference {
+		d := point.Ts.UTC().Truncate(day)
+		if d.Before(fromDay) {
+			fromDay = d
+		}
+		if !d.Before(toDay) {
+			toDay = d.Add(day)
+		}
+	}
+
+	stored, err := s.priceAndCap.GetList(ctx, item.ID, fromDay.Add(-s.config.MaxTimeGap), toDay.Add(s.config.MaxTimeGap))
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		res.Err = err
+		return res
+	}
+
+	compared, list := Compare(stored, reference, s.config, time.Now().UTC())
+	if err = s.replicaSet.WriteRepo().Replace(ctx, item.ID, price_and_cap.Source_Coingecko, fromDay, toDay, &list); err != nil {
+		res.Err = err
+		return res
+	}
+	import_run.AddRows(ctx, len(list))
+	res.Compared = compared
+	res.Diverged = uint(len(list))
+	return res
+}
+
+// Compare matches the first reference point of every day with the nearest stored point not further than the MaxTimeGap
+// and returns the number of the compared days and the days diverged beyond the thresholds of the config
+func Compare(stored *price_and_cap.PriceAndCapList, reference *price_and_cap.PriceAndCapList, cfg Config, checkedAt time.Time) (compared uint, res PriceDivergenceList) {
+	res = make(PriceDivergenceList, 0)
+	if stored == nil || len(*stored) == 0 || reference == nil || len(*reference) == 0 {
+		return 0, res
+	}
+
+	points := make(price_and_cap.PriceAndCapList, len(*stored))
+	copy(points, *stored)
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Ts.Before(points[j].Ts)
+	})
+
+	days := make(map[time.Time]struct{}, len(*reference))
+	var ref price_and_cap.PriceAndCap
+	for _, ref = range *reference {
+		d := ref.Ts.UTC().Truncate(day)
+		if _, ok := days[d]; ok || ref.Price <= 0 {
+			continue
+		}
+		days[d] = struct{}{}
+
+		point, ok := nearest(points, ref.Ts, cfg.MaxTimeGap)
+		if !ok {
+			continue
+		}
+		compared++
+
+		item := PriceDivergence{
+			CurrencyID: ref.CurrencyID,
+			Day:        d,
+			Source:     ref.Source,
+			Price:      point.Price,
+			RefPrice:   ref.Price,
+			PriceDiff:  relativeDiff(point.Price, ref.Price),
+			Cap:        point.Cap,
+			RefCap:     ref.Cap,
+			CheckedAt:  checkedAt,
+		}
+		// a source without the cap stores 0, it is not a divergence
+		if point.Cap > 0 {
+			item.CapDiff = relativeDiff(point.Cap, ref.Cap)
+		}
+		if item.PriceDiff > cfg.PriceThreshold || item.CapDiff > cfg.CapThreshold {
+			res = append(res, item)
+		}
+	}
+	return compared, res
+}
+
+// nearest returns the point of the sorted list which is the nearest to the time, but not further than the gap
+func nearest(points price_and_cap.PriceAndCapList, t time.Time, gap time.Duration) (*price_and_cap.PriceAndCap, bool) {
+	i := sort.Search(len(points), func(i int) bool {
+		return !points[i].Ts.Before(t)
+	})
+	var res *price_and_cap.PriceAndCap
+	best := gap + 1
+	for _, j := range []int{i - 1, i} {
+		if j < 0 || j >= len(points) {
+			continue
+		}
+		d := points[j].Ts.Sub(t)
+		if d < 0 {
+			d = -d
+		}
+		if d < best {
+			best = d
+			res = &points[j]
+		}
+	}
+	return res, res != nil
+}
+
+// relativeDiff returns |v - ref| / ref; 0 if the reference is unknown
+func relativeDiff(v float64, ref float64) float64 {
+	if ref <= 0 {
+		return 0
+	}
+	return math.Abs(v-ref) / ref
+}
@@ -0,0 +1,95 @@
+package price_divergence
+
+import (
+	"testing"
+	"time"
+
+	"info/internal/domain/price_and_cap"
+)
+
+func TestCompare(t *testing.T) {
+	day1 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.Add(day)
+	cfg := Config{PriceThreshold: 0.03, CapThreshold: 0.05, MaxTimeGap: 2 * time.Hour}
+	ref := func(ts time.Time, price float64, cap float64) price_and_cap.PriceAndCap {
+		return price_and_cap.PriceAndCap{CurrencyID: 1, Price: price, Cap: cap, Ts: ts, Source: price_and_cap.Source_Coingecko}
+	}
+	point := func(ts time.Time, price float64, cap float64) price_and_cap.PriceAndCap {
+		return price_and_cap.PriceAndCap{CurrencyID: 1, Price: price, Cap: cap, Ts: ts, Source: price_and_cap.Source_Cmc}
+	}
+
+	tests := []struct {
+		name         string
+		stored       price_and_cap.PriceAndCapList
+		reference    price_and_cap.PriceAndCapList
+		wantCompared uint
+		wantDays     []time.Time
+	}{
+		{
+			name:         "within the thresholds",
+			stored:       price_and_cap.PriceAndCapList{point(day1, 101, 1020), point(day2, 99, 990)},
+			reference:    price_and_cap.PriceAndCapList{ref(day1, 100, 1000), ref(day2, 100, 1000)},
+			wantCompared: 2,
+		},
+		{
+			name:         "price diverges",
+			stored:       price_and_cap.PriceAndCapList{point(day1, 100, 1000), point(day2, 150, 1000)},
+			reference:    price_and_cap.PriceAndCapList{ref(day1, 100, 1000), ref(day2, 100, 1000)},
+			wantCompared: 2,
+			wantDays:     []time.Time{day2},
+		},
+		{
+			name:         "cap diverges",
+			stored:       price_and_cap.PriceAndCapList{point(day1, 100, 2000)},
+			reference:    price_and_cap.PriceAndCapList{ref(day1, 100, 1000)},
+			wantCompared: 1,
+			wantDays:     []time.Time{day1},
+		},
+		{
+			name:         "unknown stored cap is not a divergence",
+			stored:       price_and_cap.PriceAndCapList{point(day1, 100, 0)},
+			reference:    price_and_cap.PriceAndCapList{ref(day1, 100, 1000)},
+			wantCompared: 1,
+		},
+		{
+			name:         "zero stored price diverges",
+			stored:       price_and_cap.PriceAndCapList{point(day1, 0, 1000)},
+			reference:    price_and_cap.PriceAndCapList{ref(day1, 100, 1000)},
+			wantCompared: 1,
+			wantDays:     []time.Time{day1},
+		},
+		{
+			name:         "the nearest point is used",
+			stored:       price_and_cap.PriceAndCapList{point(day1.Add(time.Hour), 100, 1000), point(day1.Add(-10*time.Minute), 200, 1000)},
+			reference:    price_and_cap.PriceAndCapList{ref(day1, 200, 1000)},
+			wantCompared: 1,
+		},
+		{
+			name:      "the point is too far",
+			stored:    price_and_cap.PriceAndCapList{point(day1.Add(3*time.Hour), 100, 1000)},
+			reference: price_and_cap.PriceAndCapList{ref(day1, 200, 1000)},
+		},
+		{
+			name:         "only the first reference point of the day",
+			stored:       price_and_cap.PriceAndCapList{point(day1, 100, 1000), point(day1.Add(12*time.Hour), 300, 1000)},
+			reference:    price_and_cap.PriceAndCapList{ref(day1, 100, 1000), ref(day1.Add(12*time.Hour), 100, 1000)},
+			wantCompared: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compared, list := Compare(&tt.stored, &tt.reference, cfg, time.Now())
+			if compared != tt.wantCompared {
+				t.Errorf("got compared %d, want %d", compared, tt.wantCompared)
+			}
+			if len(list) != len(tt.wantDays) {
+				t.Fatalf("got %d divergences, want %d: %+v", len(list), len(tt.wantDays), list)
+			}
+			for i, d := range tt.wantDays {
+				if !list[i].Day.Equal(d) || list[i].Source != price_and_cap.Source_Coingecko {
+					t.Errorf("got divergence %+v, want the day %v", list[i], d)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package raw_response
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"time"
+)
+
+// RawResponse is a raw JSON response of an upstream API; the body is compressed by gzip
+type RawResponse struct {
+	ID         uint
+	Source     string
+	Endpoint   string
+	CurrencyID uint // 0 - the response is not related to a currency
+	FetchedAt  time.Time
+	Body       []byte
+}
+
+type RawResponseList []RawResponse
+
+// Filter selects the archived responses of the endpoint fetched in [From, To); empty CurrencyIDs means all the currencies
+type Filter struct {
+	Endpoint    string
+	CurrencyIDs []uint
+	From        time.Time
+	To          time.Time
+}
+
+// SetData compresses the raw response into the body
+func (e *RawResponse) SetData(data []byte) error {
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	e.Body = buf.Bytes()
+	return nil
+}
+
+// Data decompresses the body into the raw response
+func (e *RawResponse) Data() ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(e.Body))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func (l *RawResponseList) LastID() uint {
+	if l == nil || len(*l) == 0 {
+		return 0
+	}
+	return (*l)[len(*l)-1].ID
+}
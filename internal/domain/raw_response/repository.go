@@ -0,0 +1,18 @@
+package raw_response
+
+import (
+	"context"
+)
+
+type ReplicaSet interface {
+	WriteRepo() WriteRepository
+	ReadRepo() ReadRepository
+}
+
+type WriteRepository interface {
+	Create(ctx context.Context, entity *RawResponse) error
+}
+
+type ReadRepository interface {
+	GetList(ctx context.Context, filter *Filter, afterID uint, limit uint) (*RawResponseList, error)
+}
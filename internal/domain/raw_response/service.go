@@ -0,0 +1,63 @@
+package raw_response
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"info/internal/pkg/apperror"
+)
+
+const (
+	batchSize = 100
+)
+
+type Service struct {
+	replicaSet ReplicaSet
+}
+
+func NewService(replicaSet ReplicaSet) *Service {
+	return &Service{
+		replicaSet: replicaSet,
+	}
+}
+
+// Archive stores the compressed raw response of the endpoint
+func (s *Service) Archive(ctx context.Context, source string, endpoint string, currencyID uint, fetchedAt time.Time, data []byte) error {
+	entity := &RawResponse{
+		Source:     source,
+		Endpoint:   endpoint,
+		CurrencyID: currencyID,
+		FetchedAt:  fetchedAt.UTC(),
+	}
+	if err := entity.SetData(data); err != nil {
+		return fmt.Errorf("[%w] raw_response.Service.Archive gzip error: %w", apperror.ErrInternal, err)
+	}
+	return s.replicaSet.WriteRepo().Create(ctx, entity)
+}
+
+// Each calls fn for every archived response matched by the filter in order of archiving; the responses are read by batches
+func (s *Service) Each(ctx context.Context, filter *Filter, fn func(ctx context.Context, entity *RawResponse) error) error {
+	var afterID uint
+	for {
+		list, err := s.replicaSet.ReadRepo().GetList(ctx, filter, afterID, batchSize)
+		if err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		for i := range *list {
+			if err = fn(ctx, &(*list)[i]); err != nil {
+				return err
+			}
+		}
+
+		if len(*list) < batchSize {
+			return nil
+		}
+		afterID = list.LastID()
+	}
+}
@@ -0,0 +1,125 @@
+package trend
+
+import (
+	"fmt"
+	"info/internal/pkg/apperror"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	DefaultPeriod   = time.Hour * 24 * 61
+	DefaultMaxBreak = time.Hour * 24 * 5
+	MaxPeriod       = time.Hour * 24 * 365 * 5
+)
+
+// NewParams returns the params of the direction with the defaults for the zero period and break
+func NewParams(direction string, period time.Duration, maxBreak time.Duration, minMagnitude float64) (*Params, error) {
+	if err := DirectionValidate(direction); err != nil {
+		return nil, fmt.Errorf("[%w] direction: %w", apperror.ErrBadRequest, err)
+	}
+	if period == 0 {
+		period = DefaultPeriod
+	}
+	if maxBreak == 0 {
+		maxBreak = DefaultMaxBreak
+	}
+	if period < 0 || period > MaxPeriod {
+		return nil, fmt.Errorf("[%w] the period must be in (0, %s]", apperror.ErrBadRequest, MaxPeriod)
+	}
+	if maxBreak < 0 {
+		return nil, fmt.Errorf("[%w] the break must be positive", apperror.ErrBadRequest)
+	}
+	if minMagnitude < 0 || math.IsNaN(minMagnitude) {
+		return nil, fmt.Errorf("[%w] the min magnitude must not be negative", apperror.ErrBadRequest)
+	}
+	return &Params{
+		Direction:    direction,
+		Period:       period,
+		MaxBreak:     maxBreak,
+		MinMagnitude: minMagnitude,
+	}, nil
+}
+
+// Detect returns the latest trend of the series in the direction of the params or nil.
+// The last point is the end of the trend and the trend goes back while the older points are below (rise) or above (fall) it;
+// the points which do not continue the trend are allowed during MaxBreak from the start of the trend found so far.
+func Detect(points *PointList, params *Params, now time.Time) *Trend {
+	if points == nil || params == nil || len(*points) < 2 {
+		return nil
+	}
+	// the loop goes from the last point to the older ones
+	list := make(PointList, len(*points))
+	copy(list, *points)
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].D.After(list[j].D)
+	})
+
+	// minTime: ограничение, дальше которого не смотрим
+	minTime := now.Add(-params.Period)
+	var inTrend bool
+	var i int
+	var prev, next, valueFrom, valueTo, localStart *Point
+	// в цикле next идёт перед prev
+	for i = range list {
+		prev = &list[i]
+		// первую итерацию просто пропустим
+		if i == 0 {
+			next = prev
+			continue
+		}
+		// дальше minTime не смотрим, останавливаем цикл
+		if prev.D.Before(minTime) {
+			break
+		}
+
+		if !inTrend {
+			valueTo = next
+		}
+
+		// если это не продолжение тренда, то пропустим
+		if !continues(params.Direction, prev.Value, valueTo.Value) {
+			// если уже нашли тренд, то проверяем на MaxBreak
+			if inTrend && localStart != nil && localStart.D.Sub(prev.D) >= params.MaxBreak {
+				break
+			}
+			next = prev
+			continue
+		}
+		inTrend = true
+
+		localStart = prev
+		next = prev
+	}
+	if localStart != nil {
+		valueFrom = localStart
+	}
+	if !inTrend || valueFrom == nil || valueTo == nil {
+		return nil
+	}
+
+	change := valueTo.Value - valueFrom.Value
+	if math.Abs(change) < params.MinMagnitude {
+		return nil
+	}
+	res := &Trend{
+		Direction: params.Direction,
+		From:      *valueFrom,
+		To:        *valueTo,
+		Duration:  valueTo.D.Sub(valueFrom.D),
+		Change:    change,
+	}
+	if valueFrom.Value != 0 {
+		res.ChangePercent = math.Round(valueTo.Value*100/valueFrom.Value*100) / 100
+	}
+	return res
+}
+
+// continues returns true if the older value is the start of the trend to the value
+func continues(direction string, older float64, value float64) bool {
+	if direction == Direction_Rise {
+		return older < value
+	}
+	return older > value
+}
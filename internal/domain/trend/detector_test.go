@@ -0,0 +1,194 @@
+package trend
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"info/internal/pkg/apperror"
+)
+
+var now = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func day(ago int) time.Time {
+	return now.AddDate(0, 0, -ago)
+}
+
+func params(direction string, minMagnitude float64) *Params {
+	return &Params{Direction: direction, Period: DefaultPeriod, MaxBreak: DefaultMaxBreak, MinMagnitude: minMagnitude}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		points   PointList
+		params   *Params
+		wantNil  bool
+		wantFrom Point
+		wantTo   Point
+	}{
+		{
+			name:    "one point",
+			points:  PointList{{Value: 1, D: day(0)}},
+			params:  params(Direction_Fall, 0),
+			wantNil: true,
+		},
+		{
+			name:     "fall",
+			points:   PointList{{Value: 40, D: day(0)}, {Value: 45, D: day(1)}, {Value: 50, D: day(2)}, {Value: 30, D: day(10)}},
+			params:   params(Direction_Fall, 0),
+			wantFrom: Point{Value: 50, D: day(2)},
+			wantTo:   Point{Value: 40, D: day(0)},
+		},
+		{
+			name:     "rise",
+			points:   PointList{{Value: 60, D: day(0)}, {Value: 55, D: day(1)}, {Value: 40, D: day(3)}, {Value: 70, D: day(10)}},
+			params:   params(Direction_Rise, 0),
+			wantFrom: Point{Value: 40, D: day(3)},
+			wantTo:   Point{Value: 60, D: day(0)},
+		},
+		{
+			name:    "the opposite direction",
+			points:  PointList{{Value: 60, D: day(0)}, {Value: 55, D: day(1)}},
+			params:  params(Direction_Fall, 0),
+			wantNil: true,
+		},
+		{
+			name:    "flat",
+			points:  PointList{{Value: 50, D: day(0)}, {Value: 50, D: day(1)}, {Value: 50, D: day(2)}},
+			params:  params(Direction_Rise, 0),
+			wantNil: true,
+		},
+		{
+			name:     "the trend ends before the plateau of the last days",
+			points:   PointList{{Value: 60, D: day(0)}, {Value: 60, D: day(1)}, {Value: 50, D: day(2)}},
+			params:   params(Direction_Rise, 0),
+			wantFrom: Point{Value: 50, D: day(2)},
+			wantTo:   Point{Value: 60, D: day(1)},
+		},
+		{
+			name:     "a point against the trend inside the break",
+			points:   PointList{{Value: 60, D: day(0)}, {Value: 50, D: day(1)}, {Value: 65, D: day(2)}, {Value: 45, D: day(3)}},
+			params:   params(Direction_Rise, 0),
+			wantFrom: Point{Value: 45, D: day(3)},
+			wantTo:   Point{Value: 60, D: day(0)},
+		},
+		{
+			name:     "a point against the trend after the break",
+			points:   PointList{{Value: 60, D: day(0)}, {Value: 50, D: day(1)}, {Value: 65, D: day(8)}, {Value: 40, D: day(9)}},
+			params:   params(Direction_Rise, 0),
+			wantFrom: Point{Value: 50, D: day(1)},
+			wantTo:   Point{Value: 60, D: day(0)},
+		},
+		{
+			name:     "a gap of the data continues the trend",
+			points:   PointList{{Value: 60, D: day(0)}, {Value: 50, D: day(1)}, {Value: 40, D: day(20)}},
+			params:   params(Direction_Rise, 0),
+			wantFrom: Point{Value: 40, D: day(20)},
+			wantTo:   Point{Value: 60, D: day(0)},
+		},
+		{
+			name:     "the older points are compared with the end, not with the neighbour",
+			points:   PointList{{Value: 40, D: day(0)}, {Value: 50, D: day(1)}, {Value: 45, D: day(2)}},
+			params:   params(Direction_Fall, 0),
+			wantFrom: Point{Value: 45, D: day(2)},
+			wantTo:   Point{Value: 40, D: day(0)},
+		},
+		{
+			name:     "the points older than the period are skipped",
+			points:   PointList{{Value: 40, D: day(0)}, {Value: 50, D: day(60)}, {Value: 90, D: day(62)}},
+			params:   params(Direction_Fall, 0),
+			wantFrom: Point{Value: 50, D: day(60)},
+			wantTo:   Point{Value: 40, D: day(0)},
+		},
+		{
+			name:    "all the older points are older than the period",
+			points:  PointList{{Value: 40, D: day(0)}, {Value: 50, D: day(62)}},
+			params:  params(Direction_Fall, 0),
+			wantNil: true,
+		},
+		{
+			name:    "the change is less than the min magnitude",
+			points:  PointList{{Value: 40, D: day(0)}, {Value: 50, D: day(1)}},
+			params:  params(Direction_Fall, 10.5),
+			wantNil: true,
+		},
+		{
+			name:     "the change is equal to the min magnitude",
+			points:   PointList{{Value: 40, D: day(0)}, {Value: 50, D: day(1)}},
+			params:   params(Direction_Fall, 10),
+			wantFrom: Point{Value: 50, D: day(1)},
+			wantTo:   Point{Value: 40, D: day(0)},
+		},
+		{
+			name:     "the points are not sorted",
+			points:   PointList{{Value: 50, D: day(2)}, {Value: 40, D: day(0)}, {Value: 45, D: day(1)}},
+			params:   params(Direction_Fall, 0),
+			wantFrom: Point{Value: 50, D: day(2)},
+			wantTo:   Point{Value: 40, D: day(0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Detect(&tt.points, tt.params, now)
+			if tt.wantNil {
+				if res != nil {
+					t.Errorf("got %+v, want nil", *res)
+				}
+				return
+			}
+			if res == nil {
+				t.Fatal("got nil")
+			}
+			if res.From != tt.wantFrom || res.To != tt.wantTo {
+				t.Errorf("got %+v - %+v, want %+v - %+v", res.From, res.To, tt.wantFrom, tt.wantTo)
+			}
+			if res.Duration != tt.wantTo.D.Sub(tt.wantFrom.D) || res.Change != tt.wantTo.Value-tt.wantFrom.Value || res.Direction != tt.params.Direction {
+				t.Errorf("got %+v", *res)
+			}
+		})
+	}
+}
+
+func TestDetect_ChangePercent(t *testing.T) {
+	points := PointList{{Value: 30, D: day(0)}, {Value: 0, D: day(1)}}
+	if res := Detect(&points, params(Direction_Rise, 0), now); res == nil || res.ChangePercent != 0 {
+		t.Errorf("got %+v, want 0 percent from 0", res)
+	}
+	points = PointList{{Value: 20, D: day(0)}, {Value: 30, D: day(1)}}
+	if res := Detect(&points, params(Direction_Fall, 0), now); res == nil || res.ChangePercent != 66.67 {
+		t.Errorf("got %+v, want 66.67 percent", res)
+	}
+}
+
+func TestNewParams(t *testing.T) {
+	res, err := NewParams(Direction_Rise, 0, 0, 0)
+	if err != nil {
+		t.Fatalf("NewParams error: %v", err)
+	}
+	if res.Period != DefaultPeriod || res.MaxBreak != DefaultMaxBreak {
+		t.Errorf("got %+v, want the defaults", *res)
+	}
+
+	tests := []struct {
+		name         string
+		direction    string
+		period       time.Duration
+		maxBreak     time.Duration
+		minMagnitude float64
+	}{
+		{name: "unknown direction", direction: "up"},
+		{name: "empty direction", direction: ""},
+		{name: "negative period", direction: Direction_Fall, period: -time.Hour},
+		{name: "too long period", direction: Direction_Fall, period: MaxPeriod + time.Hour},
+		{name: "negative break", direction: Direction_Fall, maxBreak: -time.Hour},
+		{name: "negative min magnitude", direction: Direction_Fall, minMagnitude: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewParams(tt.direction, tt.period, tt.maxBreak, tt.minMagnitude); !errors.Is(err, apperror.ErrBadRequest) {
+				t.Errorf("got %v, want ErrBadRequest", err)
+			}
+		})
+	}
+}
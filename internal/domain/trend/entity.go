@@ -0,0 +1,47 @@
+package trend
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	Direction_Rise = "rise"
+	Direction_Fall = "fall"
+)
+
+var DirectionList = []interface{}{
+	Direction_Rise,
+	Direction_Fall,
+}
+
+func DirectionValidate(s string) error {
+	return validation.Validate(s, validation.Required, validation.In(DirectionList...))
+}
+
+// Point is a value of a series at the time
+type Point struct {
+	Value float64
+	D     time.Time
+}
+
+type PointList []Point
+
+// Params are the params of the detection
+type Params struct {
+	Direction    string
+	Period       time.Duration // the detector does not look older than now - Period
+	MaxBreak     time.Duration // the max duration without the continuation of the found trend
+	MinMagnitude float64       // the min absolute change of the value; 0 - any change
+}
+
+// Trend is the latest sustained change of a series in the direction: Change = To.Value - From.Value
+type Trend struct {
+	Direction     string
+	From          Point
+	To            Point
+	Duration      time.Duration
+	Change        float64
+	ChangePercent float64 // To.Value as the percent of From.Value; 0 without From.Value
+}
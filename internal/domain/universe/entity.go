@@ -0,0 +1,48 @@
+package universe
+
+import (
+	"info/internal/domain/currency"
+	"time"
+)
+
+const (
+	Action_Added   = "added"
+	Action_Retired = "retired"
+
+	Tag_Stablecoin = "stablecoin"
+
+	Reason_Rules    = "matches the rules"
+	Reason_Unlisted = "is not in the listings"
+)
+
+// Candidate is a currency of the listings with the metrics which are checked by the rules
+type Candidate struct {
+	Currency  currency.Currency
+	MarketCap float64
+	Volume24h float64
+	Tags      []string
+}
+
+type CandidateList []Candidate
+
+// Change is a record of the audit trail of the universe of the observed currencies
+type Change struct {
+	ID         uint
+	CurrencyID uint
+	Slug       string
+	Action     string
+	Reason     string
+	CmcRank    uint
+	MarketCap  float64
+	Volume24h  float64
+	ChangedAt  time.Time
+}
+
+type ChangeList []Change
+
+// Filter selects the changes of [From, To); an empty Action means all the actions
+type Filter struct {
+	Action string
+	From   time.Time
+	To     time.Time
+}
@@ -0,0 +1,18 @@
+package universe
+
+import (
+	"context"
+)
+
+type ReplicaSet interface {
+	WriteRepo() WriteRepository
+	ReadRepo() ReadRepository
+}
+
+type WriteRepository interface {
+	MCreate(ctx context.Context, entities *ChangeList) error
+}
+
+type ReadRepository interface {
+	GetList(ctx context.Context, filter *Filter, limit uint) (*ChangeList, error)
+}
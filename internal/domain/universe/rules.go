@@ -0,0 +1,45 @@
+package universe
+
+import (
+	"strconv"
+)
+
+// Rules select the observed currencies from the listings; a zero value turns the rule off
+type Rules struct {
+	TopN               uint    // the max CmcRank
+	MinMarketCap       float64 // USD
+	MinVolume24h       float64 // USD
+	ExcludeStablecoins bool
+	ExcludeTags        []string
+	ExcludeSlugs       []string
+}
+
+// Check returns an empty string if the candidate matches the rules, otherwise the reason of the mismatch
+func (r *Rules) Check(c *Candidate) string {
+	if r.TopN > 0 && (c.Currency.CmcRank == 0 || c.Currency.CmcRank > r.TopN) {
+		return "rank " + strconv.FormatUint(uint64(c.Currency.CmcRank), 10) + " is out of the top " + strconv.FormatUint(uint64(r.TopN), 10)
+	}
+	if r.MinMarketCap > 0 && c.MarketCap < r.MinMarketCap {
+		return "market cap " + strconv.FormatFloat(c.MarketCap, 'f', 0, 64) + " is less than " + strconv.FormatFloat(r.MinMarketCap, 'f', 0, 64)
+	}
+	if r.MinVolume24h > 0 && c.Volume24h < r.MinVolume24h {
+		return "volume 24h " + strconv.FormatFloat(c.Volume24h, 'f', 0, 64) + " is less than " + strconv.FormatFloat(r.MinVolume24h, 'f', 0, 64)
+	}
+	var item string
+	for _, item = range r.ExcludeSlugs {
+		if c.Currency.Slug == item {
+			return "slug is excluded"
+		}
+	}
+	for _, item = range c.Tags {
+		if r.ExcludeStablecoins && item == Tag_Stablecoin {
+			return "stablecoin is excluded"
+		}
+		for _, tag := range r.ExcludeTags {
+			if item == tag {
+				return "tag " + tag + " is excluded"
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,199 @@
+package universe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"info/internal/domain/currency"
+	"info/internal/domain/import_run"
+	"info/internal/pkg/apperror"
+	"time"
+)
+
+const (
+	defaultMaxListings = 1000
+	// listingsPageSize is the number of the listings of one request; CMC charges 1 credit for every 200 listings
+	listingsPageSize = 200
+	defaultLimit     = 50
+	maxLimit         = 1000
+)
+
+// ListingsApi returns the listings sorted by the market cap, from start (1 - the first one)
+type ListingsApi interface {
+	GetListings(ctx context.Context, start uint, limit uint) (*CandidateList, error)
+}
+
+type Config struct {
+	Rules       Rules
+	MaxListings uint // the number of the requested listings; 0 - 1000
+}
+
+type Service struct {
+	replicaSet  ReplicaSet
+	config      Config
+	currency    *currency.Service
+	listingsApi ListingsApi
+}
+
+// NewService creates the service; without the listings API the discovery is unavailable, but the audit trail works
+func NewService(replicaSet ReplicaSet, cfg Config, currency *currency.Service, listingsApi ListingsApi) *Service {
+	if cfg.MaxListings == 0 {
+		cfg.MaxListings = defaultMaxListings
+	}
+	return &Service{
+		replicaSet:  replicaSet,
+		config:      cfg,
+		currency:    currency,
+		listingsApi: listingsApi,
+	}
+}
+
+// GetList returns the audit trail of the universe, newest first
+func (s *Service) GetList(ctx context.Context, filter *Filter, limit uint) (*ChangeList, error) {
+	if limit == 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	return s.replicaSet.ReadRepo().GetList(ctx, filter, limit)
+}
+
+// Discover selects the currencies of the listings by the rules: the selected ones are upserted as observed,
+// the observed ones which do not match the rules any more are retired, except the pinned slugs.
+// It returns the changes of the universe; with dryRun nothing is stored.
+func (s *Service) Discover(ctx context.Context, pinnedSlugs *[]string, dryRun bool) (*ChangeList, error) {
+	if s.listingsApi == nil {
+		return nil, fmt.Errorf("[%w] universe.Service: there is no listings API", apperror.ErrInternal)
+	}
+
+	candidates, err := s.getListings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	observed, err := s.currency.GetAll(ctx)
+	if err != nil {
+		if !errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		observed = &currency.CurrencyList{}
+	}
+
+	selected, changes := Diff(candidates, observed, &s.config.Rules, pinnedSlugs, time.Now().UTC())
+	if dryRun || (len(*selected) == 0 && len(*changes) == 0) {
+		return changes, nil
+	}
+
+	if len(*selected) > 0 {
+		if err = s.currency.MUpsert(ctx, selected); err != nil {
+			return nil, err
+		}
+		import_run.AddRows(ctx, len(*selected))
+	}
+	retiredIDs := make([]uint, 0, len(*changes))
+	var item Change
+	for _, item = range *changes {
+		if item.Action == Action_Retired {
+			retiredIDs = append(retiredIDs, item.CurrencyID)
+		}
+	}
+	if len(retiredIDs) > 0 {
+		if err = s.currency.MSetObserving(ctx, &retiredIDs, false); err != nil {
+			return nil, err
+		}
+	}
+	if len(*changes) > 0 {
+		if err = s.replicaSet.WriteRepo().MCreate(ctx, changes); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+// getListings requests the listings page by page up to MaxListings; an empty result is an error, so nothing is retired
+func (s *Service) getListings(ctx context.Context) (*CandidateList, error) {
+	res := make(CandidateList, 0, s.config.MaxListings)
+	for start := uint(1); start <= s.config.MaxListings; start += listingsPageSize {
+		limit := uint(listingsPageSize)
+		if start+limit-1 > s.config.MaxListings {
+			limit = s.config.MaxListings - start + 1
+		}
+		page, err := s.listingsApi.GetListings(ctx, start, limit)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *page...)
+		if uint(len(*page)) < limit {
+			break
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("[%w] universe.Service: the listings are empty", apperror.ErrNotFound)
+	}
+	return &res, nil
+}
+
+// Diff returns the currencies of the candidates matched by the rules and the changes of the observed universe:
+// the matched currencies which are not observed yet are added, the observed ones which are not matched and not pinned are retired
+func Diff(candidates *CandidateList, observed *currency.CurrencyList, rules *Rules, pinnedSlugs *[]string, changedAt time.Time) (selected *currency.CurrencyList, changes *ChangeList) {
+	sel := make(currency.CurrencyList, 0, len(*candidates))
+	ch := make(ChangeList, 0)
+
+	observedIDs := make(map[uint]struct{}, len(*observed))
+	var item currency.Currency
+	for _, item = range *observed {
+		observedIDs[item.ID] = struct{}{}
+	}
+	pinned := make(map[string]struct{})
+	if pinnedSlugs != nil {
+		var slug string
+		for _, slug = range *pinnedSlugs {
+			pinned[slug] = struct{}{}
+		}
+	}
+
+	reasons := make(map[uint]string, len(*candidates))
+	metrics := make(map[uint]*Candidate, len(*candidates))
+	for i := range *candidates {
+		c := &(*candidates)[i]
+		metrics[c.Currency.ID] = c
+		reason := rules.Check(c)
+		if reason != "" {
+			reasons[c.Currency.ID] = reason
+			continue
+		}
+		c.Currency.IsForObserving = true
+		sel = append(sel, c.Currency)
+		if _, ok := observedIDs[c.Currency.ID]; !ok {
+			ch = append(ch, newChange(c, Action_Added, Reason_Rules, changedAt))
+		}
+	}
+
+	for _, item = range *observed {
+		if _, ok := pinned[item.Slug]; ok {
+			continue
+		}
+		c, ok := metrics[item.ID]
+		if !ok {
+			ch = append(ch, newChange(&Candidate{Currency: item}, Action_Retired, Reason_Unlisted, changedAt))
+			continue
+		}
+		if reason, ok := reasons[item.ID]; ok {
+			ch = append(ch, newChange(c, Action_Retired, reason, changedAt))
+		}
+	}
+	return &sel, &ch
+}
+
+func newChange(c *Candidate, action string, reason string, changedAt time.Time) Change {
+	return Change{
+		CurrencyID: c.Currency.ID,
+		Slug:       c.Currency.Slug,
+		Action:     action,
+		Reason:     reason,
+		CmcRank:    c.Currency.CmcRank,
+		MarketCap:  c.MarketCap,
+		Volume24h:  c.Volume24h,
+		ChangedAt:  changedAt,
+	}
+}
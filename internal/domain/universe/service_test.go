@@ -0,0 +1,103 @@
+package universe
+
+import (
+	"testing"
+	"time"
+
+	"info/internal/domain/currency"
+)
+
+func candidate(ID uint, slug string, rank uint, cap float64, volume float64, tags ...string) Candidate {
+	return Candidate{
+		Currency:  currency.Currency{ID: ID, Slug: slug, CmcRank: rank},
+		MarketCap: cap,
+		Volume24h: volume,
+		Tags:      tags,
+	}
+}
+
+func TestRules_Check(t *testing.T) {
+	rules := Rules{TopN: 100, MinMarketCap: 1e9, MinVolume24h: 1e7, ExcludeStablecoins: true, ExcludeTags: []string{"memes"}, ExcludeSlugs: []string{"wrapped-bitcoin"}}
+
+	tests := []struct {
+		name      string
+		candidate Candidate
+		wantMatch bool
+	}{
+		{name: "matches", candidate: candidate(1, "bitcoin", 1, 1e12, 1e10), wantMatch: true},
+		{name: "out of the top", candidate: candidate(2, "coin", 101, 1e12, 1e10)},
+		{name: "without the rank", candidate: candidate(3, "coin", 0, 1e12, 1e10)},
+		{name: "small cap", candidate: candidate(4, "coin", 50, 1e8, 1e10)},
+		{name: "small volume", candidate: candidate(5, "coin", 50, 1e12, 1e6)},
+		{name: "stablecoin", candidate: candidate(825, "tether", 3, 1e11, 1e10, "stablecoin")},
+		{name: "excluded tag", candidate: candidate(6, "doge", 8, 1e10, 1e9, "mineable", "memes")},
+		{name: "excluded slug", candidate: candidate(3717, "wrapped-bitcoin", 20, 1e10, 1e9)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := rules.Check(&tt.candidate)
+			if (reason == "") != tt.wantMatch {
+				t.Errorf("got reason %q, want the match %v", reason, tt.wantMatch)
+			}
+		})
+	}
+
+	if reason := (&Rules{}).Check(&Candidate{}); reason != "" {
+		t.Errorf("empty rules have to match everything, got reason %q", reason)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	changedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rules := Rules{TopN: 3, ExcludeStablecoins: true}
+	candidates := CandidateList{
+		candidate(1, "bitcoin", 1, 1e12, 1e10),
+		candidate(1027, "ethereum", 2, 1e11, 1e10),
+		candidate(825, "tether", 3, 1e11, 1e10, "stablecoin"),
+		candidate(1839, "bnb", 4, 1e10, 1e9),
+		candidate(5426, "solana", 5, 1e10, 1e9),
+	}
+	observed := currency.CurrencyList{
+		{ID: 1, Slug: "bitcoin", IsForObserving: true},
+		{ID: 1839, Slug: "bnb", IsForObserving: true},
+		{ID: 5426, Slug: "solana", IsForObserving: true},
+		{ID: 7083, Slug: "uniswap", IsForObserving: true},
+	}
+	pinned := []string{"solana"}
+
+	selected, changes := Diff(&candidates, &observed, &rules, &pinned, changedAt)
+
+	if len(*selected) != 2 || (*selected)[0].ID != 1 || (*selected)[1].ID != 1027 {
+		t.Fatalf("got selected %+v, want bitcoin and ethereum", *selected)
+	}
+	var item currency.Currency
+	for _, item = range *selected {
+		if !item.IsForObserving {
+			t.Errorf("the selected currency %d has to be observed", item.ID)
+		}
+	}
+
+	want := []struct {
+		currencyID uint
+		action     string
+	}{
+		{currencyID: 1027, action: Action_Added},
+		{currencyID: 1839, action: Action_Retired},
+		{currencyID: 7083, action: Action_Retired},
+	}
+	if len(*changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(*changes), len(want), *changes)
+	}
+	for i, w := range want {
+		c := (*changes)[i]
+		if c.CurrencyID != w.currencyID || c.Action != w.action || c.Reason == "" || !c.ChangedAt.Equal(changedAt) {
+			t.Errorf("got change %+v, want the currency %d %s", c, w.currencyID, w.action)
+		}
+	}
+	if (*changes)[1].CmcRank != 4 || (*changes)[1].MarketCap != 1e10 {
+		t.Errorf("the retired listed currency has to keep its metrics: %+v", (*changes)[1])
+	}
+	if (*changes)[2].Reason != Reason_Unlisted {
+		t.Errorf("got reason %q of the unlisted currency", (*changes)[2].Reason)
+	}
+}
@@ -0,0 +1,15 @@
+package domain
+
+// UpsertStats is the number of rows inserted and updated by upserts
+type UpsertStats struct {
+	Inserted uint
+	Updated  uint
+}
+
+func (s *UpsertStats) Add(other *UpsertStats) {
+	if other == nil {
+		return
+	}
+	s.Inserted += other.Inserted
+	s.Updated += other.Updated
+}
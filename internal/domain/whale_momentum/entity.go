@@ -0,0 +1,103 @@
+package whale_momentum
+
+import (
+	"fmt"
+	"info/internal/pkg/apperror"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Window is a lookback window of the momentum: Nd - days, Nw - weeks, Nm - calendar months
+type Window struct {
+	Name   string
+	Days   int
+	Months int
+}
+
+type WindowList []Window
+
+// DefaultWindowList are the windows of the former cw1, cw2 and cm1 views
+var DefaultWindowList = WindowList{
+	{Name: "1w", Days: 7},
+	{Name: "2w", Days: 14},
+	{Name: "1m", Months: 1},
+	{Name: "2m", Months: 2},
+}
+
+// ParseWindow parses the window like 3d, 1w or 2m
+func ParseWindow(s string) (*Window, error) {
+	if len(s) < 2 {
+		return nil, fmt.Errorf("[%w] bad window %q", apperror.ErrBadRequest, s)
+	}
+	n, err := strconv.ParseUint(s[:len(s)-1], 10, 64)
+	if err != nil || n == 0 || n > maxWindowUnits {
+		return nil, fmt.Errorf("[%w] bad window %q", apperror.ErrBadRequest, s)
+	}
+	res := &Window{Name: s}
+	switch s[len(s)-1] {
+	case 'd':
+		res.Days = int(n)
+	case 'w':
+		res.Days = int(n) * 7
+	case 'm':
+		res.Months = int(n)
+	default:
+		return nil, fmt.Errorf("[%w] bad unit of the window %q; d, w or m are expected", apperror.ErrBadRequest, s)
+	}
+	return res, nil
+}
+
+// ParseWindowList parses the comma separated windows like 1w,2w,1m
+func ParseWindowList(s string) (WindowList, error) {
+	res := make(WindowList, 0)
+	for _, item := range strings.Split(s, ",") {
+		w, err := ParseWindow(strings.TrimSpace(item))
+		if err != nil {
+			return nil, err
+		}
+		for i := range res {
+			if res[i].Name == w.Name {
+				return nil, fmt.Errorf("[%w] duplicated window %q", apperror.ErrBadRequest, w.Name)
+			}
+		}
+		res = append(res, *w)
+	}
+	return res, nil
+}
+
+// Day returns the last day of the data of the window from the time;
+// the months are subtracted like in postgres: 31 March - 1 month is 28 February, not 3 March
+func (e *Window) Day(t time.Time) time.Time {
+	t = t.AddDate(0, 0, -e.Days)
+	if e.Months > 0 {
+		firstDay := time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location()).AddDate(0, -e.Months, 0)
+		day := firstDay.AddDate(0, 1, -1).Day()
+		if t.Day() < day {
+			day = t.Day()
+		}
+		t = firstDay.AddDate(0, 0, day-1)
+	}
+	return t.Truncate(time.Hour * 24)
+}
+
+// WindowChange is the change of the share of the whales from the start of the window in percent
+type WindowChange struct {
+	Window        string
+	D             time.Time
+	Whales        float64
+	ChangePercent float64
+}
+
+// WhaleMomentum is the change of the share of the whales of a currency from the start of every window;
+// Bonus is the change of the window of the sort * 1000
+type WhaleMomentum struct {
+	CurrencyID uint
+	Symbol     string
+	Bonus      float64
+	Whales     float64
+	D          time.Time
+	Changes    []WindowChange
+}
+
+type WhaleMomentumList []WhaleMomentum
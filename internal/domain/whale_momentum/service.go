@@ -0,0 +1,205 @@
+package whale_momentum
+
+import (
+	"context"
+	"fmt"
+	"info/internal/domain/concentration"
+	"info/internal/domain/currency"
+	"info/internal/pkg/apperror"
+	"math"
+	"slices"
+	"time"
+)
+
+const (
+	// DefaultLag is the lag of the last day of the data "because of the glitches of the data"
+	DefaultLag     = time.Hour * 24 * 2
+	maxLag         = time.Hour * 24 * 30
+	maxWindowUnits = 3660
+	maxWindows     = 10
+	bonusFactor    = 1000
+)
+
+// Query is the params of the report; SortBy is the name of one of the windows
+type Query struct {
+	Windows WindowList
+	Lag     time.Duration
+	SortBy  string
+	Desc    bool
+	Limit   uint // 0 - without the limit
+}
+
+// NewQuery returns the query of the report with the defaults: DefaultWindowList, DefaultLag and the sort by the first window
+func NewQuery(windows WindowList, lag *time.Duration, sortBy string, desc bool, limit uint) (*Query, error) {
+	if len(windows) == 0 {
+		windows = DefaultWindowList
+	}
+	if len(windows) > maxWindows {
+		return nil, fmt.Errorf("[%w] too many windows; max: %d", apperror.ErrBadRequest, maxWindows)
+	}
+	res := &Query{
+		Windows: windows,
+		Lag:     DefaultLag,
+		SortBy:  sortBy,
+		Desc:    desc,
+		Limit:   limit,
+	}
+	if lag != nil {
+		if *lag < 0 || *lag > maxLag {
+			return nil, fmt.Errorf("[%w] the lag must be in [0, %s]", apperror.ErrBadRequest, maxLag)
+		}
+		res.Lag = *lag
+	}
+	if res.SortBy == "" {
+		res.SortBy = windows[0].Name
+	}
+	if res.sortIndex() < 0 {
+		return nil, fmt.Errorf("[%w] the sort key %q is not one of the windows", apperror.ErrBadRequest, res.SortBy)
+	}
+	return res, nil
+}
+
+// sortIndex returns the index of the window of the sort or -1
+func (q *Query) sortIndex() int {
+	for i := range q.Windows {
+		if q.Windows[i].Name == q.SortBy {
+			return i
+		}
+	}
+	return -1
+}
+
+type Service struct {
+	currency      *currency.Service
+	concentration *concentration.Service
+}
+
+func NewService(currency *currency.Service, concentration *concentration.Service) *Service {
+	return &Service{
+		currency:      currency,
+		concentration: concentration,
+	}
+}
+
+// Report returns the momentum of the whales of the observed currencies
+func (s *Service) Report(ctx context.Context, query *Query) (*WhaleMomentumList, error) {
+	if query == nil {
+		return nil, fmt.Errorf("[%w] the query is required", apperror.ErrBadRequest)
+	}
+	currencyList, err := s.currency.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	concentrationMap, err := s.concentration.MGet(ctx, currencyList.IDs())
+	if err != nil {
+		return nil, err
+	}
+
+	res := CalcWhaleMomentumList(currencyList, concentrationMap, query, time.Now().UTC())
+	if len(*res) == 0 {
+		return nil, fmt.Errorf("[%w] there is no currency with the data of all the windows", apperror.ErrNotFound)
+	}
+	return res, nil
+}
+
+// CalcWhaleMomentumList returns the momentum of the currencies which have the data of all the windows sorted by the query
+func CalcWhaleMomentumList(currencyList *currency.CurrencyList, concentrationMap concentration.ConcentrationMap, query *Query, now time.Time) *WhaleMomentumList {
+	res := make(WhaleMomentumList, 0)
+	if currencyList == nil || concentrationMap == nil || query == nil {
+		return &res
+	}
+	var item currency.Currency
+	for _, item = range *currencyList {
+		list, ok := concentrationMap[item.ID]
+		if !ok {
+			continue
+		}
+		if e := CalcWhaleMomentum(&item, &list, query, now); e != nil {
+			res = append(res, *e)
+		}
+	}
+
+	// the sort by the window of the sort, then by the other windows in their order
+	order := make([]int, 0, len(query.Windows))
+	order = append(order, query.sortIndex())
+	for i := range query.Windows {
+		if i != order[0] {
+			order = append(order, i)
+		}
+	}
+	slices.SortStableFunc(res, func(a, b WhaleMomentum) int {
+		for _, i := range order {
+			x, y := a.Changes[i].ChangePercent, b.Changes[i].ChangePercent
+			if x == y {
+				continue
+			}
+			if (x < y) != query.Desc {
+				return -1
+			}
+			return 1
+		}
+		return 0
+	})
+
+	if query.Limit > 0 && uint(len(res)) > query.Limit {
+		res = res[:query.Limit]
+	}
+	return &res
+}
+
+// CalcWhaleMomentum returns the momentum of the currency or nil if there is no data of a window or the start of a window has no whales.
+// The last value is the last one not later than now - Lag; the start of a window is the last value not later than now - window.
+func CalcWhaleMomentum(item *currency.Currency, list *concentration.ConcentrationList, query *Query, now time.Time) *WhaleMomentum {
+	if item == nil || list == nil || query == nil {
+		return nil
+	}
+	last := lastBefore(list, now.Add(-query.Lag).Truncate(time.Hour*24))
+	if last == nil {
+		return nil
+	}
+	res := &WhaleMomentum{
+		CurrencyID: item.ID,
+		Symbol:     item.Symbol,
+		Whales:     last.Whales,
+		D:          last.D,
+		Changes:    make([]WindowChange, 0, len(query.Windows)),
+	}
+	sortIndex := query.sortIndex()
+	for i := range query.Windows {
+		start := lastBefore(list, query.Windows[i].Day(now))
+		if start == nil || start.Whales == 0 {
+			return nil
+		}
+		change := (last.Whales - start.Whales) * 100 / start.Whales
+		if i == sortIndex {
+			// the bonus is rounded once, from the exact change
+			res.Bonus = math.Round(change * bonusFactor)
+		}
+		res.Changes = append(res.Changes, WindowChange{
+			Window:        query.Windows[i].Name,
+			D:             start.D,
+			Whales:        start.Whales,
+			ChangePercent: round(change, 4),
+		})
+	}
+	return res
+}
+
+// lastBefore returns the last item of the list not later than the day or nil
+func lastBefore(list *concentration.ConcentrationList, d time.Time) *concentration.Concentration {
+	var res *concentration.Concentration
+	for i := range *list {
+		if (*list)[i].D.After(d) {
+			continue
+		}
+		if res == nil || (*list)[i].D.After(res.D) {
+			res = &(*list)[i]
+		}
+	}
+	return res
+}
+
+func round(v float64, digits int) float64 {
+	p := math.Pow(10, float64(digits))
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,175 @@
+package whale_momentum
+
+import (
+	"errors"
+	"info/internal/domain/concentration"
+	"info/internal/domain/currency"
+	"info/internal/pkg/apperror"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2025, 3, 31, 15, 0, 0, 0, time.UTC)
+
+// series returns the daily values of the whales from the day of now - len(values) + 1 to the day of now
+func series(ID uint, values ...float64) concentration.ConcentrationList {
+	res := make(concentration.ConcentrationList, 0, len(values))
+	day := now.Truncate(time.Hour * 24)
+	for i, v := range values {
+		res = append(res, concentration.Concentration{CurrencyID: ID, Whales: v, D: day.AddDate(0, 0, i-len(values)+1)})
+	}
+	return res
+}
+
+// linear returns n values starting from the value with the step
+func linear(n int, from float64, step float64) []float64 {
+	res := make([]float64, n)
+	for i := range res {
+		res[i] = from + float64(i)*step
+	}
+	return res
+}
+
+func TestParseWindowList(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    WindowList
+		wantErr bool
+	}{
+		{name: "the defaults", s: "1w,2w,1m,2m", want: DefaultWindowList},
+		{name: "days", s: "3d, 10d", want: WindowList{{Name: "3d", Days: 3}, {Name: "10d", Days: 10}}},
+		{name: "bad unit", s: "1y", wantErr: true},
+		{name: "zero", s: "0d", wantErr: true},
+		{name: "empty", s: "1w,", wantErr: true},
+		{name: "duplicated", s: "1w,1w", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := ParseWindowList(tt.s)
+			if tt.wantErr {
+				if !errors.Is(err, apperror.ErrBadRequest) {
+					t.Fatalf("got %v, want ErrBadRequest", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res) != len(tt.want) {
+				t.Fatalf("got %+v, want %+v", res, tt.want)
+			}
+			for i := range res {
+				if res[i] != tt.want[i] {
+					t.Errorf("window %d: got %+v, want %+v", i, res[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewQuery(t *testing.T) {
+	lag := time.Hour * 24 * 31
+	if _, err := NewQuery(nil, &lag, "", false, 0); !errors.Is(err, apperror.ErrBadRequest) {
+		t.Errorf("too big lag: got %v", err)
+	}
+	if _, err := NewQuery(nil, nil, "3d", false, 0); !errors.Is(err, apperror.ErrBadRequest) {
+		t.Errorf("unknown sort key: got %v", err)
+	}
+	q, err := NewQuery(nil, nil, "", false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Windows) != 4 || q.Lag != DefaultLag || q.SortBy != "1w" {
+		t.Errorf("got %+v", *q)
+	}
+}
+
+func TestCalcWhaleMomentum(t *testing.T) {
+	item := currency.Currency{ID: 1, Symbol: "BTC"}
+	// 100 days: 100, 101, ..., 199 at now
+	list := series(1, linear(100, 100, 1)...)
+	query, _ := NewQuery(nil, nil, "1m", false, 0)
+
+	res := CalcWhaleMomentum(&item, &list, query, now)
+	if res == nil {
+		t.Fatal("got nil")
+	}
+	// the last value is 2 days before now
+	if res.Whales != 197 || !res.D.Equal(now.Truncate(time.Hour*24).AddDate(0, 0, -2)) {
+		t.Errorf("last: got %v at %v", res.Whales, res.D)
+	}
+	// 1w: 192, 2w: 185, 1m (2025-02-28): 168, 2m (2025-01-31): 140
+	want := []struct {
+		whales float64
+		change float64
+	}{{192, 2.6042}, {185, 6.4865}, {168, 17.2619}, {140, 40.7143}}
+	if len(res.Changes) != len(want) {
+		t.Fatalf("got %+v", res.Changes)
+	}
+	for i := range want {
+		if res.Changes[i].Whales != want[i].whales || res.Changes[i].ChangePercent != want[i].change {
+			t.Errorf("window %s: got %+v, want %+v", res.Changes[i].Window, res.Changes[i], want[i])
+		}
+	}
+	if res.Bonus != 17262 {
+		t.Errorf("bonus: got %v", res.Bonus)
+	}
+	// the bonus is calculated from the exact change 6.486486..., not from the rounded 6.4865
+	query2w, _ := NewQuery(nil, nil, "2w", false, 0)
+	if res := CalcWhaleMomentum(&item, &list, query2w, now); res == nil || res.Bonus != 6486 {
+		t.Errorf("bonus of 2w: got %+v", res)
+	}
+
+	// there is no data of the 2m window
+	short := series(1, linear(40, 100, 1)...)
+	if res := CalcWhaleMomentum(&item, &short, query, now); res != nil {
+		t.Errorf("short series: got %+v", *res)
+	}
+	// the start of a window has no whales
+	zero := series(1, linear(100, 100, 1)...)
+	zero[40].Whales = 0 // 2025-01-31
+	if res := CalcWhaleMomentum(&item, &zero, query, now); res != nil {
+		t.Errorf("zero start: got %+v", *res)
+	}
+}
+
+func TestCalcWhaleMomentumList(t *testing.T) {
+	currencyList := currency.CurrencyList{{ID: 1, Symbol: "A"}, {ID: 2, Symbol: "B"}, {ID: 3, Symbol: "C"}, {ID: 4, Symbol: "D"}}
+	concentrationMap := concentration.ConcentrationMap{
+		1: series(1, linear(30, 100, 1)...),
+		2: series(2, linear(30, 100, -1)...),
+		3: series(3, linear(30, 100, 0)...),
+		// there is no data of the 3w window
+		4: series(4, linear(10, 100, 1)...),
+	}
+	windows, _ := ParseWindowList("1w,3w")
+
+	tests := []struct {
+		name    string
+		desc    bool
+		limit   uint
+		wantIDs []uint
+	}{
+		{name: "asc", wantIDs: []uint{2, 3, 1}},
+		{name: "desc", desc: true, wantIDs: []uint{1, 3, 2}},
+		{name: "limit", desc: true, limit: 2, wantIDs: []uint{1, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := NewQuery(windows, nil, "3w", tt.desc, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res := CalcWhaleMomentumList(&currencyList, concentrationMap, query, now)
+			if len(*res) != len(tt.wantIDs) {
+				t.Fatalf("got %+v, want %v", *res, tt.wantIDs)
+			}
+			for i := range tt.wantIDs {
+				if (*res)[i].CurrencyID != tt.wantIDs[i] {
+					t.Errorf("item %d: got %d, want %d", i, (*res)[i].CurrencyID, tt.wantIDs[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"info/internal/pkg/apperror"
+)
+
+const (
+	lockPrefix        = "lock_"
+	lockFencingPrefix = "lock_fencing_"
+
+	defaultLockTTL = 30 * time.Second
+
+	// lock_script_Renew prolongs the lease only if it is still held by the same token
+	lock_script_Renew = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("PEXPIRE", KEYS[1], ARGV[2]) else return 0 end`
+	// lock_script_Release deletes the lease only if it is still held by the same token
+	lock_script_Release = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) else return 0 end`
+)
+
+// LockDB is a part of the redis client which is used by the lock
+type LockDB interface {
+	Incr(ctx context.Context, key string) *goredis.IntCmd
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *goredis.BoolCmd
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) *goredis.Cmd
+}
+
+// LockRepository is a lease based distributed lock.
+// Every acquired lease gets a fencing token which is greater than the tokens of all the previous leases of the lock,
+// so a store which keeps the token of the last write rejects the writes of a holder which lost its lease.
+type LockRepository struct {
+	db      LockDB
+	metrics RedisMetrics
+	ttl     time.Duration
+}
+
+func NewLockRepository(repository *Repository, ttl time.Duration) *LockRepository {
+	return newLockRepository(repository.DB(), repository.metrics, ttl)
+}
+
+func newLockRepository(db LockDB, metrics RedisMetrics, ttl time.Duration) *LockRepository {
+	if ttl <= 0 {
+		ttl = defaultLockTTL
+	}
+	return &LockRepository{
+		db:      db,
+		metrics: metrics,
+		ttl:     ttl,
+	}
+}
+
+// Acquire takes the named lock; it returns apperror.ErrLocked if the lock is held by somebody else.
+// The lease is renewed in background until Release; the context of the lease is cancelled when the lease is lost.
+func (r *LockRepository) Acquire(ctx context.Context, name string) (*Lease, error) {
+	const metricName = "LockRepository.Acquire"
+	start := time.Now().UTC()
+
+	token, err := r.db.Incr(ctx, lockFencingPrefix+name).Uint64()
+	if err != nil {
+		r.metrics.Inc(metricName, metricsFail)
+		r.metrics.WriteTiming(start, metricName, metricsFail)
+		return nil, fmt.Errorf("[%w] "+metricName+" r.db.Incr() error: %w", apperror.ErrInternal, err)
+	}
+
+	ok, err := r.db.SetNX(ctx, lockPrefix+name, strconv.FormatUint(token, 10), r.ttl).Result()
+	if err != nil {
+		r.metrics.Inc(metricName, metricsFail)
+		r.metrics.WriteTiming(start, metricName, metricsFail)
+		return nil, fmt.Errorf("[%w] "+metricName+" r.db.SetNX() error: %w", apperror.ErrInternal, err)
+	}
+	r.metrics.Inc(metricName, metricsSuccess)
+	r.metrics.WriteTiming(start, metricName, metricsSuccess)
+	if !ok {
+		return nil, fmt.Errorf("[%w] lock %q is held by another instance", apperror.ErrLocked, name)
+	}
+
+	return newLease(ctx, r, name, token), nil
+}
+
+func (r *LockRepository) renew(ctx context.Context, name string, token uint64) (bool, error) {
+	const metricName = "LockRepository.renew"
+	start := time.Now().UTC()
+
+	res, err := r.db.Eval(ctx, lock_script_Renew, []string{lockPrefix + name}, strconv.FormatUint(token, 10), r.ttl.Milliseconds()).Int64()
+	if err != nil {
+		r.metrics.Inc(metricName, metricsFail)
+		r.metrics.WriteTiming(start, metricName, metricsFail)
+		return false, fmt.Errorf("[%w] "+metricName+" r.db.Eval() error: %w", apperror.ErrInternal, err)
+	}
+	r.metrics.Inc(metricName, metricsSuccess)
+	r.metrics.WriteTiming(start, metricName, metricsSuccess)
+	return res == 1, nil
+}
+
+func (r *LockRepository) release(ctx context.Context, name string, token uint64) error {
+	const metricName = "LockRepository.release"
+	start := time.Now().UTC()
+
+	if err := r.db.Eval(ctx, lock_script_Release, []string{lockPrefix + name}, strconv.FormatUint(token, 10)).Err(); err != nil {
+		r.metrics.Inc(metricName, metricsFail)
+		r.metrics.WriteTiming(start, metricName, metricsFail)
+		return fmt.Errorf("[%w] "+metricName+" r.db.Eval() error: %w", apperror.ErrInternal, err)
+	}
+	r.metrics.Inc(metricName, metricsSuccess)
+	r.metrics.WriteTiming(start, metricName, metricsSuccess)
+	return nil
+}
+
+// Lease is an acquired lock
+type Lease struct {
+	repo   *LockRepository
+	name   string
+	token  uint64
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+func newLease(ctx context.Context, repo *LockRepository, name string, token uint64) *Lease {
+	l := &Lease{
+		repo:  repo,
+		name:  name,
+		token: token,
+		done:  make(chan struct{}),
+	}
+	l.ctx, l.cancel = context.WithCancel(ctx)
+	go l.renewLoop()
+	return l
+}
+
+// Token is the fencing token of the lease; a write with a token less than the token of the previous write has to be rejected
+func (l *Lease) Token() uint64 {
+	return l.token
+}
+
+// Context is cancelled when the lease is lost or released
+func (l *Lease) Context() context.Context {
+	return l.ctx
+}
+
+// renewLoop prolongs the lease every third of the TTL; the lease is lost when the key is taken by another token
+// or when it was not renewed successfully during the TTL
+func (l *Lease) renewLoop() {
+	defer close(l.done)
+	defer l.cancel()
+
+	interval := l.repo.ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	renewedAt := time.Now()
+
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ok, err := l.repo.renew(l.ctx, l.name, l.token)
+		if err == nil && !ok {
+			return
+		}
+		if err == nil {
+			renewedAt = time.Now()
+			continue
+		}
+		if time.Since(renewedAt) >= l.repo.ttl {
+			return
+		}
+	}
+}
+
+// Release stops the renewal and deletes the lock if it is still held by the lease
+func (l *Lease) Release(ctx context.Context) (err error) {
+	l.once.Do(func() {
+		l.cancel()
+		<-l.done
+		err = l.repo.release(ctx, l.name, l.token)
+	})
+	return err
+}
@@ -0,0 +1,187 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"info/internal/pkg/apperror"
+)
+
+// fakeLockDB is an in-process stand-in of redis which supports the commands and the scripts of the lock
+type fakeLockDB struct {
+	mu       sync.Mutex
+	counters map[string]int64
+	values   map[string]string
+	expireAt map[string]time.Time
+}
+
+var _ LockDB = (*fakeLockDB)(nil)
+
+func newFakeLockDB() *fakeLockDB {
+	return &fakeLockDB{
+		counters: make(map[string]int64),
+		values:   make(map[string]string),
+		expireAt: make(map[string]time.Time),
+	}
+}
+
+func (db *fakeLockDB) get(key string) (string, bool) {
+	if t, ok := db.expireAt[key]; ok && !time.Now().Before(t) {
+		delete(db.values, key)
+		delete(db.expireAt, key)
+	}
+	v, ok := db.values[key]
+	return v, ok
+}
+
+func (db *fakeLockDB) Incr(ctx context.Context, key string) *goredis.IntCmd {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.counters[key]++
+	return goredis.NewIntResult(db.counters[key], nil)
+}
+
+func (db *fakeLockDB) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *goredis.BoolCmd {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.get(key); ok {
+		return goredis.NewBoolResult(false, nil)
+	}
+	db.values[key] = fmt.Sprint(value)
+	db.expireAt[key] = time.Now().Add(expiration)
+	return goredis.NewBoolResult(true, nil)
+}
+
+func (db *fakeLockDB) Eval(ctx context.Context, script string, keys []string, args ...interface{}) *goredis.Cmd {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if v, ok := db.get(keys[0]); !ok || v != fmt.Sprint(args[0]) {
+		return goredis.NewCmdResult(int64(0), nil)
+	}
+
+	switch script {
+	case lock_script_Renew:
+		ms, err := strconv.ParseInt(fmt.Sprint(args[1]), 10, 64)
+		if err != nil {
+			return goredis.NewCmdResult(nil, err)
+		}
+		db.expireAt[keys[0]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+	case lock_script_Release:
+		delete(db.values, keys[0])
+		delete(db.expireAt, keys[0])
+	default:
+		return goredis.NewCmdResult(nil, errors.New("unknown script"))
+	}
+	return goredis.NewCmdResult(int64(1), nil)
+}
+
+// expire emulates the expiration of the key, e.g. after a long GC pause of the holder
+func (db *fakeLockDB) expire(key string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.expireAt[key] = time.Now()
+}
+
+type fakeRedisMetrics struct{}
+
+func (m fakeRedisMetrics) Inc(query, success string)                              {}
+func (m fakeRedisMetrics) WriteTiming(startTime time.Time, query, success string) {}
+
+func TestLockRepository_AcquireRelease(t *testing.T) {
+	ctx := context.Background()
+	repo := newLockRepository(newFakeLockDB(), fakeRedisMetrics{}, time.Second)
+
+	lease, err := repo.Acquire(ctx, "import")
+	if err != nil {
+		t.Fatalf("Acquire() error: %v", err)
+	}
+
+	if _, err = repo.Acquire(ctx, "import"); !errors.Is(err, apperror.ErrLocked) {
+		t.Fatalf("second Acquire() error = %v, want %v", err, apperror.ErrLocked)
+	}
+
+	other, err := repo.Acquire(ctx, "other")
+	if err != nil {
+		t.Fatalf("Acquire() of another lock error: %v", err)
+	}
+	defer other.Release(ctx)
+
+	if err = lease.Release(ctx); err != nil {
+		t.Fatalf("Release() error: %v", err)
+	}
+	if lease.Context().Err() == nil {
+		t.Fatal("the context of the released lease is not cancelled")
+	}
+
+	next, err := repo.Acquire(ctx, "import")
+	if err != nil {
+		t.Fatalf("Acquire() after Release() error: %v", err)
+	}
+	defer next.Release(ctx)
+
+	if next.Token() <= lease.Token() {
+		t.Fatalf("fencing token %d is not greater than the previous one %d", next.Token(), lease.Token())
+	}
+}
+
+func TestLockRepository_Renew(t *testing.T) {
+	ctx := context.Background()
+	ttl := 150 * time.Millisecond
+	repo := newLockRepository(newFakeLockDB(), fakeRedisMetrics{}, ttl)
+
+	lease, err := repo.Acquire(ctx, "import")
+	if err != nil {
+		t.Fatalf("Acquire() error: %v", err)
+	}
+	defer lease.Release(ctx)
+
+	time.Sleep(3 * ttl)
+
+	if lease.Context().Err() != nil {
+		t.Fatal("the lease is lost in spite of the renewal")
+	}
+	if _, err = repo.Acquire(ctx, "import"); !errors.Is(err, apperror.ErrLocked) {
+		t.Fatalf("Acquire() of the renewed lock error = %v, want %v", err, apperror.ErrLocked)
+	}
+}
+
+func TestLockRepository_LostLease(t *testing.T) {
+	ctx := context.Background()
+	ttl := 150 * time.Millisecond
+	db := newFakeLockDB()
+	repo := newLockRepository(db, fakeRedisMetrics{}, ttl)
+
+	lease, err := repo.Acquire(ctx, "import")
+	if err != nil {
+		t.Fatalf("Acquire() error: %v", err)
+	}
+	defer lease.Release(ctx)
+
+	db.expire(lockPrefix + "import")
+	next, err := repo.Acquire(ctx, "import")
+	if err != nil {
+		t.Fatalf("Acquire() of the expired lock error: %v", err)
+	}
+	defer next.Release(ctx)
+
+	select {
+	case <-lease.Context().Done():
+	case <-time.After(3 * ttl):
+		t.Fatal("the context of the lost lease is not cancelled")
+	}
+
+	// the release of the lost lease must not delete the lock of the new holder
+	if err = lease.Release(ctx); err != nil {
+		t.Fatalf("Release() of the lost lease error: %v", err)
+	}
+	if _, err = repo.Acquire(ctx, "import"); !errors.Is(err, apperror.ErrLocked) {
+		t.Fatalf("Acquire() error = %v, want %v", err, apperror.ErrLocked)
+	}
+}
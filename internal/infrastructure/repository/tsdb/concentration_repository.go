@@ -158,3 +158,54 @@ func (r *ConcentrationRepository) mUpsertTx(ctx context.Context, tx domain.Tx, e
 	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
 	return nil
 }
+
+// MUpsertWithStats upserts the entities and returns the number of the inserted and the updated rows
+func (r *ConcentrationRepository) MUpsertWithStats(ctx context.Context, entities *[]concentration.Concentration) (*domain.UpsertStats, error) {
+	if entities == nil {
+		return &domain.UpsertStats{}, nil
+	}
+	if len(*entities) <= MUpsertConcentration_Limit {
+		return r.mUpsertWithStats(ctx, entities)
+	}
+
+	res := &domain.UpsertStats{}
+	lbound := 0
+	hbound := MUpsertConcentration_Limit
+	for lbound < hbound {
+		entitiesItem := (*entities)[lbound:hbound]
+		stats, err := r.mUpsertWithStats(ctx, &entitiesItem)
+		if err != nil {
+			return nil, err
+		}
+		res.Add(stats)
+		lbound = hbound
+		hbound += MUpsertConcentration_Limit
+		if hbound > len(*entities) {
+			hbound = len(*entities)
+		}
+	}
+	return res, nil
+}
+
+func (r *ConcentrationRepository) mUpsertWithStats(ctx context.Context, entities *[]concentration.Concentration) (*domain.UpsertStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "ConcentrationRepository.mUpsertWithStats"
+	const fields_nb = 5
+	if len(*entities) == 0 {
+		return &domain.UpsertStats{}, nil
+	}
+	b := strings.Builder{}
+	params := make([]interface{}, 0, len(*entities)*fields_nb)
+	b.WriteString(concentration_sql_MUpsert)
+	for i, entity := range *entities {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("($" + strconv.Itoa(i*fields_nb+1) + ", $" + strconv.Itoa(i*fields_nb+2) + ", $" + strconv.Itoa(i*fields_nb+3) + ", $" + strconv.Itoa(i*fields_nb+4) + ", $" + strconv.Itoa(i*fields_nb+5) + ")")
+		params = append(params, entity.CurrencyID, entity.Whales, entity.Investors, entity.Retail, entity.D)
+	}
+	b.WriteString(strings.TrimSuffix(concentration_sql_MUpsert_OnConflictDoUpdate, ";") + sql_ReturningInserted)
+
+	return r.queryUpsertStats(ctx, metricName, b.String(), params...)
+}
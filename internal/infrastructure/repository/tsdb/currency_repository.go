@@ -35,19 +35,26 @@ func NewCurrencyRepository(repository *Repository) *CurrencyRepository {
 const (
 	currency_sql_Get                       = "SELECT id, symbol, slug, name, is_for_observing, circulating_supply, self_reported_circulating_supply, total_supply, max_supply, latest_price, cmc_rank, date_added, platform FROM cmc.currency WHERE id = $1;"
 	currency_sql_GetBySlug                 = "SELECT id, symbol, slug, name, is_for_observing, circulating_supply, self_reported_circulating_supply, total_supply, max_supply, latest_price, cmc_rank, date_added, platform FROM cmc.currency WHERE slug = $1;"
-	currency_sql_GetImportMaxTimeForUpdate = "SELECT currency_id, price_and_cap, concentration FROM cmc.import_max_time WHERE currency_id = ANY($1) FOR UPDATE;"
+	currency_sql_GetImportMaxTimeForUpdate = "SELECT currency_id, price_and_cap, concentration, price_and_cap_fencing_token, concentration_fencing_token FROM cmc.import_max_time WHERE currency_id = ANY($1) FOR UPDATE;"
 	currency_sql_MGet                      = "SELECT id, symbol, slug, name, is_for_observing, circulating_supply, self_reported_circulating_supply, total_supply, max_supply, latest_price, cmc_rank, date_added, platform FROM cmc.currency WHERE id = any($1);"
 	currency_sql_MGetTokenAddress          = "SELECT currency_id, blockchain, address FROM cmc.token_address WHERE currency_id = any($1);"
+	currency_sql_MUpsertTokenAddress       = "INSERT INTO cmc.token_address(currency_id, blockchain, address) VALUES "
 	currency_sql_MGetBySlug                = "SELECT id, symbol, slug, name, is_for_observing, circulating_supply, self_reported_circulating_supply, total_supply, max_supply, latest_price, cmc_rank, date_added, platform FROM cmc.currency WHERE slug = any($1);"
 	currency_sql_GetAll                    = "SELECT id, symbol, slug, name, is_for_observing, circulating_supply, self_reported_circulating_supply, total_supply, max_supply, latest_price, cmc_rank, date_added, platform FROM cmc.currency WHERE is_for_observing = TRUE;"
+	currency_sql_GetList                   = "SELECT id, symbol, slug, name, is_for_observing, circulating_supply, self_reported_circulating_supply, total_supply, max_supply, latest_price, cmc_rank, date_added, platform FROM cmc.currency WHERE $1::boolean IS NULL OR is_for_observing = $1;"
 	currency_sql_Create                    = "INSERT INTO cmc.currency(id, symbol, slug, name, is_for_observing) VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING RETURNING id;"
 	currency_sql_MCreate                   = "INSERT INTO cmc.currency(id, symbol, slug, name, is_for_observing, circulating_supply, self_reported_circulating_supply, total_supply, max_supply, latest_price, cmc_rank, date_added, platform) VALUES "
 	currency_sql_Create_OnConflictDoUpdate = " ON CONFLICT (id) DO UPDATE SET symbol = EXCLUDED.symbol, slug = EXCLUDED.slug, name = EXCLUDED.name, is_for_observing = EXCLUDED.is_for_observing, circulating_supply = EXCLUDED.circulating_supply, self_reported_circulating_supply = EXCLUDED.self_reported_circulating_supply, total_supply = EXCLUDED.total_supply, max_supply = EXCLUDED.max_supply, latest_price = EXCLUDED.latest_price, cmc_rank = EXCLUDED.cmc_rank, date_added = EXCLUDED.date_added, platform = EXCLUDED.platform;"
 	currency_sql_Update                    = "UPDATE cmc.currency SET symbol = $2, slug = $3, name = $4, is_for_observing = $5 WHERE id = $1;"
 	currency_sql_Delete                    = "DELETE FROM cmc.currency WHERE id = $1;"
+	currency_sql_MSetObserving             = "UPDATE cmc.currency SET is_for_observing = $2 WHERE id = any($1);"
 
 	import_max_time_sql_MCreate                    = "INSERT INTO cmc.import_max_time(currency_id, price_and_cap, concentration) VALUES "
-	import_max_time_sql_MCreate_OnConflictDoUpdate = " ON CONFLICT (currency_id) DO UPDATE SET price_and_cap = EXCLUDED.price_and_cap, concentration = EXCLUDED.concentration;"
+	import_max_time_sql_MUpsert                    = "INSERT INTO cmc.import_max_time(currency_id, price_and_cap, concentration, price_and_cap_fencing_token, concentration_fencing_token) VALUES "
+	import_max_time_sql_MUpsert_OnConflictDoUpdate = " ON CONFLICT (currency_id) DO UPDATE SET price_and_cap = EXCLUDED.price_and_cap, concentration = EXCLUDED.concentration, price_and_cap_fencing_token = EXCLUDED.price_and_cap_fencing_token, concentration_fencing_token = EXCLUDED.concentration_fencing_token WHERE cmc.import_max_time.price_and_cap_fencing_token <= EXCLUDED.price_and_cap_fencing_token AND cmc.import_max_time.concentration_fencing_token <= EXCLUDED.concentration_fencing_token;"
+
+	import_error_sql_MUpsert = "INSERT INTO cmc.import_error(currency_id, dataset, error_count, last_error, last_error_at) SELECT $1, dataset, 1, $3, $4 FROM unnest($2::text[]) AS dataset ON CONFLICT (currency_id, dataset) DO UPDATE SET error_count = cmc.import_error.error_count + 1, last_error = EXCLUDED.last_error, last_error_at = EXCLUDED.last_error_at;"
+	import_error_sql_MDelete = "DELETE FROM cmc.import_error WHERE currency_id = $1 AND dataset = any($2);"
 )
 
 func (r *CurrencyRepository) Get(ctx context.Context, ID uint) (*currency.Currency, error) {
@@ -117,7 +124,7 @@ func (r *CurrencyRepository) GetImportMaxTimeForUpdateTx(ctx context.Context, tx
 	defer rows.Close()
 
 	for rows.Next() {
-		if err = rows.Scan(&entity.CurrencyID, &entity.PriceAndCap, &entity.Concentration); err != nil {
+		if err = rows.Scan(&entity.CurrencyID, &entity.PriceAndCap, &entity.Concentration, &entity.PriceAndCapFencingToken, &entity.ConcentrationFencingToken); err != nil {
 			r.metrics.SqlMetrics.Inc(metricName, metricsFail)
 			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
 			return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, currency_sql_GetImportMaxTimeForUpdate, err)
@@ -254,6 +261,47 @@ func (r *CurrencyRepository) GetAll(ctx context.Context) (*currency.CurrencyList
 	return &res, nil
 }
 
+// GetList returns the observed or the not observed currencies; all the currencies if isForObserving is nil
+func (r *CurrencyRepository) GetList(ctx context.Context, isForObserving *bool) (*currency.CurrencyList, error) {
+	//ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	//defer cancel()
+	const metricName = "CurrencyRepository.GetList"
+
+	var entity currency.Currency
+	res := make(currency.CurrencyList, 0, defaultCapacityForResult)
+
+	start := time.Now().UTC()
+	rows, err := r.db.Query(ctx, currency_sql_GetList, isForObserving)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+			return nil, apperror.ErrNotFound
+		}
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, currency_sql_GetList, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&entity.ID, &entity.Symbol, &entity.Slug, &entity.Name, &entity.IsForObserving, &entity.CirculatingSupply, &entity.SelfReportedCirculatingSupply, &entity.TotalSupply, &entity.MaxSupply, &entity.LatestPrice, &entity.CmcRank, &entity.AddedAt, &entity.Platform); err != nil {
+			r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+			return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, currency_sql_GetList, err)
+		}
+		res = append(res, entity)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+
+	if len(res) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+
+	return &res, nil
+}
+
 func (r *CurrencyRepository) Create(ctx context.Context, entity *currency.Currency) (ID uint, err error) {
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
@@ -319,6 +367,26 @@ func (r *CurrencyRepository) Delete(ctx context.Context, ID uint) error {
 	return nil
 }
 
+func (r *CurrencyRepository) MSetObserving(ctx context.Context, IDs *[]uint, isForObserving bool) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "CurrencyRepository.MSetObserving"
+	if len(*IDs) == 0 {
+		return nil
+	}
+	start := time.Now().UTC()
+
+	_, err := r.db.Exec(ctx, currency_sql_MSetObserving, *IDs, isForObserving)
+	if err != nil {
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, currency_sql_MSetObserving, err)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+	return nil
+}
+
 func (r CurrencyRepository) MCreateImportMaxTime(ctx context.Context, entities *[]currency.ImportMaxTime) error {
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
@@ -360,28 +428,100 @@ func (r CurrencyRepository) MUpsertImportMaxTimeMapTx(ctx context.Context, tx do
 	return r.MUpsertImportMaxTimeTx(ctx, tx, &list)
 }
 
+// MUpsertImportMaxTimeTx upserts the checkpoints; it returns apperror.ErrLocked if a checkpoint is not updated because of a stale fencing token
 func (r CurrencyRepository) MUpsertImportMaxTimeTx(ctx context.Context, tx domain.Tx, entities *[]currency.ImportMaxTime) error {
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 	const metricName = "PriceAndCapRepository.MUpsertImportMaxTime"
-	const fields_nb = 3 // при изменении количества полей нужно изменить MUpsertNmDimensions_Limit, чтобы, в результате, кол-во пар-ов не превышало 65т
+	const fields_nb = 5 // при изменении количества полей нужно изменить MUpsertNmDimensions_Limit, чтобы, в результате, кол-во пар-ов не превышало 65т
 	if len(*entities) == 0 {
 		return nil
 	}
 	b := strings.Builder{}
 	params := make([]interface{}, 0, len(*entities)*fields_nb)
-	b.WriteString(import_max_time_sql_MCreate)
+	b.WriteString(import_max_time_sql_MUpsert)
+	for i, entity := range *entities {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("($" + strconv.Itoa(i*fields_nb+1) + ", $" + strconv.Itoa(i*fields_nb+2) + ", $" + strconv.Itoa(i*fields_nb+3) + ", $" + strconv.Itoa(i*fields_nb+4) + ", $" + strconv.Itoa(i*fields_nb+5) + ")")
+		params = append(params, entity.CurrencyID, entity.PriceAndCap, entity.Concentration, int64(entity.PriceAndCapFencingToken), int64(entity.ConcentrationFencingToken))
+	}
+	b.WriteString(import_max_time_sql_MUpsert_OnConflictDoUpdate)
+	start := time.Now().UTC()
+
+	tag, err := tx.Exec(ctx, b.String(), params...)
+	if err != nil {
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, b.String(), err)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+
+	if tag.RowsAffected() < int64(len(*entities)) {
+		return fmt.Errorf("[%w] %s the checkpoint is imported under a newer lock; stale fencing token", apperror.ErrLocked, metricName)
+	}
+	return nil
+}
+
+// UpsertImportError records the failed import of the datasets of the currency; the checkpoint and the errors of the other datasets stay as they were
+func (r *CurrencyRepository) UpsertImportError(ctx context.Context, currencyID uint, datasets []string, errText string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "CurrencyRepository.UpsertImportError"
+	start := time.Now().UTC()
+
+	if _, err := r.db.Exec(ctx, import_error_sql_MUpsert, currencyID, datasets, errText, start); err != nil {
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, import_error_sql_MUpsert, err)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+	return nil
+}
+
+// DeleteImportErrorTx clears the errors of the successfully imported datasets of the currency
+func (r *CurrencyRepository) DeleteImportErrorTx(ctx context.Context, tx domain.Tx, currencyID uint, datasets []string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "CurrencyRepository.DeleteImportErrorTx"
+	start := time.Now().UTC()
+
+	if _, err := tx.Exec(ctx, import_error_sql_MDelete, currencyID, datasets); err != nil {
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, import_error_sql_MDelete, err)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+	return nil
+}
+
+// MUpsertTokenAddress creates the new pairs of the blockchain and the address of the currencies; the known ones stay as they are
+func (r *CurrencyRepository) MUpsertTokenAddress(ctx context.Context, entities *currency.TokenAddressList) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "CurrencyRepository.MUpsertTokenAddress"
+	const fields_nb = 3
+	if len(*entities) == 0 {
+		return nil
+	}
+	b := strings.Builder{}
+	params := make([]interface{}, 0, len(*entities)*fields_nb)
+	b.WriteString(currency_sql_MUpsertTokenAddress)
 	for i, entity := range *entities {
 		if i > 0 {
 			b.WriteString(", ")
 		}
 		b.WriteString("($" + strconv.Itoa(i*fields_nb+1) + ", $" + strconv.Itoa(i*fields_nb+2) + ", $" + strconv.Itoa(i*fields_nb+3) + ")")
-		params = append(params, entity.CurrencyID, entity.PriceAndCap, entity.Concentration)
+		params = append(params, entity.CurrencyID, entity.Blockchain, entity.Address)
 	}
-	b.WriteString(import_max_time_sql_MCreate_OnConflictDoUpdate)
+	b.WriteString(sql_OnConflictDoNothing)
 	start := time.Now().UTC()
 
-	_, err := tx.Exec(ctx, b.String(), params...)
+	_, err := r.db.Exec(ctx, b.String(), params...)
 	if err != nil {
 		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
 		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
@@ -613,20 +753,20 @@ ORDER BY time;
 
 
 Сводная таблица
-SELECT c.symbol, w.whales_prc, ((GREATEST(c.circulating_supply, c.self_reported_circulating_supply) * c.latest_price)/1000000)::integer AS cap, ((coalesce(c.max_supply, c.total_supply) * c.latest_price)/1000000)::integer AS fdv, w.to_ath, w.from_atl, d.bonus, round(cast(coalesce(pit.crypto_holdings, 0) AS numeric), 2) AS crypto_holdings, round(cast(coalesce(pit.pl_percent_value, 0) AS numeric), 2) AS pl_percent_value
+SELECT c.symbol, ((GREATEST(c.circulating_supply, c.self_reported_circulating_supply) * c.latest_price)/1000000)::integer AS cap, ((coalesce(c.max_supply, c.total_supply) * c.latest_price)/1000000)::integer AS fdv, round(cast(coalesce(pit.crypto_holdings, 0) AS numeric), 2) AS crypto_holdings, round(cast(coalesce(pit.pl_percent_value, 0) AS numeric), 2) AS pl_percent_value
 FROM
 	currency AS c
-	LEFT JOIN whales_prc_and_min_max_price AS w ON c.id = w.id
-	LEFT JOIN cm1 AS d ON c.id = d.id
+	-- whales_prc, to_ath, from_atl (ex whales_prc_and_min_max_price) are computed on the daily candles by currency.Service.Report_AthAtl, GET /cmc/report/ath-atl
+	-- bonus (ex cm1) is computed by whale_momentum.Service, GET /cmc/report/whale-momentum?sort=1m
 	LEFT JOIN portfolio_item AS pit ON pit.portfolio_source_id = '6651f947db928013879d191c' AND c.id = pit.currency_id
 WHERE c.is_for_observing = true
 ORDER BY c.cmc_rank;
 
 Сводная таблица V2
-select c.symbol, w.whales_prc, ((GREATEST(c.circulating_supply, c.self_reported_circulating_supply) * c.latest_price)/1000000)::integer as cap, ((coalesce(c.max_supply, c.total_supply) * c.latest_price)/1000000)::integer as fdv, w.to_ath, w.from_atl, oa.whales_concentration, d.bonus, round(cast(coalesce(pit.crypto_holdings, 0) AS numeric), 2) as crypto_holdings, round(cast(coalesce(pit.pl_percent_value, 0) AS numeric), 2) as pl_percent_value
+select c.symbol, ((GREATEST(c.circulating_supply, c.self_reported_circulating_supply) * c.latest_price)/1000000)::integer as cap, ((coalesce(c.max_supply, c.total_supply) * c.latest_price)/1000000)::integer as fdv, oa.whales_concentration, round(cast(coalesce(pit.crypto_holdings, 0) AS numeric), 2) as crypto_holdings, round(cast(coalesce(pit.pl_percent_value, 0) AS numeric), 2) as pl_percent_value
 from currency as c
-left join whales_prc_and_min_max_price as w on c.id = w.id
-left join cw2 as d on c.id = d.id
+-- whales_prc, to_ath, from_atl (ex whales_prc_and_min_max_price) are computed on the daily candles by currency.Service.Report_AthAtl, GET /cmc/report/ath-atl
+-- bonus (ex cw2) is computed by whale_momentum.Service, GET /cmc/report/whale-momentum?sort=2w
 left join portfolio_item as pit on pit.portfolio_source_id = '6651f947db928013879d191c' and c.id = pit.currency_id
 left join (
 	select oa.currency_id, oa.whales_concentration
@@ -641,10 +781,10 @@ where c.is_for_observing = true
 order by c.cmc_rank;
 
 Сводная таблица V3
-select c.symbol, w.whales_prc, ((GREATEST(c.circulating_supply, c.self_reported_circulating_supply) * c.latest_price)/1000000)::integer as cap, ((coalesce(c.max_supply, c.total_supply) * c.latest_price)/1000000)::integer as fdv, w.to_ath, w.from_atl, oa.whales_concentration, round(cast(coalesce(pit.buy_avg_price, 0) AS numeric), 2) as buy_avg_price, round(cast(coalesce(pit.current_price, 0) AS numeric), 2) as current_price, round(cast(coalesce(pit.pl_percent_value, 0) AS numeric), 2)*100 as pl_percent_value, round(cast(coalesce(pit.holdings_percent, 0) AS numeric), 2)*100 as holdings_percent, round(cast(coalesce(pit.total_buy_spent, 0) AS numeric), 2) as total_buy_spent, round(cast(coalesce(pit.crypto_holdings, 0) AS numeric), 2) as crypto_holdings
+select c.symbol, ((GREATEST(c.circulating_supply, c.self_reported_circulating_supply) * c.latest_price)/1000000)::integer as cap, ((coalesce(c.max_supply, c.total_supply) * c.latest_price)/1000000)::integer as fdv, oa.whales_concentration, round(cast(coalesce(pit.buy_avg_price, 0) AS numeric), 2) as buy_avg_price, round(cast(coalesce(pit.current_price, 0) AS numeric), 2) as current_price, round(cast(coalesce(pit.pl_percent_value, 0) AS numeric), 2)*100 as pl_percent_value, round(cast(coalesce(pit.holdings_percent, 0) AS numeric), 2)*100 as holdings_percent, round(cast(coalesce(pit.total_buy_spent, 0) AS numeric), 2) as total_buy_spent, round(cast(coalesce(pit.crypto_holdings, 0) AS numeric), 2) as crypto_holdings
 from currency as c
-left join whales_prc_and_min_max_price as w on c.id = w.id
-left join cw2 as d on c.id = d.id
+-- whales_prc, to_ath, from_atl (ex whales_prc_and_min_max_price) are computed on the daily candles by currency.Service.Report_AthAtl, GET /cmc/report/ath-atl
+-- bonus (ex cw2) is computed by whale_momentum.Service, GET /cmc/report/whale-momentum?sort=2w
 left join portfolio_item as pit on pit.portfolio_source_id = '6651f947db928013879d191c' and c.id = pit.currency_id
 left join (
 	select oa.currency_id, oa.whales_concentration
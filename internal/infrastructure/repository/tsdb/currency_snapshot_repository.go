@@ -0,0 +1,130 @@
+package tsdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
+
+	"info/internal/domain/currency_snapshot"
+	"info/internal/pkg/apperror"
+)
+
+type CurrencySnapshotRepository struct {
+	*Repository
+}
+
+var _ currency_snapshot.WriteRepository = (*CurrencySnapshotRepository)(nil)
+var _ currency_snapshot.ReadRepository = (*CurrencySnapshotRepository)(nil)
+
+func NewCurrencySnapshotRepository(repository *Repository) *CurrencySnapshotRepository {
+	return &CurrencySnapshotRepository{
+		Repository: repository,
+	}
+}
+
+const (
+	MUpsertCurrencySnapshot_Limit = 8000 // 8 пар-ов * 8т = 64т ~= max
+
+	currency_snapshot_sql_GetList                    = "SELECT currency_id, cmc_rank, circulating_supply, self_reported_circulating_supply, total_supply, max_supply, latest_price, ts FROM cmc.currency_snapshot WHERE currency_id = $1 AND ts >= $2 AND ts < $3 ORDER BY ts;"
+	currency_snapshot_sql_MUpsert                    = "INSERT INTO cmc.currency_snapshot(currency_id, cmc_rank, circulating_supply, self_reported_circulating_supply, total_supply, max_supply, latest_price, ts) VALUES "
+	currency_snapshot_sql_MUpsert_OnConflictDoUpdate = " ON CONFLICT (currency_id, ts) DO UPDATE SET cmc_rank = EXCLUDED.cmc_rank, circulating_supply = EXCLUDED.circulating_supply, self_reported_circulating_supply = EXCLUDED.self_reported_circulating_supply, total_supply = EXCLUDED.total_supply, max_supply = EXCLUDED.max_supply, latest_price = EXCLUDED.latest_price;"
+)
+
+func (r *CurrencySnapshotRepository) GetList(ctx context.Context, currencyID uint, from time.Time, to time.Time) (*currency_snapshot.CurrencySnapshotList, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "CurrencySnapshotRepository.GetList"
+
+	var entity currency_snapshot.CurrencySnapshot
+	res := make(currency_snapshot.CurrencySnapshotList, 0, defaultCapacityForResult)
+
+	start := time.Now().UTC()
+	rows, err := r.db.Query(ctx, currency_snapshot_sql_GetList, currencyID, from, to)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+			return nil, apperror.ErrNotFound
+		}
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, currency_snapshot_sql_GetList, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&entity.CurrencyID, &entity.CmcRank, &entity.CirculatingSupply, &entity.SelfReportedCirculatingSupply, &entity.TotalSupply, &entity.MaxSupply, &entity.LatestPrice, &entity.Ts); err != nil {
+			r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+			return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, currency_snapshot_sql_GetList, err)
+		}
+		res = append(res, entity)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+
+	if len(res) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+
+	return &res, nil
+}
+
+func (r *CurrencySnapshotRepository) MUpsert(ctx context.Context, entities *currency_snapshot.CurrencySnapshotList) error {
+	if len(*entities) <= MUpsertCurrencySnapshot_Limit {
+		return r.mUpsert(ctx, entities)
+	}
+
+	lbound := 0
+	hbound := MUpsertCurrencySnapshot_Limit
+	for lbound < hbound {
+		entitiesItem := (*entities)[lbound:hbound]
+		if err := r.mUpsert(ctx, &entitiesItem); err != nil {
+			return err
+		}
+		lbound = hbound
+		hbound += MUpsertCurrencySnapshot_Limit
+		if hbound > len(*entities) {
+			hbound = len(*entities)
+		}
+	}
+	return nil
+}
+
+func (r *CurrencySnapshotRepository) mUpsert(ctx context.Context, entities *currency_snapshot.CurrencySnapshotList) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "CurrencySnapshotRepository.MUpsert"
+	const fields_nb = 8 // при изменении количества полей нужно изменить MUpsertCurrencySnapshot_Limit, чтобы, в результате, кол-во пар-ов не превышало 65т
+	if len(*entities) == 0 {
+		return nil
+	}
+	b := strings.Builder{}
+	params := make([]interface{}, 0, len(*entities)*fields_nb)
+	b.WriteString(currency_snapshot_sql_MUpsert)
+	for i, entity := range *entities {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("($" + strconv.Itoa(i*fields_nb+1) + ", $" + strconv.Itoa(i*fields_nb+2) + ", $" + strconv.Itoa(i*fields_nb+3) + ", $" + strconv.Itoa(i*fields_nb+4) + ", $" + strconv.Itoa(i*fields_nb+5) + ", $" + strconv.Itoa(i*fields_nb+6) + ", $" + strconv.Itoa(i*fields_nb+7) + ", $" + strconv.Itoa(i*fields_nb+8) + ")")
+		params = append(params, entity.CurrencyID, entity.CmcRank, entity.CirculatingSupply, entity.SelfReportedCirculatingSupply, entity.TotalSupply, entity.MaxSupply, entity.LatestPrice, entity.Ts)
+	}
+	b.WriteString(currency_snapshot_sql_MUpsert_OnConflictDoUpdate)
+	start := time.Now().UTC()
+
+	_, err := r.db.Exec(ctx, b.String(), params...)
+	if err != nil {
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, b.String(), err)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+	return nil
+}
@@ -0,0 +1,154 @@
+package tsdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
+
+	"info/internal/domain/global_metrics"
+	"info/internal/pkg/apperror"
+)
+
+type GlobalMetricsRepository struct {
+	*Repository
+}
+
+var _ global_metrics.WriteRepository = (*GlobalMetricsRepository)(nil)
+var _ global_metrics.ReadRepository = (*GlobalMetricsRepository)(nil)
+
+func NewGlobalMetricsRepository(repository *Repository) *GlobalMetricsRepository {
+	return &GlobalMetricsRepository{
+		Repository: repository,
+	}
+}
+
+const (
+	MUpsertGlobalMetrics_Limit = 9000 // 7 пар-ов * 9т = 63т ~= max
+
+	global_metrics_sql_GetLast = "SELECT total_market_cap, total_volume_24h, btc_dominance, eth_dominance, stablecoin_market_cap, defi_market_cap, ts FROM cmc.global_metrics ORDER BY ts DESC LIMIT 1;"
+	global_metrics_sql_GetList = "SELECT total_market_cap, total_volume_24h, btc_dominance, eth_dominance, stablecoin_market_cap, defi_market_cap, ts FROM cmc.global_metrics WHERE ts >= $1 AND ts < $2 ORDER BY ts;"
+	global_metrics_sql_MUpsert = "INSERT INTO cmc.global_metrics(total_market_cap, total_volume_24h, btc_dominance, eth_dominance, stablecoin_market_cap, defi_market_cap, ts) VALUES "
+	// the history has no stablecoin and DeFi caps, so they do not replace the stored ones with null
+	global_metrics_sql_MUpsert_OnConflictDoUpdate = " ON CONFLICT (ts) DO UPDATE SET total_market_cap = EXCLUDED.total_market_cap, total_volume_24h = EXCLUDED.total_volume_24h, btc_dominance = EXCLUDED.btc_dominance, eth_dominance = EXCLUDED.eth_dominance, stablecoin_market_cap = COALESCE(EXCLUDED.stablecoin_market_cap, cmc.global_metrics.stablecoin_market_cap), defi_market_cap = COALESCE(EXCLUDED.defi_market_cap, cmc.global_metrics.defi_market_cap);"
+)
+
+func (r *GlobalMetricsRepository) GetLast(ctx context.Context) (*global_metrics.GlobalMetrics, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "GlobalMetricsRepository.GetLast"
+	start := time.Now().UTC()
+
+	entity := &global_metrics.GlobalMetrics{}
+	if err := r.db.QueryRow(ctx, global_metrics_sql_GetLast).Scan(&entity.TotalMarketCap, &entity.TotalVolume24h, &entity.BtcDominance, &entity.EthDominance, &entity.StablecoinMarketCap, &entity.DefiMarketCap, &entity.Ts); err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+			return nil, apperror.ErrNotFound
+		}
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, global_metrics_sql_GetLast, err)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+	return entity, nil
+}
+
+func (r *GlobalMetricsRepository) GetList(ctx context.Context, from time.Time, to time.Time) (*global_metrics.GlobalMetricsList, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "GlobalMetricsRepository.GetList"
+
+	var entity global_metrics.GlobalMetrics
+	res := make(global_metrics.GlobalMetricsList, 0, defaultCapacityForResult)
+
+	start := time.Now().UTC()
+	rows, err := r.db.Query(ctx, global_metrics_sql_GetList, from, to)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+			return nil, apperror.ErrNotFound
+		}
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, global_metrics_sql_GetList, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&entity.TotalMarketCap, &entity.TotalVolume24h, &entity.BtcDominance, &entity.EthDominance, &entity.StablecoinMarketCap, &entity.DefiMarketCap, &entity.Ts); err != nil {
+			r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+			return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, global_metrics_sql_GetList, err)
+		}
+		res = append(res, entity)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+
+	if len(res) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+
+	return &res, nil
+}
+
+func (r *GlobalMetricsRepository) MUpsert(ctx context.Context, entities *global_metrics.GlobalMetricsList) error {
+	if len(*entities) <= MUpsertGlobalMetrics_Limit {
+		return r.mUpsert(ctx, entities)
+	}
+
+	lbound := 0
+	hbound := MUpsertGlobalMetrics_Limit
+	for lbound < hbound {
+		entitiesItem := (*entities)[lbound:hbound]
+		if err := r.mUpsert(ctx, &entitiesItem); err != nil {
+			return err
+		}
+		lbound = hbound
+		hbound += MUpsertGlobalMetrics_Limit
+		if hbound > len(*entities) {
+			hbound = len(*entities)
+		}
+	}
+	return nil
+}
+
+func (r *GlobalMetricsRepository) mUpsert(ctx context.Context, entities *global_metrics.GlobalMetricsList) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "GlobalMetricsRepository.MUpsert"
+	const fields_nb = 7 // при изменении количества полей нужно изменить MUpsertGlobalMetrics_Limit, чтобы, в результате, кол-во пар-ов не превышало 65т
+	if len(*entities) == 0 {
+		return nil
+	}
+	b := strings.Builder{}
+	params := make([]interface{}, 0, len(*entities)*fields_nb)
+	b.WriteString(global_metrics_sql_MUpsert)
+	for i, entity := range *entities {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("($" + strconv.Itoa(i*fields_nb+1) + ", $" + strconv.Itoa(i*fields_nb+2) + ", $" + strconv.Itoa(i*fields_nb+3) + ", $" + strconv.Itoa(i*fields_nb+4) + ", $" + strconv.Itoa(i*fields_nb+5) + ", $" + strconv.Itoa(i*fields_nb+6) + ", $" + strconv.Itoa(i*fields_nb+7) + ")")
+		params = append(params, entity.TotalMarketCap, entity.TotalVolume24h, entity.BtcDominance, entity.EthDominance, entity.StablecoinMarketCap, entity.DefiMarketCap, entity.Ts)
+	}
+	b.WriteString(global_metrics_sql_MUpsert_OnConflictDoUpdate)
+	start := time.Now().UTC()
+
+	_, err := r.db.Exec(ctx, b.String(), params...)
+	if err != nil {
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, b.String(), err)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+	return nil
+}
@@ -0,0 +1,114 @@
+package tsdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
+
+	"info/internal/domain/import_run"
+	"info/internal/pkg/apperror"
+)
+
+type ImportRunRepository struct {
+	*Repository
+}
+
+var _ import_run.WriteRepository = (*ImportRunRepository)(nil)
+var _ import_run.ReadRepository = (*ImportRunRepository)(nil)
+
+func NewImportRunRepository(repository *Repository) *ImportRunRepository {
+	return &ImportRunRepository{
+		Repository: repository,
+	}
+}
+
+const (
+	import_run_sql_GetList        = "SELECT id, stage, started_at, ended_at, currencies_attempted, currencies_succeeded, currencies_failed, rows_upserted, credits_used, coalesce(error, '') FROM cmc.import_run ORDER BY started_at DESC LIMIT $1;"
+	import_run_sql_GetListByStage = "SELECT id, stage, started_at, ended_at, currencies_attempted, currencies_succeeded, currencies_failed, rows_upserted, credits_used, coalesce(error, '') FROM cmc.import_run WHERE stage = $1 ORDER BY started_at DESC LIMIT $2;"
+	import_run_sql_Create         = "INSERT INTO cmc.import_run(stage, started_at) VALUES ($1, $2) RETURNING id;"
+	import_run_sql_Update         = "UPDATE cmc.import_run SET ended_at = $2, currencies_attempted = $3, currencies_succeeded = $4, currencies_failed = $5, rows_upserted = $6, credits_used = $7, error = nullif($8, '') WHERE id = $1;"
+)
+
+func (r *ImportRunRepository) GetList(ctx context.Context, stage string, limit uint) (*import_run.ImportRunList, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "ImportRunRepository.GetList"
+
+	var entity import_run.ImportRun
+	var rows pgx.Rows
+	var err error
+	query := import_run_sql_GetList
+	res := make(import_run.ImportRunList, 0, limit)
+
+	start := time.Now().UTC()
+	if stage == "" {
+		rows, err = r.db.Query(ctx, query, limit)
+	} else {
+		query = import_run_sql_GetListByStage
+		rows, err = r.db.Query(ctx, query, stage, limit)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+			return nil, apperror.ErrNotFound
+		}
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, query, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&entity.ID, &entity.Stage, &entity.StartedAt, &entity.EndedAt, &entity.CurrenciesAttempted, &entity.CurrenciesSucceeded, &entity.CurrenciesFailed, &entity.RowsUpserted, &entity.CreditsUsed, &entity.Error); err != nil {
+			r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+			return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, query, err)
+		}
+		res = append(res, entity)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+
+	if len(res) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+
+	return &res, nil
+}
+
+func (r *ImportRunRepository) Create(ctx context.Context, entity *import_run.ImportRun) (ID uint, err error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "ImportRunRepository.Create"
+	start := time.Now().UTC()
+
+	if err := r.db.QueryRow(ctx, import_run_sql_Create, entity.Stage, entity.StartedAt).Scan(&ID); err != nil {
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return 0, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, import_run_sql_Create, err)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+	return ID, nil
+}
+
+func (r *ImportRunRepository) Update(ctx context.Context, entity *import_run.ImportRun) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "ImportRunRepository.Update"
+	start := time.Now().UTC()
+
+	if _, err := r.db.Exec(ctx, import_run_sql_Update, entity.ID, entity.EndedAt, entity.CurrenciesAttempted, entity.CurrenciesSucceeded, entity.CurrenciesFailed, entity.RowsUpserted, entity.CreditsUsed, entity.Error); err != nil {
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, import_run_sql_Update, err)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+	return nil
+}
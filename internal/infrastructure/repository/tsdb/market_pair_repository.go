@@ -0,0 +1,206 @@
+package tsdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
+
+	"info/internal/domain/market_pair"
+	"info/internal/pkg/apperror"
+)
+
+type MarketPairRepository struct {
+	*Repository
+}
+
+var _ market_pair.WriteRepository = (*MarketPairRepository)(nil)
+var _ market_pair.ReadRepository = (*MarketPairRepository)(nil)
+
+func NewMarketPairRepository(repository *Repository) *MarketPairRepository {
+	return &MarketPairRepository{
+		Repository: repository,
+	}
+}
+
+const (
+	MUpsertMarketPair_Limit = 5000 // 12 пар-ов * 5т = 60т ~= max
+
+	market_pair_sql_GetLatest                  = "SELECT currency_id, exchange_id, market_id, pair, category, base_symbol, quote_symbol, price, volume_usd, depth_negative_two, depth_positive_two, ts FROM cmc.market_pair WHERE currency_id = $1 AND ts = (SELECT max(ts) FROM cmc.market_pair WHERE currency_id = $1) ORDER BY volume_usd DESC;"
+	market_pair_sql_MUpsert                    = "INSERT INTO cmc.market_pair(currency_id, exchange_id, market_id, pair, category, base_symbol, quote_symbol, price, volume_usd, depth_negative_two, depth_positive_two, ts) VALUES "
+	market_pair_sql_MUpsert_OnConflictDoUpdate = " ON CONFLICT (currency_id, market_id, ts) DO UPDATE SET exchange_id = EXCLUDED.exchange_id, pair = EXCLUDED.pair, category = EXCLUDED.category, base_symbol = EXCLUDED.base_symbol, quote_symbol = EXCLUDED.quote_symbol, price = EXCLUDED.price, volume_usd = EXCLUDED.volume_usd, depth_negative_two = EXCLUDED.depth_negative_two, depth_positive_two = EXCLUDED.depth_positive_two;"
+
+	exchange_sql_MGet                       = "SELECT id, name, slug FROM cmc.exchange WHERE id = any($1);"
+	exchange_sql_MUpsert                    = "INSERT INTO cmc.exchange(id, name, slug) VALUES "
+	exchange_sql_MUpsert_OnConflictDoUpdate = " ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, slug = EXCLUDED.slug;"
+)
+
+func (r *MarketPairRepository) GetLatest(ctx context.Context, currencyID uint) (*market_pair.MarketPairList, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "MarketPairRepository.GetLatest"
+
+	var entity market_pair.MarketPair
+	res := make(market_pair.MarketPairList, 0, defaultCapacityForResult)
+
+	start := time.Now().UTC()
+	rows, err := r.db.Query(ctx, market_pair_sql_GetLatest, currencyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+			return nil, apperror.ErrNotFound
+		}
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, market_pair_sql_GetLatest, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&entity.CurrencyID, &entity.ExchangeID, &entity.MarketID, &entity.Pair, &entity.Category, &entity.BaseSymbol, &entity.QuoteSymbol, &entity.Price, &entity.VolumeUsd, &entity.DepthNegativeTwo, &entity.DepthPositiveTwo, &entity.Ts); err != nil {
+			r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+			return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, market_pair_sql_GetLatest, err)
+		}
+		res = append(res, entity)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+
+	if len(res) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+
+	return &res, nil
+}
+
+func (r *MarketPairRepository) MGetExchange(ctx context.Context, IDs *[]uint) (*market_pair.ExchangeList, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "MarketPairRepository.MGetExchange"
+
+	var entity market_pair.Exchange
+	res := make(market_pair.ExchangeList, 0, len(*IDs))
+
+	start := time.Now().UTC()
+	rows, err := r.db.Query(ctx, exchange_sql_MGet, *IDs)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+			return nil, apperror.ErrNotFound
+		}
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, exchange_sql_MGet, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&entity.ID, &entity.Name, &entity.Slug); err != nil {
+			r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+			return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, exchange_sql_MGet, err)
+		}
+		res = append(res, entity)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+
+	if len(res) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+
+	return &res, nil
+}
+
+func (r *MarketPairRepository) MUpsertExchange(ctx context.Context, entities *market_pair.ExchangeList) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "MarketPairRepository.MUpsertExchange"
+	const fields_nb = 3
+	if len(*entities) == 0 {
+		return nil
+	}
+	b := strings.Builder{}
+	params := make([]interface{}, 0, len(*entities)*fields_nb)
+	b.WriteString(exchange_sql_MUpsert)
+	for i, entity := range *entities {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("($" + strconv.Itoa(i*fields_nb+1) + ", $" + strconv.Itoa(i*fields_nb+2) + ", $" + strconv.Itoa(i*fields_nb+3) + ")")
+		params = append(params, entity.ID, entity.Name, entity.Slug)
+	}
+	b.WriteString(exchange_sql_MUpsert_OnConflictDoUpdate)
+	start := time.Now().UTC()
+
+	_, err := r.db.Exec(ctx, b.String(), params...)
+	if err != nil {
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, b.String(), err)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+	return nil
+}
+
+func (r *MarketPairRepository) MUpsert(ctx context.Context, entities *market_pair.MarketPairList) error {
+	if len(*entities) <= MUpsertMarketPair_Limit {
+		return r.mUpsert(ctx, entities)
+	}
+
+	lbound := 0
+	hbound := MUpsertMarketPair_Limit
+	for lbound < hbound {
+		entitiesItem := (*entities)[lbound:hbound]
+		if err := r.mUpsert(ctx, &entitiesItem); err != nil {
+			return err
+		}
+		lbound = hbound
+		hbound += MUpsertMarketPair_Limit
+		if hbound > len(*entities) {
+			hbound = len(*entities)
+		}
+	}
+	return nil
+}
+
+func (r *MarketPairRepository) mUpsert(ctx context.Context, entities *market_pair.MarketPairList) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "MarketPairRepository.MUpsert"
+	const fields_nb = 12 // при изменении количества полей нужно изменить MUpsertMarketPair_Limit, чтобы, в результате, кол-во пар-ов не превышало 65т
+	if len(*entities) == 0 {
+		return nil
+	}
+	b := strings.Builder{}
+	params := make([]interface{}, 0, len(*entities)*fields_nb)
+	b.WriteString(market_pair_sql_MUpsert)
+	for i, entity := range *entities {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("($" + strconv.Itoa(i*fields_nb+1) + ", $" + strconv.Itoa(i*fields_nb+2) + ", $" + strconv.Itoa(i*fields_nb+3) + ", $" + strconv.Itoa(i*fields_nb+4) + ", $" + strconv.Itoa(i*fields_nb+5) + ", $" + strconv.Itoa(i*fields_nb+6) + ", $" + strconv.Itoa(i*fields_nb+7) + ", $" + strconv.Itoa(i*fields_nb+8) + ", $" + strconv.Itoa(i*fields_nb+9) + ", $" + strconv.Itoa(i*fields_nb+10) + ", $" + strconv.Itoa(i*fields_nb+11) + ", $" + strconv.Itoa(i*fields_nb+12) + ")")
+		params = append(params, entity.CurrencyID, entity.ExchangeID, entity.MarketID, entity.Pair, entity.Category, entity.BaseSymbol, entity.QuoteSymbol, entity.Price, entity.VolumeUsd, entity.DepthNegativeTwo, entity.DepthPositiveTwo, entity.Ts)
+	}
+	b.WriteString(market_pair_sql_MUpsert_OnConflictDoUpdate)
+	start := time.Now().UTC()
+
+	_, err := r.db.Exec(ctx, b.String(), params...)
+	if err != nil {
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, b.String(), err)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+	return nil
+}
@@ -2,10 +2,14 @@ package tsdb
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"info/internal/domain/oracul_analytics"
 	"info/internal/pkg/apperror"
 	"time"
+
+	pgx "github.com/jackc/pgx/v5"
 )
 
 type OraculAnalyticsRepository struct {
@@ -23,6 +27,9 @@ func NewOraculAnalyticsRepository(repository *Repository) *OraculAnalyticsReposi
 
 const (
 	oracul_analytics_sql_Upsert = "INSERT INTO oracul.analytics(currency_id, whales_concentration, worm_index, growth_fuel, ts) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (currency_id, ts) DO UPDATE SET whales_concentration = EXCLUDED.whales_concentration, worm_index = EXCLUDED.worm_index, growth_fuel = EXCLUDED.growth_fuel;"
+
+	oracul_import_max_time_sql_MGet   = "SELECT currency_id, blockchain, daily_balance_stats FROM oracul.import_max_time WHERE currency_id = any($1);"
+	oracul_import_max_time_sql_Upsert = "INSERT INTO oracul.import_max_time(currency_id, blockchain, daily_balance_stats) VALUES ($1, $2, $3) ON CONFLICT (currency_id, blockchain) DO UPDATE SET daily_balance_stats = EXCLUDED.daily_balance_stats;"
 )
 
 func (r *OraculAnalyticsRepository) Upsert(ctx context.Context, entity *oracul_analytics.OraculAnalytics) error {
@@ -40,3 +47,59 @@ func (r *OraculAnalyticsRepository) Upsert(ctx context.Context, entity *oracul_a
 	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
 	return nil
 }
+
+func (r *OraculAnalyticsRepository) MGetImportMaxTime(ctx context.Context, currencyIDs *[]uint) (oracul_analytics.ImportMaxTimeMap, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "OraculAnalyticsRepository.MGetImportMaxTime"
+
+	var entity oracul_analytics.ImportMaxTime
+	res := make(oracul_analytics.ImportMaxTimeMap, len(*currencyIDs))
+
+	start := time.Now().UTC()
+	rows, err := r.db.Query(ctx, oracul_import_max_time_sql_MGet, *currencyIDs)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+			return nil, apperror.ErrNotFound
+		}
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, oracul_import_max_time_sql_MGet, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&entity.CurrencyID, &entity.Blockchain, &entity.DailyBalanceStats); err != nil {
+			r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+			return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, oracul_import_max_time_sql_MGet, err)
+		}
+		res[oracul_analytics.ImportMaxTimeKey{CurrencyID: entity.CurrencyID, Blockchain: entity.Blockchain}] = entity
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+
+	if len(res) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+
+	return res, nil
+}
+
+func (r *OraculAnalyticsRepository) UpsertImportMaxTime(ctx context.Context, entity *oracul_analytics.ImportMaxTime) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "OraculAnalyticsRepository.UpsertImportMaxTime"
+	start := time.Now().UTC()
+
+	if _, err := r.db.Exec(ctx, oracul_import_max_time_sql_Upsert, entity.CurrencyID, entity.Blockchain, entity.DailyBalanceStats); err != nil {
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, oracul_import_max_time_sql_Upsert, err)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+	return nil
+}
@@ -2,12 +2,17 @@ package tsdb
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"info/internal/domain"
 	"info/internal/domain/oracul_daily_balance_stats"
 	"info/internal/pkg/apperror"
 	"strconv"
 	"strings"
 	"time"
+
+	pgx "github.com/jackc/pgx/v5"
 )
 
 type OraculDailyBalanceStatsRepository struct {
@@ -26,10 +31,55 @@ func NewOraculDailyBalanceStatsRepository(repository *Repository) *OraculDailyBa
 const (
 	oracul_daily_balance_stats_MUpsert_Limit = 8000 // 8 пар-ра * 8т <= ~65т ~= max
 
+	oracul_daily_balance_stats_sql_MGet                       = "SELECT currency_id, whales_balance, whales_total_holders, investors_balance, investors_total_holders, retailers_balance, retailers_total_holders, d FROM oracul.daily_balance_stats WHERE currency_id = any($1) AND d >= $2 ORDER BY d DESC;"
 	oracul_daily_balance_stats_sql_MUpsert                    = "INSERT INTO oracul.daily_balance_stats(currency_id, whales_balance, whales_total_holders, investors_balance, investors_total_holders, retailers_balance, retailers_total_holders, d) VALUES "
 	oracul_daily_balance_stats_sql_MUpsert_OnConflictDoUpdate = " ON CONFLICT (currency_id, d) DO UPDATE SET whales_balance = EXCLUDED.whales_balance, whales_total_holders = EXCLUDED.whales_total_holders, investors_balance = EXCLUDED.investors_balance, investors_total_holders = EXCLUDED.investors_total_holders, retailers_balance = EXCLUDED.retailers_balance, retailers_total_holders = EXCLUDED.retailers_total_holders;"
 )
 
+func (r *OraculDailyBalanceStatsRepository) MGet(ctx context.Context, currencyIDs *[]uint, from time.Time) (oracul_daily_balance_stats.OraculDailyBalanceStatsMap, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "OraculDailyBalanceStatsRepository.MGet"
+
+	var entity oracul_daily_balance_stats.OraculDailyBalanceStats
+	res := make(oracul_daily_balance_stats.OraculDailyBalanceStatsMap, len(*currencyIDs))
+
+	start := time.Now().UTC()
+	rows, err := r.db.Query(ctx, oracul_daily_balance_stats_sql_MGet, *currencyIDs, from)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+			return nil, apperror.ErrNotFound
+		}
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, oracul_daily_balance_stats_sql_MGet, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&entity.CurrencyID, &entity.WhalesBalance, &entity.WhalesTotalHolders, &entity.InvestorsBalance, &entity.InvestorsTotalHolders, &entity.RetailersBalance, &entity.RetailersTotalHolders, &entity.D); err != nil {
+			r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+			return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, oracul_daily_balance_stats_sql_MGet, err)
+		}
+		if _, ok := res[entity.CurrencyID]; !ok {
+			res[entity.CurrencyID] = make(oracul_daily_balance_stats.OraculDailyBalanceStatsList, 0, defaultCapacityForResult)
+		}
+
+		res[entity.CurrencyID] = append(res[entity.CurrencyID], entity)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+
+	if len(res) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+
+	return res, nil
+}
+
 func (r *OraculDailyBalanceStatsRepository) MUpsert(ctx context.Context, entities *oracul_daily_balance_stats.OraculDailyBalanceStatsList) error {
 	if len(*entities) <= oracul_daily_balance_stats_MUpsert_Limit {
 		return r.mUpsert(ctx, entities)
@@ -82,3 +132,54 @@ func (r *OraculDailyBalanceStatsRepository) mUpsert(ctx context.Context, entitie
 	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
 	return nil
 }
+
+// MUpsertWithStats upserts the entities and returns the number of the inserted and the updated rows
+func (r *OraculDailyBalanceStatsRepository) MUpsertWithStats(ctx context.Context, entities *oracul_daily_balance_stats.OraculDailyBalanceStatsList) (*domain.UpsertStats, error) {
+	if entities == nil {
+		return &domain.UpsertStats{}, nil
+	}
+	if len(*entities) <= oracul_daily_balance_stats_MUpsert_Limit {
+		return r.mUpsertWithStats(ctx, entities)
+	}
+
+	res := &domain.UpsertStats{}
+	lbound := 0
+	hbound := oracul_daily_balance_stats_MUpsert_Limit
+	for lbound < hbound {
+		entitiesItem := (*entities)[lbound:hbound]
+		stats, err := r.mUpsertWithStats(ctx, &entitiesItem)
+		if err != nil {
+			return nil, err
+		}
+		res.Add(stats)
+		lbound = hbound
+		hbound += oracul_daily_balance_stats_MUpsert_Limit
+		if hbound > len(*entities) {
+			hbound = len(*entities)
+		}
+	}
+	return res, nil
+}
+
+func (r *OraculDailyBalanceStatsRepository) mUpsertWithStats(ctx context.Context, entities *oracul_daily_balance_stats.OraculDailyBalanceStatsList) (*domain.UpsertStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "OraculDailyBalanceStatsRepository.mUpsertWithStats"
+	const fields_nb = 8
+	if len(*entities) == 0 {
+		return &domain.UpsertStats{}, nil
+	}
+	b := strings.Builder{}
+	params := make([]interface{}, 0, len(*entities)*fields_nb)
+	b.WriteString(oracul_daily_balance_stats_sql_MUpsert)
+	for i, entity := range *entities {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("($" + strconv.Itoa(i*fields_nb+1) + ", $" + strconv.Itoa(i*fields_nb+2) + ", $" + strconv.Itoa(i*fields_nb+3) + ", $" + strconv.Itoa(i*fields_nb+4) + ", $" + strconv.Itoa(i*fields_nb+5) + ", $" + strconv.Itoa(i*fields_nb+6) + ", $" + strconv.Itoa(i*fields_nb+7) + ", $" + strconv.Itoa(i*fields_nb+8) + ")")
+		params = append(params, entity.CurrencyID, entity.WhalesBalance, entity.WhalesTotalHolders, entity.InvestorsBalance, entity.InvestorsTotalHolders, entity.RetailersBalance, entity.RetailersTotalHolders, entity.D)
+	}
+	b.WriteString(strings.TrimSuffix(oracul_daily_balance_stats_sql_MUpsert_OnConflictDoUpdate, ";") + sql_ReturningInserted)
+
+	return r.queryUpsertStats(ctx, metricName, b.String(), params...)
+}
@@ -30,14 +30,43 @@ func NewPriceAndCapRepository(repository *Repository) *PriceAndCapRepository {
 }
 
 const (
-	MUpsertPriceAndCap_Limit = 13000 // 5 пар-ра * 13т = 65т ~= max
+	MUpsertPriceAndCap_Limit = 10000 // 6 пар-ов * 10т = 60т ~= max
 
-	price_and_cap_sql_MGet                       = "SELECT currency_id, price, daily_volume, cap, ts FROM cmc.price_and_cap WHERE currency_id = any($1) ORDER BY ts DESC;"
-	price_and_cap_sql_Upsert                     = "INSERT INTO cmc.price_and_cap(currency_id, price, daily_volume, cap, ts) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (currency_id, ts) DO UPDATE SET price = EXCLUDED.price, daily_volume = EXCLUDED.daily_volume, cap = EXCLUDED.cap;"
-	price_and_cap_sql_MUpsert                    = "INSERT INTO cmc.price_and_cap(currency_id, price, daily_volume, cap, ts) VALUES "
-	price_and_cap_sql_MUpsert_OnConflictDoUpdate = " ON CONFLICT (currency_id, ts) DO UPDATE SET price = EXCLUDED.price, daily_volume = EXCLUDED.daily_volume, cap = EXCLUDED.cap;"
+	// a source without the cap (binance) sends 0, so the cap of the existing row is kept
+	price_and_cap_sql_MGet                       = "SELECT currency_id, price, daily_volume, coalesce(cap, 0), ts, source FROM cmc.price_and_cap WHERE currency_id = any($1) ORDER BY ts DESC;"
+	price_and_cap_sql_GetList                    = "SELECT currency_id, price, daily_volume, coalesce(cap, 0), ts, source FROM cmc.price_and_cap WHERE currency_id = $1 AND ts >= $2 AND ts < $3 ORDER BY ts;"
+	price_and_cap_sql_Upsert                     = "INSERT INTO cmc.price_and_cap(currency_id, price, daily_volume, cap, ts, source) VALUES ($1, $2, $3, nullif($4::double precision, 0), $5, $6) ON CONFLICT (currency_id, ts) DO UPDATE SET price = EXCLUDED.price, daily_volume = EXCLUDED.daily_volume, cap = coalesce(EXCLUDED.cap, price_and_cap.cap), source = EXCLUDED.source;"
+	price_and_cap_sql_MUpsert                    = "INSERT INTO cmc.price_and_cap(currency_id, price, daily_volume, cap, ts, source) VALUES "
+	price_and_cap_sql_MUpsert_OnConflictDoUpdate = " ON CONFLICT (currency_id, ts) DO UPDATE SET price = EXCLUDED.price, daily_volume = EXCLUDED.daily_volume, cap = coalesce(EXCLUDED.cap, price_and_cap.cap), source = EXCLUDED.source;"
+	price_and_cap_sql_GetCandles_1h              = "SELECT currency_id, open, high, low, close, volume, coalesce(cap, 0), ts FROM cmc.price_and_cap_1h WHERE currency_id = $1 AND ts >= $2 AND ts < $3 ORDER BY ts;"
+	price_and_cap_sql_GetCandles_1d              = "SELECT currency_id, open, high, low, close, volume, coalesce(cap, 0), ts FROM cmc.price_and_cap_1d WHERE currency_id = $1 AND ts >= $2 AND ts < $3 ORDER BY ts;"
+	price_and_cap_sql_GetCandles_1w              = "SELECT currency_id, open, high, low, close, volume, coalesce(cap, 0), ts FROM cmc.price_and_cap_1w WHERE currency_id = $1 AND ts >= $2 AND ts < $3 ORDER BY ts;"
+	price_and_cap_sql_MGetCandles_1h             = "SELECT currency_id, open, high, low, close, volume, coalesce(cap, 0), ts FROM cmc.price_and_cap_1h WHERE currency_id = any($1) AND ts >= $2 AND ts < $3 ORDER BY currency_id, ts;"
+	price_and_cap_sql_MGetCandles_1d             = "SELECT currency_id, open, high, low, close, volume, coalesce(cap, 0), ts FROM cmc.price_and_cap_1d WHERE currency_id = any($1) AND ts >= $2 AND ts < $3 ORDER BY currency_id, ts;"
+	price_and_cap_sql_MGetCandles_1w             = "SELECT currency_id, open, high, low, close, volume, coalesce(cap, 0), ts FROM cmc.price_and_cap_1w WHERE currency_id = any($1) AND ts >= $2 AND ts < $3 ORDER BY currency_id, ts;"
+	// only the buckets entirely inside the window are refreshed, so the window is widened by a bucket
+	price_and_cap_sql_RefreshCandles = "CALL public.refresh_continuous_aggregate($1::regclass, $2::timestamp, $3::timestamp);"
 )
 
+var price_and_cap_sql_GetCandles = map[string]string{
+	price_and_cap.Interval_1H: price_and_cap_sql_GetCandles_1h,
+	price_and_cap.Interval_1D: price_and_cap_sql_GetCandles_1d,
+	price_and_cap.Interval_1W: price_and_cap_sql_GetCandles_1w,
+}
+
+var price_and_cap_sql_MGetCandles = map[string]string{
+	price_and_cap.Interval_1H: price_and_cap_sql_MGetCandles_1h,
+	price_and_cap.Interval_1D: price_and_cap_sql_MGetCandles_1d,
+	price_and_cap.Interval_1W: price_and_cap_sql_MGetCandles_1w,
+}
+
+// price_and_cap_CandleViews are the continuous aggregates of the candles
+var price_and_cap_CandleViews = map[string]string{
+	price_and_cap.Interval_1H: "cmc.price_and_cap_1h",
+	price_and_cap.Interval_1D: "cmc.price_and_cap_1d",
+	price_and_cap.Interval_1W: "cmc.price_and_cap_1w",
+}
+
 func (r *PriceAndCapRepository) MGet(ctx context.Context, currencyIDs *[]uint) (price_and_cap.PriceAndCapMap, error) {
 	//ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	//defer cancel()
@@ -61,7 +90,7 @@ func (r *PriceAndCapRepository) MGet(ctx context.Context, currencyIDs *[]uint) (
 	defer rows.Close()
 
 	for rows.Next() {
-		if err = rows.Scan(&entity.CurrencyID, &entity.Price, &entity.DailyVolume, &entity.Cap, &entity.Ts); err != nil {
+		if err = rows.Scan(&entity.CurrencyID, &entity.Price, &entity.DailyVolume, &entity.Cap, &entity.Ts, &entity.Source); err != nil {
 			r.metrics.SqlMetrics.Inc(metricName, metricsFail)
 			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
 			return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, price_and_cap_sql_MGet, err)
@@ -81,13 +110,164 @@ func (r *PriceAndCapRepository) MGet(ctx context.Context, currencyIDs *[]uint) (
 	return res, nil
 }
 
+func (r *PriceAndCapRepository) GetList(ctx context.Context, currencyID uint, from time.Time, to time.Time) (*price_and_cap.PriceAndCapList, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "PriceAndCapRepository.GetList"
+
+	var entity price_and_cap.PriceAndCap
+	res := make(price_and_cap.PriceAndCapList, 0, defaultCapacityForResult)
+
+	start := time.Now().UTC()
+	rows, err := r.db.Query(ctx, price_and_cap_sql_GetList, currencyID, from, to)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+			return nil, apperror.ErrNotFound
+		}
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, price_and_cap_sql_GetList, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&entity.CurrencyID, &entity.Price, &entity.DailyVolume, &entity.Cap, &entity.Ts, &entity.Source); err != nil {
+			r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+			return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, price_and_cap_sql_GetList, err)
+		}
+		res = append(res, entity)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+
+	if len(res) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+
+	return &res, nil
+}
+
+func (r *PriceAndCapRepository) GetCandles(ctx context.Context, currencyID uint, interval string, from time.Time, to time.Time) (*price_and_cap.CandleList, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "PriceAndCapRepository.GetCandles"
+
+	query, ok := price_and_cap_sql_GetCandles[interval]
+	if !ok {
+		return nil, fmt.Errorf("[%w] %s unknown interval %q", apperror.ErrBadRequest, metricName, interval)
+	}
+
+	var entity price_and_cap.Candle
+	res := make(price_and_cap.CandleList, 0, defaultCapacityForResult)
+
+	start := time.Now().UTC()
+	rows, err := r.db.Query(ctx, query, currencyID, from, to)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+			return nil, apperror.ErrNotFound
+		}
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, query, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&entity.CurrencyID, &entity.Open, &entity.High, &entity.Low, &entity.Close, &entity.Volume, &entity.Cap, &entity.Ts); err != nil {
+			r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+			return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, query, err)
+		}
+		res = append(res, entity)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+
+	if len(res) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+
+	return &res, nil
+}
+
+func (r *PriceAndCapRepository) MGetCandles(ctx context.Context, currencyIDs *[]uint, interval string, from time.Time, to time.Time) (price_and_cap.CandleMap, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "PriceAndCapRepository.MGetCandles"
+
+	query, ok := price_and_cap_sql_MGetCandles[interval]
+	if !ok {
+		return nil, fmt.Errorf("[%w] %s unknown interval %q", apperror.ErrBadRequest, metricName, interval)
+	}
+
+	var entity price_and_cap.Candle
+	res := make(price_and_cap.CandleMap, len(*currencyIDs))
+
+	start := time.Now().UTC()
+	rows, err := r.db.Query(ctx, query, *currencyIDs, from, to)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+			return nil, apperror.ErrNotFound
+		}
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, query, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&entity.CurrencyID, &entity.Open, &entity.High, &entity.Low, &entity.Close, &entity.Volume, &entity.Cap, &entity.Ts); err != nil {
+			r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+			return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, query, err)
+		}
+		if _, ok := res[entity.CurrencyID]; !ok {
+			res[entity.CurrencyID] = make(price_and_cap.CandleList, 0, defaultCapacityForResult)
+		}
+		res[entity.CurrencyID] = append(res[entity.CurrencyID], entity)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+
+	if len(res) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+
+	return res, nil
+}
+
+// RefreshCandles refreshes the continuous aggregates of all the intervals in the window [from, to)
+func (r *PriceAndCapRepository) RefreshCandles(ctx context.Context, from time.Time, to time.Time) error {
+	const metricName = "PriceAndCapRepository.RefreshCandles"
+
+	for interval, view := range price_and_cap_CandleViews {
+		d := price_and_cap.IntervalDuration(interval)
+		start := time.Now().UTC()
+		if _, err := r.db.Exec(ctx, price_and_cap_sql_RefreshCandles, view, from.Add(-d), to.Add(d)); err != nil {
+			r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+			return fmt.Errorf("[%w] %s query error; query: %s; view: %s; error: %w", apperror.ErrInternal, metricName, price_and_cap_sql_RefreshCandles, view, err)
+		}
+		r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+	}
+	return nil
+}
+
 func (r *PriceAndCapRepository) Upsert(ctx context.Context, entity *price_and_cap.PriceAndCap) (err error) {
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 	const metricName = "PriceAndCapRepository.Upsert"
 	start := time.Now().UTC()
 
-	if _, err := r.db.Exec(ctx, price_and_cap_sql_Upsert, entity.CurrencyID, entity.Price, entity.DailyVolume, entity.Cap, entity.Ts); err != nil {
+	if _, err := r.db.Exec(ctx, price_and_cap_sql_Upsert, entity.CurrencyID, entity.Price, entity.DailyVolume, entity.Cap, entity.Ts, entity.Source); err != nil {
 		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
 			r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
 			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
@@ -127,7 +307,7 @@ func (r PriceAndCapRepository) mUpsertTx(ctx context.Context, tx domain.Tx, enti
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 	const metricName = "PriceAndCapRepository.mUpsertTx"
-	const fields_nb = 5 // при изменении количества полей нужно изменить MUpsertNmDimensions_Limit, чтобы, в результате, кол-во пар-ов не превышало 65т
+	const fields_nb = 6 // при изменении количества полей нужно изменить MUpsertNmDimensions_Limit, чтобы, в результате, кол-во пар-ов не превышало 65т
 	if len(*entities) == 0 {
 		return nil
 	}
@@ -138,8 +318,8 @@ func (r PriceAndCapRepository) mUpsertTx(ctx context.Context, tx domain.Tx, enti
 		if i > 0 {
 			b.WriteString(", ")
 		}
-		b.WriteString("($" + strconv.Itoa(i*fields_nb+1) + ", $" + strconv.Itoa(i*fields_nb+2) + ", $" + strconv.Itoa(i*fields_nb+3) + ", $" + strconv.Itoa(i*fields_nb+4) + ", $" + strconv.Itoa(i*fields_nb+5) + ")")
-		params = append(params, entity.CurrencyID, entity.Price, entity.DailyVolume, entity.Cap, entity.Ts)
+		b.WriteString("($" + strconv.Itoa(i*fields_nb+1) + ", $" + strconv.Itoa(i*fields_nb+2) + ", $" + strconv.Itoa(i*fields_nb+3) + ", nullif($" + strconv.Itoa(i*fields_nb+4) + "::double precision, 0), $" + strconv.Itoa(i*fields_nb+5) + ", $" + strconv.Itoa(i*fields_nb+6) + ")")
+		params = append(params, entity.CurrencyID, entity.Price, entity.DailyVolume, entity.Cap, entity.Ts, entity.Source)
 	}
 	b.WriteString(price_and_cap_sql_MUpsert_OnConflictDoUpdate)
 	start := time.Now().UTC()
@@ -154,3 +334,54 @@ func (r PriceAndCapRepository) mUpsertTx(ctx context.Context, tx domain.Tx, enti
 	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
 	return nil
 }
+
+// MUpsertWithStats upserts the entities and returns the number of the inserted and the updated rows
+func (r *PriceAndCapRepository) MUpsertWithStats(ctx context.Context, entities *[]price_and_cap.PriceAndCap) (*domain.UpsertStats, error) {
+	if entities == nil {
+		return &domain.UpsertStats{}, nil
+	}
+	if len(*entities) <= MUpsertPriceAndCap_Limit {
+		return r.mUpsertWithStats(ctx, entities)
+	}
+
+	res := &domain.UpsertStats{}
+	lbound := 0
+	hbound := MUpsertPriceAndCap_Limit
+	for lbound < hbound {
+		entitiesItem := (*entities)[lbound:hbound]
+		stats, err := r.mUpsertWithStats(ctx, &entitiesItem)
+		if err != nil {
+			return nil, err
+		}
+		res.Add(stats)
+		lbound = hbound
+		hbound += MUpsertPriceAndCap_Limit
+		if hbound > len(*entities) {
+			hbound = len(*entities)
+		}
+	}
+	return res, nil
+}
+
+func (r *PriceAndCapRepository) mUpsertWithStats(ctx context.Context, entities *[]price_and_cap.PriceAndCap) (*domain.UpsertStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "PriceAndCapRepository.mUpsertWithStats"
+	const fields_nb = 6
+	if len(*entities) == 0 {
+		return &domain.UpsertStats{}, nil
+	}
+	b := strings.Builder{}
+	params := make([]interface{}, 0, len(*entities)*fields_nb)
+	b.WriteString(price_and_cap_sql_MUpsert)
+	for i, entity := range *entities {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("($" + strconv.Itoa(i*fields_nb+1) + ", $" + strconv.Itoa(i*fields_nb+2) + ", $" + strconv.Itoa(i*fields_nb+3) + ", nullif($" + strconv.Itoa(i*fields_nb+4) + "::double precision, 0), $" + strconv.Itoa(i*fields_nb+5) + ", $" + strconv.Itoa(i*fields_nb+6) + ")")
+		params = append(params, entity.CurrencyID, entity.Price, entity.DailyVolume, entity.Cap, entity.Ts, entity.Source)
+	}
+	b.WriteString(strings.TrimSuffix(price_and_cap_sql_MUpsert_OnConflictDoUpdate, ";") + sql_ReturningInserted)
+
+	return r.queryUpsertStats(ctx, metricName, b.String(), params...)
+}
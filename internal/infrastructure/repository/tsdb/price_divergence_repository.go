@@ -0,0 +1,132 @@
+package tsdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
+
+	"info/internal/domain/price_divergence"
+	"info/internal/pkg/apperror"
+)
+
+type PriceDivergenceRepository struct {
+	*Repository
+}
+
+var _ price_divergence.WriteRepository = (*PriceDivergenceRepository)(nil)
+var _ price_divergence.ReadRepository = (*PriceDivergenceRepository)(nil)
+
+func NewPriceDivergenceRepository(repository *Repository) *PriceDivergenceRepository {
+	return &PriceDivergenceRepository{
+		Repository: repository,
+	}
+}
+
+const (
+	price_divergence_sql_GetList = "SELECT currency_id, day, source, price, ref_price, price_diff, cap, ref_cap, cap_diff, checked_at FROM cmc.price_divergence WHERE (cardinality($1::bigint[]) = 0 OR currency_id = ANY($1)) AND day >= $2 AND day < $3 ORDER BY greatest(price_diff, cap_diff) DESC, day DESC LIMIT $4;"
+	price_divergence_sql_Delete  = "DELETE FROM cmc.price_divergence WHERE currency_id = $1 AND source = $2 AND day >= $3 AND day < $4;"
+	price_divergence_sql_MCreate = "INSERT INTO cmc.price_divergence(currency_id, day, source, price, ref_price, price_diff, cap, ref_cap, cap_diff, checked_at) VALUES "
+)
+
+func (r *PriceDivergenceRepository) GetList(ctx context.Context, filter *price_divergence.Filter, limit uint) (*price_divergence.PriceDivergenceList, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "PriceDivergenceRepository.GetList"
+
+	var entity price_divergence.PriceDivergence
+	currencyIDs := filter.CurrencyIDs
+	if currencyIDs == nil {
+		currencyIDs = []uint{}
+	}
+	res := make(price_divergence.PriceDivergenceList, 0, limit)
+
+	start := time.Now().UTC()
+	rows, err := r.db.Query(ctx, price_divergence_sql_GetList, currencyIDs, filter.From, filter.To, limit)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+			return nil, apperror.ErrNotFound
+		}
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, price_divergence_sql_GetList, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&entity.CurrencyID, &entity.Day, &entity.Source, &entity.Price, &entity.RefPrice, &entity.PriceDiff, &entity.Cap, &entity.RefCap, &entity.CapDiff, &entity.CheckedAt); err != nil {
+			r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+			return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, price_divergence_sql_GetList, err)
+		}
+		res = append(res, entity)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+
+	if len(res) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+
+	return &res, nil
+}
+
+// Replace deletes the divergences of the window and creates the new ones in one transaction
+func (r *PriceDivergenceRepository) Replace(ctx context.Context, currencyID uint, source string, from time.Time, to time.Time, entities *price_divergence.PriceDivergenceList) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "PriceDivergenceRepository.Replace"
+	const fields_nb = 10
+
+	b := strings.Builder{}
+	params := make([]interface{}, 0, len(*entities)*fields_nb)
+	b.WriteString(price_divergence_sql_MCreate)
+	for i, entity := range *entities {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("($" + strconv.Itoa(i*fields_nb+1) + ", $" + strconv.Itoa(i*fields_nb+2) + ", $" + strconv.Itoa(i*fields_nb+3) + ", $" + strconv.Itoa(i*fields_nb+4) + ", $" + strconv.Itoa(i*fields_nb+5) + ", $" + strconv.Itoa(i*fields_nb+6) + ", $" + strconv.Itoa(i*fields_nb+7) + ", $" + strconv.Itoa(i*fields_nb+8) + ", $" + strconv.Itoa(i*fields_nb+9) + ", $" + strconv.Itoa(i*fields_nb+10) + ")")
+		params = append(params, entity.CurrencyID, entity.Day, entity.Source, entity.Price, entity.RefPrice, entity.PriceDiff, entity.Cap, entity.RefCap, entity.CapDiff, entity.CheckedAt)
+	}
+	b.WriteString(";")
+
+	start := time.Now().UTC()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return fmt.Errorf("[%w] %s begin transaction error: %w", apperror.ErrInternal, metricName, err)
+	}
+	defer func() {
+		if err != nil {
+			if err2 := tx.Rollback(ctx); err2 != nil {
+				err = errors.Join(err, fmt.Errorf("[%w] %s rollback error: %w", apperror.ErrInternal, metricName, err2))
+			}
+			r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+			return
+		}
+		r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+	}()
+
+	if _, err = tx.Exec(ctx, price_divergence_sql_Delete, currencyID, source, from, to); err != nil {
+		return fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, price_divergence_sql_Delete, err)
+	}
+	if len(*entities) > 0 {
+		if _, err = tx.Exec(ctx, b.String(), params...); err != nil {
+			return fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, b.String(), err)
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("[%w] %s commit error: %w", apperror.ErrInternal, metricName, err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package tsdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
+
+	"info/internal/domain/raw_response"
+	"info/internal/pkg/apperror"
+)
+
+type RawResponseRepository struct {
+	*Repository
+}
+
+var _ raw_response.WriteRepository = (*RawResponseRepository)(nil)
+var _ raw_response.ReadRepository = (*RawResponseRepository)(nil)
+
+func NewRawResponseRepository(repository *Repository) *RawResponseRepository {
+	return &RawResponseRepository{
+		Repository: repository,
+	}
+}
+
+const (
+	raw_response_sql_GetList = "SELECT id, source, endpoint, currency_id, fetched_at, body FROM cmc.raw_response WHERE endpoint = $1 AND id > $2 AND (cardinality($3::bigint[]) = 0 OR currency_id = ANY($3)) AND fetched_at >= $4 AND fetched_at < $5 ORDER BY id LIMIT $6;"
+	raw_response_sql_Create  = "INSERT INTO cmc.raw_response(source, endpoint, currency_id, fetched_at, body) VALUES ($1, $2, $3, $4, $5);"
+)
+
+func (r *RawResponseRepository) GetList(ctx context.Context, filter *raw_response.Filter, afterID uint, limit uint) (*raw_response.RawResponseList, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "RawResponseRepository.GetList"
+
+	var entity raw_response.RawResponse
+	currencyIDs := filter.CurrencyIDs
+	if currencyIDs == nil {
+		currencyIDs = []uint{}
+	}
+	res := make(raw_response.RawResponseList, 0, limit)
+
+	start := time.Now().UTC()
+	rows, err := r.db.Query(ctx, raw_response_sql_GetList, filter.Endpoint, afterID, currencyIDs, filter.From, filter.To, limit)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+			return nil, apperror.ErrNotFound
+		}
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, raw_response_sql_GetList, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&entity.ID, &entity.Source, &entity.Endpoint, &entity.CurrencyID, &entity.FetchedAt, &entity.Body); err != nil {
+			r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+			return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, raw_response_sql_GetList, err)
+		}
+		res = append(res, entity)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+
+	if len(res) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+
+	return &res, nil
+}
+
+func (r *RawResponseRepository) Create(ctx context.Context, entity *raw_response.RawResponse) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "RawResponseRepository.Create"
+	start := time.Now().UTC()
+
+	if _, err := r.db.Exec(ctx, raw_response_sql_Create, entity.Source, entity.Endpoint, entity.CurrencyID, entity.FetchedAt, entity.Body); err != nil {
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, raw_response_sql_Create, err)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+	return nil
+}
@@ -86,6 +86,7 @@ const (
 	sql_Desc  = " DESC"
 
 	sql_OnConflictDoNothing = " ON CONFLICT DO NOTHING;"
+	sql_ReturningInserted   = " RETURNING (xmax = 0);" // xmax of a just inserted row is 0, of an updated one is the id of the transaction
 )
 
 func NewRepository(cfg Config, dbMetrics DbMetrics, metrics *RepositoryMetrics) (*Repository, error) {
@@ -208,3 +209,39 @@ func (r *Repository) ExecTx(ctx context.Context, tx Tx, sql string, arguments ..
 	}
 	return nil
 }
+
+// queryUpsertStats executes the upsert query ended with sql_ReturningInserted and counts the inserted and the updated rows
+func (r *Repository) queryUpsertStats(ctx context.Context, metricName string, query string, params ...interface{}) (*domain.UpsertStats, error) {
+	var isInserted bool
+	res := &domain.UpsertStats{}
+
+	start := time.Now().UTC()
+	rows, err := r.db.Query(ctx, query, params...)
+	if err != nil {
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, query, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&isInserted); err != nil {
+			r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+			return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, query, err)
+		}
+		if isInserted {
+			res.Inserted++
+		} else {
+			res.Updated++
+		}
+	}
+	if err = rows.Err(); err != nil {
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, query, err)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+	return res, nil
+}
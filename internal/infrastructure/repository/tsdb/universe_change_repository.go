@@ -0,0 +1,106 @@
+package tsdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
+
+	"info/internal/domain/universe"
+	"info/internal/pkg/apperror"
+)
+
+type UniverseChangeRepository struct {
+	*Repository
+}
+
+var _ universe.WriteRepository = (*UniverseChangeRepository)(nil)
+var _ universe.ReadRepository = (*UniverseChangeRepository)(nil)
+
+func NewUniverseChangeRepository(repository *Repository) *UniverseChangeRepository {
+	return &UniverseChangeRepository{
+		Repository: repository,
+	}
+}
+
+const (
+	universe_change_sql_GetList = "SELECT id, currency_id, slug, action, reason, cmc_rank, market_cap, volume_24h, changed_at FROM cmc.universe_change WHERE ($1 = '' OR action = $1) AND changed_at >= $2 AND changed_at < $3 ORDER BY id DESC LIMIT $4;"
+	universe_change_sql_MCreate = "INSERT INTO cmc.universe_change(currency_id, slug, action, reason, cmc_rank, market_cap, volume_24h, changed_at) VALUES "
+)
+
+func (r *UniverseChangeRepository) GetList(ctx context.Context, filter *universe.Filter, limit uint) (*universe.ChangeList, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "UniverseChangeRepository.GetList"
+
+	var entity universe.Change
+	res := make(universe.ChangeList, 0, limit)
+
+	start := time.Now().UTC()
+	rows, err := r.db.Query(ctx, universe_change_sql_GetList, filter.Action, filter.From, filter.To, limit)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+			return nil, apperror.ErrNotFound
+		}
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, universe_change_sql_GetList, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&entity.ID, &entity.CurrencyID, &entity.Slug, &entity.Action, &entity.Reason, &entity.CmcRank, &entity.MarketCap, &entity.Volume24h, &entity.ChangedAt); err != nil {
+			r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+			r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+			return nil, fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, universe_change_sql_GetList, err)
+		}
+		res = append(res, entity)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+
+	if len(res) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+
+	return &res, nil
+}
+
+func (r *UniverseChangeRepository) MCreate(ctx context.Context, entities *universe.ChangeList) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	const metricName = "UniverseChangeRepository.MCreate"
+	const fields_nb = 8
+	if len(*entities) == 0 {
+		return nil
+	}
+	b := strings.Builder{}
+	params := make([]interface{}, 0, len(*entities)*fields_nb)
+	b.WriteString(universe_change_sql_MCreate)
+	for i, entity := range *entities {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("($" + strconv.Itoa(i*fields_nb+1) + ", $" + strconv.Itoa(i*fields_nb+2) + ", $" + strconv.Itoa(i*fields_nb+3) + ", $" + strconv.Itoa(i*fields_nb+4) + ", $" + strconv.Itoa(i*fields_nb+5) + ", $" + strconv.Itoa(i*fields_nb+6) + ", $" + strconv.Itoa(i*fields_nb+7) + ", $" + strconv.Itoa(i*fields_nb+8) + ")")
+		params = append(params, entity.CurrencyID, entity.Slug, entity.Action, entity.Reason, entity.CmcRank, entity.MarketCap, entity.Volume24h, entity.ChangedAt)
+	}
+	b.WriteString(";")
+	start := time.Now().UTC()
+
+	_, err := r.db.Exec(ctx, b.String(), params...)
+	if err != nil {
+		r.metrics.SqlMetrics.Inc(metricName, metricsFail)
+		r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsFail)
+		return fmt.Errorf("[%w] %s query error; query: %s; error: %w", apperror.ErrInternal, metricName, b.String(), err)
+	}
+	r.metrics.SqlMetrics.Inc(metricName, metricsSuccess)
+	r.metrics.SqlMetrics.WriteTiming(start, metricName, metricsSuccess)
+	return nil
+}
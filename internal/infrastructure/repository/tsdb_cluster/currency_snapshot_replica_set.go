@@ -0,0 +1,26 @@
+package tsdb_cluster
+
+import (
+	"info/internal/domain/currency_snapshot"
+	"info/internal/infrastructure/repository/tsdb"
+)
+
+type CurrencySnapshotReplicaSet struct {
+	*ReplicaSet
+}
+
+var _ currency_snapshot.ReplicaSet = (*CurrencySnapshotReplicaSet)(nil)
+
+func NewCurrencySnapshotReplicaSet(replicaSet *ReplicaSet) *CurrencySnapshotReplicaSet {
+	return &CurrencySnapshotReplicaSet{
+		ReplicaSet: replicaSet,
+	}
+}
+
+func (c *CurrencySnapshotReplicaSet) WriteRepo() currency_snapshot.WriteRepository {
+	return tsdb.NewCurrencySnapshotRepository(c.ReplicaSet.WriteRepo())
+}
+
+func (c *CurrencySnapshotReplicaSet) ReadRepo() currency_snapshot.ReadRepository {
+	return tsdb.NewCurrencySnapshotRepository(c.ReplicaSet.ReadRepo())
+}
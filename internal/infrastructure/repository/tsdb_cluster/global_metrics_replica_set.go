@@ -0,0 +1,26 @@
+package tsdb_cluster
+
+import (
+	"info/internal/domain/global_metrics"
+	"info/internal/infrastructure/repository/tsdb"
+)
+
+type GlobalMetricsReplicaSet struct {
+	*ReplicaSet
+}
+
+var _ global_metrics.ReplicaSet = (*GlobalMetricsReplicaSet)(nil)
+
+func NewGlobalMetricsReplicaSet(replicaSet *ReplicaSet) *GlobalMetricsReplicaSet {
+	return &GlobalMetricsReplicaSet{
+		ReplicaSet: replicaSet,
+	}
+}
+
+func (c *GlobalMetricsReplicaSet) WriteRepo() global_metrics.WriteRepository {
+	return tsdb.NewGlobalMetricsRepository(c.ReplicaSet.WriteRepo())
+}
+
+func (c *GlobalMetricsReplicaSet) ReadRepo() global_metrics.ReadRepository {
+	return tsdb.NewGlobalMetricsRepository(c.ReplicaSet.ReadRepo())
+}
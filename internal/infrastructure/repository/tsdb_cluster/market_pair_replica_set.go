@@ -0,0 +1,26 @@
+package tsdb_cluster
+
+import (
+	"info/internal/domain/market_pair"
+	"info/internal/infrastructure/repository/tsdb"
+)
+
+type MarketPairReplicaSet struct {
+	*ReplicaSet
+}
+
+var _ market_pair.ReplicaSet = (*MarketPairReplicaSet)(nil)
+
+func NewMarketPairReplicaSet(replicaSet *ReplicaSet) *MarketPairReplicaSet {
+	return &MarketPairReplicaSet{
+		ReplicaSet: replicaSet,
+	}
+}
+
+func (c *MarketPairReplicaSet) WriteRepo() market_pair.WriteRepository {
+	return tsdb.NewMarketPairRepository(c.ReplicaSet.WriteRepo())
+}
+
+func (c *MarketPairReplicaSet) ReadRepo() market_pair.ReadRepository {
+	return tsdb.NewMarketPairRepository(c.ReplicaSet.ReadRepo())
+}
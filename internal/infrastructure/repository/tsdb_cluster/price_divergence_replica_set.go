@@ -0,0 +1,26 @@
+package tsdb_cluster
+
+import (
+	"info/internal/domain/price_divergence"
+	"info/internal/infrastructure/repository/tsdb"
+)
+
+type PriceDivergenceReplicaSet struct {
+	*ReplicaSet
+}
+
+var _ price_divergence.ReplicaSet = (*PriceDivergenceReplicaSet)(nil)
+
+func NewPriceDivergenceReplicaSet(replicaSet *ReplicaSet) *PriceDivergenceReplicaSet {
+	return &PriceDivergenceReplicaSet{
+		ReplicaSet: replicaSet,
+	}
+}
+
+func (c *PriceDivergenceReplicaSet) WriteRepo() price_divergence.WriteRepository {
+	return tsdb.NewPriceDivergenceRepository(c.ReplicaSet.WriteRepo())
+}
+
+func (c *PriceDivergenceReplicaSet) ReadRepo() price_divergence.ReadRepository {
+	return tsdb.NewPriceDivergenceRepository(c.ReplicaSet.ReadRepo())
+}
@@ -0,0 +1,26 @@
+package tsdb_cluster
+
+import (
+	"info/internal/domain/raw_response"
+	"info/internal/infrastructure/repository/tsdb"
+)
+
+type RawResponseReplicaSet struct {
+	*ReplicaSet
+}
+
+var _ raw_response.ReplicaSet = (*RawResponseReplicaSet)(nil)
+
+func NewRawResponseReplicaSet(replicaSet *ReplicaSet) *RawResponseReplicaSet {
+	return &RawResponseReplicaSet{
+		ReplicaSet: replicaSet,
+	}
+}
+
+func (c *RawResponseReplicaSet) WriteRepo() raw_response.WriteRepository {
+	return tsdb.NewRawResponseRepository(c.ReplicaSet.WriteRepo())
+}
+
+func (c *RawResponseReplicaSet) ReadRepo() raw_response.ReadRepository {
+	return tsdb.NewRawResponseRepository(c.ReplicaSet.ReadRepo())
+}
@@ -0,0 +1,26 @@
+package tsdb_cluster
+
+import (
+	"info/internal/domain/universe"
+	"info/internal/infrastructure/repository/tsdb"
+)
+
+type UniverseChangeReplicaSet struct {
+	*ReplicaSet
+}
+
+var _ universe.ReplicaSet = (*UniverseChangeReplicaSet)(nil)
+
+func NewUniverseChangeReplicaSet(replicaSet *ReplicaSet) *UniverseChangeReplicaSet {
+	return &UniverseChangeReplicaSet{
+		ReplicaSet: replicaSet,
+	}
+}
+
+func (c *UniverseChangeReplicaSet) WriteRepo() universe.WriteRepository {
+	return tsdb.NewUniverseChangeRepository(c.ReplicaSet.WriteRepo())
+}
+
+func (c *UniverseChangeReplicaSet) ReadRepo() universe.ReadRepository {
+	return tsdb.NewUniverseChangeRepository(c.ReplicaSet.ReadRepo())
+}
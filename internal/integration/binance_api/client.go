@@ -0,0 +1,204 @@
+package binance_api
+
+import (
+	"context"
+	"fmt"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/minipkg/httpclient"
+	prometheus_utils "github.com/minipkg/prometheus-utils"
+	uuid "github.com/satori/go.uuid"
+	"go.uber.org/zap"
+	"info/internal/domain/price_and_cap"
+	"info/internal/pkg/apperror"
+	"info/internal/pkg/log_key"
+	"info/internal/pkg/ratelimit"
+	"info/internal/pkg/resilient"
+	"strconv"
+	"time"
+)
+
+// Archiver stores the raw responses, so they can be converted again without a request to the API
+type Archiver interface {
+	Archive(ctx context.Context, source string, endpoint string, currencyID uint, fetchedAt time.Time, data []byte) error
+}
+
+type AppConfig struct {
+	NameSpace string
+	Subsystem string
+	Service   string
+}
+
+type Config struct {
+	Httpconfig httpclient.Config
+	RateLimit  ratelimit.Config
+	Resilience resilient.Config
+	Symbols    []SymbolMapping // the currencies without a symbol are not requested
+}
+
+// SymbolMapping maps the CoinMarketCap currency onto the Binance trading pair
+type SymbolMapping struct {
+	CurrencyID uint
+	Symbol     string // e.g. BTCUSDT
+}
+
+type BinanceApiClient struct {
+	config   *Config
+	http     *resilient.Client
+	symbols  map[uint]string
+	archiver Archiver
+	logger   *zap.Logger
+}
+
+const (
+	Source                = "binance_api"
+	Name                  = "BinanceApiClient"
+	ContentType           = "application/json; charset=utf-8"
+	HeaderParam_RequestId = "X-Request-Id"
+
+	Interval_1h = "1h"
+	Interval_1d = "1d"
+
+	// KlinesLimit is the max number of the candles in one response
+	KlinesLimit = 1000
+	// klinesMaxPages limits the pages of one chart; 20 pages of the daily candles are more than 50 years
+	klinesMaxPages = 20
+	// hoursInDay is the number of the hourly candles summed up into the daily volume
+	hoursInDay = 24
+
+	URI_GetKlines string = "/api/v3/klines"
+)
+
+var IntervalList = []interface{}{
+	Interval_1h,
+	Interval_1d,
+}
+
+var _ price_and_cap.Source = (*BinanceApiClient)(nil)
+
+func IntervalValidate(s string) error {
+	return validation.Validate(s, validation.Required, validation.In(IntervalList...))
+}
+
+func New(appConfig *AppConfig, conf *Config, logger *zap.Logger) *BinanceApiClient {
+	client := httpclient.New(conf.Httpconfig, prometheus_utils.NewHttpClientMetrics(appConfig.NameSpace, appConfig.Subsystem, appConfig.Service, conf.Httpconfig.Name).SetCuttingPathOpts(&prometheus_utils.CuttingPathOpts{IsNeedToRemoveQueryInPath: true}))
+	symbols := make(map[uint]string, len(conf.Symbols))
+	var item SymbolMapping
+	for _, item = range conf.Symbols {
+		symbols[item.CurrencyID] = item.Symbol
+	}
+	return &BinanceApiClient{
+		config:  conf,
+		http:    resilient.New(Name, conf.Resilience, client, ratelimit.New(conf.RateLimit), resilient.NewMetrics(appConfig.NameSpace, appConfig.Subsystem, appConfig.Service, conf.Httpconfig.Name)),
+		symbols: symbols,
+		logger:  logger,
+	}
+}
+
+// SetArchiver turns on the archive of the raw responses
+func (c *BinanceApiClient) SetArchiver(archiver Archiver) *BinanceApiClient {
+	c.archiver = archiver
+	return c
+}
+
+// archive stores the raw response if the archive is turned on; an error of the archive does not fail the request
+func (c *BinanceApiClient) archive(ctx context.Context, endpoint string, currencyID uint, fetchedAt time.Time, data []byte) {
+	if c.archiver == nil || data == nil {
+		return
+	}
+	if err := c.archiver.Archive(ctx, Source, endpoint, currencyID, fetchedAt, data); err != nil {
+		c.logger.Error("archiver.Archive error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Endpoint, endpoint), zap.Error(err))
+	}
+}
+
+func (c *BinanceApiClient) getDefaultRequestOptions() (requestId string, options []httpclient.RequestOption) {
+	requestId = uuid.NewV4().String()
+	return requestId, []httpclient.RequestOption{
+		httpclient.WithContentType(ContentType),
+		httpclient.WithHeader(HeaderParam_RequestId, requestId),
+	}
+}
+
+// Symbol returns the trading pair of the currency
+func (c *BinanceApiClient) Symbol(currencyID uint) (string, error) {
+	symbol, ok := c.symbols[currencyID]
+	if !ok || symbol == "" {
+		return "", fmt.Errorf("[%w] %s there is no symbol of the currency %d", apperror.ErrNotFound, Name, currencyID)
+	}
+	return symbol, nil
+}
+
+// GetKlines returns the candles of the symbol opened since the startTime; the pages are requested while they are full
+func (c *BinanceApiClient) GetKlines(ctx context.Context, currencyID uint, symbol string, interval string, startTime time.Time) (*KlineList, error) {
+	if err := IntervalValidate(interval); err != nil {
+		return nil, err
+	}
+
+	const funcName = "GetKlines"
+	res := make(KlineList, 0, KlinesLimit)
+	start := startTime.UnixMilli()
+	for page := 0; page < klinesMaxPages; page++ {
+		resp := make(KlineList, 0, KlinesLimit)
+		requestId, options := c.getDefaultRequestOptions()
+		uri := URI_GetKlines + "?symbol=" + symbol + "&interval=" + interval + "&startTime=" + strconv.FormatInt(start, 10) + "&limit=" + strconv.Itoa(KlinesLimit)
+		fetchedAt := time.Now().UTC()
+
+		data, err := c.http.GetJSON(ctx, uri, &resp, options...)
+		c.archive(ctx, URI_GetKlines, currencyID, fetchedAt, data)
+		if err != nil {
+			c.logger.Error("http.GetJSON error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
+			return nil, fmt.Errorf(Name+"."+funcName+" %w; requestId: %s; uri: %s", err, requestId, uri)
+		}
+
+		res = append(res, resp...)
+		if len(resp) < KlinesLimit {
+			break
+		}
+		start = resp[len(resp)-1].OpenTime + 1
+	}
+
+	return &res, nil
+}
+
+// GetDetailChart is the price_and_cap.Source over the klines: 1M is the hourly candles, 1Y and All are the daily ones.
+// The price is the close price at the close time of a closed candle, the daily volume is the quote asset volume of the last 24 hours, the cap is unknown and equals 0.
+func (c *BinanceApiClient) GetDetailChart(ctx context.Context, currencyID uint, tRange string) (*price_and_cap.PriceAndCapList, error) {
+	if err := price_and_cap.TimeRangeValidate(tRange); err != nil {
+		return nil, err
+	}
+	symbol, err := c.Symbol(currencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	const funcName = "GetDetailChart"
+	now := time.Now().UTC()
+	interval := Interval_1d
+	var startTime time.Time
+	var window int
+	switch tRange {
+	case price_and_cap.TimeRange_1M:
+		interval = Interval_1h
+		// the previous day is needed for the volume of the first hours
+		startTime = now.Add(-time.Hour * 24 * 30).Add(-time.Hour * (hoursInDay - 1))
+		window = hoursInDay
+	case price_and_cap.TimeRange_1Y:
+		startTime = now.Add(-time.Hour * 24 * 365)
+		window = 1
+	default:
+		startTime = time.Unix(0, 0)
+		window = 1
+	}
+
+	klines, err := c.GetKlines(ctx, currencyID, symbol, interval, startTime)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := klines.PriceAndCapList(currencyID, window, now)
+	if err != nil {
+		c.logger.Error("error while convertation result", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
+		return nil, fmt.Errorf(funcName+" [%w] error while convertation result; symbol: %s; interval: %s; error: %w;", apperror.ErrBadPayload, symbol, interval, err)
+	}
+
+	return res, nil
+}
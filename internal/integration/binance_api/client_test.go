@@ -0,0 +1,180 @@
+package binance_api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/minipkg/httpclient"
+	"go.uber.org/zap"
+
+	"info/internal/domain/price_and_cap"
+	"info/internal/pkg/apperror"
+)
+
+const (
+	testCurrencyID = 1
+	testSymbol     = "BTCUSDT"
+)
+
+var clientNb atomic.Uint64
+
+// klineJSON is the candle in the format of the API; the close price is the number of the candle, the quote volume is 10
+func klineJSON(openTime time.Time, step time.Duration, nb int) string {
+	price := strconv.Itoa(nb)
+	return fmt.Sprintf(`[%d,"%s","%s","%s","%s","1.5",%d,"10.0",100,"0.5","5.0","0"]`,
+		openTime.UnixMilli(), price, price, price, price, openTime.Add(step).UnixMilli()-1)
+}
+
+// startKlinesServer is a stand-in of the klines endpoint: it returns the candles of the interval since startTime up to total candles
+func startKlinesServer(t *testing.T, total int, requests *atomic.Int64) *BinanceApiClient {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		q := r.URL.Query()
+		if r.URL.Path != URI_GetKlines || q.Get("symbol") != testSymbol {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-1121,"msg":"Invalid symbol."}`))
+			return
+		}
+		step := time.Hour
+		if q.Get("interval") == Interval_1d {
+			step = time.Hour * 24
+		}
+		startMs, _ := strconv.ParseInt(q.Get("startTime"), 10, 64)
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		// the first candle opens at the epoch, every next one after the step
+		first := time.Duration(0)
+		if startMs > 0 {
+			first = (time.Duration(startMs)*time.Millisecond + step - 1) / step
+		}
+		items := make([]string, 0, limit)
+		for nb := int(first); nb < total && len(items) < limit; nb++ {
+			items = append(items, klineJSON(time.Unix(0, 0).Add(step*time.Duration(nb)), step, nb))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[" + strings.Join(items, ",") + "]"))
+	}))
+	t.Cleanup(srv.Close)
+
+	return New(&AppConfig{NameSpace: "test", Subsystem: "binance_api", Service: "test"}, &Config{
+		Httpconfig: httpclient.Config{
+			// every client registers its own metrics, so the names have to differ
+			Name:    "binance_api_test_" + strconv.FormatUint(clientNb.Add(1), 10),
+			Host:    srv.URL,
+			Timeout: time.Second,
+		},
+		Symbols: []SymbolMapping{{CurrencyID: testCurrencyID, Symbol: testSymbol}},
+	}, zap.NewNop())
+}
+
+func TestBinanceApiClient_GetDetailChart_All(t *testing.T) {
+	var requests atomic.Int64
+	client := startKlinesServer(t, 2500, &requests)
+
+	list, err := client.GetDetailChart(context.Background(), testCurrencyID, price_and_cap.TimeRange_All)
+	if err != nil {
+		t.Fatalf("GetDetailChart error: %v", err)
+	}
+	if len(*list) != 2500 {
+		t.Fatalf("GetDetailChart: got %d items, want 2500", len(*list))
+	}
+	if requests.Load() != 3 {
+		t.Errorf("GetDetailChart: got %d requests, want 3 pages", requests.Load())
+	}
+	last := (*list)[len(*list)-1]
+	if last.CurrencyID != testCurrencyID || last.Price != 2499 || last.DailyVolume != 10 || last.Cap != 0 || last.Source != price_and_cap.Source_Binance {
+		t.Errorf("GetDetailChart: unexpected last item %+v", last)
+	}
+	// the price of a candle is at its close time
+	if !last.Ts.Equal(time.Unix(0, 0).Add(time.Hour * 24 * 2500)) {
+		t.Errorf("GetDetailChart: got ts %v of the last item", last.Ts)
+	}
+}
+
+func TestBinanceApiClient_GetDetailChart_UnknownSymbol(t *testing.T) {
+	var requests atomic.Int64
+	client := startKlinesServer(t, 10, &requests)
+
+	_, err := client.GetDetailChart(context.Background(), 2, price_and_cap.TimeRange_All)
+	if !errors.Is(err, apperror.ErrNotFound) {
+		t.Errorf("GetDetailChart: got error %v, want %v", err, apperror.ErrNotFound)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("GetDetailChart: got %d requests, want 0", requests.Load())
+	}
+}
+
+func TestKlineList_PriceAndCapList(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		klinesNb   int
+		window     int
+		wantNb     int
+		now        time.Time // zero - all the candles are closed
+		wantVolume float64   // of the last item
+		wantErr    error
+	}{
+		{name: "daily", klinesNb: 3, window: 1, wantNb: 3, wantVolume: 10},
+		{name: "the unfinished candle is skipped", klinesNb: 3, window: 1, now: start.Add(time.Hour*2 + time.Minute*30), wantNb: 2, wantVolume: 10},
+		{name: "hourly with the volume of 24 hours", klinesNb: 30, window: 24, wantNb: 7, wantVolume: 240},
+		{name: "not enough for the window", klinesNb: 5, window: 24, wantErr: apperror.ErrNotFound},
+		{name: "empty", klinesNb: 0, window: 1, wantErr: apperror.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			klines := make(KlineList, 0, tt.klinesNb)
+			for i := 0; i < tt.klinesNb; i++ {
+				openTime := start.Add(time.Hour * time.Duration(i))
+				klines = append(klines, Kline{OpenTime: openTime.UnixMilli(), Close: float64(i), CloseTime: openTime.Add(time.Hour).UnixMilli() - 1, QuoteVolume: 10})
+			}
+			now := tt.now
+			if now.IsZero() {
+				now = start.Add(time.Hour * 24 * 365)
+			}
+			list, err := klines.PriceAndCapList(testCurrencyID, tt.window, now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PriceAndCapList error: %v", err)
+			}
+			if len(*list) != tt.wantNb {
+				t.Fatalf("got %d items, want %d", len(*list), tt.wantNb)
+			}
+			last := (*list)[len(*list)-1]
+			if last.DailyVolume != tt.wantVolume {
+				t.Errorf("got volume %v, want %v", last.DailyVolume, tt.wantVolume)
+			}
+			// the close price of the last candle is at its close time
+			lastNb := tt.window - 1 + tt.wantNb - 1
+			if last.Price != float64(lastNb) || !last.Ts.Equal(start.Add(time.Hour*time.Duration(lastNb+1))) {
+				t.Errorf("got the last item %+v, want the close price %d at %v", last, lastNb, start.Add(time.Hour*time.Duration(lastNb+1)))
+			}
+		})
+	}
+}
+
+func TestKline_UnmarshalJSON(t *testing.T) {
+	var k Kline
+	if err := k.UnmarshalJSON([]byte(klineJSON(time.Unix(3600, 0), time.Hour, 42))); err != nil {
+		t.Fatalf("UnmarshalJSON error: %v", err)
+	}
+	if k.OpenTime != 3600000 || k.Close != 42 || k.Volume != 1.5 || k.QuoteVolume != 10 {
+		t.Errorf("unexpected kline %+v", k)
+	}
+	if err := k.UnmarshalJSON([]byte(`[1,"2"]`)); !errors.Is(err, apperror.ErrBadPayload) {
+		t.Errorf("got error %v, want %v", err, apperror.ErrBadPayload)
+	}
+}
@@ -0,0 +1,106 @@
+package binance_api
+
+import (
+	"encoding/json"
+	"fmt"
+	"info/internal/domain/price_and_cap"
+	"info/internal/pkg/apperror"
+	"strconv"
+	"time"
+)
+
+const (
+	// klineFieldsMinNb is the number of the fields up to the quote asset volume
+	klineFieldsMinNb = 8
+)
+
+// Kline is the candle; the API sends it as an array:
+// [open time, open, high, low, close, volume, close time, quote asset volume, number of trades, taker buy base asset volume, taker buy quote asset volume, ignore]
+type Kline struct {
+	OpenTime    int64 // ms
+	Open        float64
+	High        float64
+	Low         float64
+	Close       float64
+	Volume      float64
+	CloseTime   int64 // ms
+	QuoteVolume float64
+}
+
+func (e *Kline) UnmarshalJSON(data []byte) error {
+	var fields []json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if len(fields) < klineFieldsMinNb {
+		return fmt.Errorf("[%w] kline has %d fields, want at least %d", apperror.ErrBadPayload, len(fields), klineFieldsMinNb)
+	}
+
+	var err error
+	if err = json.Unmarshal(fields[0], &e.OpenTime); err != nil {
+		return err
+	}
+	if err = json.Unmarshal(fields[6], &e.CloseTime); err != nil {
+		return err
+	}
+	prices := []*float64{&e.Open, &e.High, &e.Low, &e.Close, &e.Volume}
+	for i, p := range prices {
+		if *p, err = parseDecimal(fields[i+1]); err != nil {
+			return err
+		}
+	}
+	if e.QuoteVolume, err = parseDecimal(fields[7]); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseDecimal parses the decimal which is sent as a string
+func parseDecimal(data json.RawMessage) (float64, error) {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+type KlineList []Kline
+
+// PriceAndCapList converts the candles closed before now into the close prices at the close time; the daily volume is the sum of the quote volumes
+// of the window of the last candles, the first window-1 candles are needed only for the volume and are skipped
+func (l *KlineList) PriceAndCapList(currencyID uint, window int, now time.Time) (*price_and_cap.PriceAndCapList, error) {
+	if l == nil || len(*l) == 0 || currencyID == 0 {
+		return nil, apperror.ErrNotFound
+	}
+	if window < 1 {
+		window = 1
+	}
+
+	var volume float64
+	var item Kline
+	res := make(price_and_cap.PriceAndCapList, 0, len(*l))
+	for i := range *l {
+		item = (*l)[i]
+		volume += item.QuoteVolume
+		if i >= window {
+			volume -= (*l)[i-window].QuoteVolume
+		}
+		if i < window-1 || item.CloseTime >= now.UnixMilli() {
+			continue
+		}
+
+		// the close time is the last millisecond of the candle; Binance does not know the cap, it is left unknown
+		res = append(res, price_and_cap.PriceAndCap{
+			CurrencyID:  currencyID,
+			Price:       item.Close,
+			DailyVolume: volume,
+			Ts:          time.UnixMilli(item.CloseTime + 1).UTC(),
+			Source:      price_and_cap.Source_Binance,
+		})
+	}
+
+	if len(res) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+	return &res, nil
+}
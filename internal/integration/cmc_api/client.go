@@ -2,7 +2,6 @@ package cmc_api
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/minipkg/httpclient"
@@ -11,16 +10,20 @@ import (
 	"go.uber.org/zap"
 	"info/internal/domain/concentration"
 	"info/internal/domain/currency"
+	"info/internal/domain/market_pair"
 	"info/internal/domain/portfolio_item"
 	"info/internal/domain/price_and_cap"
 	"info/internal/pkg/apperror"
 	"info/internal/pkg/log_key"
+	"info/internal/pkg/ratelimit"
+	"info/internal/pkg/resilient"
 	"strconv"
+	"time"
 )
 
-type httpClient interface {
-	Get(ctx context.Context, path string, opts ...httpclient.RequestOption) ([]byte, int, error)
-	Post(ctx context.Context, path string, reqObj interface{}, opts ...httpclient.RequestOption) ([]byte, int, error)
+// Archiver stores the raw responses, so they can be converted again without a request to the API
+type Archiver interface {
+	Archive(ctx context.Context, source string, endpoint string, currencyID uint, fetchedAt time.Time, data []byte) error
 }
 
 type AppConfig struct {
@@ -31,16 +34,21 @@ type AppConfig struct {
 
 type Config struct {
 	Httpconfig httpclient.Config
+	RateLimit  ratelimit.Config
+	Resilience resilient.Config
 	Cookie     string
 }
 
 type CmcApiClient struct {
-	config     *Config
-	httpClient httpClient
-	logger     *zap.Logger
+	config   *Config
+	http     *resilient.Client
+	limiter  *ratelimit.Limiter
+	archiver Archiver
+	logger   *zap.Logger
 }
 
 const (
+	Source                = "cmc_api"
 	Name                  = "CmcApiClient"
 	ContentType           = "application/json; charset=utf-8"
 	HeaderParam_RequestId = "X-Request-Id"
@@ -102,10 +110,28 @@ func ChartRange2AnalyticsRange(s string) (string, error) {
 
 func New(appConfig *AppConfig, conf *Config, logger *zap.Logger) *CmcApiClient {
 	client := httpclient.New(conf.Httpconfig, prometheus_utils.NewHttpClientMetrics(appConfig.NameSpace, appConfig.Subsystem, appConfig.Service, conf.Httpconfig.Name).SetCuttingPathOpts(&prometheus_utils.CuttingPathOpts{IsNeedToRemoveQueryInPath: true}))
+	limiter := ratelimit.New(conf.RateLimit)
 	return &CmcApiClient{
-		config:     conf,
-		httpClient: client,
-		logger:     logger,
+		config:  conf,
+		http:    resilient.New(Name, conf.Resilience, client, limiter, resilient.NewMetrics(appConfig.NameSpace, appConfig.Subsystem, appConfig.Service, conf.Httpconfig.Name)),
+		limiter: limiter,
+		logger:  logger,
+	}
+}
+
+// SetArchiver turns on the archive of the raw responses
+func (c *CmcApiClient) SetArchiver(archiver Archiver) *CmcApiClient {
+	c.archiver = archiver
+	return c
+}
+
+// archive stores the raw response if the archive is turned on; an error of the archive does not fail the request
+func (c *CmcApiClient) archive(ctx context.Context, endpoint string, currencyID uint, fetchedAt time.Time, data []byte) {
+	if c.archiver == nil || data == nil {
+		return
+	}
+	if err := c.archiver.Archive(ctx, Source, endpoint, currencyID, fetchedAt, data); err != nil {
+		c.logger.Error("archiver.Archive error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Endpoint, endpoint), zap.Error(err))
 	}
 }
 
@@ -132,32 +158,20 @@ func (c *CmcApiClient) GetDetailChart(ctx context.Context, currencyID uint, tRan
 	resp := &DetailChartResponse{}
 	requestId, options := c.getDefaultRequestOptions()
 	uri := URI_GetDetailChart + "?id=" + strconv.FormatUint(uint64(currencyID), 10) + "&range=" + tRange
+	fetchedAt := time.Now().UTC()
 
-	data, code, err := c.httpClient.Get(ctx, uri, options...)
+	data, err := c.http.GetJSON(ctx, uri, resp, options...)
+	c.archive(ctx, URI_GetDetailChart, currencyID, fetchedAt, data)
 	if err != nil {
-		c.logger.Error("httpClient.Get error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
-		return nil, fmt.Errorf(Name+"."+funcName+" [%w] http error: %s; requestId: %s; uri: %s", apperror.ErrInternal, err.Error(), requestId, uri)
-	}
-	if code != 200 {
-		c.logger.Error("httpClient.Get error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err), zap.Int(log_key.Code, code))
-		return nil, fmt.Errorf(funcName+" [%w] http response error code: "+strconv.Itoa(code)+"; requestId: %s; uri: %s; response: %s", apperror.ErrInternal, requestId, uri, string(data))
-	}
-
-	if err = json.Unmarshal(data, resp); err != nil {
-		c.logger.Error("json.Unmarshal error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
-		return nil, fmt.Errorf(funcName+" [%w] json.Unmarshal error: %s; requestId: %s; uri: %s; response: %s", apperror.ErrInternal, err.Error(), requestId, uri, string(data))
-	}
-
-	if resp.Status.ErrorCode != "0" || resp.Status.ErrorMessage != ErrorMessage_Success {
-		c.logger.Error("response with error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.String(log_key.ErrorCode, resp.Status.ErrorCode), zap.String(log_key.ErrorMessage, resp.Status.ErrorMessage))
-		return nil, fmt.Errorf(funcName+" [%w] response with error; code: "+resp.Status.ErrorCode+"; error message: "+resp.Status.ErrorMessage+"; requestId: %s; uri: %s; response: %s", apperror.ErrInternal, requestId, uri, string(data))
+		c.logger.Error("http.GetJSON error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
+		return nil, fmt.Errorf(Name+"."+funcName+" %w; requestId: %s; uri: %s", err, requestId, uri)
 	}
 
 	resp.Data.CurrencyID = currencyID
 	res, err := resp.Data.PriceAndCapList()
 	if err != nil {
 		c.logger.Error("error while convertation result", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
-		return nil, fmt.Errorf(funcName+" [%w] error while convertation result; requestId: %s; uri: %s; response: %s; error: %w;", apperror.ErrInternal, requestId, uri, string(data), err)
+		return nil, fmt.Errorf(funcName+" [%w] error while convertation result; requestId: %s; uri: %s; response: %s; error: %w;", apperror.ErrBadPayload, requestId, uri, string(data), err)
 	}
 
 	return res, nil
@@ -177,32 +191,20 @@ func (c *CmcApiClient) GetAnalytics(ctx context.Context, currencyID uint, tRange
 	resp := &GetAnalyticsResponse{}
 	requestId, options := c.getDefaultRequestOptions()
 	uri := URI_GetAnalytics + "?cryptoId=" + strconv.FormatUint(uint64(currencyID), 10) + "&timeRangeType=" + tRange
+	fetchedAt := time.Now().UTC()
 
-	data, code, err := c.httpClient.Get(ctx, uri, options...)
+	data, err := c.http.GetJSON(ctx, uri, resp, options...)
+	c.archive(ctx, URI_GetAnalytics, currencyID, fetchedAt, data)
 	if err != nil {
-		c.logger.Error("httpClient.Get error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
-		return nil, fmt.Errorf(Name+"."+funcName+" [%w] http error: %s; requestId: %s; uri: %s", apperror.ErrInternal, err.Error(), requestId, uri)
-	}
-	if code != 200 {
-		c.logger.Error("httpClient.Get error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err), zap.Int(log_key.Code, code))
-		return nil, fmt.Errorf(funcName+" [%w] http response error code: "+strconv.Itoa(code)+"; requestId: %s; uri: %s; response: %s", apperror.ErrInternal, requestId, uri, string(data))
-	}
-
-	if err = json.Unmarshal(data, resp); err != nil {
-		c.logger.Error("json.Unmarshal error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
-		return nil, fmt.Errorf(funcName+" [%w] json.Unmarshal error: %s; requestId: %s; uri: %s; response: %s", apperror.ErrInternal, err.Error(), requestId, uri, string(data))
-	}
-
-	if resp.Status.ErrorCode != "0" || resp.Status.ErrorMessage != ErrorMessage_Success {
-		c.logger.Error("response with error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.String(log_key.ErrorCode, resp.Status.ErrorCode), zap.String(log_key.ErrorMessage, resp.Status.ErrorMessage))
-		return nil, fmt.Errorf(funcName+" [%w] response with error; code: "+resp.Status.ErrorCode+"; error message: "+resp.Status.ErrorMessage+"; requestId: %s; uri: %s; response: %s", apperror.ErrInternal, requestId, uri, string(data))
+		c.logger.Error("http.GetJSON error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
+		return nil, fmt.Errorf(Name+"."+funcName+" %w; requestId: %s; uri: %s", err, requestId, uri)
 	}
 
 	resp.Data.CurrencyID = currencyID
 	res, err := resp.Data.ConcentrationList()
 	if err != nil {
 		c.logger.Error("error while convertation result", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
-		return nil, fmt.Errorf(funcName+" [%w] error while convertation result; requestId: %s; uri: %s; response: %s; error: %w;", apperror.ErrInternal, requestId, uri, string(data), err)
+		return nil, fmt.Errorf(funcName+" [%w] error while convertation result; requestId: %s; uri: %s; response: %s; error: %w;", apperror.ErrBadPayload, requestId, uri, string(data), err)
 	}
 
 	return res, nil
@@ -214,25 +216,13 @@ func (c *CmcApiClient) GetCurrency(ctx context.Context, currencySlug string) (*c
 	resp := &GetCurrencyResponse{}
 	requestId, options := c.getDefaultRequestOptions()
 	uri := URI_GetCurrencySimple + "?start=1&limit=10&category=spot&slug=" + currencySlug
+	fetchedAt := time.Now().UTC()
 
-	data, code, err := c.httpClient.Get(ctx, uri, options...)
+	data, err := c.http.GetJSON(ctx, uri, resp, options...)
+	c.archive(ctx, URI_GetCurrencySimple, 0, fetchedAt, data)
 	if err != nil {
-		c.logger.Error("httpClient.Get error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
-		return nil, fmt.Errorf(Name+"."+funcName+" [%w] http error: %s; requestId: %s; uri: %s", apperror.ErrInternal, err.Error(), requestId, uri)
-	}
-	if code != 200 {
-		c.logger.Error("httpClient.Get error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err), zap.Int(log_key.Code, code))
-		return nil, fmt.Errorf(funcName+" [%w] http response error code: "+strconv.Itoa(code)+"; requestId: %s; uri: %s; response: %s", apperror.ErrInternal, requestId, uri, string(data))
-	}
-
-	if err = json.Unmarshal(data, resp); err != nil {
-		c.logger.Error("json.Unmarshal error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
-		return nil, fmt.Errorf(funcName+" [%w] json.Unmarshal error: %s; requestId: %s; uri: %s; response: %s", apperror.ErrInternal, err.Error(), requestId, uri, string(data))
-	}
-
-	if resp.Status.ErrorCode != "0" || resp.Status.ErrorMessage != ErrorMessage_Success {
-		c.logger.Error("response with error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.String(log_key.ErrorCode, resp.Status.ErrorCode), zap.String(log_key.ErrorMessage, resp.Status.ErrorMessage))
-		return nil, fmt.Errorf(funcName+" [%w] response with error; code: "+resp.Status.ErrorCode+"; error message: "+resp.Status.ErrorMessage+"; requestId: %s; uri: %s; response: %s", apperror.ErrInternal, requestId, uri, string(data))
+		c.logger.Error("http.GetJSON error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
+		return nil, fmt.Errorf(Name+"."+funcName+" %w; requestId: %s; uri: %s", err, requestId, uri)
 	}
 
 	resp.Data.Slug = currencySlug
@@ -241,6 +231,30 @@ func (c *CmcApiClient) GetCurrency(ctx context.Context, currencySlug string) (*c
 	return res, nil
 }
 
+// GetMarketPairs returns the top market pairs of the currency of the category (spot, perpetual, futures) in the order of CMC
+func (c *CmcApiClient) GetMarketPairs(ctx context.Context, currencyID uint, slug string, category string, limit uint) (*market_pair.ImportData, error) {
+	var err error
+	const funcName = "GetMarketPairs"
+	resp := &GetCurrencyResponse{}
+	requestId, options := c.getDefaultRequestOptions()
+	uri := URI_GetCurrencySimple + "?start=1&limit=" + strconv.FormatUint(uint64(limit), 10) + "&category=" + category + "&slug=" + slug
+	fetchedAt := time.Now().UTC()
+
+	data, err := c.http.GetJSON(ctx, uri, resp, options...)
+	c.archive(ctx, URI_GetCurrencySimple, currencyID, fetchedAt, data)
+	if err != nil {
+		c.logger.Error("http.GetJSON error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
+		return nil, fmt.Errorf(Name+"."+funcName+" %w; requestId: %s; uri: %s", err, requestId, uri)
+	}
+
+	res, err := resp.Data.MarketPairImportData(currencyID)
+	if err != nil {
+		c.logger.Error("error while convertation result", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
+		return nil, fmt.Errorf(funcName+" [%w] error while convertation result; requestId: %s; uri: %s; response: %s; error: %w;", apperror.ErrBadPayload, requestId, uri, string(data), err)
+	}
+	return res, nil
+}
+
 func (c *CmcApiClient) getPortfolioSummaryRequest(portfolioSourceId string) *GetPortfolioSummaryRequest {
 	return &GetPortfolioSummaryRequest{
 		PortfolioSourceId: portfolioSourceId,
@@ -257,30 +271,18 @@ func (c *CmcApiClient) GetPortfolioSummary(ctx context.Context, portfolioSourceI
 	resp := &GetPortfolioSummaryResponse{}
 	requestId, options := c.getRequestOptionsWithCookie()
 	uri := URI_GetPortfolioSummary
+	fetchedAt := time.Now().UTC()
 
-	data, code, err := c.httpClient.Post(ctx, uri, c.getPortfolioSummaryRequest(portfolioSourceId), options...)
+	data, err := c.http.PostJSON(ctx, uri, c.getPortfolioSummaryRequest(portfolioSourceId), resp, options...)
+	c.archive(ctx, URI_GetPortfolioSummary, 0, fetchedAt, data)
 	if err != nil {
-		c.logger.Error("httpClient.Get error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
-		return nil, fmt.Errorf(Name+"."+funcName+" [%w] http error: %s; requestId: %s; uri: %s", apperror.ErrInternal, err.Error(), requestId, uri)
-	}
-	if code != 200 {
-		c.logger.Error("httpClient.Get error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err), zap.Int(log_key.Code, code))
-		return nil, fmt.Errorf(funcName+" [%w] http response error code: "+strconv.Itoa(code)+"; requestId: %s; uri: %s; response: %s", apperror.ErrInternal, requestId, uri, string(data))
-	}
-
-	if err = json.Unmarshal(data, resp); err != nil {
-		c.logger.Error("json.Unmarshal error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
-		return nil, fmt.Errorf(funcName+" [%w] json.Unmarshal error: %s; requestId: %s; uri: %s; response: %s", apperror.ErrInternal, err.Error(), requestId, uri, string(data))
-	}
-
-	if resp.Status.ErrorCode != "0" || resp.Status.ErrorMessage != ErrorMessage_Success {
-		c.logger.Error("response with error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.String(log_key.ErrorCode, resp.Status.ErrorCode), zap.String(log_key.ErrorMessage, resp.Status.ErrorMessage))
-		return nil, fmt.Errorf(funcName+" [%w] response with error; code: "+resp.Status.ErrorCode+"; error message: "+resp.Status.ErrorMessage+"; requestId: %s; uri: %s; response: %s", apperror.ErrInternal, requestId, uri, string(data))
+		c.logger.Error("http.PostJSON error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
+		return nil, fmt.Errorf(Name+"."+funcName+" %w; requestId: %s; uri: %s", err, requestId, uri)
 	}
 
 	if resp.Data == nil || len(resp.Data.ManualSummary) == 0 {
 		c.logger.Error("response with empty data", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName))
-		return nil, fmt.Errorf(funcName+" [%w] response with empty data; requestId: %s; uri: %s; response: %s", apperror.ErrInternal, requestId, uri, string(data))
+		return nil, fmt.Errorf(funcName+" [%w] response with empty data; requestId: %s; uri: %s; response: %s", apperror.ErrBadPayload, requestId, uri, string(data))
 	}
 
 	return resp.Data.ManualSummary[0].List.SetPortfolioSourceId(portfolioSourceId).PortfolioItemList(), nil
@@ -1,8 +1,11 @@
 package cmc_api
 
 import (
+	"encoding/json"
+	"fmt"
 	"info/internal/domain/concentration"
 	"info/internal/domain/currency"
+	"info/internal/domain/market_pair"
 	"info/internal/domain/portfolio_item"
 	"info/internal/domain/price_and_cap"
 	"info/internal/pkg/apperror"
@@ -18,16 +21,116 @@ type Status struct {
 	CreditCount  uint   `json:"credit_count"`
 }
 
+// Err returns the error of the response with an error status
+func (s *Status) Err() error {
+	if s.ErrorCode != "0" || s.ErrorMessage != ErrorMessage_Success {
+		return fmt.Errorf("[%w] response with error; code: %s; error message: %s", apperror.ErrInternal, s.ErrorCode, s.ErrorMessage)
+	}
+	return nil
+}
+
+// Credits returns the API credits spent by the request
+func (s *Status) Credits() uint {
+	return s.CreditCount
+}
+
+// ParseDetailChart converts the raw response of URI_GetDetailChart; it is used to convert the archived responses again
+func ParseDetailChart(data []byte, currencyID uint) (*price_and_cap.PriceAndCapList, error) {
+	resp := &DetailChartResponse{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return nil, fmt.Errorf("[%w] json.Unmarshal error: %w", apperror.ErrInternal, err)
+	}
+	if err := resp.Status.Err(); err != nil {
+		return nil, err
+	}
+	if resp.Data == nil {
+		return nil, apperror.ErrNotFound
+	}
+	resp.Data.CurrencyID = currencyID
+	return resp.Data.PriceAndCapList()
+}
+
+// ParseAnalytics converts the raw response of URI_GetAnalytics; it is used to convert the archived responses again
+func ParseAnalytics(data []byte, currencyID uint) (*concentration.ConcentrationList, error) {
+	resp := &GetAnalyticsResponse{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return nil, fmt.Errorf("[%w] json.Unmarshal error: %w", apperror.ErrInternal, err)
+	}
+	if err := resp.Status.Err(); err != nil {
+		return nil, err
+	}
+	if resp.Data == nil {
+		return &concentration.ConcentrationList{}, nil
+	}
+	resp.Data.CurrencyID = currencyID
+	return resp.Data.ConcentrationList()
+}
+
 type GetCurrencyResponse struct {
 	Data   *CurrencyData `json:"data"`
-	Status Status        `json:"status"`
+	Status `json:"status"`
 }
 
 type CurrencyData struct {
-	ID     uint   `json:"id"`
-	Symbol string `json:"symbol"`
-	Slug   string `json:"slug"`
-	Name   string `json:"name"`
+	ID             uint             `json:"id"`
+	Symbol         string           `json:"symbol"`
+	Slug           string           `json:"slug"`
+	Name           string           `json:"name"`
+	NumMarketPairs uint             `json:"numMarketPairs"`
+	MarketPairs    []MarketPairData `json:"marketPairs"`
+}
+
+type MarketPairData struct {
+	ExchangeID          uint    `json:"exchangeId"`
+	ExchangeName        string  `json:"exchangeName"`
+	ExchangeSlug        string  `json:"exchangeSlug"`
+	MarketID            uint    `json:"marketId"`
+	MarketPair          string  `json:"marketPair"`
+	Category            string  `json:"category"`
+	BaseSymbol          string  `json:"baseSymbol"`
+	QuoteSymbol         string  `json:"quoteSymbol"`
+	Price               float64 `json:"price"`
+	VolumeUsd           float64 `json:"volumeUsd"`
+	DepthUsdNegativeTwo float64 `json:"depthUsdNegativeTwo"`
+	DepthUsdPositiveTwo float64 `json:"depthUsdPositiveTwo"`
+	VolumeExcluded      bool    `json:"volumeExcluded"`
+	PriceExcluded       bool    `json:"priceExcluded"`
+}
+
+// MarketPairImportData returns the market pairs with their exchanges; the pairs excluded by CMC from the volume are skipped
+func (e *CurrencyData) MarketPairImportData(currencyID uint) (*market_pair.ImportData, error) {
+	if e == nil || currencyID == 0 {
+		return nil, apperror.ErrNotFound
+	}
+	res := &market_pair.ImportData{
+		ExchangeList:   make(market_pair.ExchangeList, 0, len(e.MarketPairs)),
+		MarketPairList: make(market_pair.MarketPairList, 0, len(e.MarketPairs)),
+	}
+	var item MarketPairData
+	for _, item = range e.MarketPairs {
+		if item.VolumeExcluded || item.ExchangeID == 0 || item.MarketID == 0 {
+			continue
+		}
+		res.ExchangeList = append(res.ExchangeList, market_pair.Exchange{
+			ID:   item.ExchangeID,
+			Name: item.ExchangeName,
+			Slug: item.ExchangeSlug,
+		})
+		res.MarketPairList = append(res.MarketPairList, market_pair.MarketPair{
+			CurrencyID:       currencyID,
+			ExchangeID:       item.ExchangeID,
+			MarketID:         item.MarketID,
+			Pair:             item.MarketPair,
+			Category:         item.Category,
+			BaseSymbol:       item.BaseSymbol,
+			QuoteSymbol:      item.QuoteSymbol,
+			Price:            item.Price,
+			VolumeUsd:        item.VolumeUsd,
+			DepthNegativeTwo: item.DepthUsdNegativeTwo,
+			DepthPositiveTwo: item.DepthUsdPositiveTwo,
+		})
+	}
+	return res, nil
 }
 
 func (e *CurrencyData) Currency() *currency.Currency {
@@ -41,7 +144,7 @@ func (e *CurrencyData) Currency() *currency.Currency {
 
 type DetailChartResponse struct {
 	Data   *DetailChartData `json:"data"`
-	Status Status           `json:"status"`
+	Status `json:"status"`
 }
 
 type DetailChartData struct {
@@ -72,6 +175,7 @@ func (e *DetailChartData) PriceAndCapList() (*price_and_cap.PriceAndCapList, err
 			DailyVolume: point.V[1],
 			Cap:         point.V[2],
 			Ts:          time.Unix(i, 0),
+			Source:      price_and_cap.Source_Cmc,
 		})
 	}
 
@@ -90,7 +194,7 @@ type DetailChartPoint struct {
 
 type GetAnalyticsResponse struct {
 	Data   *GetAnalyticsData `json:"data"`
-	Status Status            `json:"status"`
+	Status `json:"status"`
 }
 
 type GetAnalyticsData struct {
@@ -167,7 +271,7 @@ type GetPortfolioSummaryRequest struct {
 
 type GetPortfolioSummaryResponse struct {
 	Data   *PortfolioSummary `json:"data"`
-	Status Status            `json:"status"`
+	Status `json:"status"`
 }
 
 type PortfolioSummary struct {
@@ -0,0 +1,38 @@
+package cmc_pro_api
+
+import (
+	"info/internal/domain/oracul_analytics"
+	"strings"
+)
+
+// blockchainCodes maps the lower case names and slugs of the CMC platforms to the codes of the Oracul API
+var blockchainCodes = map[string]string{
+	"ethereum":                oracul_analytics.Blockchain_ETH,
+	"bnb smart chain (bep20)": oracul_analytics.Blockchain_BNB,
+	"bnb smart chain":         oracul_analytics.Blockchain_BNB,
+	"binance smart chain":     oracul_analytics.Blockchain_BNB,
+	"bnb":                     oracul_analytics.Blockchain_BNB,
+	"polygon":                 oracul_analytics.Blockchain_POL,
+	"polygon pos":             oracul_analytics.Blockchain_POL,
+	"polygon-pos":             oracul_analytics.Blockchain_POL,
+	"polygon-ecosystem-token": oracul_analytics.Blockchain_POL,
+	"matic-network":           oracul_analytics.Blockchain_POL,
+	"fantom":                  oracul_analytics.Blockchain_FTM,
+	"fantom opera":            oracul_analytics.Blockchain_FTM,
+	"optimism":                oracul_analytics.Blockchain_OP,
+	"optimism-ethereum":       oracul_analytics.Blockchain_OP,
+	"op mainnet":              oracul_analytics.Blockchain_OP,
+}
+
+// NormalizeBlockchain returns the Oracul code of the CMC platform by its name or the slug of its coin;
+// an unknown platform gets its name in upper case, so its addresses are stored but not imported from Oracul
+func NormalizeBlockchain(platformName string, coinSlug string) string {
+	name := strings.ToLower(strings.TrimSpace(platformName))
+	if code, ok := blockchainCodes[name]; ok {
+		return code
+	}
+	if code, ok := blockchainCodes[strings.ToLower(coinSlug)]; ok && name == "" {
+		return code
+	}
+	return strings.ToUpper(name)
+}
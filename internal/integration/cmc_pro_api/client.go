@@ -2,23 +2,27 @@ package cmc_pro_api
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/minipkg/httpclient"
 	prometheus_utils "github.com/minipkg/prometheus-utils"
 	uuid "github.com/satori/go.uuid"
 	"go.uber.org/zap"
 	"info/internal/domain/currency"
+	"info/internal/domain/global_metrics"
+	"info/internal/domain/universe"
 	"info/internal/pkg/apperror"
 	"info/internal/pkg/fasthttp_tools"
 	"info/internal/pkg/log_key"
+	"info/internal/pkg/ratelimit"
+	"info/internal/pkg/resilient"
 	"strconv"
 	"strings"
+	"time"
 )
 
-type httpClient interface {
-	Get(ctx context.Context, path string, opts ...httpclient.RequestOption) ([]byte, int, error)
-	Post(ctx context.Context, path string, reqObj interface{}, opts ...httpclient.RequestOption) ([]byte, int, error)
+// Archiver stores the raw responses, so they can be converted again without a request to the API
+type Archiver interface {
+	Archive(ctx context.Context, source string, endpoint string, currencyID uint, fetchedAt time.Time, data []byte) error
 }
 
 type AppConfig struct {
@@ -29,16 +33,21 @@ type AppConfig struct {
 
 type Config struct {
 	Httpconfig httpclient.Config
+	RateLimit  ratelimit.Config
+	Resilience resilient.Config
 	Token      string
 }
 
 type CmcApiClient struct {
-	config     *Config
-	httpClient httpClient
-	logger     *zap.Logger
+	config   *Config
+	http     *resilient.Client
+	limiter  *ratelimit.Limiter
+	archiver Archiver
+	logger   *zap.Logger
 }
 
 const (
+	Source                = "cmc_pro_api"
 	Name                  = "CmcApiClient"
 	ContentType           = "application/json; charset=utf-8"
 	HeaderParam_RequestId = "X-Request-Id"
@@ -47,14 +56,37 @@ const (
 	ErrorMessage_Success = "SUCCESS"
 
 	URI_GetCurrencies string = "/v2/cryptocurrency/quotes/latest"
+	URI_GetListings   string = "/v1/cryptocurrency/listings/latest"
+	URI_GetInfo       string = "/v2/cryptocurrency/info"
+
+	URI_GetGlobalMetricsLatest     string = "/v1/global-metrics/quotes/latest"
+	URI_GetGlobalMetricsHistorical string = "/v1/global-metrics/quotes/historical"
 )
 
 func New(appConfig *AppConfig, conf *Config, logger *zap.Logger) *CmcApiClient {
 	client := httpclient.New(conf.Httpconfig, prometheus_utils.NewHttpClientMetrics(appConfig.NameSpace, appConfig.Subsystem, appConfig.Service, conf.Httpconfig.Name).SetCuttingPathOpts(&prometheus_utils.CuttingPathOpts{IsNeedToRemoveQueryInPath: true}))
+	limiter := ratelimit.New(conf.RateLimit)
 	return &CmcApiClient{
-		config:     conf,
-		httpClient: client,
-		logger:     logger,
+		config:  conf,
+		http:    resilient.New(Name, conf.Resilience, client, limiter, resilient.NewMetrics(appConfig.NameSpace, appConfig.Subsystem, appConfig.Service, conf.Httpconfig.Name)),
+		limiter: limiter,
+		logger:  logger,
+	}
+}
+
+// SetArchiver turns on the archive of the raw responses
+func (c *CmcApiClient) SetArchiver(archiver Archiver) *CmcApiClient {
+	c.archiver = archiver
+	return c
+}
+
+// archive stores the raw response if the archive is turned on; an error of the archive does not fail the request
+func (c *CmcApiClient) archive(ctx context.Context, endpoint string, currencyID uint, fetchedAt time.Time, data []byte) {
+	if c.archiver == nil || data == nil {
+		return
+	}
+	if err := c.archiver.Archive(ctx, Source, endpoint, currencyID, fetchedAt, data); err != nil {
+		c.logger.Error("archiver.Archive error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Endpoint, endpoint), zap.Error(err))
 	}
 }
 
@@ -93,31 +125,108 @@ func (c *CmcApiClient) getCurrencies(ctx context.Context, params string) (curren
 	requestId, options := c.getRequestOptions()
 
 	uri := URI_GetCurrencies + "?" + params
+	fetchedAt := time.Now().UTC()
 
-	data, code, err := c.httpClient.Get(ctx, uri, options...)
+	data, err := c.http.GetJSON(ctx, uri, resp, options...)
+	c.archive(ctx, URI_GetCurrencies, 0, fetchedAt, data)
 	if err != nil {
-		c.logger.Error("httpClient.Get error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
-		return nil, fmt.Errorf(Name+"."+funcName+" [%w] http error: %s; requestId: %s; uri: %s", apperror.ErrInternal, err.Error(), requestId, uri)
+		c.logger.Error("http.GetJSON error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
+		return nil, fmt.Errorf(Name+"."+funcName+" %w; requestId: %s; uri: %s", err, requestId, uri)
 	}
-	if code != 200 {
-		c.logger.Error("httpClient.Get error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err), zap.Int(log_key.Code, code))
-		return nil, fmt.Errorf(funcName+" [%w] http response error code: "+strconv.Itoa(code)+"; requestId: %s; uri: %s; response: %s", apperror.ErrInternal, requestId, uri, string(data))
+
+	if currencyMap, err = resp.Data.CurrencyMap(); err != nil {
+		c.logger.Error("error while convertation result", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
+		return nil, fmt.Errorf(funcName+" [%w] error while convertation result; requestId: %s; uri: %s; response: %s; error: %w;", apperror.ErrBadPayload, requestId, uri, string(data), err)
 	}
 
-	if err = json.Unmarshal(data, resp); err != nil {
-		c.logger.Error("json.Unmarshal error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
-		return nil, fmt.Errorf(funcName+" [%w] json.Unmarshal error: %s; requestId: %s; uri: %s; response: %s", apperror.ErrInternal, err.Error(), requestId, uri, string(data))
+	return currencyMap, nil
+}
+
+// GetListings returns the listings sorted by the market cap from start (1 - the first one) in USD
+func (c *CmcApiClient) GetListings(ctx context.Context, start uint, limit uint) (*universe.CandidateList, error) {
+	const funcName = "GetListings"
+	resp := &ListingsResponse{}
+	requestId, options := c.getRequestOptions()
+
+	uri := URI_GetListings + "?start=" + strconv.FormatUint(uint64(start), 10) + "&limit=" + strconv.FormatUint(uint64(limit), 10) + "&convert=USD"
+	fetchedAt := time.Now().UTC()
+
+	data, err := c.http.GetJSON(ctx, uri, resp, options...)
+	c.archive(ctx, URI_GetListings, 0, fetchedAt, data)
+	if err != nil {
+		c.logger.Error("http.GetJSON error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
+		return nil, fmt.Errorf(Name+"."+funcName+" %w; requestId: %s; uri: %s", err, requestId, uri)
 	}
 
-	if resp.Status.ErrorCode != 0 || (resp.Status.ErrorMessage != ErrorMessage_Success && resp.Status.ErrorMessage != "") {
-		c.logger.Error("response with error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Int(log_key.ErrorCode, resp.Status.ErrorCode), zap.String(log_key.ErrorMessage, resp.Status.ErrorMessage))
-		return nil, fmt.Errorf(funcName+" [%w] response with error; code: %d; error message: "+resp.Status.ErrorMessage+"; requestId: %s; uri: %s; response: %s", apperror.ErrInternal, resp.Status.ErrorCode, requestId, uri, string(data))
+	return resp.CandidateList(), nil
+}
+
+// GetTokenAddresses returns the contract addresses of the currencies on every blockchain
+func (c *CmcApiClient) GetTokenAddresses(ctx context.Context, currencyIDs *[]uint) (*currency.TokenAddressList, error) {
+	const funcName = "GetTokenAddresses"
+	if currencyIDs == nil || len(*currencyIDs) == 0 {
+		return &currency.TokenAddressList{}, nil
 	}
+	resp := &InfoResponse{}
+	requestId, options := c.getRequestOptions()
 
-	if currencyMap, err = resp.Data.CurrencyMap(); err != nil {
+	uri := URI_GetInfo + "?id=" + fasthttp_tools.Uints2Str(currencyIDs, nil)
+	fetchedAt := time.Now().UTC()
+
+	data, err := c.http.GetJSON(ctx, uri, resp, options...)
+	c.archive(ctx, URI_GetInfo, 0, fetchedAt, data)
+	if err != nil {
+		c.logger.Error("http.GetJSON error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
+		return nil, fmt.Errorf(Name+"."+funcName+" %w; requestId: %s; uri: %s", err, requestId, uri)
+	}
+
+	list, err := resp.Data.TokenAddressList()
+	if err != nil {
 		c.logger.Error("error while convertation result", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
-		return nil, fmt.Errorf(funcName+" [%w] error while convertation result; requestId: %s; uri: %s; response: %s; error: %w;", apperror.ErrInternal, requestId, uri, string(data), err)
+		return nil, fmt.Errorf(funcName+" [%w] error while convertation result; requestId: %s; uri: %s; response: %s; error: %w;", apperror.ErrBadPayload, requestId, uri, string(data), err)
 	}
+	return list, nil
+}
 
-	return currencyMap, nil
+// GetGlobalMetricsLatest returns the latest global metrics in USD
+func (c *CmcApiClient) GetGlobalMetricsLatest(ctx context.Context) (*global_metrics.GlobalMetrics, error) {
+	const funcName = "GetGlobalMetricsLatest"
+	resp := &GlobalMetricsLatestResponse{}
+	requestId, options := c.getRequestOptions()
+
+	uri := URI_GetGlobalMetricsLatest + "?convert=USD"
+	fetchedAt := time.Now().UTC()
+
+	data, err := c.http.GetJSON(ctx, uri, resp, options...)
+	c.archive(ctx, URI_GetGlobalMetricsLatest, 0, fetchedAt, data)
+	if err != nil {
+		c.logger.Error("http.GetJSON error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
+		return nil, fmt.Errorf(Name+"."+funcName+" %w; requestId: %s; uri: %s", err, requestId, uri)
+	}
+
+	res, err := resp.Data.GlobalMetrics()
+	if err != nil {
+		c.logger.Error("error while convertation result", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
+		return nil, fmt.Errorf(funcName+" [%w] error while convertation result; requestId: %s; uri: %s; response: %s; error: %w;", apperror.ErrBadPayload, requestId, uri, string(data), err)
+	}
+	return res, nil
+}
+
+// GetGlobalMetricsHistorical returns the hourly global metrics in USD in [from, to); the history is not available on the basic plans of CMC
+func (c *CmcApiClient) GetGlobalMetricsHistorical(ctx context.Context, from time.Time, to time.Time, count uint) (*global_metrics.GlobalMetricsList, error) {
+	const funcName = "GetGlobalMetricsHistorical"
+	resp := &GlobalMetricsHistoricalResponse{}
+	requestId, options := c.getRequestOptions()
+
+	uri := URI_GetGlobalMetricsHistorical + "?time_start=" + strconv.FormatInt(from.Unix(), 10) + "&time_end=" + strconv.FormatInt(to.Unix(), 10) + "&count=" + strconv.FormatUint(uint64(count), 10) + "&interval=hourly&convert=USD"
+	fetchedAt := time.Now().UTC()
+
+	data, err := c.http.GetJSON(ctx, uri, resp, options...)
+	c.archive(ctx, URI_GetGlobalMetricsHistorical, 0, fetchedAt, data)
+	if err != nil {
+		c.logger.Error("http.GetJSON error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
+		return nil, fmt.Errorf(Name+"."+funcName+" %w; requestId: %s; uri: %s", err, requestId, uri)
+	}
+
+	return resp.Data.GlobalMetricsList(), nil
 }
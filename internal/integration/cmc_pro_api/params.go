@@ -1,11 +1,15 @@
 package cmc_pro_api
 
 import (
+	"fmt"
 	"info/internal/domain/concentration"
 	"info/internal/domain/currency"
+	"info/internal/domain/global_metrics"
 	"info/internal/domain/price_and_cap"
+	"info/internal/domain/universe"
 	"info/internal/pkg/apperror"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -17,9 +21,22 @@ type Status struct {
 	CreditCount  uint   `json:"credit_count"`
 }
 
+// Err returns the error of the response with an error status
+func (s *Status) Err() error {
+	if s.ErrorCode != 0 || (s.ErrorMessage != ErrorMessage_Success && s.ErrorMessage != "") {
+		return fmt.Errorf("[%w] response with error; code: %d; error message: %s", apperror.ErrInternal, s.ErrorCode, s.ErrorMessage)
+	}
+	return nil
+}
+
+// Credits returns the API credits spent by the request
+func (s *Status) Credits() uint {
+	return s.CreditCount
+}
+
 type GetCurrencyResponse struct {
 	Data   *CurrencyData `json:"data"`
-	Status Status        `json:"status"`
+	Status `json:"status"`
 }
 
 type CurrencyData struct {
@@ -40,7 +57,7 @@ func (e *CurrencyData) Currency() *currency.Currency {
 
 type DetailChartResponse struct {
 	Data   *DetailChartData `json:"data"`
-	Status Status           `json:"status"`
+	Status `json:"status"`
 }
 
 type DetailChartData struct {
@@ -71,6 +88,7 @@ func (e *DetailChartData) PriceAndCapList() (*price_and_cap.PriceAndCapList, err
 			DailyVolume: point.V[1],
 			Cap:         point.V[2],
 			Ts:          time.Unix(i, 0),
+			Source:      price_and_cap.Source_Cmc,
 		})
 	}
 
@@ -89,7 +107,7 @@ type DetailChartPoint struct {
 
 type GetAnalyticsResponse struct {
 	Data   *GetAnalyticsData `json:"data"`
-	Status Status            `json:"status"`
+	Status `json:"status"`
 }
 
 type GetAnalyticsData struct {
@@ -158,7 +176,7 @@ func (e *HistoricalConcentration) ConcentrationList(currencyID uint) (*concentra
 
 type CurrencyQuotesResponse struct {
 	Data   CurrencyQuoteMap `json:"data"`
-	Status Status           `json:"status"`
+	Status `json:"status"`
 }
 
 type CurrencyQuoteMap map[string]CurrencyQuote
@@ -195,6 +213,7 @@ type CurrencyQuote struct {
 	CmcRank                       uint                   `json:"cmc_rank"`
 	AddedAt                       time.Time              `json:"date_added"`
 	Platform                      *QuoteCurrencyPlatform `json:"platform"`
+	Tags                          []string               `json:"tags"`
 	Quote                         Quote                  `json:"quote"`
 }
 
@@ -220,10 +239,12 @@ func (e *QuoteCurrencyPlatform) CurrencyPlatform() *currency.CurrencyPlatform {
 }
 
 type Quote struct {
-	USD QuoteUSD
+	USD QuoteUSD `json:"USD"`
 }
 type QuoteUSD struct {
-	Price float64
+	Price     float64 `json:"price"`
+	Volume24h float64 `json:"volume_24h"`
+	MarketCap float64 `json:"market_cap"`
 }
 
 func (e *CurrencyQuote) Currency() *currency.Currency {
@@ -243,3 +264,177 @@ func (e *CurrencyQuote) Currency() *currency.Currency {
 		Platform:                      e.Platform.CurrencyPlatform(),
 	}
 }
+
+// Candidate returns the currency with the metrics used by the rules of the universe
+func (e *CurrencyQuote) Candidate() *universe.Candidate {
+	return &universe.Candidate{
+		Currency:  *e.Currency(),
+		MarketCap: e.Quote.USD.MarketCap,
+		Volume24h: e.Quote.USD.Volume24h,
+		Tags:      e.Tags,
+	}
+}
+
+type ListingsResponse struct {
+	Data   []CurrencyQuote `json:"data"`
+	Status `json:"status"`
+}
+
+func (r *ListingsResponse) CandidateList() *universe.CandidateList {
+	res := make(universe.CandidateList, 0, len(r.Data))
+	for i := range r.Data {
+		res = append(res, *r.Data[i].Candidate())
+	}
+	return &res
+}
+
+type InfoResponse struct {
+	Data   InfoMap `json:"data"`
+	Status `json:"status"`
+}
+
+type InfoMap map[string]Info
+
+type Info struct {
+	ID              uint              `json:"id"`
+	Symbol          string            `json:"symbol"`
+	Slug            string            `json:"slug"`
+	ContractAddress []ContractAddress `json:"contract_address"`
+}
+
+type ContractAddress struct {
+	ContractAddress string           `json:"contract_address"`
+	Platform        ContractPlatform `json:"platform"`
+}
+
+type ContractPlatform struct {
+	Name string       `json:"name"`
+	Coin PlatformCoin `json:"coin"`
+}
+
+type PlatformCoin struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+	Slug   string `json:"slug"`
+}
+
+// TokenAddressList returns every pair of the blockchain and the address of the currencies; the duplicates are skipped
+func (m InfoMap) TokenAddressList() (*currency.TokenAddressList, error) {
+	res := make(currency.TokenAddressList, 0, len(m))
+	isAdded := make(map[currency.TokenAddress]struct{}, len(m))
+	var ok bool
+	for k, v := range m {
+		id, err := strconv.ParseUint(k, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range v.ContractAddress {
+			address := strings.TrimSpace(item.ContractAddress)
+			blockchain := NormalizeBlockchain(item.Platform.Name, item.Platform.Coin.Slug)
+			if address == "" || blockchain == "" {
+				continue
+			}
+			tokenAddress := currency.TokenAddress{
+				CurrencyID: uint(id),
+				Blockchain: blockchain,
+				Address:    address,
+			}
+			if _, ok = isAdded[tokenAddress]; ok {
+				continue
+			}
+			isAdded[tokenAddress] = struct{}{}
+			res = append(res, tokenAddress)
+		}
+	}
+	return &res, nil
+}
+
+type GlobalMetricsLatestResponse struct {
+	Data   *GlobalMetricsLatest `json:"data"`
+	Status `json:"status"`
+}
+
+type GlobalMetricsLatest struct {
+	BtcDominance        float64            `json:"btc_dominance"`
+	EthDominance        float64            `json:"eth_dominance"`
+	StablecoinMarketCap *float64           `json:"stablecoin_market_cap"`
+	DefiMarketCap       *float64           `json:"defi_market_cap"`
+	LastUpdated         time.Time          `json:"last_updated"`
+	Quote               GlobalMetricsQuote `json:"quote"`
+}
+
+type GlobalMetricsQuote struct {
+	USD GlobalMetricsQuoteUSD `json:"USD"`
+}
+
+type GlobalMetricsQuoteUSD struct {
+	TotalMarketCap      float64   `json:"total_market_cap"`
+	TotalVolume24h      float64   `json:"total_volume_24h"`
+	StablecoinMarketCap *float64  `json:"stablecoin_market_cap"`
+	DefiMarketCap       *float64  `json:"defi_market_cap"`
+	Timestamp           time.Time `json:"timestamp"`
+}
+
+// GlobalMetrics returns the global metrics in USD; the caps of the quote are used when the data has no them
+func (e *GlobalMetricsLatest) GlobalMetrics() (*global_metrics.GlobalMetrics, error) {
+	if e == nil || e.LastUpdated.IsZero() {
+		return nil, apperror.ErrNotFound
+	}
+	res := &global_metrics.GlobalMetrics{
+		TotalMarketCap:      e.Quote.USD.TotalMarketCap,
+		TotalVolume24h:      e.Quote.USD.TotalVolume24h,
+		BtcDominance:        e.BtcDominance,
+		EthDominance:        e.EthDominance,
+		StablecoinMarketCap: e.StablecoinMarketCap,
+		DefiMarketCap:       e.DefiMarketCap,
+		Ts:                  e.LastUpdated,
+	}
+	if res.StablecoinMarketCap == nil {
+		res.StablecoinMarketCap = e.Quote.USD.StablecoinMarketCap
+	}
+	if res.DefiMarketCap == nil {
+		res.DefiMarketCap = e.Quote.USD.DefiMarketCap
+	}
+	return res, nil
+}
+
+type GlobalMetricsHistoricalResponse struct {
+	Data   *GlobalMetricsHistorical `json:"data"`
+	Status `json:"status"`
+}
+
+type GlobalMetricsHistorical struct {
+	Quotes []GlobalMetricsHistoricalQuote `json:"quotes"`
+}
+
+type GlobalMetricsHistoricalQuote struct {
+	Timestamp    time.Time          `json:"timestamp"`
+	BtcDominance float64            `json:"btc_dominance"`
+	EthDominance float64            `json:"eth_dominance"`
+	Quote        GlobalMetricsQuote `json:"quote"`
+}
+
+// GlobalMetricsList returns the points of the history; the points without the time are skipped
+func (e *GlobalMetricsHistorical) GlobalMetricsList() *global_metrics.GlobalMetricsList {
+	if e == nil {
+		return &global_metrics.GlobalMetricsList{}
+	}
+	res := make(global_metrics.GlobalMetricsList, 0, len(e.Quotes))
+	var item GlobalMetricsHistoricalQuote
+	for _, item = range e.Quotes {
+		if item.Timestamp.IsZero() {
+			continue
+		}
+		res = append(res, global_metrics.GlobalMetrics{
+			TotalMarketCap:      item.Quote.USD.TotalMarketCap,
+			TotalVolume24h:      item.Quote.USD.TotalVolume24h,
+			BtcDominance:        item.BtcDominance,
+			EthDominance:        item.EthDominance,
+			StablecoinMarketCap: item.Quote.USD.StablecoinMarketCap,
+			DefiMarketCap:       item.Quote.USD.DefiMarketCap,
+			Ts:                  item.Timestamp,
+		})
+	}
+	return &res
+}
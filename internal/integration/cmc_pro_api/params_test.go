@@ -0,0 +1,114 @@
+package cmc_pro_api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"info/internal/domain/currency"
+	"info/internal/domain/oracul_analytics"
+)
+
+func TestNormalizeBlockchain(t *testing.T) {
+	tests := []struct {
+		platformName string
+		coinSlug     string
+		want         string
+	}{
+		{platformName: "Ethereum", coinSlug: "ethereum", want: oracul_analytics.Blockchain_ETH},
+		{platformName: "BNB Smart Chain (BEP20)", coinSlug: "bnb", want: oracul_analytics.Blockchain_BNB},
+		{platformName: "Polygon", coinSlug: "polygon-ecosystem-token", want: oracul_analytics.Blockchain_POL},
+		{platformName: "Fantom", coinSlug: "fantom", want: oracul_analytics.Blockchain_FTM},
+		{platformName: " Optimism ", coinSlug: "optimism-ethereum", want: oracul_analytics.Blockchain_OP},
+		{platformName: "", coinSlug: "matic-network", want: oracul_analytics.Blockchain_POL},
+		// the coin of Arbitrum is ETH, but it is not Ethereum
+		{platformName: "Arbitrum", coinSlug: "ethereum", want: "ARBITRUM"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeBlockchain(tt.platformName, tt.coinSlug); got != tt.want {
+			t.Errorf("NormalizeBlockchain(%q, %q) = %q, want %q", tt.platformName, tt.coinSlug, got, tt.want)
+		}
+	}
+}
+
+func TestInfoMap_TokenAddressList(t *testing.T) {
+	data := `{"data":{"7083":{"id":7083,"symbol":"UNI","slug":"uniswap","contract_address":[
+		{"contract_address":"0x1f9840a85d5af5bf1d1762f925bdaddc4201f984","platform":{"name":"Ethereum","coin":{"id":"1027","name":"Ethereum","symbol":"ETH","slug":"ethereum"}}},
+		{"contract_address":"0xbf5140a22578168fd562dccf235e5d43a02ce9b1","platform":{"name":"BNB Smart Chain (BEP20)","coin":{"id":"1839","name":"BNB","symbol":"BNB","slug":"bnb"}}},
+		{"contract_address":"0x1f9840a85d5af5bf1d1762f925bdaddc4201f984","platform":{"name":"Ethereum","coin":{"id":"1027","name":"Ethereum","symbol":"ETH","slug":"ethereum"}}},
+		{"contract_address":"","platform":{"name":"Polygon","coin":{"id":"28321","name":"POL","symbol":"POL","slug":"polygon-ecosystem-token"}}}
+	]}},"status":{"error_code":0,"error_message":null}}`
+	resp := &InfoResponse{}
+	if err := json.Unmarshal([]byte(data), resp); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+
+	list, err := resp.Data.TokenAddressList()
+	if err != nil {
+		t.Fatalf("TokenAddressList error: %v", err)
+	}
+	want := currency.TokenAddressList{
+		{CurrencyID: 7083, Blockchain: oracul_analytics.Blockchain_ETH, Address: "0x1f9840a85d5af5bf1d1762f925bdaddc4201f984"},
+		{CurrencyID: 7083, Blockchain: oracul_analytics.Blockchain_BNB, Address: "0xbf5140a22578168fd562dccf235e5d43a02ce9b1"},
+	}
+	if len(*list) != len(want) {
+		t.Fatalf("got %+v, want %+v", *list, want)
+	}
+	for i := range want {
+		if (*list)[i] != want[i] {
+			t.Errorf("got %+v, want %+v", (*list)[i], want[i])
+		}
+	}
+}
+
+func TestGlobalMetricsLatest_GlobalMetrics(t *testing.T) {
+	data := `{"data":{"btc_dominance":54.1,"eth_dominance":17.2,"defi_market_cap":90000000000,"last_updated":"2025-01-02T10:15:00.000Z",
+		"quote":{"USD":{"total_market_cap":3400000000000,"total_volume_24h":120000000000,"stablecoin_market_cap":200000000000,"defi_market_cap":1}}},
+		"status":{"error_code":0,"error_message":null}}`
+	resp := &GlobalMetricsLatestResponse{}
+	if err := json.Unmarshal([]byte(data), resp); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+
+	res, err := resp.Data.GlobalMetrics()
+	if err != nil {
+		t.Fatalf("GlobalMetrics error: %v", err)
+	}
+	if res.TotalMarketCap != 3.4e12 || res.TotalVolume24h != 1.2e11 || res.BtcDominance != 54.1 || res.EthDominance != 17.2 {
+		t.Errorf("got %+v", *res)
+	}
+	// the stablecoin cap is only in the quote; the DeFi cap of the data is preferred
+	if res.StablecoinMarketCap == nil || *res.StablecoinMarketCap != 2e11 {
+		t.Errorf("StablecoinMarketCap: got %v", res.StablecoinMarketCap)
+	}
+	if res.DefiMarketCap == nil || *res.DefiMarketCap != 9e10 {
+		t.Errorf("DefiMarketCap: got %v", res.DefiMarketCap)
+	}
+	if res.Ts.Format(time.RFC3339) != "2025-01-02T10:15:00Z" {
+		t.Errorf("Ts: got %s", res.Ts)
+	}
+
+	if _, err = (&GlobalMetricsLatest{}).GlobalMetrics(); err == nil {
+		t.Error("an empty data must be an error")
+	}
+}
+
+func TestGlobalMetricsHistorical_GlobalMetricsList(t *testing.T) {
+	data := `{"data":{"quotes":[
+		{"timestamp":"2025-01-01T00:00:00.000Z","btc_dominance":55,"eth_dominance":18,"quote":{"USD":{"total_market_cap":3000000000000,"total_volume_24h":100000000000}}},
+		{"btc_dominance":1,"quote":{"USD":{"total_market_cap":1}}},
+		{"timestamp":"2025-01-01T01:00:00.000Z","btc_dominance":55.5,"eth_dominance":17.5,"quote":{"USD":{"total_market_cap":3100000000000,"total_volume_24h":110000000000}}}
+	]},"status":{"error_code":0,"error_message":null}}`
+	resp := &GlobalMetricsHistoricalResponse{}
+	if err := json.Unmarshal([]byte(data), resp); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+
+	list := *resp.Data.GlobalMetricsList()
+	if len(list) != 2 {
+		t.Fatalf("got %+v, want 2 points", list)
+	}
+	if list[1].TotalMarketCap != 3.1e12 || list[1].BtcDominance != 55.5 || list[1].StablecoinMarketCap != nil {
+		t.Errorf("got %+v", list[1])
+	}
+}
@@ -0,0 +1,143 @@
+package coingecko_api
+
+import (
+	"context"
+	"fmt"
+	"github.com/minipkg/httpclient"
+	prometheus_utils "github.com/minipkg/prometheus-utils"
+	uuid "github.com/satori/go.uuid"
+	"go.uber.org/zap"
+	"info/internal/domain/price_and_cap"
+	"info/internal/pkg/apperror"
+	"info/internal/pkg/log_key"
+	"info/internal/pkg/ratelimit"
+	"info/internal/pkg/resilient"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Archiver stores the raw responses, so they can be converted again without a request to the API
+type Archiver interface {
+	Archive(ctx context.Context, source string, endpoint string, currencyID uint, fetchedAt time.Time, data []byte) error
+}
+
+type AppConfig struct {
+	NameSpace string
+	Subsystem string
+	Service   string
+}
+
+type Config struct {
+	Httpconfig httpclient.Config
+	RateLimit  ratelimit.Config
+	Resilience resilient.Config
+	ApiKey     string      // the demo API key; empty - the public API without a key
+	IDs        []IDMapping // the currencies without a mapping are requested by the slug
+}
+
+// IDMapping maps the CoinMarketCap slug onto the CoinGecko coin ID when they differ, e.g. bnb -> binancecoin
+type IDMapping struct {
+	Slug string
+	ID   string
+}
+
+type CoingeckoApiClient struct {
+	config   *Config
+	http     *resilient.Client
+	coinIDs  map[string]string
+	archiver Archiver
+	logger   *zap.Logger
+}
+
+const (
+	Source                = "coingecko_api"
+	Name                  = "CoingeckoApiClient"
+	ContentType           = "application/json; charset=utf-8"
+	HeaderParam_RequestId = "X-Request-Id"
+	HeaderParam_ApiKey    = "x-cg-demo-api-key"
+
+	VsCurrency_Usd = "usd"
+	Interval_Daily = "daily"
+
+	URI_GetMarketChart string = "/api/v3/coins/{id}/market_chart"
+)
+
+func New(appConfig *AppConfig, conf *Config, logger *zap.Logger) *CoingeckoApiClient {
+	client := httpclient.New(conf.Httpconfig, prometheus_utils.NewHttpClientMetrics(appConfig.NameSpace, appConfig.Subsystem, appConfig.Service, conf.Httpconfig.Name).SetCuttingPathOpts(&prometheus_utils.CuttingPathOpts{IsNeedToRemoveQueryInPath: true}))
+	coinIDs := make(map[string]string, len(conf.IDs))
+	var item IDMapping
+	for _, item = range conf.IDs {
+		coinIDs[item.Slug] = item.ID
+	}
+	return &CoingeckoApiClient{
+		config:  conf,
+		http:    resilient.New(Name, conf.Resilience, client, ratelimit.New(conf.RateLimit), resilient.NewMetrics(appConfig.NameSpace, appConfig.Subsystem, appConfig.Service, conf.Httpconfig.Name)),
+		coinIDs: coinIDs,
+		logger:  logger,
+	}
+}
+
+// SetArchiver turns on the archive of the raw responses
+func (c *CoingeckoApiClient) SetArchiver(archiver Archiver) *CoingeckoApiClient {
+	c.archiver = archiver
+	return c
+}
+
+// archive stores the raw response if the archive is turned on; an error of the archive does not fail the request
+func (c *CoingeckoApiClient) archive(ctx context.Context, endpoint string, currencyID uint, fetchedAt time.Time, data []byte) {
+	if c.archiver == nil || data == nil {
+		return
+	}
+	if err := c.archiver.Archive(ctx, Source, endpoint, currencyID, fetchedAt, data); err != nil {
+		c.logger.Error("archiver.Archive error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Endpoint, endpoint), zap.Error(err))
+	}
+}
+
+func (c *CoingeckoApiClient) getDefaultRequestOptions() (requestId string, options []httpclient.RequestOption) {
+	requestId = uuid.NewV4().String()
+	options = []httpclient.RequestOption{
+		httpclient.WithContentType(ContentType),
+		httpclient.WithHeader(HeaderParam_RequestId, requestId),
+	}
+	if c.config.ApiKey != "" {
+		options = append(options, httpclient.WithHeader(HeaderParam_ApiKey, c.config.ApiKey))
+	}
+	return requestId, options
+}
+
+// CoinID returns the CoinGecko coin ID of the currency slug; mostly they are equal
+func (c *CoingeckoApiClient) CoinID(slug string) string {
+	if ID, ok := c.coinIDs[slug]; ok && ID != "" {
+		return ID
+	}
+	return slug
+}
+
+// GetMarketChart returns the daily prices, caps and volumes of the currency for the last days; the items are at 00:00 UTC except the last one, which is the current value
+func (c *CoingeckoApiClient) GetMarketChart(ctx context.Context, currencyID uint, slug string, days uint) (*price_and_cap.PriceAndCapList, error) {
+	if slug == "" || days == 0 {
+		return nil, fmt.Errorf("[%w] %s empty slug or days", apperror.ErrBadRequest, Name)
+	}
+
+	const funcName = "GetMarketChart"
+	resp := &MarketChartResponse{}
+	requestId, options := c.getDefaultRequestOptions()
+	uri := strings.Replace(URI_GetMarketChart, "{id}", c.CoinID(slug), 1) + "?vs_currency=" + VsCurrency_Usd + "&days=" + strconv.FormatUint(uint64(days), 10) + "&interval=" + Interval_Daily
+	fetchedAt := time.Now().UTC()
+
+	data, err := c.http.GetJSON(ctx, uri, resp, options...)
+	c.archive(ctx, URI_GetMarketChart, currencyID, fetchedAt, data)
+	if err != nil {
+		c.logger.Error("http.GetJSON error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
+		return nil, fmt.Errorf(Name+"."+funcName+" %w; requestId: %s; uri: %s", err, requestId, uri)
+	}
+
+	res, err := resp.PriceAndCapList(currencyID)
+	if err != nil {
+		c.logger.Error("error while convertation result", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
+		return nil, fmt.Errorf(funcName+" [%w] error while convertation result; requestId: %s; uri: %s; response: %s; error: %w;", apperror.ErrBadPayload, requestId, uri, string(data), err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,56 @@
+package coingecko_api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/minipkg/httpclient"
+	"go.uber.org/zap"
+
+	"info/internal/domain/price_and_cap"
+)
+
+func TestCoingeckoApiClient_GetMarketChart(t *testing.T) {
+	var gotPath, gotQuery, gotApiKey string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery, gotApiKey = r.URL.Path, r.URL.RawQuery, r.Header.Get(HeaderParam_ApiKey)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"prices":[[1735689600000,93000.5],[1735776000000,0],[1735862400000,96000]],"market_caps":[[1735689600000,1840000000000],[1735862400000,1900000000000]],"total_volumes":[[1735689600000,25000000000]]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	client := New(&AppConfig{NameSpace: "test", Subsystem: "coingecko_api", Service: "test"}, &Config{
+		Httpconfig: httpclient.Config{Name: "coingecko_api_test", Host: srv.URL, Timeout: time.Second},
+		ApiKey:     "key",
+		IDs:        []IDMapping{{Slug: "bnb", ID: "binancecoin"}},
+	}, zap.NewNop())
+
+	list, err := client.GetMarketChart(context.Background(), 1839, "bnb", 30)
+	if err != nil {
+		t.Fatalf("GetMarketChart error: %v", err)
+	}
+	if gotPath != "/api/v3/coins/binancecoin/market_chart" || gotQuery != "vs_currency=usd&days=30&interval=daily" || gotApiKey != "key" {
+		t.Errorf("unexpected request: path %s, query %s, api key %q", gotPath, gotQuery, gotApiKey)
+	}
+	// the point without the price is skipped
+	if len(*list) != 2 {
+		t.Fatalf("got %d items, want 2", len(*list))
+	}
+	first, last := (*list)[0], (*list)[1]
+	if first.CurrencyID != 1839 || first.Price != 93000.5 || first.Cap != 1840000000000 || first.DailyVolume != 25000000000 || first.Source != price_and_cap.Source_Coingecko {
+		t.Errorf("unexpected first item %+v", first)
+	}
+	if !first.Ts.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got ts %v of the first item", first.Ts)
+	}
+	if last.DailyVolume != 0 || last.Cap != 1900000000000 {
+		t.Errorf("unexpected last item %+v", last)
+	}
+
+	if client.CoinID("bitcoin") != "bitcoin" {
+		t.Errorf("the slug without a mapping has to be the coin ID")
+	}
+}
@@ -0,0 +1,57 @@
+package coingecko_api
+
+import (
+	"info/internal/domain/price_and_cap"
+	"info/internal/pkg/apperror"
+	"time"
+)
+
+// MarketChartPoint is [timestamp in ms, value]
+type MarketChartPoint [2]float64
+
+func (p MarketChartPoint) Time() time.Time {
+	return time.UnixMilli(int64(p[0])).UTC()
+}
+
+type MarketChartResponse struct {
+	Prices       []MarketChartPoint `json:"prices"`
+	MarketCaps   []MarketChartPoint `json:"market_caps"`
+	TotalVolumes []MarketChartPoint `json:"total_volumes"`
+}
+
+// PriceAndCapList joins the series by the time; a point without the price is skipped, without the cap or the volume - has 0
+func (e *MarketChartResponse) PriceAndCapList(currencyID uint) (*price_and_cap.PriceAndCapList, error) {
+	if e == nil || len(e.Prices) == 0 || currencyID == 0 {
+		return nil, apperror.ErrNotFound
+	}
+
+	caps := make(map[int64]float64, len(e.MarketCaps))
+	volumes := make(map[int64]float64, len(e.TotalVolumes))
+	var point MarketChartPoint
+	for _, point = range e.MarketCaps {
+		caps[int64(point[0])] = point[1]
+	}
+	for _, point = range e.TotalVolumes {
+		volumes[int64(point[0])] = point[1]
+	}
+
+	res := make(price_and_cap.PriceAndCapList, 0, len(e.Prices))
+	for _, point = range e.Prices {
+		if point[1] <= 0 {
+			continue
+		}
+		res = append(res, price_and_cap.PriceAndCap{
+			CurrencyID:  currencyID,
+			Price:       point[1],
+			DailyVolume: volumes[int64(point[0])],
+			Cap:         caps[int64(point[0])],
+			Ts:          point.Time(),
+			Source:      price_and_cap.Source_Coingecko,
+		})
+	}
+
+	if len(res) == 0 {
+		return nil, apperror.ErrNotFound
+	}
+	return &res, nil
+}
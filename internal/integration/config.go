@@ -1,8 +1,10 @@
 package integration
 
 import (
+	"info/internal/integration/binance_api"
 	"info/internal/integration/cmc_api"
 	"info/internal/integration/cmc_pro_api"
+	"info/internal/integration/coingecko_api"
 	"info/internal/integration/oracul_analytics_api"
 )
 
@@ -10,10 +12,16 @@ type Config struct {
 	CmcAPI             *cmc_api.Config
 	CmcProAPI          *cmc_pro_api.Config
 	OraculAnalyticsAPI *oracul_analytics_api.Config
+	BinanceAPI         *binance_api.Config
+	CoingeckoAPI       *coingecko_api.Config
+	// IsArchiveEnabled turns on the archive of the raw responses of all the clients
+	IsArchiveEnabled bool
 }
 
 type UsageConfig struct {
 	CmcAPI             bool
 	CmcProAPI          bool
 	OraculAnalyticsAPI bool
+	BinanceAPI         bool
+	CoingeckoAPI       bool
 }
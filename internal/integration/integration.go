@@ -1,13 +1,22 @@
 package integration
 
 import (
+	"context"
 	"errors"
 	"go.uber.org/zap"
+	"info/internal/integration/binance_api"
 	"info/internal/integration/cmc_api"
 	"info/internal/integration/cmc_pro_api"
+	"info/internal/integration/coingecko_api"
 	"info/internal/integration/oracul_analytics_api"
+	"time"
 )
 
+// Archiver stores the raw responses of the clients
+type Archiver interface {
+	Archive(ctx context.Context, source string, endpoint string, currencyID uint, fetchedAt time.Time, data []byte) error
+}
+
 type AppConfig struct {
 	NameSpace   string
 	Subsystem   string
@@ -19,6 +28,8 @@ type Integration struct {
 	CmcAPI             *cmc_api.CmcApiClient
 	CmcProAPI          *cmc_pro_api.CmcApiClient
 	OraculAnalyticsAPI *oracul_analytics_api.OraculAnalyticsAPIClient
+	BinanceAPI         *binance_api.BinanceApiClient
+	CoingeckoAPI       *coingecko_api.CoingeckoApiClient
 }
 
 func New(appConfig *AppConfig, cfg *Config, logger *zap.Logger) (*Integration, error) {
@@ -48,9 +59,44 @@ func New(appConfig *AppConfig, cfg *Config, logger *zap.Logger) (*Integration, e
 		}, cfg.OraculAnalyticsAPI, logger)
 	}
 
+	if cfg.BinanceAPI != nil {
+		integration.BinanceAPI = binance_api.New(&binance_api.AppConfig{
+			NameSpace: appConfig.NameSpace,
+			Subsystem: appConfig.Subsystem,
+			Service:   appConfig.Service,
+		}, cfg.BinanceAPI, logger)
+	}
+
+	if cfg.CoingeckoAPI != nil {
+		integration.CoingeckoAPI = coingecko_api.New(&coingecko_api.AppConfig{
+			NameSpace: appConfig.NameSpace,
+			Subsystem: appConfig.Subsystem,
+			Service:   appConfig.Service,
+		}, cfg.CoingeckoAPI, logger)
+	}
+
 	return integration, nil
 }
 
+// SetArchiver turns on the archive of the raw responses for all the clients
+func (intgr *Integration) SetArchiver(archiver Archiver) {
+	if intgr.CmcAPI != nil {
+		intgr.CmcAPI.SetArchiver(archiver)
+	}
+	if intgr.CmcProAPI != nil {
+		intgr.CmcProAPI.SetArchiver(archiver)
+	}
+	if intgr.OraculAnalyticsAPI != nil {
+		intgr.OraculAnalyticsAPI.SetArchiver(archiver)
+	}
+	if intgr.BinanceAPI != nil {
+		intgr.BinanceAPI.SetArchiver(archiver)
+	}
+	if intgr.CoingeckoAPI != nil {
+		intgr.CoingeckoAPI.SetArchiver(archiver)
+	}
+}
+
 func (intgr *Integration) Close() error {
 	return errors.Join()
 }
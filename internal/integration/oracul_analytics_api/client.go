@@ -2,7 +2,6 @@ package oracul_analytics_api
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/minipkg/httpclient"
 	prometheus_utils "github.com/minipkg/prometheus-utils"
@@ -11,13 +10,15 @@ import (
 	"info/internal/domain/oracul_analytics"
 	"info/internal/pkg/apperror"
 	"info/internal/pkg/log_key"
+	"info/internal/pkg/ratelimit"
+	"info/internal/pkg/resilient"
 	"strconv"
 	"time"
 )
 
-type httpClient interface {
-	Get(ctx context.Context, path string, opts ...httpclient.RequestOption) ([]byte, int, error)
-	Post(ctx context.Context, path string, reqObj interface{}, opts ...httpclient.RequestOption) ([]byte, int, error)
+// Archiver stores the raw responses, so they can be converted again without a request to the API
+type Archiver interface {
+	Archive(ctx context.Context, source string, endpoint string, currencyID uint, fetchedAt time.Time, data []byte) error
 }
 
 type AppConfig struct {
@@ -28,15 +29,20 @@ type AppConfig struct {
 
 type Config struct {
 	Httpconfig httpclient.Config
+	RateLimit  ratelimit.Config
+	Resilience resilient.Config
 }
 
 type OraculAnalyticsAPIClient struct {
-	config     *Config
-	httpClient httpClient
-	logger     *zap.Logger
+	config   *Config
+	http     *resilient.Client
+	limiter  *ratelimit.Limiter
+	archiver Archiver
+	logger   *zap.Logger
 }
 
 const (
+	Source                = "oracul_analytics_api"
 	Name                  = "OraculAnalyticsAPI"
 	ContentType           = "application/json; charset=utf-8"
 	HeaderParam_RequestId = "X-Request-Id"
@@ -49,10 +55,28 @@ var _ oracul_analytics.OraculAnalyticsAPIClient = (*OraculAnalyticsAPIClient)(ni
 
 func New(appConfig *AppConfig, conf *Config, logger *zap.Logger) *OraculAnalyticsAPIClient {
 	client := httpclient.New(conf.Httpconfig, prometheus_utils.NewHttpClientMetrics(appConfig.NameSpace, appConfig.Subsystem, appConfig.Service, conf.Httpconfig.Name).SetCuttingPathOpts(&prometheus_utils.CuttingPathOpts{IsNeedToRemoveQueryInPath: true}))
+	limiter := ratelimit.New(conf.RateLimit)
 	return &OraculAnalyticsAPIClient{
-		config:     conf,
-		httpClient: client,
-		logger:     logger,
+		config:  conf,
+		http:    resilient.New(Name, conf.Resilience, client, limiter, resilient.NewMetrics(appConfig.NameSpace, appConfig.Subsystem, appConfig.Service, conf.Httpconfig.Name)),
+		limiter: limiter,
+		logger:  logger,
+	}
+}
+
+// SetArchiver turns on the archive of the raw responses
+func (c *OraculAnalyticsAPIClient) SetArchiver(archiver Archiver) *OraculAnalyticsAPIClient {
+	c.archiver = archiver
+	return c
+}
+
+// archive stores the raw response if the archive is turned on; an error of the archive does not fail the request
+func (c *OraculAnalyticsAPIClient) archive(ctx context.Context, endpoint string, currencyID uint, fetchedAt time.Time, data []byte) {
+	if c.archiver == nil || data == nil {
+		return
+	}
+	if err := c.archiver.Archive(ctx, Source, endpoint, currencyID, fetchedAt, data); err != nil {
+		c.logger.Error("archiver.Archive error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Endpoint, endpoint), zap.Error(err))
 	}
 }
 
@@ -64,7 +88,8 @@ func (c *OraculAnalyticsAPIClient) getDefaultRequestOptions() (requestId string,
 	}
 }
 
-func (c *OraculAnalyticsAPIClient) GetHoldersStats(ctx context.Context, currencyID uint, blockchain string, coinAddress string) (*oracul_analytics.ImportData, error) {
+// GetHoldersStats requests the holders stats with a daily candle for every day of the window from startAt to endAt
+func (c *OraculAnalyticsAPIClient) GetHoldersStats(ctx context.Context, currencyID uint, blockchain string, coinAddress string, startAt time.Time, endAt time.Time) (*oracul_analytics.ImportData, error) {
 	if blockchain == "" || coinAddress == "" {
 		return nil, apperror.ErrNotFound
 	}
@@ -74,28 +99,29 @@ func (c *OraculAnalyticsAPIClient) GetHoldersStats(ctx context.Context, currency
 	requestId, options := c.getDefaultRequestOptions()
 
 	ts := time.Now().UTC()
-	uri := URI_GetHoldersStats + "?coin_address=" + coinAddress + "&blockchain=" + blockchain + "&start_at=" + ts.Add(-1*time.Hour*24*365).Format(time.DateOnly) + "&end_at=" + ts.Format(time.DateOnly) + "&total_candles=27"
+	uri := URI_GetHoldersStats + "?coin_address=" + coinAddress + "&blockchain=" + blockchain + "&start_at=" + startAt.Format(time.DateOnly) + "&end_at=" + endAt.Format(time.DateOnly) + "&total_candles=" + strconv.Itoa(totalCandles(startAt, endAt))
 
-	data, code, err := c.httpClient.Get(ctx, uri, options...)
+	data, err := c.http.GetJSON(ctx, uri, resp, options...)
+	c.archive(ctx, URI_GetHoldersStats, currencyID, ts, data)
 	if err != nil {
-		c.logger.Error("httpClient.Get error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
-		return nil, fmt.Errorf(Name+"."+funcName+" [%w] http error: %s; requestId: %s; uri: %s", apperror.ErrInternal, err.Error(), requestId, uri)
-	}
-	if code != 200 {
-		c.logger.Error("httpClient.Get error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err), zap.Int(log_key.Code, code))
-		return nil, fmt.Errorf(funcName+" [%w] http response error code: "+strconv.Itoa(code)+"; requestId: %s; uri: %s; response: %s", apperror.ErrInternal, requestId, uri, string(data))
-	}
-
-	if err = json.Unmarshal(data, resp); err != nil {
-		c.logger.Error("json.Unmarshal error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
-		return nil, fmt.Errorf(funcName+" [%w] json.Unmarshal error: %s; requestId: %s; uri: %s; response: %s", apperror.ErrInternal, err.Error(), requestId, uri, string(data))
+		c.logger.Error("http.GetJSON error", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
+		return nil, fmt.Errorf(Name+"."+funcName+" %w; requestId: %s; uri: %s", err, requestId, uri)
 	}
 
 	res, err := resp.ImportData(currencyID, ts)
 	if err != nil {
 		c.logger.Error("error while convertation result", zap.String(log_key.ApiClient, Name), zap.String(log_key.Func, funcName), zap.Error(err))
-		return nil, fmt.Errorf(funcName+" [%w] error while convertation result; requestId: %s; uri: %s; response: %s; error: %w;", apperror.ErrInternal, requestId, uri, string(data), err)
+		return nil, fmt.Errorf(funcName+" [%w] error while convertation result; requestId: %s; uri: %s; response: %s; error: %w;", apperror.ErrBadPayload, requestId, uri, string(data), err)
 	}
 
 	return res, nil
 }
+
+// totalCandles is the number of days in the window including both ends
+func totalCandles(startAt time.Time, endAt time.Time) int {
+	days := int(endAt.Sub(startAt).Hours()/24) + 1
+	if days < 1 {
+		return 1
+	}
+	return days
+}
@@ -1,10 +1,13 @@
 package oracul_analytics_api
 
 import (
+	"encoding/json"
+	"fmt"
 	"info/internal/domain/oracul_analytics"
 	"info/internal/domain/oracul_daily_balance_stats"
 	"info/internal/domain/oracul_holder_stats"
 	"info/internal/domain/oracul_speedometers"
+	"info/internal/pkg/apperror"
 	"strconv"
 	"time"
 )
@@ -18,6 +21,15 @@ type GetHoldersStatsResponse struct {
 	DailyBalanceStats   DailyBalanceStatsList `json:"daily_balance_stats"`
 }
 
+// ParseHoldersStats converts the raw response of URI_GetHoldersStats fetched at the time ts; it is used to convert the archived responses again
+func ParseHoldersStats(data []byte, currencyID uint, ts time.Time) (*oracul_analytics.ImportData, error) {
+	resp := &GetHoldersStatsResponse{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return nil, fmt.Errorf("[%w] json.Unmarshal error: %w", apperror.ErrInternal, err)
+	}
+	return resp.ImportData(currencyID, ts)
+}
+
 func (r *GetHoldersStatsResponse) ImportData(currencyID uint, ts time.Time) (res *oracul_analytics.ImportData, err error) {
 
 	res = &oracul_analytics.ImportData{}
@@ -16,6 +16,10 @@ const (
 	ErrAlreadyExists Error = "Already exists"
 	ErrInternal      Error = "Internal server error"
 	ErrData          Error = "Data error"
+	ErrLocked        Error = "Locked"
+	ErrRateLimited   Error = "Rate limited"
+	ErrUpstreamDown  Error = "Upstream down"
+	ErrBadPayload    Error = "Bad payload"
 )
 
 func NewError(msg string) *Error {
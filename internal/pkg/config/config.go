@@ -3,12 +3,17 @@ package config
 import (
 	"flag"
 	"fmt"
+	"info/internal/domain/market_pair"
+	"info/internal/domain/price_and_cap"
+	"info/internal/domain/price_divergence"
+	"info/internal/domain/universe"
 	"info/internal/integration"
 	"time"
 
 	"github.com/spf13/viper"
 
 	"info/internal/infrastructure"
+	"info/internal/pkg/workerpool"
 )
 
 const (
@@ -43,6 +48,15 @@ type Configuration struct {
 	Cli         *CliConfig
 	Integration *integration.Config
 	Infra       *infrastructure.Config
+	WorkerPool  workerpool.Config
+	Domain      DomainConfig
+}
+
+type DomainConfig struct {
+	PriceAndCap     price_and_cap.Config
+	PriceDivergence price_divergence.Config
+	Universe        universe.Config
+	MarketPair      market_pair.Config
 }
 
 type API struct {
@@ -60,12 +74,35 @@ type RestAPIConfig struct {
 
 type CliConfig struct {
 	CurrencyCollector *CurrencyCollector
+	OraculCollector   *OraculCollector
 }
 
 type CurrencyCollector struct {
 	Duration            time.Duration
 	PortfolioSourceIDs  []string
 	ListOfCurrencySlugs []string
+	IsObservedUniverse  bool // the import stages use the observed currencies of the universe too
+	Schedule            *CurrencyCollectorSchedule
+	LockTTL             time.Duration // TTL of the stage locks; 0 - the default
+}
+
+// CurrencyCollectorSchedule is a per stage schedule for the daemon mode; a zero value means the common Duration
+type CurrencyCollectorSchedule struct {
+	Currency      time.Duration
+	PriceAndCap   time.Duration
+	Concentration time.Duration
+	Portfolio     time.Duration
+	Oracul        time.Duration
+	TokenAddress  time.Duration
+	Reconcile     time.Duration // 0 - the reconciliation with the reference source is disabled
+	Discovery     time.Duration // 0 - the discovery of the universe is disabled
+	MarketPairs   time.Duration // 0 - the import of the market pairs is disabled
+	GlobalMetrics time.Duration // 0 - the import of the global metrics is disabled
+}
+
+type OraculCollector struct {
+	ListOfCurrencySlugs []string      // empty - all the observed currencies
+	LockTTL             time.Duration // TTL of the stage lock; 0 - the default
 }
 
 // Get func return the app config
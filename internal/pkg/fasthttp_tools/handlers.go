@@ -7,6 +7,7 @@ import (
 	"github.com/valyala/fasthttp"
 	"info/internal/pkg/apperror"
 	"strconv"
+	"time"
 )
 
 const (
@@ -30,6 +31,20 @@ func ParseQueryArgUint(ctx *fasthttp.RequestCtx, name string) (uint, error) {
 	return uint(val), nil
 }
 
+func ParseQueryArgFloat(ctx *fasthttp.RequestCtx, name string) (float64, error) {
+	valStr, err := ParseQueryArgString(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+
+	val, err := strconv.ParseFloat(valStr, 64)
+	if err != nil {
+		return 0, fmt.Errorf("[%w] failed to parse float param %s; error: %w", apperror.ErrBadRequest, name, err)
+	}
+
+	return val, nil
+}
+
 func ParseQueryArgString(ctx *fasthttp.RequestCtx, name string) (string, error) {
 	val := string(ctx.QueryArgs().Peek(name))
 	if val == "" {
@@ -39,6 +54,21 @@ func ParseQueryArgString(ctx *fasthttp.RequestCtx, name string) (string, error)
 	return val, nil
 }
 
+// ParseQueryArgDate parses the date YYYY-MM-DD
+func ParseQueryArgDate(ctx *fasthttp.RequestCtx, name string) (time.Time, error) {
+	valStr, err := ParseQueryArgString(ctx, name)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	val, err := time.Parse(time.DateOnly, valStr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("[%w] failed to parse date param %s; error: %w", apperror.ErrBadRequest, name, err)
+	}
+
+	return val, nil
+}
+
 func BadRequest(ctx *fasthttp.RequestCtx, err error) {
 	ctx.SetStatusCode(fasthttp.StatusBadRequest)
 	resp := errorResp{
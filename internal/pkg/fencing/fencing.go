@@ -0,0 +1,20 @@
+package fencing
+
+import (
+	"context"
+)
+
+type tokenCtxKey struct {
+	name string
+}
+
+// WithToken returns the context carrying the fencing token of the named lock
+func WithToken(ctx context.Context, name string, token uint64) context.Context {
+	return context.WithValue(ctx, tokenCtxKey{name: name}, token)
+}
+
+// Token returns the fencing token of the named lock carried by the context; 0 - the context carries no token, the writes are not fenced
+func Token(ctx context.Context, name string) uint64 {
+	token, _ := ctx.Value(tokenCtxKey{name: name}).(uint64)
+	return token
+}
@@ -0,0 +1,21 @@
+package fencing
+
+import (
+	"context"
+	"testing"
+)
+
+func TestToken(t *testing.T) {
+	ctx := WithToken(context.Background(), "price_and_cap", 7)
+	ctx = WithToken(ctx, "concentration", 3)
+
+	if token := Token(ctx, "price_and_cap"); token != 7 {
+		t.Errorf("Token(price_and_cap) = %d, want 7", token)
+	}
+	if token := Token(ctx, "concentration"); token != 3 {
+		t.Errorf("Token(concentration) = %d, want 3", token)
+	}
+	if token := Token(ctx, "currency"); token != 0 {
+		t.Errorf("Token(currency) = %d, want 0", token)
+	}
+}
@@ -4,6 +4,7 @@ const (
 	ApiClient       = "apiClient"
 	Func            = "func"
 	Code            = "code"
+	Endpoint        = "endpoint"
 	ErrorCode       = "errorCode"
 	ErrorMessage    = "errorMessage"
 	ErrorStacktrace = "errorStacktrace"
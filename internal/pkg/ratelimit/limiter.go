@@ -0,0 +1,205 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultRetryAfter = time.Minute
+)
+
+type creditCounterCtxKey struct{}
+
+var retryAfterRegexp = regexp.MustCompile(`(?i)Retry-After:\s*([^\r\n]+)`)
+
+type Config struct {
+	RequestsPerMinute uint          // 0 - without limit
+	Burst             uint          // 0 - 1
+	MaxRetries        uint          // retries of a request answered with 429
+	DefaultRetryAfter time.Duration // pause after 429 without the Retry-After header
+}
+
+// CreditCounter counts the API credits spent by the requests made with the context carrying the counter, so the concurrent callers are counted apart
+type CreditCounter struct {
+	count atomic.Uint64
+}
+
+// WithCreditCounter returns the context carrying the counter
+func WithCreditCounter(ctx context.Context, counter *CreditCounter) context.Context {
+	return context.WithValue(ctx, creditCounterCtxKey{}, counter)
+}
+
+func creditCounterFromContext(ctx context.Context) *CreditCounter {
+	counter, _ := ctx.Value(creditCounterCtxKey{}).(*CreditCounter)
+	return counter
+}
+
+// Count returns the counted API credits
+func (c *CreditCounter) Count() uint64 {
+	return c.count.Load()
+}
+
+// Limiter is a token bucket with a budget of requests per minute.
+// After 429 it pauses all the requests for the time from Retry-After.
+type Limiter struct {
+	mu                sync.Mutex
+	interval          time.Duration
+	burst             float64
+	tokens            float64
+	last              time.Time
+	pausedUntil       time.Time
+	maxRetries        uint
+	defaultRetryAfter time.Duration
+	creditCount       atomic.Uint64
+}
+
+func New(cfg Config) *Limiter {
+	l := &Limiter{
+		burst:             float64(cfg.Burst),
+		maxRetries:        cfg.MaxRetries,
+		defaultRetryAfter: cfg.DefaultRetryAfter,
+	}
+	if l.burst < 1 {
+		l.burst = 1
+	}
+	if l.defaultRetryAfter == 0 {
+		l.defaultRetryAfter = defaultRetryAfter
+	}
+	if cfg.RequestsPerMinute > 0 {
+		l.interval = time.Minute / time.Duration(cfg.RequestsPerMinute)
+	}
+	l.tokens = l.burst
+	return l
+}
+
+// Wait blocks until a request is allowed or the context is done
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		wait := l.reserve(time.Now())
+		if wait <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (l *Limiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+	if l.interval == 0 {
+		return 0
+	}
+
+	if !l.last.IsZero() {
+		l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) * float64(l.interval))
+}
+
+// Pause stops all the requests for the duration
+func (l *Limiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	until := time.Now().Add(d)
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	l.tokens = 0
+}
+
+// AddCredits records the API credits spent by a request; the credits are also added to the counter of the context, if any
+func (l *Limiter) AddCredits(ctx context.Context, n uint) {
+	l.creditCount.Add(uint64(n))
+	if counter := creditCounterFromContext(ctx); counter != nil {
+		counter.count.Add(uint64(n))
+	}
+}
+
+// CreditCount returns the API credits spent since the start
+func (l *Limiter) CreditCount() uint64 {
+	return l.creditCount.Load()
+}
+
+// Do waits for the budget and executes the request; a request answered with 429 is repeated after the Retry-After pause up to MaxRetries times
+func (l *Limiter) Do(ctx context.Context, request func() ([]byte, int, error)) (data []byte, code int, err error) {
+	var attempt uint
+	for {
+		if err = l.Wait(ctx); err != nil {
+			return nil, 0, err
+		}
+
+		data, code, err = request()
+		if code != http.StatusTooManyRequests {
+			return data, code, err
+		}
+
+		l.Pause(l.retryAfter(err))
+		if attempt >= l.maxRetries {
+			return data, code, err
+		}
+		attempt++
+	}
+}
+
+// retryAfter gets the pause from the Retry-After header, which httpclient puts into the error text together with the whole response
+func (l *Limiter) retryAfter(err error) time.Duration {
+	if err == nil {
+		return l.defaultRetryAfter
+	}
+	if d, ok := ParseRetryAfter(err.Error()); ok {
+		return d
+	}
+	return l.defaultRetryAfter
+}
+
+// ParseRetryAfter finds the Retry-After header in the raw response and parses it as seconds or as a HTTP-date
+func ParseRetryAfter(rawResponse string) (time.Duration, bool) {
+	m := retryAfterRegexp.FindStringSubmatch(rawResponse)
+	if len(m) < 2 {
+		return 0, false
+	}
+
+	val := strings.TrimSpace(m[1])
+
+	if sec, err := strconv.ParseUint(val, 10, 64); err == nil {
+		return time.Duration(sec) * time.Second, true
+	}
+
+	t, err := http.ParseTime(val)
+	if err != nil {
+		return 0, false
+	}
+	d := time.Until(t)
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
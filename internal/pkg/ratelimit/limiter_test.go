@@ -0,0 +1,157 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLimiter_BurstAndRefill(t *testing.T) {
+	l := New(Config{RequestsPerMinute: 60, Burst: 3})
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		if wait := l.reserve(now); wait != 0 {
+			t.Fatalf("request %d of the burst: got wait %v", i, wait)
+		}
+	}
+	if wait := l.reserve(now); wait != time.Second {
+		t.Fatalf("request over the burst: got wait %v, want %v", wait, time.Second)
+	}
+
+	// 2.5 tokens are refilled in 2.5s
+	now = now.Add(2500 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if wait := l.reserve(now); wait != 0 {
+			t.Fatalf("request %d after the refill: got wait %v", i, wait)
+		}
+	}
+	if wait := l.reserve(now); wait != 500*time.Millisecond {
+		t.Fatalf("request over the refill: got wait %v, want %v", wait, 500*time.Millisecond)
+	}
+
+	// the bucket is not refilled over the burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if wait := l.reserve(now); wait != 0 {
+			t.Fatalf("request %d after the long pause: got wait %v", i, wait)
+		}
+	}
+	if wait := l.reserve(now); wait == 0 {
+		t.Fatal("request over the burst after the long pause is allowed")
+	}
+}
+
+func TestLimiter_WithoutLimit(t *testing.T) {
+	l := New(Config{})
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		if wait := l.reserve(now); wait != 0 {
+			t.Fatalf("request %d: got wait %v", i, wait)
+		}
+	}
+}
+
+func TestLimiter_WaitCancelled(t *testing.T) {
+	l := New(Config{RequestsPerMinute: 1})
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("first Wait() error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Wait() returned after %v, it must return on the cancel of the context", d)
+	}
+}
+
+func TestLimiter_Pause(t *testing.T) {
+	l := New(Config{})
+	l.Pause(time.Minute)
+	now := time.Now()
+	if wait := l.reserve(now); wait < 59*time.Second || wait > time.Minute {
+		t.Fatalf("request during the pause: got wait %v", wait)
+	}
+	// a shorter pause does not shorten the current one
+	l.Pause(time.Second)
+	if wait := l.reserve(now); wait < 59*time.Second {
+		t.Fatalf("request after the shorter pause: got wait %v", wait)
+	}
+}
+
+func TestLimiter_Do(t *testing.T) {
+	tooManyRequests := errors.New("http request failed with code 429; response: HTTP/1.1 429 Too Many Requests\r\nRetry-After: 0\r\n")
+
+	tests := []struct {
+		name       string
+		maxRetries uint
+		codes      []int
+		wantCalls  int
+		wantCode   int
+	}{
+		{name: "success", codes: []int{http.StatusOK}, wantCalls: 1, wantCode: http.StatusOK},
+		{name: "retried after 429", maxRetries: 2, codes: []int{http.StatusTooManyRequests, http.StatusOK}, wantCalls: 2, wantCode: http.StatusOK},
+		{name: "out of retries", maxRetries: 1, codes: []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusOK}, wantCalls: 2, wantCode: http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(Config{MaxRetries: tt.maxRetries})
+			var calls int
+			_, code, _ := l.Do(context.Background(), func() ([]byte, int, error) {
+				code := tt.codes[calls]
+				calls++
+				if code == http.StatusTooManyRequests {
+					return nil, code, tooManyRequests
+				}
+				return []byte("ok"), code, nil
+			})
+			if calls != tt.wantCalls || code != tt.wantCode {
+				t.Fatalf("got %d calls with the code %d, want %d calls with the code %d", calls, code, tt.wantCalls, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	inFuture := time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat)
+	inPast := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+
+	tests := []struct {
+		name    string
+		raw     string
+		wantMin time.Duration
+		wantMax time.Duration
+		wantOk  bool
+	}{
+		{name: "seconds", raw: "HTTP/1.1 429 Too Many Requests\r\nRetry-After: 120\r\n\r\n", wantMin: 2 * time.Minute, wantMax: 2 * time.Minute, wantOk: true},
+		{name: "case insensitive", raw: "retry-after:7", wantMin: 7 * time.Second, wantMax: 7 * time.Second, wantOk: true},
+		{name: "http-date", raw: "Retry-After: " + inFuture + "\r\n", wantMin: 28 * time.Second, wantMax: 30 * time.Second, wantOk: true},
+		{name: "http-date in the past", raw: "Retry-After: " + inPast + "\r\n", wantOk: true},
+		{name: "without the header", raw: "HTTP/1.1 429 Too Many Requests\r\n\r\n"},
+		{name: "malformed", raw: "Retry-After: soon\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, ok := ParseRetryAfter(tt.raw)
+			if ok != tt.wantOk || d < tt.wantMin || d > tt.wantMax {
+				t.Fatalf("got %v, %v; want [%v, %v], %v", d, ok, tt.wantMin, tt.wantMax, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestLimiter_AddCredits(t *testing.T) {
+	l := New(Config{})
+	counter := &CreditCounter{}
+	l.AddCredits(WithCreditCounter(context.Background(), counter), 3)
+	l.AddCredits(context.Background(), 2)
+	if l.CreditCount() != 5 || counter.Count() != 3 {
+		t.Fatalf("got %d credits of the limiter and %d of the counter, want 5 and 3", l.CreditCount(), counter.Count())
+	}
+}
@@ -0,0 +1,138 @@
+package resilient
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"info/internal/pkg/apperror"
+)
+
+type BreakerState int
+
+const (
+	BreakerState_Closed BreakerState = iota
+	BreakerState_HalfOpen
+	BreakerState_Open
+
+	defaultFailureThreshold = 5
+	defaultOpenTimeout      = 30 * time.Second
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerState_HalfOpen:
+		return "half_open"
+	case BreakerState_Open:
+		return "open"
+	}
+	return "closed"
+}
+
+var BreakerStateList = []BreakerState{
+	BreakerState_Closed,
+	BreakerState_HalfOpen,
+	BreakerState_Open,
+}
+
+type BreakerConfig struct {
+	FailureThreshold uint          // consecutive failures which open the breaker; 0 - 5
+	OpenTimeout      time.Duration // time in the open state before a probe request; 0 - 30s
+}
+
+// Breaker is a circuit breaker of an upstream. After FailureThreshold consecutive failures it rejects the requests for OpenTimeout,
+// then lets one probe request through: its success closes the breaker, its failure opens it again.
+type Breaker struct {
+	mu               sync.Mutex
+	failureThreshold uint
+	openTimeout      time.Duration
+	state            BreakerState
+	failures         uint
+	openedAt         time.Time
+	isProbing        bool
+	onChange         func(state BreakerState)
+	now              func() time.Time
+}
+
+func NewBreaker(cfg BreakerConfig, onChange func(state BreakerState)) *Breaker {
+	b := &Breaker{
+		failureThreshold: cfg.FailureThreshold,
+		openTimeout:      cfg.OpenTimeout,
+		onChange:         onChange,
+		now:              time.Now,
+	}
+	if b.failureThreshold == 0 {
+		b.failureThreshold = defaultFailureThreshold
+	}
+	if b.openTimeout == 0 {
+		b.openTimeout = defaultOpenTimeout
+	}
+	return b
+}
+
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow returns apperror.ErrUpstreamDown if the request is rejected by the breaker
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerState_Open:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return fmt.Errorf("[%w] circuit breaker is open", apperror.ErrUpstreamDown)
+		}
+		b.setState(BreakerState_HalfOpen)
+		b.isProbing = true
+		return nil
+	case BreakerState_HalfOpen:
+		if b.isProbing {
+			return fmt.Errorf("[%w] circuit breaker is half-open, the probe request is in progress", apperror.ErrUpstreamDown)
+		}
+		b.isProbing = true
+	}
+	return nil
+}
+
+// Success records the request which reached the upstream
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.isProbing = false
+	if b.state != BreakerState_Closed {
+		b.setState(BreakerState_Closed)
+	}
+}
+
+// Failure records the request which failed because of the upstream
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.isProbing = false
+	if b.state == BreakerState_HalfOpen || (b.state == BreakerState_Closed && b.failures >= b.failureThreshold) {
+		b.openedAt = b.now()
+		b.setState(BreakerState_Open)
+	}
+}
+
+// Cancel records the request which was cancelled before the result, so it neither closes nor opens the breaker
+func (b *Breaker) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.isProbing = false
+}
+
+func (b *Breaker) setState(state BreakerState) {
+	b.state = state
+	if b.onChange != nil {
+		b.onChange(state)
+	}
+}
@@ -0,0 +1,232 @@
+package resilient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/minipkg/httpclient"
+
+	"info/internal/pkg/apperror"
+	"info/internal/pkg/ratelimit"
+)
+
+const (
+	defaultRetryBaseDelay = 200 * time.Millisecond
+	defaultRetryMaxDelay  = 5 * time.Second
+)
+
+// HttpClient is the client of minipkg/httpclient
+type HttpClient interface {
+	Get(ctx context.Context, path string, opts ...httpclient.RequestOption) ([]byte, int, error)
+	Post(ctx context.Context, path string, reqObj interface{}, opts ...httpclient.RequestOption) ([]byte, int, error)
+}
+
+// StatusResponse is a response which reports the result and the spent API credits in its status field
+type StatusResponse interface {
+	Err() error
+	Credits() uint
+}
+
+type Config struct {
+	MaxRetries     uint          // retries of a GET failed because of the upstream; 0 - without retries
+	RetryBaseDelay time.Duration // delay before the first retry, it doubles with every retry; 0 - 200ms
+	RetryMaxDelay  time.Duration // 0 - 5s
+	RequestTimeout time.Duration // timeout of every attempt; 0 - the timeout of the http client
+	Breaker        BreakerConfig
+}
+
+// Client decorates the http client of an upstream with the rate limiter, the circuit breaker, the timeouts of the requests
+// and the jittered exponential retries of the idempotent GETs. The errors are typed:
+// apperror.ErrRateLimited for 429, apperror.ErrUpstreamDown for the network errors, the timeouts, 5xx and the open breaker,
+// apperror.ErrBadPayload for a response which can not be decoded.
+type Client struct {
+	name           string
+	client         HttpClient
+	limiter        *ratelimit.Limiter
+	breaker        *Breaker
+	metrics        Metrics
+	maxRetries     uint
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
+	requestTimeout time.Duration
+}
+
+func New(name string, cfg Config, client HttpClient, limiter *ratelimit.Limiter, metrics Metrics) *Client {
+	c := &Client{
+		name:           name,
+		client:         client,
+		limiter:        limiter,
+		metrics:        metrics,
+		maxRetries:     cfg.MaxRetries,
+		retryBaseDelay: cfg.RetryBaseDelay,
+		retryMaxDelay:  cfg.RetryMaxDelay,
+		requestTimeout: cfg.RequestTimeout,
+	}
+	if c.retryBaseDelay == 0 {
+		c.retryBaseDelay = defaultRetryBaseDelay
+	}
+	if c.retryMaxDelay == 0 {
+		c.retryMaxDelay = defaultRetryMaxDelay
+	}
+	c.breaker = NewBreaker(cfg.Breaker, func(state BreakerState) {
+		if c.metrics != nil {
+			c.metrics.SetBreakerState(state)
+			c.metrics.Inc(state.String())
+		}
+	})
+	return c
+}
+
+func (c *Client) BreakerState() BreakerState {
+	return c.breaker.State()
+}
+
+// Get requests the path with the retries; it returns the body of a response with the status 200
+func (c *Client) Get(ctx context.Context, path string, opts ...httpclient.RequestOption) ([]byte, error) {
+	var err error
+	var data []byte
+	var attempt uint
+	for {
+		data, err = c.do(ctx, path, func(ctx context.Context) ([]byte, int, error) {
+			return c.client.Get(ctx, path, opts...)
+		})
+		if err == nil || !errors.Is(err, apperror.ErrUpstreamDown) || attempt >= c.maxRetries || ctx.Err() != nil {
+			return data, err
+		}
+
+		if c.metrics != nil {
+			c.metrics.Inc(Event_Retry)
+		}
+		timer := time.NewTimer(c.retryDelay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+		attempt++
+	}
+}
+
+// Post requests the path once, because a POST is not idempotent; it returns the body of a response with the status 200
+func (c *Client) Post(ctx context.Context, path string, reqObj interface{}, opts ...httpclient.RequestOption) ([]byte, error) {
+	return c.do(ctx, path, func(ctx context.Context) ([]byte, int, error) {
+		return c.client.Post(ctx, path, reqObj, opts...)
+	})
+}
+
+// GetJSON gets the path and decodes the response into resp; see decode.
+// The body is returned together with the errors of the decoding, so the raw response can be inspected.
+func (c *Client) GetJSON(ctx context.Context, path string, resp interface{}, opts ...httpclient.RequestOption) ([]byte, error) {
+	data, err := c.Get(ctx, path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return data, c.decode(ctx, path, data, resp)
+}
+
+// PostJSON posts the request object and decodes the response into resp; see decode
+func (c *Client) PostJSON(ctx context.Context, path string, reqObj interface{}, resp interface{}, opts ...httpclient.RequestOption) ([]byte, error) {
+	data, err := c.Post(ctx, path, reqObj, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return data, c.decode(ctx, path, data, resp)
+}
+
+// decode unmarshals the body into resp; for a StatusResponse it records the spent credits to the limiter and to the counter of the context
+// and returns the error of the status field
+func (c *Client) decode(ctx context.Context, path string, data []byte, resp interface{}) error {
+	if err := json.Unmarshal(data, resp); err != nil {
+		return fmt.Errorf("[%w] %s json.Unmarshal error: %w; path: %s; response: %s", apperror.ErrBadPayload, c.name, err, path, string(data))
+	}
+
+	statusResp, ok := resp.(StatusResponse)
+	if !ok {
+		return nil
+	}
+	if c.limiter != nil {
+		c.limiter.AddCredits(ctx, statusResp.Credits())
+	}
+	if err := statusResp.Err(); err != nil {
+		return fmt.Errorf("%s %w; path: %s; response: %s", c.name, err, path, string(data))
+	}
+	return nil
+}
+
+// do executes one attempt of the request through the breaker and the rate limiter with the timeout
+func (c *Client) do(ctx context.Context, path string, request func(ctx context.Context) ([]byte, int, error)) ([]byte, error) {
+	if err := c.breaker.Allow(); err != nil {
+		if c.metrics != nil {
+			c.metrics.Inc(Event_Rejected)
+		}
+		return nil, fmt.Errorf("%s %w; path: %s", c.name, err, path)
+	}
+
+	// the limiter waits and the pauses after 429 are bounded by the context of the caller only: the timeout covers an http attempt,
+	// so a long wait for the limiter is neither a timeout nor a failure of the upstream
+	attempt := func() ([]byte, int, error) {
+		attemptCtx := ctx
+		if c.requestTimeout > 0 {
+			var cancel context.CancelFunc
+			attemptCtx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+			defer cancel()
+		}
+		return request(attemptCtx)
+	}
+
+	var data []byte
+	var code int
+	var err error
+	if c.limiter != nil {
+		data, code, err = c.limiter.Do(ctx, attempt)
+	} else {
+		data, code, err = attempt()
+	}
+
+	if code == 0 && ctx.Err() != nil {
+		// the request is cancelled by the caller, it says nothing about the upstream
+		c.breaker.Cancel()
+		return nil, fmt.Errorf("%s request is cancelled; path: %s; error: %w", c.name, path, errors.Join(err, ctx.Err()))
+	}
+
+	err = classify(c.name, path, data, code, err)
+	if errors.Is(err, apperror.ErrUpstreamDown) {
+		c.breaker.Failure()
+	} else {
+		c.breaker.Success()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// classify maps the result of the request onto the error taxonomy
+func classify(name string, path string, data []byte, code int, err error) error {
+	switch {
+	case err == nil && code == http.StatusOK:
+		return nil
+	case code == http.StatusTooManyRequests:
+		return fmt.Errorf("[%w] %s http response error code: %d; path: %s; response: %s", apperror.ErrRateLimited, name, code, path, string(data))
+	case code == 0 || code >= http.StatusInternalServerError:
+		return fmt.Errorf("[%w] %s http error code: %d; path: %s; error: %w; response: %s", apperror.ErrUpstreamDown, name, code, path, err, string(data))
+	}
+	return fmt.Errorf("[%w] %s http response error code: %d; path: %s; response: %s", apperror.ErrInternal, name, code, path, string(data))
+}
+
+// retryDelay is the full jitter of the exponential backoff: a random delay up to base * 2^attempt, but not more than the max delay
+func (c *Client) retryDelay(attempt uint) time.Duration {
+	d := c.retryMaxDelay
+	if attempt < 32 {
+		if backoff := c.retryBaseDelay << attempt; backoff > 0 && backoff < d {
+			d = backoff
+		}
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
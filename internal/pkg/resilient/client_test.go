@@ -0,0 +1,221 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minipkg/httpclient"
+
+	"info/internal/pkg/apperror"
+	"info/internal/pkg/ratelimit"
+)
+
+type fakeResponse struct {
+	data []byte
+	code int
+	err  error
+}
+
+// fakeHttpClient returns the responses in order, the last one is repeated
+type fakeHttpClient struct {
+	mu        sync.Mutex
+	responses []fakeResponse
+	calls     int
+}
+
+func (c *fakeHttpClient) next() ([]byte, int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := c.calls
+	if i >= len(c.responses) {
+		i = len(c.responses) - 1
+	}
+	c.calls++
+	return c.responses[i].data, c.responses[i].code, c.responses[i].err
+}
+
+func (c *fakeHttpClient) Get(ctx context.Context, path string, opts ...httpclient.RequestOption) ([]byte, int, error) {
+	return c.next()
+}
+
+func (c *fakeHttpClient) Post(ctx context.Context, path string, reqObj interface{}, opts ...httpclient.RequestOption) ([]byte, int, error) {
+	return c.next()
+}
+
+var (
+	respOK          = fakeResponse{data: []byte(`{"status":{"error_code":0,"credit_count":2}}`), code: http.StatusOK}
+	respDown        = fakeResponse{data: []byte(`down`), code: http.StatusBadGateway, err: errors.New("http request failed with code 502")}
+	respNetwork     = fakeResponse{err: errors.New("error executing request: dial tcp: connection refused")}
+	respRateLimited = fakeResponse{data: []byte(`slow down`), code: http.StatusTooManyRequests, err: errors.New("http request failed with code 429")}
+	respNotFound    = fakeResponse{data: []byte(`not found`), code: http.StatusNotFound, err: errors.New("http request failed with code 404")}
+	respMalformed   = fakeResponse{data: []byte(`{"status":`), code: http.StatusOK}
+	respStatusError = fakeResponse{data: []byte(`{"status":{"error_code":1008,"credit_count":1}}`), code: http.StatusOK}
+)
+
+type testStatus struct {
+	ErrorCode   int  `json:"error_code"`
+	CreditCount uint `json:"credit_count"`
+}
+
+type testResponse struct {
+	Status testStatus `json:"status"`
+}
+
+func (r *testResponse) Err() error {
+	if r.Status.ErrorCode != 0 {
+		return apperror.ErrInternal
+	}
+	return nil
+}
+
+func (r *testResponse) Credits() uint {
+	return r.Status.CreditCount
+}
+
+func newTestClient(fake *fakeHttpClient, maxRetries uint) *Client {
+	return New("test", Config{
+		MaxRetries:     maxRetries,
+		RetryBaseDelay: time.Millisecond,
+		RetryMaxDelay:  time.Millisecond,
+		Breaker:        BreakerConfig{FailureThreshold: 100},
+	}, fake, nil, nil)
+}
+
+func TestClient_GetJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []fakeResponse
+		retries   uint
+		wantErr   error
+		wantCalls int
+	}{
+		{name: "success", responses: []fakeResponse{respOK}, retries: 2, wantCalls: 1},
+		{name: "retry after 5xx", responses: []fakeResponse{respDown, respNetwork, respOK}, retries: 2, wantCalls: 3},
+		{name: "retries are exhausted", responses: []fakeResponse{respDown}, retries: 2, wantErr: apperror.ErrUpstreamDown, wantCalls: 3},
+		{name: "rate limited is not retried", responses: []fakeResponse{respRateLimited}, retries: 2, wantErr: apperror.ErrRateLimited, wantCalls: 1},
+		{name: "4xx is not retried", responses: []fakeResponse{respNotFound}, retries: 2, wantErr: apperror.ErrInternal, wantCalls: 1},
+		{name: "malformed", responses: []fakeResponse{respMalformed}, retries: 2, wantErr: apperror.ErrBadPayload, wantCalls: 1},
+		{name: "status error", responses: []fakeResponse{respStatusError}, retries: 2, wantErr: apperror.ErrInternal, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeHttpClient{responses: tt.responses}
+			_, err := newTestClient(fake, tt.retries).GetJSON(context.Background(), "/path", &testResponse{})
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("GetJSON error = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetJSON error = %v, want %v", err, tt.wantErr)
+			}
+			if fake.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", fake.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClient_PostIsNotRetried(t *testing.T) {
+	fake := &fakeHttpClient{responses: []fakeResponse{respDown, respOK}}
+	_, err := newTestClient(fake, 3).PostJSON(context.Background(), "/path", struct{}{}, &testResponse{})
+	if !errors.Is(err, apperror.ErrUpstreamDown) {
+		t.Errorf("PostJSON error = %v, want %v", err, apperror.ErrUpstreamDown)
+	}
+	if fake.calls != 1 {
+		t.Errorf("calls = %d, want 1", fake.calls)
+	}
+}
+
+func TestClient_BreakerOpens(t *testing.T) {
+	fake := &fakeHttpClient{responses: []fakeResponse{respDown}}
+	c := New("test", Config{Breaker: BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Hour}}, fake, nil, nil)
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.Get(context.Background(), "/path"); !errors.Is(err, apperror.ErrUpstreamDown) {
+			t.Fatalf("request %d: error = %v, want %v", i, err, apperror.ErrUpstreamDown)
+		}
+	}
+	if fake.calls != 2 {
+		t.Errorf("calls = %d, want 2: the request after the opening of the breaker has to be rejected", fake.calls)
+	}
+	if c.BreakerState() != BreakerState_Open {
+		t.Errorf("breaker state = %s, want %s", c.BreakerState(), BreakerState_Open)
+	}
+}
+
+func TestClient_CancelledRequestDoesNotOpenBreaker(t *testing.T) {
+	fake := &fakeHttpClient{responses: []fakeResponse{respNetwork}}
+	c := New("test", Config{MaxRetries: 3, Breaker: BreakerConfig{FailureThreshold: 1}}, fake, nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Get(ctx, "/path"); err == nil {
+		t.Fatalf("Get error = nil, want an error")
+	}
+	if c.BreakerState() != BreakerState_Closed {
+		t.Errorf("breaker state = %s, want %s", c.BreakerState(), BreakerState_Closed)
+	}
+}
+
+func TestClient_LimiterWaitIsNotTimeout(t *testing.T) {
+	fake := &fakeHttpClient{responses: []fakeResponse{respOK}}
+	limiter := ratelimit.New(ratelimit.Config{})
+	c := New("test", Config{RequestTimeout: 20 * time.Millisecond, Breaker: BreakerConfig{FailureThreshold: 1}}, fake, limiter, nil)
+
+	limiter.Pause(100 * time.Millisecond)
+	if _, err := c.Get(context.Background(), "/path"); err != nil {
+		t.Fatalf("Get error = %v, want nil: the wait for the limiter is not a part of the request timeout", err)
+	}
+	if c.BreakerState() != BreakerState_Closed {
+		t.Errorf("breaker state = %s, want %s", c.BreakerState(), BreakerState_Closed)
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	states := make([]BreakerState, 0)
+	b := NewBreaker(BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute}, func(state BreakerState) {
+		states = append(states, state)
+	})
+	b.now = func() time.Time { return now }
+
+	b.Failure()
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow after 1 failure: %v", err)
+	}
+	b.Failure()
+	if err := b.Allow(); !errors.Is(err, apperror.ErrUpstreamDown) {
+		t.Fatalf("Allow of the open breaker = %v, want %v", err, apperror.ErrUpstreamDown)
+	}
+
+	now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow of the probe: %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, apperror.ErrUpstreamDown) {
+		t.Fatalf("Allow during the probe = %v, want %v", err, apperror.ErrUpstreamDown)
+	}
+	b.Failure()
+	if b.State() != BreakerState_Open {
+		t.Fatalf("state after the failed probe = %s, want %s", b.State(), BreakerState_Open)
+	}
+
+	now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow of the probe: %v", err)
+	}
+	b.Success()
+
+	want := []BreakerState{BreakerState_Open, BreakerState_HalfOpen, BreakerState_Open, BreakerState_HalfOpen, BreakerState_Closed}
+	if len(states) != len(want) {
+		t.Fatalf("states = %v, want %v", states, want)
+	}
+	for i := range want {
+		if states[i] != want[i] {
+			t.Fatalf("states = %v, want %v", states, want)
+		}
+	}
+}
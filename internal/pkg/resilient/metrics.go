@@ -0,0 +1,49 @@
+package resilient
+
+import (
+	prometheus_utils "github.com/minipkg/prometheus-utils"
+)
+
+const (
+	Event_Retry    = "retry"
+	Event_Rejected = "rejected"
+)
+
+// Metrics reports the state of the breaker and the events of the client
+type Metrics interface {
+	SetBreakerState(state BreakerState)
+	Inc(event string)
+}
+
+type metrics struct {
+	gauge   prometheus_utils.Gauge
+	counter prometheus_utils.Counter
+}
+
+var _ Metrics = (*metrics)(nil)
+
+// NewMetrics creates the prometheus metrics of the client of the upstream: the breaker state gauge, where the current state is 1 and the others are 0,
+// and the counter of the retries, the rejected requests and the transitions of the breaker
+func NewMetrics(namespace, subsystem, service, upstream string) *metrics {
+	m := &metrics{
+		gauge:   prometheus_utils.NewGauge(namespace, subsystem, service, "circuit_breaker_state", upstream, "state"),
+		counter: prometheus_utils.NewCounter(namespace, subsystem, service, "circuit_breaker_events", upstream, "event"),
+	}
+	m.SetBreakerState(BreakerState_Closed)
+	return m
+}
+
+func (m *metrics) SetBreakerState(state BreakerState) {
+	var item BreakerState
+	for _, item = range BreakerStateList {
+		val := 0.0
+		if item == state {
+			val = 1
+		}
+		m.gauge.Set(item.String(), val)
+	}
+}
+
+func (m *metrics) Inc(event string) {
+	m.counter.Inc(event)
+}
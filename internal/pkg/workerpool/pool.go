@@ -0,0 +1,146 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	prometheus_utils "github.com/minipkg/prometheus-utils"
+
+	"info/internal/pkg/apperror"
+)
+
+const (
+	defaultWorkersNb = 1
+
+	metricsSuccess = "true"
+	metricsFail    = "false"
+
+	valueName_Total  = "total"
+	valueName_Done   = "done"
+	valueName_Failed = "failed"
+)
+
+type Config struct {
+	WorkersNb uint // 0 - 1
+}
+
+// Metrics reports the progress of the jobs
+type Metrics interface {
+	Start(job string, total int)
+	Done(job string, err error)
+}
+
+// Pool executes the items of a job by a bounded number of workers
+type Pool struct {
+	workersNb int
+	metrics   Metrics
+}
+
+func New(cfg Config, metrics Metrics) *Pool {
+	workersNb := int(cfg.WorkersNb)
+	if workersNb < defaultWorkersNb {
+		workersNb = defaultWorkersNb
+	}
+	return &Pool{
+		workersNb: workersNb,
+		metrics:   metrics,
+	}
+}
+
+func (p *Pool) WorkersNb() int {
+	return p.workersNb
+}
+
+// Run executes fn for every index from 0 to total-1 and returns the joined errors of all the items.
+// A panic in fn is returned as an error of the item; the items which are not started yet are skipped after the context is done.
+func (p *Pool) Run(ctx context.Context, job string, total int, fn func(ctx context.Context, i int) error) error {
+	if total <= 0 {
+		return nil
+	}
+	if p.metrics != nil {
+		p.metrics.Start(job, total)
+	}
+
+	workersNb := p.workersNb
+	if workersNb > total {
+		workersNb = total
+	}
+
+	indexes := make(chan int)
+	errs := make([]error, total)
+	wg := &sync.WaitGroup{}
+
+	for w := 0; w < workersNb; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = p.exec(ctx, job, i, fn)
+			}
+		}()
+	}
+
+	var ctxErr error
+loop:
+	for i := 0; i < total; i++ {
+		select {
+		case <-ctx.Done():
+			ctxErr = ctx.Err()
+			break loop
+		case indexes <- i:
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	return errors.Join(errors.Join(errs...), ctxErr)
+}
+
+func (p *Pool) exec(ctx context.Context, job string, i int, fn func(ctx context.Context, i int) error) (err error) {
+	const metricName = "workerpool.Pool.exec"
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Join(err, fmt.Errorf("[%w] "+metricName+" Recover from panic: %v; stacktrace from panic: %s", apperror.ErrInternal, r, string(debug.Stack())))
+		}
+		if p.metrics != nil {
+			p.metrics.Done(job, err)
+		}
+	}()
+
+	return fn(ctx, i)
+}
+
+type metrics struct {
+	counter prometheus_utils.Counter
+	gauge   prometheus_utils.Gauge
+}
+
+var _ Metrics = (*metrics)(nil)
+
+// NewMetrics creates the prometheus metrics of the pool: the counter of the executed items and the progress gauge of the current run of every job
+func NewMetrics(namespace, subsystem, service string) *metrics {
+	return &metrics{
+		counter: prometheus_utils.NewCounter(namespace, subsystem, service, "workerpool_items", "workerpool", "job", "success"),
+		gauge:   prometheus_utils.NewGauge(namespace, subsystem, service, "workerpool_progress", "workerpool", "job", "value_name"),
+	}
+}
+
+func (m *metrics) Start(job string, total int) {
+	m.gauge.SetWithLabelValues(&[]string{job, valueName_Total}, float64(total))
+	m.gauge.SetWithLabelValues(&[]string{job, valueName_Done}, 0)
+	m.gauge.SetWithLabelValues(&[]string{job, valueName_Failed}, 0)
+}
+
+func (m *metrics) Done(job string, err error) {
+	m.gauge.AddWithLabelValues(&[]string{job, valueName_Done}, 1)
+	if err != nil {
+		m.gauge.AddWithLabelValues(&[]string{job, valueName_Failed}, 1)
+		m.counter.Inc(job, metricsFail)
+		return
+	}
+	m.counter.Inc(job, metricsSuccess)
+}
@@ -0,0 +1,135 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"info/internal/pkg/apperror"
+)
+
+func TestPool_Run(t *testing.T) {
+	p := New(Config{WorkersNb: 3}, nil)
+
+	var running, maxRunning atomic.Int64
+	done := make([]bool, 10)
+	err := p.Run(context.Background(), "test", len(done), func(ctx context.Context, i int) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		done[i] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	for i := range done {
+		if !done[i] {
+			t.Fatalf("item %d is not executed", i)
+		}
+	}
+	if maxRunning.Load() > 3 {
+		t.Fatalf("got %d items executed at once, want at most 3", maxRunning.Load())
+	}
+}
+
+func TestPool_RunErrors(t *testing.T) {
+	p := New(Config{WorkersNb: 2}, nil)
+	errItem := errors.New("item error")
+
+	var executed atomic.Int64
+	err := p.Run(context.Background(), "test", 5, func(ctx context.Context, i int) error {
+		executed.Add(1)
+		switch i {
+		case 1:
+			return errItem
+		case 3:
+			panic("item panic")
+		}
+		return nil
+	})
+	if executed.Load() != 5 {
+		t.Fatalf("got %d executed items, want 5: an error of an item does not stop the others", executed.Load())
+	}
+	if !errors.Is(err, errItem) {
+		t.Fatalf("Run() error = %v, want the error of the item", err)
+	}
+	if !errors.Is(err, apperror.ErrInternal) {
+		t.Fatalf("Run() error = %v, want the panic as %v", err, apperror.ErrInternal)
+	}
+}
+
+func TestPool_RunCancelled(t *testing.T) {
+	p := New(Config{WorkersNb: 1}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var executed atomic.Int64
+	err := p.Run(ctx, "test", 10, func(ctx context.Context, i int) error {
+		executed.Add(1)
+		if i == 2 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() error = %v, want %v", err, context.Canceled)
+	}
+	// the items which are not started after the cancel are skipped; one more item might be already passed to the worker
+	if n := executed.Load(); n < 3 || n > 4 {
+		t.Fatalf("got %d executed items, want 3 or 4", n)
+	}
+}
+
+type fakeMetrics struct {
+	mu     sync.Mutex
+	total  int
+	done   int
+	failed int
+}
+
+func (m *fakeMetrics) Start(job string, total int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.total += total
+}
+
+func (m *fakeMetrics) Done(job string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.done++
+	if err != nil {
+		m.failed++
+	}
+}
+
+func TestPool_RunMetrics(t *testing.T) {
+	m := &fakeMetrics{}
+	p := New(Config{}, m)
+	if p.WorkersNb() != defaultWorkersNb {
+		t.Fatalf("got %d workers, want %d", p.WorkersNb(), defaultWorkersNb)
+	}
+
+	_ = p.Run(context.Background(), "test", 4, func(ctx context.Context, i int) error {
+		if i%2 == 0 {
+			return errors.New("item error")
+		}
+		return nil
+	})
+	if m.total != 4 || m.done != 4 || m.failed != 2 {
+		t.Fatalf("got %+v", m)
+	}
+
+	if err := p.Run(context.Background(), "empty", 0, nil); err != nil || m.total != 4 {
+		t.Fatalf("empty job: got %v, %+v", err, m)
+	}
+}
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+create table cmc.import_error
+(
+    currency_id   integer   not null,
+    dataset       text      not null,
+    error_count   integer   not null default 0,
+    last_error    text      null,
+    last_error_at timestamp null,
+    primary key (currency_id, dataset)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+drop table cmc.import_error;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+create table oracul.import_max_time
+(
+    currency_id                         bigint                  not null,
+    blockchain                          text                    not null,
+    daily_balance_stats                 date                    null,
+    CONSTRAINT import_max_time__currency_id__fk FOREIGN KEY (currency_id) REFERENCES cmc.currency(id)
+);
+
+create unique index import_max_time__currency_id__blockchain__pk ON oracul.import_max_time (currency_id, blockchain) include (daily_balance_stats);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+drop table oracul.import_max_time;
+-- +goose StatementEnd
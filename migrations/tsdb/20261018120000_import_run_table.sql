@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+create table cmc.import_run
+(
+    id                                  bigserial               primary key,
+    stage                               text                    not null,
+    started_at                          timestamp               not null,
+    ended_at                            timestamp               null,
+    currencies_attempted                bigint                  not null default 0,
+    currencies_succeeded                bigint                  not null default 0,
+    currencies_failed                   bigint                  not null default 0,
+    rows_upserted                       bigint                  not null default 0,
+    credits_used                        bigint                  not null default 0,
+    error                               text                    null
+);
+
+create index import_run__started_at__idx ON cmc.import_run (started_at desc);
+create index import_run__stage__started_at__idx ON cmc.import_run (stage, started_at desc);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+drop table cmc.import_run;
+-- +goose StatementEnd
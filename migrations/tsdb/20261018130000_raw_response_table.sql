@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+create table cmc.raw_response
+(
+    id                                  bigserial               primary key,
+    source                              text                    not null,
+    endpoint                            text                    not null,
+    currency_id                         bigint                  not null default 0,
+    fetched_at                          timestamp               not null,
+    body                                bytea                   not null
+);
+
+create index raw_response__endpoint__currency_id__fetched_at__idx ON cmc.raw_response (endpoint, currency_id, fetched_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+drop table cmc.raw_response;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+alter table cmc.price_and_cap add column source text not null default 'cmc';
+
+-- the unknown cap (e.g. of the Binance prices) is NULL instead of 0, so the consumers of the cap do not take it for a real value
+alter table cmc.price_and_cap alter column cap drop not null;
+update cmc.price_and_cap set cap = NULL where cap = 0;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+update cmc.price_and_cap set cap = 0 where cap is NULL;
+alter table cmc.price_and_cap alter column cap set not null;
+
+alter table cmc.price_and_cap drop column source;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+create table cmc.price_divergence
+(
+    currency_id                         bigint                  not null,
+    day                                 date                    not null,
+    source                              text                    not null,
+    price                               double precision        not null,
+    ref_price                           double precision        not null,
+    price_diff                          double precision        not null,
+    cap                                 double precision        not null,
+    ref_cap                             double precision        not null,
+    cap_diff                            double precision        not null,
+    checked_at                          timestamp               not null,
+    CONSTRAINT price_divergence__currency_id__fk FOREIGN KEY (currency_id) REFERENCES cmc.currency(id)
+);
+
+create unique index price_divergence__currency_id__day__source__pk ON cmc.price_divergence (currency_id, day, source);
+create index price_divergence__day__idx ON cmc.price_divergence (day);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+drop table cmc.price_divergence;
+-- +goose StatementEnd
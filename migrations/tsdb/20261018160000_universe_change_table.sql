@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+create table cmc.universe_change
+(
+    id                                  bigserial               primary key,
+    currency_id                         bigint                  not null,
+    slug                                text                    not null,
+    action                              text                    not null,
+    reason                              text                    not null,
+    cmc_rank                            bigint                  not null default 0,
+    market_cap                          double precision        not null default 0,
+    volume_24h                          double precision        not null default 0,
+    changed_at                          timestamp               not null,
+    CONSTRAINT universe_change__currency_id__fk FOREIGN KEY (currency_id) REFERENCES cmc.currency(id)
+);
+
+create index universe_change__changed_at__idx ON cmc.universe_change (changed_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+drop table cmc.universe_change;
+-- +goose StatementEnd
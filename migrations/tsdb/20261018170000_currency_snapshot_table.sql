@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+create table cmc.currency_snapshot
+(
+    currency_id                         bigint                  not null,
+    cmc_rank                            bigint                  not null,
+    circulating_supply                  double precision        not null,
+    self_reported_circulating_supply    double precision        not null,
+    total_supply                        double precision        not null,
+    max_supply                          double precision        null,
+    latest_price                        double precision        not null,
+    ts                                  timestamp               not null,
+    CONSTRAINT currency_snapshot__currency_id__fk FOREIGN KEY (currency_id) REFERENCES cmc.currency(id)
+);
+
+create unique index currency_snapshot__currency_id__ts__pk ON cmc.currency_snapshot (currency_id, ts);
+
+select public.create_hypertable('cmc.currency_snapshot', 'ts', chunk_time_interval => INTERVAL '1 year');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+drop table cmc.currency_snapshot;
+-- +goose StatementEnd
@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+create table cmc.exchange
+(
+    id                                  bigint                  not null,
+    name                                text                    not null,
+    slug                                text                    not null,
+    CONSTRAINT exchange__id__pk PRIMARY KEY (id)
+);
+
+
+create table cmc.market_pair
+(
+    currency_id                         bigint                  not null,
+    exchange_id                         bigint                  not null,
+    market_id                           bigint                  not null,
+    pair                                text                    not null,
+    category                            text                    not null,
+    base_symbol                         text                    not null,
+    quote_symbol                        text                    not null,
+    price                               double precision        not null,
+    volume_usd                          double precision        not null,
+    depth_negative_two                  double precision        not null,
+    depth_positive_two                  double precision        not null,
+    ts                                  timestamp               not null,
+    CONSTRAINT market_pair__currency_id__fk FOREIGN KEY (currency_id) REFERENCES cmc.currency(id),
+    CONSTRAINT market_pair__exchange_id__fk FOREIGN KEY (exchange_id) REFERENCES cmc.exchange(id)
+);
+
+create unique index market_pair__currency_id__market_id__ts__pk ON cmc.market_pair (currency_id, market_id, ts);
+create index market_pair__currency_id__ts__idx ON cmc.market_pair (currency_id, ts desc);
+
+select public.create_hypertable('cmc.market_pair', 'ts', chunk_time_interval => INTERVAL '1 month');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+drop table cmc.market_pair;
+drop table cmc.exchange;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+create table cmc.global_metrics
+(
+    total_market_cap                    double precision        not null,
+    total_volume_24h                    double precision        not null,
+    btc_dominance                       double precision        not null,
+    eth_dominance                       double precision        not null,
+    stablecoin_market_cap               double precision        null,
+    defi_market_cap                     double precision        null,
+    ts                                  timestamp               not null
+);
+
+create unique index global_metrics__ts__pk ON cmc.global_metrics (ts);
+
+select public.create_hypertable('cmc.global_metrics', 'ts', chunk_time_interval => INTERVAL '1 year');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+drop table cmc.global_metrics;
+-- +goose StatementEnd
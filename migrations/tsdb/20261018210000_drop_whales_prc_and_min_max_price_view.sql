@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- the distance from the extremes is calculated by the currency service: /api/v1/cmc/report/ath-atl
+DROP VIEW cmc.whales_prc_and_min_max_price;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+CREATE VIEW cmc.whales_prc_and_min_max_price AS
+with d as (
+    select currency_id, max(d) as last_d
+    from cmc.concentration
+    group by currency_id),
+     whales as (
+         select currency_id, d, whales * 100 / (whales + investors + retail) as whales_prc
+         from cmc.concentration),
+     ts as (
+         select currency_id, max(ts) as last_ts
+         from cmc.price_and_cap
+         group by currency_id),
+     min_max as (
+         select currency_id, min(price) as min_price, max(price) as max_price
+         from cmc.price_and_cap
+         group by currency_id),
+     price as (
+         select currency_id, price, ts
+         from cmc.price_and_cap
+     )
+select c.id, c.symbol, round(cast(whales.whales_prc AS numeric), 2) as whales_prc, price.price, min_max.min_price, min_max.max_price,
+       round(cast(min_max.max_price/price.price AS numeric)) as to_ath, round(cast(price.price/min_max.min_price AS numeric)) as from_atl
+from cmc.currency c
+         inner join ts on c.id = ts.currency_id
+         inner join price on ts.currency_id = price.currency_id and ts.last_ts = price.ts
+         left join d on c.id = d.currency_id
+         left join whales on d.currency_id = whales.currency_id and d.last_d = whales.d
+         inner join min_max on c.id = min_max.currency_id
+where c.is_for_observing = true;
+-- +goose StatementEnd
@@ -0,0 +1,73 @@
+-- creating and refreshing the continuous aggregates is not allowed inside a transaction
+-- +goose NO TRANSACTION
+-- +goose Up
+SELECT 'up SQL query';
+
+-- 1h OHLCV candles; volume is the average of the rolling daily volume of the points, the cap is the last known cap in the bucket
+create materialized view cmc.price_and_cap_1h
+with (timescaledb.continuous) as
+select currency_id,
+       public.time_bucket(INTERVAL '1 hour', ts) as ts,
+       public.first(price, ts)                   as open,
+       max(price)                                as high,
+       min(price)                                as low,
+       public.last(price, ts)                    as close,
+       avg(daily_volume)                         as volume,
+       public.last(cap, ts) filter (where cap is not null) as cap
+from cmc.price_and_cap
+where price > 0
+group by currency_id, public.time_bucket(INTERVAL '1 hour', ts)
+with no data;
+
+select public.add_continuous_aggregate_policy('cmc.price_and_cap_1h', start_offset => INTERVAL '3 days', end_offset => INTERVAL '1 hour', schedule_interval => INTERVAL '30 minutes');
+
+-- 1d OHLCV candles; volume is the average of the rolling daily volume of the points, the cap is the last known cap in the bucket
+create materialized view cmc.price_and_cap_1d
+with (timescaledb.continuous) as
+select currency_id,
+       public.time_bucket(INTERVAL '1 day', ts)  as ts,
+       public.first(price, ts)                   as open,
+       max(price)                                as high,
+       min(price)                                as low,
+       public.last(price, ts)                    as close,
+       avg(daily_volume)                         as volume,
+       public.last(cap, ts) filter (where cap is not null) as cap
+from cmc.price_and_cap
+where price > 0
+group by currency_id, public.time_bucket(INTERVAL '1 day', ts)
+with no data;
+
+select public.add_continuous_aggregate_policy('cmc.price_and_cap_1d', start_offset => INTERVAL '7 days', end_offset => INTERVAL '1 hour', schedule_interval => INTERVAL '1 hour');
+
+-- 1w OHLCV candles; volume is the average of the rolling daily volume of the points, the cap is the last known cap in the bucket
+create materialized view cmc.price_and_cap_1w
+with (timescaledb.continuous) as
+select currency_id,
+       public.time_bucket(INTERVAL '1 week', ts) as ts,
+       public.first(price, ts)                   as open,
+       max(price)                                as high,
+       min(price)                                as low,
+       public.last(price, ts)                    as close,
+       avg(daily_volume)                         as volume,
+       public.last(cap, ts) filter (where cap is not null) as cap
+from cmc.price_and_cap
+where price > 0
+group by currency_id, public.time_bucket(INTERVAL '1 week', ts)
+with no data;
+
+select public.add_continuous_aggregate_policy('cmc.price_and_cap_1w', start_offset => INTERVAL '28 days', end_offset => INTERVAL '1 hour', schedule_interval => INTERVAL '1 day');
+
+-- the history; the older changes of the backfill and the reparse are refreshed by PriceAndCapRepository.RefreshCandles
+call public.refresh_continuous_aggregate('cmc.price_and_cap_1h', NULL, NULL);
+call public.refresh_continuous_aggregate('cmc.price_and_cap_1d', NULL, NULL);
+call public.refresh_continuous_aggregate('cmc.price_and_cap_1w', NULL, NULL);
+
+-- +goose Down
+SELECT 'down SQL query';
+
+select public.remove_continuous_aggregate_policy('cmc.price_and_cap_1h');
+select public.remove_continuous_aggregate_policy('cmc.price_and_cap_1d');
+select public.remove_continuous_aggregate_policy('cmc.price_and_cap_1w');
+drop materialized view cmc.price_and_cap_1h;
+drop materialized view cmc.price_and_cap_1d;
+drop materialized view cmc.price_and_cap_1w;
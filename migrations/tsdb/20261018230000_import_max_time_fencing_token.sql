@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- the fencing tokens of the stage locks of the last import of the datasets; the checkpoint is not moved by a stale token
+alter table cmc.import_max_time add column price_and_cap_fencing_token bigint not null default 0;
+alter table cmc.import_max_time add column concentration_fencing_token bigint not null default 0;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+alter table cmc.import_max_time drop column concentration_fencing_token;
+alter table cmc.import_max_time drop column price_and_cap_fencing_token;
+-- +goose StatementEnd